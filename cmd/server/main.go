@@ -2,47 +2,254 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/effati/willys-mcp/internal/config"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/telemetry"
 	"github.com/effati/willys-mcp/internal/willys"
 	"github.com/effati/willys-mcp/pkg/mcp"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Printf("No .env file found or error loading it: %v", err)
+	purge := flag.Bool("purge", false, "delete all locally stored data and exit")
+	logout := flag.Bool("logout", false, "forget the saved login (refresh token and browser profile) and exit")
+	openAPI := flag.Bool("openapi", false, "print the OpenAPI spec of the REST API and webhook and exit")
+	flag.Parse()
+
+	if *openAPI {
+		spec, err := mcp.OpenAPISpec(true, true)
+		if err != nil {
+			log.Fatalf("Failed to build OpenAPI spec: %v", err)
+		}
+		os.Stdout.Write(spec)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	baseURL := os.Getenv("WILLYS_BASE_URL")
-	if baseURL == "" {
-		baseURL = "https://www.willys.se"
+	if *purge {
+		purgeLocalData(cfg)
+		return
+	}
+	if *logout {
+		forgetLogin(cfg)
+		return
 	}
 
-	username := os.Getenv("WILLYS_USERNAME")
-	if username == "" {
-		log.Fatalf("WILLYS_USERNAME environment variable is required")
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), mcp.ServerVersion)
+	if err != nil {
+		log.Printf("Failed to set up tracing, continuing without it: %v", err)
+	} else {
+		defer shutdownTelemetry(context.Background())
 	}
 
-	password := os.Getenv("WILLYS_PASSWORD")
-	if password == "" {
-		log.Fatalf("WILLYS_PASSWORD environment variable is required")
+	dataStore, err := store.Open(cfg.DataDir)
+	if err != nil {
+		log.Printf("Failed to open data store, falling back to in-memory storage: %v", err)
+		dataStore = store.NewMemory()
+	}
+	defer dataStore.Close()
+
+	selectors, err := willys.LoadSelectors(cfg.SelectorsFile)
+	if err != nil {
+		log.Fatalf("Failed to load browser selectors: %v", err)
+	}
+
+	clientOpts := []willys.ClientOption{
+		willys.WithTransport(cfg.HTTPTransport),
+		willys.WithEndpointOverrides(cfg.Endpoints),
+		willys.WithStrictDecode(cfg.StrictDecode),
+		willys.WithB2B(cfg.B2B),
+		willys.WithDecodeOptions(cfg.Decode),
+		willys.WithBrowserProfileDir(cfg.BrowserProfileDir),
+		willys.WithDiagnosticsDir(diagnosticsDir(cfg)),
+		willys.WithSelectors(selectors),
+		willys.WithBrowserFallback(cfg.BrowserFallback...),
+		willys.WithResponseCache(cfg.ResponseCacheBytes),
+		willys.WithTokenRefreshHandler(func(refreshToken string) {
+			if err := dataStore.Put(store.BucketAuth, store.KeyRefreshToken, []byte(refreshToken)); err != nil {
+				log.Printf("Failed to persist rotated refresh token: %v", err)
+			}
+		}),
+	}
+	if cfg.MobileAPIURL != "" {
+		clientOpts = append(clientOpts, willys.WithGroupHost(cfg.MobileAPIURL, cfg.MobileAPIGroups...))
+	}
+	if cfg.PoliteMode {
+		clientOpts = append(clientOpts, willys.WithThrottle(willys.PoliteRequestInterval, willys.PoliteBackoff))
+	}
+	if cfg.DebugHTTP {
+		clientOpts = append(clientOpts, willys.WithHTTPDump(debugHTTPFile(cfg), willys.DefaultHTTPDumpMaxBytes, willys.DefaultHTTPDumpKeep))
 	}
 
-	client, err := willys.NewClient(baseURL, username, password)
+	client, err := willys.NewClient(cfg.BaseURL, cfg.Username, cfg.Password, clientOpts...)
 	if err != nil {
 		log.Fatalf("Failed to create Willys client: %v", err)
 	}
+	defer client.Close()
 
-	log.Println("Authenticating with Willys (using headless browser)...")
-	if err := client.LoginWithBrowser(context.Background(), username, password); err != nil {
-		log.Fatalf("Authentication failed: %v", err)
+	if cfg.EndpointProbe {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if _, err := client.ProbeEndpoints(ctx); err != nil {
+			log.Printf("Endpoint probe failed: %v", err)
+		}
+		cancel()
+	}
+
+	// Login normally finishes before the server starts. If Willys asks for a verification
+	// code, start serving anyway so the code can arrive through submit_verification_code.
+	loginDone := make(chan error, 1)
+	go func() {
+		loginDone <- authenticate(client, cfg, dataStore)
+	}()
+	select {
+	case err := <-loginDone:
+		if err != nil {
+			log.Fatalf("Authentication failed: %v", err)
+		}
+		log.Println("Successfully authenticated")
+	case <-client.VerificationRequested():
+		log.Println("Willys requested a verification code; waiting for submit_verification_code")
+		go func() {
+			if err := <-loginDone; err != nil {
+				log.Printf("Authentication failed: %v", err)
+				return
+			}
+			log.Println("Successfully authenticated")
+		}()
 	}
-	log.Println("Successfully authenticated")
 
-	server := mcp.NewServer(client)
+	server := mcp.NewServer(client,
+		mcp.WithConfig(cfg, config.Load),
+		mcp.WithStore(dataStore),
+	)
 	if err := server.Start(); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// authenticate prefers a refresh token (the rotated one saved from the last run, then the
+// configured one) and only falls back to the headless browser with a password.
+func authenticate(client *willys.Client, cfg *config.Config, dataStore store.Store) error {
+	var tokens []string
+	if saved, err := dataStore.Get(store.BucketAuth, store.KeyRefreshToken); err == nil {
+		tokens = append(tokens, string(saved))
+	}
+	if cfg.RefreshToken != "" {
+		tokens = append(tokens, cfg.RefreshToken)
+	}
+
+	for _, token := range tokens {
+		log.Println("Authenticating with Willys (using refresh token)...")
+		err := client.LoginWithToken(context.Background(), token)
+		if err == nil {
+			return nil
+		}
+		log.Printf("Refresh token login failed: %v", err)
+	}
+
+	if cfg.Username == "" {
+		return fmt.Errorf("WILLYS_USERNAME environment variable is required")
+	}
+	if cfg.Password == "" {
+		return fmt.Errorf("WILLYS_PASSWORD environment variable is required")
+	}
+
+	log.Println("Authenticating with Willys (using headless browser)...")
+	return client.LoginWithBrowser(context.Background(), cfg.Username, cfg.Password)
+}
+
+// The HTTP dump defaults to a file inside the data dir so --purge removes it too.
+func debugHTTPFile(cfg *config.Config) string {
+	if cfg.DebugHTTPFile != "" {
+		return cfg.DebugHTTPFile
+	}
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = store.DefaultDataDir()
+	}
+	return filepath.Join(dataDir, "http-debug.log")
+}
+
+// Diagnostics default to a directory inside the data dir so --purge removes them too.
+func diagnosticsDir(cfg *config.Config) string {
+	if cfg.DiagnosticsDir != "" {
+		return cfg.DiagnosticsDir
+	}
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = store.DefaultDataDir()
+	}
+	return filepath.Join(dataDir, "diagnostics")
+}
+
+// forgetLogin deletes what lets the next start log in without a password. A refresh token
+// in WILLYS_REFRESH_TOKEN is configuration and stays.
+func forgetLogin(cfg *config.Config) {
+	dataStore, err := store.Open(cfg.DataDir)
+	if err != nil {
+		log.Fatalf("Failed to open data store: %v", err)
+	}
+	defer dataStore.Close()
+
+	if err := dataStore.Delete(store.BucketAuth, store.KeyRefreshToken); err != nil {
+		log.Fatalf("Failed to delete the saved refresh token: %v", err)
+	}
+	if cfg.BrowserProfileDir != "" {
+		if err := os.RemoveAll(cfg.BrowserProfileDir); err != nil {
+			log.Fatalf("Failed to remove browser profile: %v", err)
+		}
+	}
+	if err := os.RemoveAll(diagnosticsDir(cfg)); err != nil {
+		log.Fatalf("Failed to remove diagnostics: %v", err)
+	}
+	log.Println("Logged out: the saved login was deleted")
+}
+
+// exportDir is where export_plan and export_delivery_calendar save files, see
+// mcp.WithConfig.
+func exportDir(cfg *config.Config) string {
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = store.DefaultDataDir()
+	}
+	return filepath.Join(dataDir, "exports")
+}
+
+func purgeLocalData(cfg *config.Config) {
+	dataStore, err := store.Open(cfg.DataDir)
+	if err != nil {
+		log.Fatalf("Failed to open data store: %v", err)
+	}
+	defer dataStore.Close()
+
+	purged, err := store.Purge(dataStore)
+	if err != nil {
+		log.Fatalf("Failed to purge local data: %v", err)
+	}
+	if cfg.BrowserProfileDir != "" {
+		if err := os.RemoveAll(cfg.BrowserProfileDir); err != nil {
+			log.Fatalf("Failed to remove browser profile: %v", err)
+		}
+		purged = append(purged, "browser_profile")
+	}
+	if err := os.RemoveAll(diagnosticsDir(cfg)); err != nil {
+		log.Fatalf("Failed to remove diagnostics: %v", err)
+	}
+	purged = append(purged, "diagnostics")
+	if err := os.RemoveAll(exportDir(cfg)); err != nil {
+		log.Fatalf("Failed to remove exports: %v", err)
+	}
+	purged = append(purged, "exports")
+	log.Printf("Purged local data: %s", strings.Join(purged, ", "))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/config"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestLogoutThenRestartIsLoggedOut(t *testing.T) {
+	tokenRequests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == willys.EndpointOAuthToken {
+			tokenRequests++
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{DataDir: t.TempDir()}
+	dataStore, err := store.Open(cfg.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dataStore.Put(store.BucketAuth, store.KeyRefreshToken, []byte("refresh")); err != nil {
+		t.Fatal(err)
+	}
+	dataStore.Close()
+
+	forgetLogin(cfg)
+
+	// A restart only finds the password login, which isn't configured
+	dataStore, err = store.Open(cfg.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dataStore.Close()
+	client, err := willys.NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = authenticate(client, cfg, dataStore)
+	if err == nil || !strings.Contains(err.Error(), "WILLYS_USERNAME") {
+		t.Errorf("Expected the restart to need a password login, got %v", err)
+	}
+	if tokenRequests != 0 {
+		t.Errorf("Expected no refresh token login, got %d token requests", tokenRequests)
+	}
+}
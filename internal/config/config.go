@@ -0,0 +1,372 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/features"
+	"github.com/effati/willys-mcp/internal/mail"
+	"github.com/effati/willys-mcp/internal/semantic"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/joho/godotenv"
+)
+
+const DefaultBaseURL = "https://www.willys.se"
+
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// mcpLogLevels are the accepted WILLYS_MCP_LOG_LEVEL values: off, then the MCP log
+// levels from most to least verbose.
+var mcpLogLevels = []string{"off", "debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// Config holds the environment-driven server settings. Fields below the credentials
+// can be changed at runtime via Load + reload; the rest require a restart.
+type Config struct {
+	BaseURL  string
+	Username string
+	Password string
+	DataDir  string
+
+	// AppLinkBase is where checkout app links point: the public site for universal
+	// links, or the app's URL scheme
+	AppLinkBase string
+
+	// RefreshToken enables password-free login through the mobile app's OAuth flow
+	RefreshToken string
+
+	BrowserProfileDir string
+	DiagnosticsDir    string
+	SelectorsFile     string
+
+	// BrowserFallback lists endpoints retried through the headless browser when bot
+	// protection blocks the direct REST call
+	BrowserFallback []string
+
+	StrictDecode bool
+	// Decode caps response sizes and can reject responses with unknown fields
+	Decode willys.DecodeOptions
+
+	// DebugHTTP writes sanitized upstream requests and responses to DebugHTTPFile, by
+	// default http-debug.log in the data directory
+	DebugHTTP     bool
+	DebugHTTPFile string
+
+	// Transport is how MCP clients connect: "stdio" or "http". In HTTP mode the server
+	// listens on HTTPAddr, and APIToken protects it and enables the REST facade. Without
+	// APIToken, HTTPAddr must be a loopback address
+	Transport string
+	HTTPAddr  string
+	APIToken  string
+	// HTTPAllowedHosts are host names requests may use besides localhost, IP addresses,
+	// and the HTTPAddr host, e.g. a reverse proxy's name
+	HTTPAllowedHosts []string
+
+	// WebhookSecret enables the add-item webhook in HTTP mode; its items go to the draft
+	// basket WebhookBasket, or the cart when that is empty
+	WebhookSecret string
+	WebhookBasket string
+
+	// GRPCAddr serves the gRPC API next to either MCP transport; empty leaves it off.
+	// It shares APIToken with the HTTP transport
+	GRPCAddr string
+
+	// MCPLogLevel is the lowest level of server log line forwarded to MCP clients as
+	// notifications, until a client sets its own; "off" keeps the log on stderr only
+	MCPLogLevel string
+
+	// ResponseCacheBytes bounds the in-memory cache of deliverability and slot lookups;
+	// zero turns it off
+	ResponseCacheBytes int64
+
+	// HTTPTransport tunes the connection pool to Willys; zero values keep the defaults
+	HTTPTransport willys.TransportOptions
+
+	// Endpoints redirects moved Willys API paths, and EndpointProbe checks at startup
+	// whether the REST API moved
+	Endpoints     willys.EndpointOverrides
+	EndpointProbe bool
+
+	// MobileAPIURL is the mobile-app API gateway, used for the endpoint groups in
+	// MobileAPIGroups instead of BaseURL
+	MobileAPIURL    string
+	MobileAPIGroups []string
+
+	// PoliteMode throttles upstream requests and turns the background pollers off unless
+	// they are enabled explicitly
+	PoliteMode   bool
+	AdminTools   bool
+	Scheduler    bool
+	SlotAutobook bool
+	PickPolicy   willys.PickPolicy
+	PriceLocale  string
+
+	// B2B makes the client act for a business account: deliverability and slots are
+	// requested as business, and checkout defaults to invoice payment
+	B2B bool
+
+	// Features turns experimental subsystems on or off, from WILLYS_FEATURES and the
+	// per-flag WILLYS_FEATURE_<NAME> variables, which win
+	Features features.Flags
+
+	// OutputDetail is the default product detail in tool results: "full" or "compact"
+	OutputDetail string
+
+	// OwnBrand biases ("prefer") or restricts ("only") searches and cart swaps to Axfood's
+	// own brands such as Garant and Eldorado
+	OwnBrand string
+
+	// SemanticMatcher picks the embedding provider used when a list item finds nothing:
+	// "off", "local", or "api" (OpenAI-compatible endpoint)
+	SemanticMatcher string
+	EmbeddingsURL   string
+	EmbeddingsKey   string
+	EmbeddingsModel string
+
+	// Diet lists restrictions the cart is checked against ("vegetarian", "vegan",
+	// "lactose_free", "gluten_free"); conflicts are reported as warnings
+	Diet []string
+
+	// WeeklyBudget in kronor; cart tools warn as the cart total nears it. Zero disables it.
+	WeeklyBudget float64
+
+	// PriceAlertPercent is how much a cart item's price has to rise over what it was last
+	// bought for before cart tools warn
+	PriceAlertPercent float64
+
+	// Mail sends order summaries to SummaryRecipients, the household addresses
+	Mail              mail.Config
+	SummaryRecipients []string
+
+	SearchesPerMinute    int
+	CartMutationsPerHour int
+}
+
+type source map[string]string
+
+// Load reads configuration from the process environment, falling back to a .env file in
+// the working directory. The file is re-read on every call so Load doubles as a reload.
+func Load() (*Config, error) {
+	env, err := godotenv.Read()
+	if err != nil {
+		env = source{}
+	}
+	src := source(env)
+
+	cfg := &Config{
+		BaseURL:  src.get("WILLYS_BASE_URL", DefaultBaseURL),
+		Username: src.get("WILLYS_USERNAME", ""),
+		Password: src.get("WILLYS_PASSWORD", ""),
+		DataDir:  src.get("WILLYS_DATA_DIR", ""),
+
+		AppLinkBase: src.get("WILLYS_APP_LINK_BASE", DefaultBaseURL),
+
+		RefreshToken: src.get("WILLYS_REFRESH_TOKEN", ""),
+
+		BrowserProfileDir: src.get("WILLYS_BROWSER_PROFILE_DIR", ""),
+		DiagnosticsDir:    src.get("WILLYS_DIAGNOSTICS_DIR", ""),
+		SelectorsFile:     src.get("WILLYS_SELECTORS_FILE", ""),
+
+		Transport: src.get("WILLYS_TRANSPORT", TransportStdio),
+		HTTPAddr:  src.get("WILLYS_HTTP_ADDR", "127.0.0.1:8080"),
+		APIToken:  src.get("WILLYS_API_TOKEN", ""),
+
+		HTTPAllowedHosts: splitList(src.get("WILLYS_HTTP_ALLOWED_HOSTS", "")),
+
+		WebhookSecret: src.get("WILLYS_WEBHOOK_SECRET", ""),
+		WebhookBasket: src.get("WILLYS_WEBHOOK_BASKET", ""),
+
+		GRPCAddr: src.get("WILLYS_GRPC_ADDR", ""),
+
+		MCPLogLevel: strings.ToLower(src.get("WILLYS_MCP_LOG_LEVEL", "info")),
+
+		StrictDecode: src.getBool("WILLYS_STRICT_DECODE", false),
+		Decode: willys.DecodeOptions{
+			MaxBytes:              int64(src.getInt("WILLYS_DECODE_MAX_BYTES", 0)),
+			DisallowUnknownFields: src.getBool("WILLYS_DECODE_DISALLOW_UNKNOWN", false),
+		},
+
+		DebugHTTP:     src.getBool("WILLYS_DEBUG_HTTP", false),
+		DebugHTTPFile: src.get("WILLYS_DEBUG_HTTP_FILE", ""),
+
+		ResponseCacheBytes: int64(src.getInt("WILLYS_CACHE_MAX_BYTES", willys.DefaultResponseCacheBytes)),
+
+		HTTPTransport: willys.TransportOptions{
+			MaxIdleConns:        src.getInt("WILLYS_HTTP_MAX_IDLE_CONNS", 0),
+			MaxIdleConnsPerHost: src.getInt("WILLYS_HTTP_MAX_IDLE_CONNS_PER_HOST", 0),
+			MaxConnsPerHost:     src.getInt("WILLYS_HTTP_MAX_CONNS_PER_HOST", 0),
+			IdleConnTimeout:     time.Duration(src.getInt("WILLYS_HTTP_IDLE_TIMEOUT_SECONDS", 0)) * time.Second,
+			DialTimeout:         time.Duration(src.getInt("WILLYS_HTTP_DIAL_TIMEOUT_SECONDS", 0)) * time.Second,
+			DisableHTTP2:        !src.getBool("WILLYS_HTTP2", true),
+		},
+
+		EndpointProbe: src.getBool("WILLYS_ENDPOINT_PROBE", false),
+		MobileAPIURL:  src.get("WILLYS_MOBILE_API_URL", ""),
+
+		PoliteMode:  src.getBool("WILLYS_POLITE_MODE", false),
+		AdminTools:  src.getBool("WILLYS_ADMIN_TOOLS", true),
+		PickPolicy:  willys.DefaultPickPolicy(),
+		PriceLocale: src.get("WILLYS_PRICE_LOCALE", willys.LocaleSwedish),
+		B2B:         src.getBool("WILLYS_B2B", false),
+
+		OutputDetail: src.get("WILLYS_OUTPUT_DETAIL", willys.OutputDetailFull),
+		OwnBrand:     src.get("WILLYS_OWN_BRAND", willys.OwnBrandOff),
+
+		SemanticMatcher: src.get("WILLYS_SEMANTIC_MATCHER", "off"),
+		EmbeddingsURL:   src.get("WILLYS_EMBEDDINGS_URL", ""),
+		EmbeddingsKey:   src.get("WILLYS_EMBEDDINGS_API_KEY", ""),
+		EmbeddingsModel: src.get("WILLYS_EMBEDDINGS_MODEL", "text-embedding-3-small"),
+
+		WeeklyBudget:      src.getFloat("WILLYS_WEEKLY_BUDGET", 0),
+		PriceAlertPercent: src.getFloat("WILLYS_PRICE_ALERT_PERCENT", willys.DefaultPriceAlertPercent),
+
+		Mail: mail.Config{
+			SMTPAddr:     src.get("WILLYS_SMTP_ADDR", ""),
+			SMTPUsername: src.get("WILLYS_SMTP_USERNAME", ""),
+			SMTPPassword: src.get("WILLYS_SMTP_PASSWORD", ""),
+			SendmailPath: src.get("WILLYS_SENDMAIL_PATH", ""),
+			From:         src.get("WILLYS_MAIL_FROM", ""),
+		},
+		SummaryRecipients: splitList(src.get("WILLYS_SUMMARY_RECIPIENTS", "")),
+
+		SearchesPerMinute:    src.getInt("WILLYS_QUOTA_SEARCHES_PER_MINUTE", 30),
+		CartMutationsPerHour: src.getInt("WILLYS_QUOTA_CART_MUTATIONS_PER_HOUR", 200),
+	}
+
+	if cfg.Features, err = features.Parse(src.get("WILLYS_FEATURES", "")); err != nil {
+		return nil, fmt.Errorf("invalid WILLYS_FEATURES: %w", err)
+	}
+	for _, flag := range features.Known {
+		cfg.Features[flag.Name] = src.getBool("WILLYS_FEATURE_"+strings.ToUpper(flag.Name), cfg.Features[flag.Name])
+	}
+
+	cfg.Scheduler = src.getBool("WILLYS_SCHEDULER", !cfg.PoliteMode)
+	cfg.SlotAutobook = src.getBool("WILLYS_SLOT_AUTOBOOK", cfg.Scheduler)
+
+	if endpoints := src.get("WILLYS_BROWSER_FALLBACK", ""); endpoints != "" {
+		cfg.BrowserFallback = splitList(endpoints)
+	}
+	if overrides := src.get("WILLYS_ENDPOINTS", ""); overrides != "" {
+		if cfg.Endpoints, err = willys.ParseEndpointOverrides(overrides); err != nil {
+			return nil, fmt.Errorf("invalid WILLYS_ENDPOINTS: %w", err)
+		}
+	}
+	if cfg.MobileAPIURL != "" {
+		cfg.MobileAPIGroups = splitList(src.get("WILLYS_MOBILE_API_GROUPS", willys.EndpointGroupCart+","+willys.EndpointGroupSlots))
+		if err := willys.ValidateEndpointGroups(cfg.MobileAPIGroups); err != nil {
+			return nil, fmt.Errorf("invalid WILLYS_MOBILE_API_GROUPS: %w", err)
+		}
+	}
+	if strategy := src.get("WILLYS_AUTOPICK_STRATEGY", ""); strategy != "" {
+		cfg.PickPolicy.Strategy = strategy
+	}
+	if brands := src.get("WILLYS_PREFERRED_BRANDS", ""); brands != "" {
+		cfg.PickPolicy.PreferredBrands = splitList(brands)
+	}
+	if diet := src.get("WILLYS_DIET", ""); diet != "" {
+		cfg.Diet = splitList(strings.ToLower(diet))
+	}
+	if cfg.Transport != TransportStdio && cfg.Transport != TransportHTTP {
+		return nil, fmt.Errorf("invalid WILLYS_TRANSPORT %q: use stdio or http", cfg.Transport)
+	}
+	if !slices.Contains(mcpLogLevels, cfg.MCPLogLevel) {
+		return nil, fmt.Errorf("invalid WILLYS_MCP_LOG_LEVEL %q: use off or one of %s", cfg.MCPLogLevel, strings.Join(mcpLogLevels[1:], ", "))
+	}
+	if err := willys.ValidatePickPolicy(cfg.PickPolicy); err != nil {
+		return nil, fmt.Errorf("invalid auto-pick policy: %w", err)
+	}
+	if err := willys.ValidateLocale(cfg.PriceLocale); err != nil {
+		return nil, fmt.Errorf("invalid price locale: %w", err)
+	}
+	if err := willys.ValidateOutputDetail(cfg.OutputDetail); err != nil {
+		return nil, fmt.Errorf("invalid output detail: %w", err)
+	}
+	if err := semantic.ValidateProvider(cfg.SemanticMatcher); err != nil {
+		return nil, fmt.Errorf("invalid semantic matcher: %w", err)
+	}
+	if err := willys.ValidateOwnBrand(cfg.OwnBrand); err != nil {
+		return nil, fmt.Errorf("invalid own-brand mode: %w", err)
+	}
+	if err := willys.ValidateDiet(cfg.Diet); err != nil {
+		return nil, fmt.Errorf("invalid diet: %w", err)
+	}
+	if cfg.WeeklyBudget < 0 {
+		return nil, fmt.Errorf("invalid weekly budget: must not be negative")
+	}
+	if cfg.PriceAlertPercent < 0 {
+		return nil, fmt.Errorf("invalid price alert percentage: must not be negative")
+	}
+	if err := mail.ValidateAddresses(cfg.SummaryRecipients); err != nil {
+		return nil, fmt.Errorf("invalid WILLYS_SUMMARY_RECIPIENTS: %w", err)
+	}
+	if cfg.Mail.From != "" {
+		if err := mail.ValidateAddresses([]string{cfg.Mail.From}); err != nil {
+			return nil, fmt.Errorf("invalid WILLYS_MAIL_FROM: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+func (s source) get(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := s[key]; value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (s source) getBool(key string, defaultValue bool) bool {
+	value := s.get(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+func (s source) getInt(key string, defaultValue int) int {
+	value := s.get(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return i
+}
+
+func (s source) getFloat(key string, defaultValue float64) float64 {
+	value := s.get(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return f
+}
+
+func splitList(value string) []string {
+	parts := strings.Split(value, ",")
+	result := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+	return result
+}
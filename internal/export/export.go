@@ -0,0 +1,115 @@
+// Package export renders plans, shopping lists, carts, and booked deliveries in formats
+// the user can review, print, or import into a calendar.
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+type (
+	Document struct {
+		Title     string
+		Generated time.Time
+		Meals     []string // one meal per entry, e.g. "Måndag: Lasagne"
+		Shopping  []ShoppingLine
+		Cart      *willys.CartSummary
+	}
+
+	ShoppingLine struct {
+		Item     string
+		Quantity int
+		Note     string // e.g. the product picked or "in pantry"
+	}
+)
+
+// escapeCell keeps user text from breaking Markdown table rows.
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+func Markdown(doc Document) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", doc.Title)
+	fmt.Fprintf(&b, "_Generated %s_\n", doc.Generated.Format("2006-01-02 15:04"))
+
+	if len(doc.Meals) > 0 {
+		b.WriteString("\n## Meal plan\n\n")
+		for _, meal := range doc.Meals {
+			fmt.Fprintf(&b, "- %s\n", meal)
+		}
+	}
+
+	if len(doc.Shopping) > 0 {
+		b.WriteString("\n## Shopping list\n\n")
+		for _, line := range doc.Shopping {
+			fmt.Fprintf(&b, "- [ ] %d × %s", line.Quantity, line.Item)
+			if line.Note != "" {
+				fmt.Fprintf(&b, " (%s)", line.Note)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if doc.Cart != nil {
+		b.WriteString("\n## Cart\n\n")
+		if len(doc.Cart.Items) == 0 {
+			b.WriteString("The cart is empty.\n")
+		} else {
+			b.WriteString("| Product | Qty | Price |\n|---|---:|---:|\n")
+			for _, item := range doc.Cart.Items {
+				fmt.Fprintf(&b, "| %s | %d | %s |\n", escapeCell(item.Name), item.Quantity, item.TotalPrice)
+			}
+			b.WriteString("\n")
+			if !doc.Cart.DeliveryFee.IsZero() {
+				fmt.Fprintf(&b, "Delivery fee: %s  \n", doc.Cart.DeliveryFee)
+			}
+			if !doc.Cart.PickingFee.IsZero() {
+				fmt.Fprintf(&b, "Picking fee: %s  \n", doc.Cart.PickingFee)
+			}
+			fmt.Fprintf(&b, "**Total: %s**\n", doc.Cart.FinalTotal)
+		}
+	}
+
+	return b.String()
+}
+
+var htmlTemplate = template.Must(template.New("plan").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border-bottom: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.num, th.num { text-align: right; }
+ul.check { list-style: none; padding-left: 0; }
+ul.check li::before { content: "☐ "; }
+.note { color: #666; }
+</style></head><body>
+<h1>{{.Title}}</h1>
+<p class="note">Generated {{.Generated.Format "2006-01-02 15:04"}}</p>
+{{if .Meals}}<h2>Meal plan</h2><ul>{{range .Meals}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Shopping}}<h2>Shopping list</h2><ul class="check">{{range .Shopping}}<li>{{.Quantity}} × {{.Item}}{{if .Note}} <span class="note">({{.Note}})</span>{{end}}</li>{{end}}</ul>{{end}}
+{{with .Cart}}<h2>Cart</h2>{{if .Items}}<table>
+<tr><th>Product</th><th class="num">Qty</th><th class="num">Price</th></tr>
+{{range .Items}}<tr><td>{{.Name}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.TotalPrice}}</td></tr>
+{{end}}</table>
+{{if not .DeliveryFee.IsZero}}<p>Delivery fee: {{.DeliveryFee}}</p>{{end}}
+{{if not .PickingFee.IsZero}}<p>Picking fee: {{.PickingFee}}</p>{{end}}
+<p><strong>Total: {{.FinalTotal}}</strong></p>{{else}}<p>The cart is empty.</p>{{end}}{{end}}
+</body></html>
+`))
+
+// HTML renders the document as a standalone page, the input for PDF.
+func HTML(doc Document) (string, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
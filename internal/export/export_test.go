@@ -0,0 +1,55 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func testDocument() Document {
+	return Document{
+		Title:     "Vecka 42",
+		Generated: time.Date(2026, 10, 16, 18, 30, 0, 0, time.Local),
+		Meals:     []string{"Måndag: Lasagne"},
+		Shopping:  []ShoppingLine{{Item: "mjölk", Quantity: 2, Note: "Mellanmjölk 1,5% 1l"}},
+		Cart: &willys.CartSummary{
+			Items:      []willys.CartItem{{Name: "Smör | Normalsaltat", Quantity: 1, TotalPrice: willys.SEK(5490)}},
+			FinalTotal: willys.SEK(5490),
+		},
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := Markdown(testDocument())
+
+	for _, want := range []string{
+		"# Vecka 42\n",
+		"_Generated 2026-10-16 18:30_",
+		"- Måndag: Lasagne\n",
+		"- [ ] 2 × mjölk (Mellanmjölk 1,5% 1l)\n",
+		`| Smör \| Normalsaltat | 1 | 54,90 kr |`,
+		"**Total: 54,90 kr**",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Expected Markdown to contain %q, got:\n%s", want, md)
+		}
+	}
+	if strings.Contains(md, "Delivery fee") {
+		t.Error("Expected zero fees to be left out")
+	}
+}
+
+func TestHTMLEscapes(t *testing.T) {
+	doc := testDocument()
+	doc.Meals = []string{"<script>alert(1)</script>"}
+
+	page, err := HTML(doc)
+	if err != nil {
+		t.Fatalf("HTML failed: %v", err)
+	}
+	if strings.Contains(page, "<script>") || !strings.Contains(page, "54,90 kr") {
+		t.Errorf("Unexpected page:\n%s", page)
+	}
+}
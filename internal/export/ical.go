@@ -0,0 +1,75 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// icalTimeFormat is the UTC DATE-TIME form from RFC 5545.
+const icalTimeFormat = "20060102T150405Z"
+
+// Event is a calendar entry. UID must stay the same when the event is exported again
+// so calendars update it instead of adding a duplicate.
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Location    string
+	Description string
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// foldLine splits content lines longer than 75 octets as RFC 5545 requires, without
+// breaking UTF-8 sequences. The space that starts a continuation line counts towards its
+// 75 octets.
+func foldLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// ICal renders events as an iCalendar file; stamp is the DTSTAMP of every event.
+func ICal(events []Event, stamp time.Time) string {
+	var b strings.Builder
+	foldLine(&b, "BEGIN:VCALENDAR")
+	foldLine(&b, "VERSION:2.0")
+	foldLine(&b, "PRODID:-//willys-mcp//Deliveries//EN")
+	foldLine(&b, "CALSCALE:GREGORIAN")
+	for _, e := range events {
+		foldLine(&b, "BEGIN:VEVENT")
+		foldLine(&b, "UID:"+e.UID)
+		foldLine(&b, "DTSTAMP:"+stamp.UTC().Format(icalTimeFormat))
+		foldLine(&b, "DTSTART:"+e.Start.UTC().Format(icalTimeFormat))
+		foldLine(&b, "DTEND:"+e.End.UTC().Format(icalTimeFormat))
+		foldLine(&b, "SUMMARY:"+escapeText(e.Summary))
+		if e.Location != "" {
+			foldLine(&b, "LOCATION:"+escapeText(e.Location))
+		}
+		if e.Description != "" {
+			foldLine(&b, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		foldLine(&b, "END:VEVENT")
+	}
+	foldLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// EventUID builds a stable UID from parts such as the slot date and start time.
+func EventUID(parts ...string) string {
+	return fmt.Sprintf("%s@willys-mcp", strings.Join(parts, "-"))
+}
@@ -0,0 +1,41 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICal(t *testing.T) {
+	start := time.Date(2026, 10, 20, 17, 0, 0, 0, time.UTC)
+	event := Event{
+		UID:         EventUID("2026-10-20", "17:00"),
+		Start:       start,
+		End:         start.Add(2 * time.Hour),
+		Summary:     "Willys delivery",
+		Location:    "Drottninggatan 1, 11151 Stockholm",
+		Description: "Order 1234; door code 1234\n" + strings.Repeat("å", 60) + strings.Repeat("x", 200),
+	}
+
+	ics := ICal([]Event{event}, start)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:2026-10-20-17:00@willys-mcp\r\n",
+		"DTSTART:20261020T170000Z\r\n",
+		"DTEND:20261020T190000Z\r\n",
+		`LOCATION:Drottninggatan 1\, 11151 Stockholm`,
+		`DESCRIPTION:Order 1234\; door code 1234\n`,
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", want, ics)
+		}
+	}
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("Expected lines folded at 75 octets, got %d: %q", len(line), line)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// pdfTimeout bounds a whole render, including starting the browser.
+const pdfTimeout = 60 * time.Second
+
+// PDF prints page with a short-lived headless Chromium, the same browser the client
+// uses for login.
+func PDF(ctx context.Context, page string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, pdfTimeout)
+	defer cancel()
+
+	path, exists := launcher.LookPath()
+	if !exists {
+		path = launcher.NewBrowser().MustGet()
+	}
+	l := launcher.New().Bin(path).Headless(true)
+	defer l.Cleanup()
+
+	u, err := l.Context(ctx).Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+	browser := rod.New().Context(ctx).ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	defer browser.Close()
+
+	p, err := browser.Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open page: %w", err)
+	}
+	if err := p.SetDocumentContent(page); err != nil {
+		return nil, fmt.Errorf("failed to load document: %w", err)
+	}
+
+	stream, err := p.PDF(&proto.PagePrintToPDF{PrintBackground: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to print PDF: %w", err)
+	}
+	return io.ReadAll(stream)
+}
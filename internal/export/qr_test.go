@@ -0,0 +1,155 @@
+package export
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// "HELLO WORLD" at 1-M, the worked example most QR tutorials use
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	expected := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, expected) {
+		t.Errorf("Expected error correction %v, got %v", expected, got)
+	}
+}
+
+// readFormat decodes the format bits next to the top-left finder.
+func readFormat(q *QRCode) int {
+	bits := 0
+	bit := func(i int, dark bool) {
+		if dark {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(i, q.Modules[i][8])
+	}
+	bit(6, q.Modules[7][8])
+	bit(7, q.Modules[8][8])
+	bit(8, q.Modules[8][7])
+	for i := 9; i < 15; i++ {
+		bit(i, q.Modules[8][14-i])
+	}
+	return bits ^ 0x5412
+}
+
+// readCodewords unmasks a copy of q and reads its codewords back in placement order.
+func readCodewords(q *QRCode, mask int) []byte {
+	copied := newQRCode(q.version)
+	for y := range q.Modules {
+		copy(copied.Modules[y], q.Modules[y])
+		copy(copied.function[y], q.function[y])
+	}
+	copied.applyMask(mask)
+
+	var bits qrBitBuffer
+	for right := q.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.Size; vert++ {
+			y := vert
+			if upward {
+				y = q.Size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				if x := right - j; !copied.function[y][x] {
+					bits = append(bits, copied.Modules[y][x])
+				}
+			}
+		}
+	}
+	return bits.bytes()
+}
+
+func TestEncodeQR(t *testing.T) {
+	tests := []struct {
+		text    string
+		version int
+	}{
+		{"https://www.willys.se/kassa", 3},
+		{"https://www.willys.se/kassa?" + strings.Repeat("x", 120), 8},
+	}
+
+	for _, tt := range tests {
+		q, err := EncodeQR(tt.text)
+		if err != nil {
+			t.Fatalf("EncodeQR failed: %v", err)
+		}
+		if q.version != tt.version || q.Size != 17+4*tt.version {
+			t.Errorf("Expected version %d, got %d (size %d)", tt.version, q.version, q.Size)
+		}
+
+		// Finder pattern: dark ring, light ring, dark 3x3 core
+		for _, row := range []int{0, 6} {
+			for x := 0; x < 7; x++ {
+				if !q.Modules[row][x] {
+					t.Fatalf("Expected a dark finder edge at (%d, %d)", x, row)
+				}
+			}
+		}
+		if q.Modules[1][1] || !q.Modules[3][3] || q.Modules[7][7] {
+			t.Error("Malformed finder pattern")
+		}
+
+		format := readFormat(q)
+		if level := format >> 13; level != 0 {
+			t.Errorf("Expected level M, got format %015b", format)
+		}
+		mask := (format >> 10) & 7
+		expected := qrCodewords([]byte(tt.text), q.version)
+		if got := readCodewords(q, mask); !bytes.Equal(got[:len(expected)], expected) {
+			t.Errorf("Codewords don't read back for %q", tt.text)
+		}
+	}
+
+	if _, err := EncodeQR(strings.Repeat("x", 214)); err != ErrQRTooLong {
+		t.Errorf("Expected ErrQRTooLong, got %v", err)
+	}
+}
+
+func TestQRFormatAndVersionBits(t *testing.T) {
+	q := newQRCode(7)
+	q.drawFormat(0)
+	// Level M with mask 0 is 101010000010010 in the standard's table
+	if got := readFormat(q) ^ 0x5412; got != 0b101010000010010 {
+		t.Errorf("Unexpected format bits %015b", got)
+	}
+
+	q.drawVersion()
+	bits := 0
+	for i := 0; i < 18; i++ {
+		if q.Modules[i/3][q.Size-11+i%3] {
+			bits |= 1 << i
+		}
+	}
+	if bits != 0b000111110010010100 {
+		t.Errorf("Unexpected version 7 bits %018b", bits)
+	}
+}
+
+func TestQRPNG(t *testing.T) {
+	q, err := EncodeQR("https://www.willys.se/kassa")
+	if err != nil {
+		t.Fatalf("EncodeQR failed: %v", err)
+	}
+	data, err := q.PNG(4)
+	if err != nil {
+		t.Fatalf("PNG failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Invalid PNG: %v", err)
+	}
+	if side := (q.Size + 2*qrQuietZone) * 4; img.Bounds().Dx() != side || img.Bounds().Dy() != side {
+		t.Errorf("Expected %dx%d, got %v", side, side, img.Bounds())
+	}
+	// Top-left module of the finder, just inside the quiet zone
+	if r, _, _, _ := img.At(qrQuietZone*4, qrQuietZone*4).RGBA(); r != 0 {
+		t.Error("Expected the finder corner to be black")
+	}
+}
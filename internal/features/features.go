@@ -0,0 +1,94 @@
+// Package features holds the runtime flags that gate experimental subsystems, so they
+// can ship dark and be turned on per deployment.
+package features
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SlotAutobook gates configure_slot_autobook and its background poller.
+	SlotAutobook = "slot_autobook"
+	// ScheduledOrders gates standing order schedules, the only subsystem that builds
+	// orders on its own. Orders are still never placed without the user.
+	ScheduledOrders = "scheduled_orders"
+	// SemanticMatching gates the embedding fallback for list items search can't find.
+	SemanticMatching = "semantic_matching"
+)
+
+// Flag describes a known feature flag.
+type Flag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Known lists the flags in a stable order. Subsystems that shipped before the flags
+// existed default to on, so flags only change behavior when they are set.
+var Known = []Flag{
+	{SlotAutobook, "Slot auto-booking (configure_slot_autobook and its poller)", true},
+	{ScheduledOrders, "Standing order schedules (create_schedule and the scheduler)", true},
+	{SemanticMatching, "Semantic fallback matching for list items", true},
+}
+
+// Flags maps flag names to whether they are on.
+type Flags map[string]bool
+
+// Defaults returns every known flag at its default.
+func Defaults() Flags {
+	flags := make(Flags, len(Known))
+	for _, f := range Known {
+		flags[f.Name] = f.Default
+	}
+	return flags
+}
+
+// Enabled reports whether name is on. Unknown names are off.
+func (f Flags) Enabled(name string) bool {
+	return f[name]
+}
+
+// Names returns the flag names in sorted order.
+func (f Flags) Names() []string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse applies a comma-separated spec to the defaults. Each entry is "name" or
+// "+name" to turn a flag on, "-name" to turn it off, or "name=<bool>".
+func Parse(spec string) (Flags, error) {
+	flags := Defaults()
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(strings.ToLower(entry))
+		if entry == "" {
+			continue
+		}
+		name, on := entry, true
+		switch {
+		case strings.HasPrefix(entry, "-"):
+			name, on = entry[1:], false
+		case strings.HasPrefix(entry, "+"):
+			name = entry[1:]
+		case strings.Contains(entry, "="):
+			var value string
+			name, value, _ = strings.Cut(entry, "=")
+			var err error
+			if on, err = strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("flag %s: %q is not a boolean", name, value)
+			}
+		}
+		name = strings.TrimSpace(name)
+		if _, ok := flags[name]; !ok {
+			return nil, fmt.Errorf("unknown flag %q (known: %s)", name, strings.Join(Defaults().Names(), ", "))
+		}
+		flags[name] = on
+	}
+	return flags, nil
+}
@@ -0,0 +1,29 @@
+package features
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	flags, err := Parse(" -slot_autobook, semantic_matching=false,+scheduled_orders ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags.Enabled(SlotAutobook) || flags.Enabled(SemanticMatching) || !flags.Enabled(ScheduledOrders) {
+		t.Errorf("flags = %v", flags)
+	}
+
+	flags, err = Parse("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range Known {
+		if flags.Enabled(f.Name) != f.Default {
+			t.Errorf("%s = %v, want default %v", f.Name, flags.Enabled(f.Name), f.Default)
+		}
+	}
+
+	for _, spec := range []string{"warp_drive", "slot_autobook=maybe"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package mail sends plain-text emails with attachments over SMTP or through a local
+// sendmail binary, for summaries the household should see.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var ErrNotConfigured = errors.New("mail is not configured")
+
+type (
+	Sender interface {
+		Send(msg Message) error
+	}
+
+	Message struct {
+		From        string
+		To          []string
+		Subject     string
+		Text        string
+		Attachments []Attachment
+	}
+
+	Attachment struct {
+		Name        string
+		ContentType string // e.g. "text/calendar; method=PUBLISH"
+		Data        []byte
+	}
+
+	// Config selects the transport: SMTP when SMTPAddr is set, otherwise sendmail when
+	// SendmailPath is set.
+	Config struct {
+		SMTPAddr     string // host:port
+		SMTPUsername string
+		SMTPPassword string
+		SendmailPath string
+		From         string
+	}
+
+	SMTPSender struct {
+		Addr     string
+		Username string
+		Password string
+	}
+
+	SendmailSender struct {
+		Path string
+	}
+)
+
+// NewSender returns the sender cfg selects, or nil when mail isn't configured.
+func NewSender(cfg Config) (Sender, error) {
+	switch {
+	case cfg.SMTPAddr != "":
+		if _, _, err := net.SplitHostPort(cfg.SMTPAddr); err != nil {
+			return nil, fmt.Errorf("invalid SMTP address %q: %w", cfg.SMTPAddr, err)
+		}
+		return &SMTPSender{Addr: cfg.SMTPAddr, Username: cfg.SMTPUsername, Password: cfg.SMTPPassword}, nil
+	case cfg.SendmailPath != "":
+		return &SendmailSender{Path: cfg.SendmailPath}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// ValidateAddresses checks that each entry is a bare email address.
+func ValidateAddresses(addresses []string) error {
+	for _, address := range addresses {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil || parsed.Address != address {
+			return fmt.Errorf("invalid email address %q", address)
+		}
+	}
+	return nil
+}
+
+// Send authenticates with PLAIN when a username is set; net/smtp only allows that over
+// TLS or to localhost, and upgrades with STARTTLS when the server offers it.
+func (s *SMTPSender) Send(msg Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, msg.From, msg.To, data)
+}
+
+func (s *SendmailSender) Send(msg Message) error {
+	data, err := msg.Bytes()
+	if err != nil {
+		return err
+	}
+	// -i keeps a lone "." line from ending the message early
+	args := append([]string{"-i", "-f", msg.From, "--"}, msg.To...)
+	cmd := exec.Command(s.Path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sendmail failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Bytes renders msg as a MIME message: the text alone, or multipart/mixed when there
+// are attachments.
+func (msg Message) Bytes() ([]byte, error) {
+	if msg.From == "" || len(msg.To) == 0 {
+		return nil, errors.New("message needs a sender and at least one recipient")
+	}
+	for _, header := range append([]string{msg.From, msg.Subject}, msg.To...) {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("header contains a line break")
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", msg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(msg.Attachments) == 0 {
+		writeText(&b, msg.Text)
+		return b.Bytes(), nil
+	}
+
+	boundary := newBoundary()
+	fmt.Fprintf(&b, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	writeText(&b, msg.Text)
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+		fmt.Fprintf(&b, "Content-Type: %s; name=%q\r\n", a.ContentType, a.Name)
+		fmt.Fprintf(&b, "Content-Disposition: attachment; filename=%q\r\n", a.Name)
+		b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+		encoded := base64.StdEncoding.EncodeToString(a.Data)
+		for len(encoded) > 76 {
+			b.WriteString(encoded[:76] + "\r\n")
+			encoded = encoded[76:]
+		}
+		b.WriteString(encoded + "\r\n")
+	}
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+	return b.Bytes(), nil
+}
+
+func writeText(b *bytes.Buffer, text string) {
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	w := quotedprintable.NewWriter(b)
+	w.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n")))
+	w.Close()
+	b.WriteString("\r\n")
+}
+
+func newBoundary() string {
+	var buf [12]byte
+	rand.Read(buf[:])
+	return "willys-" + base64.RawURLEncoding.EncodeToString(buf[:])
+}
@@ -0,0 +1,84 @@
+package mail
+
+import (
+	"io"
+	"mime"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytes(t *testing.T) {
+	msg := Message{
+		From:    "willys@example.com",
+		To:      []string{"anna@example.com", "erik@example.com"},
+		Subject: "Beställning 1234",
+		Text:    "Smör | 54,90 kr\nTotal: 54,90 kr",
+		Attachments: []Attachment{
+			{Name: "delivery.ics", ContentType: "text/calendar; charset=utf-8", Data: []byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")},
+		},
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("Not a valid message: %v", err)
+	}
+	if to, _ := parsed.Header.AddressList("To"); len(to) != 2 {
+		t.Errorf("Expected two recipients, got %v", to)
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || subject != msg.Subject {
+		t.Errorf("Expected subject %q, got %q (%v)", msg.Subject, subject, err)
+	}
+	body, _ := io.ReadAll(parsed.Body)
+	for _, want := range []string{"Content-Type: multipart/mixed", "Sm=C3=B6r", `filename="delivery.ics"`, "QkVHSU46VkNBTEVOREFS"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected message to contain %q, got:\n%s", want, body)
+		}
+	}
+
+	if _, err := (Message{From: "a@example.com", To: []string{"b@example.com"}, Subject: "x\r\nBcc: c@example.com"}).Bytes(); err == nil {
+		t.Error("Expected a header with a line break to be rejected")
+	}
+}
+
+func TestSendmailSender(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	script := filepath.Join(dir, "sendmail")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > "+out+".args\ncat > "+out+"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	sender, err := NewSender(Config{SendmailPath: script})
+	if err != nil {
+		t.Fatalf("NewSender failed: %v", err)
+	}
+	if err := sender.Send(Message{From: "willys@example.com", To: []string{"anna@example.com"}, Subject: "Hej", Text: "Hej!"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	args, _ := os.ReadFile(out + ".args")
+	if strings.TrimSpace(string(args)) != "-i -f willys@example.com -- anna@example.com" {
+		t.Errorf("Unexpected sendmail arguments: %s", args)
+	}
+	if data, _ := os.ReadFile(out); !strings.Contains(string(data), "Subject: Hej") {
+		t.Errorf("Expected the message on stdin, got %s", data)
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	if sender, err := NewSender(Config{}); sender != nil || err != nil {
+		t.Errorf("Expected no sender without configuration, got %v, %v", sender, err)
+	}
+	if _, err := NewSender(Config{SMTPAddr: "smtp.example.com"}); err == nil {
+		t.Error("Expected an SMTP address without port to be rejected")
+	}
+	if err := ValidateAddresses([]string{"anna@example.com", "Erik <erik@example.com>"}); err == nil {
+		t.Error("Expected a display name to be rejected")
+	}
+}
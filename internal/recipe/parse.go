@@ -0,0 +1,206 @@
+// Package recipe parses pasted ingredient lists (Swedish or English) into items with
+// amounts and units, so they can be searched and added to the cart.
+package recipe
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type Ingredient struct {
+	Line   string  `json:"line"`
+	Item   string  `json:"item"`
+	Amount float64 `json:"amount,omitempty"`
+	Unit   string  `json:"unit,omitempty"` // normalized, e.g. "dl", "g", "msk", "st"
+	Note   string  `json:"note,omitempty"` // parenthesized or trailing remark, e.g. "finhackad"
+	// Pantry marks staples assumed to be at home (salt, water, ...), skipped by CartItems
+	Pantry bool `json:"pantry,omitempty"`
+}
+
+// units maps spellings to their normalized unit.
+var units = map[string]string{
+	"ml": "ml", "cl": "cl", "dl": "dl", "l": "l", "liter": "l", "litre": "l", "liters": "l",
+	"g": "g", "gr": "g", "gram": "g", "grams": "g", "hg": "hg", "kg": "kg", "kilo": "kg",
+	"msk": "msk", "matsked": "msk", "matskedar": "msk", "tbsp": "msk", "tablespoon": "msk", "tablespoons": "msk",
+	"tsk": "tsk", "tesked": "tsk", "teskedar": "tsk", "tsp": "tsk", "teaspoon": "tsk", "teaspoons": "tsk",
+	"krm": "krm", "kryddmått": "krm", "pinch": "krm",
+	"st": "st", "styck": "st", "stycken": "st", "pcs": "st",
+	"förp": "förp", "förpackning": "förp", "paket": "förp", "pkt": "förp", "package": "förp", "pack": "förp",
+	"burk": "burk", "burkar": "burk", "can": "burk", "cans": "burk", "tin": "burk",
+	"klyfta": "klyfta", "klyftor": "klyfta", "clove": "klyfta", "cloves": "klyfta",
+	"knippe": "knippe", "bunch": "knippe",
+	"cup": "cup", "cups": "cup", "oz": "oz", "lb": "lb", "lbs": "lb",
+}
+
+var fractions = map[rune]float64{'½': 0.5, '¼': 0.25, '¾': 0.75, '⅓': 1.0 / 3, '⅔': 2.0 / 3}
+
+// pantryItems are staples skipped when building a shopping list. Matched against the
+// whole item, so "salt" is pantry but "saltgurka" is not.
+var pantryItems = map[string]bool{
+	"salt": true, "peppar": true, "svartpeppar": true, "vitpeppar": true, "salt och peppar": true,
+	"vatten": true, "kallt vatten": true, "varmt vatten": true, "kokande vatten": true,
+	"water": true, "cold water": true, "warm water": true, "boiling water": true,
+	"pepper": true, "black pepper": true, "salt and pepper": true, "salt & pepper": true, "is": true, "ice": true,
+}
+
+// trailingNotes are remarks cut from the end of an item, e.g. "persilja, till servering".
+var trailingNotes = []string{
+	"till servering", "till garnering", "efter smak", "to serve", "for serving", "to taste", "for garnish",
+}
+
+// Parse splits text into lines and parses each ingredient. Empty lines and headings
+// ("Sås:", "For the sauce:") are skipped.
+func Parse(text string) []Ingredient {
+	var ingredients []Ingredient
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•·–"))
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+		if ing, ok := ParseLine(line); ok {
+			ingredients = append(ingredients, ing)
+		}
+	}
+	return ingredients
+}
+
+// ParseLine parses a single ingredient line such as "2 dl crème fraiche (34%)" or
+// "1 1/2 cups flour". ok is false when no item remains after the amount and unit.
+func ParseLine(line string) (Ingredient, bool) {
+	ing := Ingredient{Line: line}
+	rest := line
+
+	if open := strings.Index(rest, "("); open >= 0 {
+		if end := strings.Index(rest[open:], ")"); end > 0 {
+			ing.Note = strings.TrimSpace(rest[open+1 : open+end])
+			rest = rest[:open] + rest[open+end+1:]
+		}
+	}
+
+	words := strings.Fields(rest)
+	i := 0
+	for i < len(words) {
+		amount, ok := parseAmount(words[i])
+		if !ok {
+			break
+		}
+		ing.Amount += amount
+		i++
+	}
+	if i < len(words) {
+		if unit, ok := units[strings.ToLower(strings.TrimSuffix(words[i], "."))]; ok && (ing.Amount > 0 || i+1 < len(words)) {
+			ing.Unit = unit
+			i++
+		}
+	}
+	if i < len(words) && (strings.EqualFold(words[i], "of") || strings.EqualFold(words[i], "av")) {
+		i++
+	}
+
+	item := strings.TrimSpace(strings.Join(words[i:], " "))
+	lower := strings.ToLower(item)
+	for _, note := range trailingNotes {
+		if idx := strings.LastIndex(lower, note); idx > 0 {
+			ing.Note = strings.TrimSpace(strings.Trim(ing.Note+" "+item[idx:], ", "))
+			item = item[:idx]
+			lower = lower[:idx]
+		}
+	}
+	if comma := strings.Index(item, ","); comma > 0 {
+		ing.Note = strings.TrimSpace(strings.Trim(item[comma+1:]+" "+ing.Note, ", "))
+		item = item[:comma]
+	}
+	ing.Item = strings.TrimSpace(strings.TrimRight(item, ".,;"))
+	if ing.Item == "" {
+		return ing, false
+	}
+	ing.Pantry = pantryItems[strings.ToLower(ing.Item)]
+	return ing, true
+}
+
+// parseAmount understands "2", "1,5", "1.5", "1/2", "½", "1½", and ranges like "2-3",
+// which count as the upper bound so the recipe isn't short.
+func parseAmount(word string) (float64, bool) {
+	if lo, hi, ok := strings.Cut(word, "-"); ok && lo != "" && hi != "" {
+		if _, ok := parseAmount(lo); ok {
+			return parseAmount(hi)
+		}
+		return 0, false
+	}
+
+	var total float64
+	runes := []rune(word)
+	if n := len(runes); n > 0 {
+		if f, ok := fractions[runes[n-1]]; ok {
+			total = f
+			word = string(runes[:n-1])
+			if word == "" {
+				return total, true
+			}
+		}
+	}
+
+	if num, den, ok := strings.Cut(word, "/"); ok {
+		n, err1 := strconv.ParseFloat(num, 64)
+		d, err2 := strconv.ParseFloat(den, 64)
+		if err1 != nil || err2 != nil || d == 0 {
+			return 0, false
+		}
+		return total + n/d, true
+	}
+
+	if word == "" || !unicode.IsDigit([]rune(word)[0]) {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(word, ",", ".", 1), 64)
+	if err != nil {
+		return 0, false
+	}
+	return total + value, true
+}
+
+// CartQuantity is how many packages to buy: the amount for counted items ("3 ägg",
+// "2 st lök"), rounded up, and one package for everything measured by volume or weight.
+func (ing Ingredient) CartQuantity() int {
+	if ing.Amount > 0 && (ing.Unit == "" || ing.Unit == "st" || ing.Unit == "förp" || ing.Unit == "burk") {
+		return int(math.Ceil(ing.Amount))
+	}
+	return 1
+}
+
+// baseUnits expresses each convertible unit in millilitres or grams.
+var baseUnits = map[string]struct {
+	base   string
+	factor float64
+}{
+	"ml": {"ml", 1}, "cl": {"ml", 10}, "dl": {"ml", 100}, "l": {"ml", 1000},
+	"krm": {"ml", 1}, "tsk": {"ml", 5}, "msk": {"ml", 15}, "cup": {"ml", 240},
+	"g": {"g", 1}, "hg": {"g", 100}, "kg": {"g", 1000}, "oz": {"g", 28.35}, "lb": {"g", 453.6},
+}
+
+// NormalizeUnit returns the normalized spelling of unit ("matskedar" -> "msk"), or unit
+// lower-cased if it isn't known.
+func NormalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if normalized, ok := units[unit]; ok {
+		return normalized
+	}
+	return unit
+}
+
+// Convert expresses amount in unit from as unit to. ok is false when the units measure
+// different things, e.g. dl and g. Counted units convert only to themselves.
+func Convert(amount float64, from, to string) (float64, bool) {
+	from, to = NormalizeUnit(from), NormalizeUnit(to)
+	if from == to {
+		return amount, true
+	}
+	f, okFrom := baseUnits[from]
+	t, okTo := baseUnits[to]
+	if !okFrom || !okTo || f.base != t.base {
+		return 0, false
+	}
+	return amount * f.factor / t.factor, true
+}
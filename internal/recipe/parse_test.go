@@ -0,0 +1,87 @@
+package recipe
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected Ingredient
+	}{
+		{"2 dl crème fraiche (34%)", Ingredient{Item: "crème fraiche", Amount: 2, Unit: "dl", Note: "34%"}},
+		{"1 1/2 cups of flour", Ingredient{Item: "flour", Amount: 1.5, Unit: "cup"}},
+		{"1½ msk smör", Ingredient{Item: "smör", Amount: 1.5, Unit: "msk"}},
+		{"2-3 vitlöksklyftor, finhackade", Ingredient{Item: "vitlöksklyftor", Amount: 3, Note: "finhackade"}},
+		{"3 ägg", Ingredient{Item: "ägg", Amount: 3}},
+		{"Salt", Ingredient{Item: "Salt", Pantry: true}},
+		{"1 l vatten", Ingredient{Item: "vatten", Amount: 1, Unit: "l", Pantry: true}},
+		{"Färsk persilja till servering", Ingredient{Item: "Färsk persilja", Note: "till servering"}},
+		{"500 g nötfärs", Ingredient{Item: "nötfärs", Amount: 500, Unit: "g"}},
+		{"2 cloves garlic", Ingredient{Item: "garlic", Amount: 2, Unit: "klyfta"}},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseLine(tt.line)
+		if !ok {
+			t.Errorf("%q: expected an ingredient", tt.line)
+			continue
+		}
+		tt.expected.Line = tt.line
+		if got != tt.expected {
+			t.Errorf("%q: expected %+v, got %+v", tt.line, tt.expected, got)
+		}
+	}
+
+	if _, ok := ParseLine("2 dl"); ok {
+		t.Error("Expected no ingredient for a bare amount")
+	}
+}
+
+func TestParse(t *testing.T) {
+	text := `Lasagne
+- 500 g nötfärs
+* 1 burk krossade tomater
+
+Sås:
+• 3 msk smör
+Salt och peppar`
+
+	ingredients := Parse(text)
+	if len(ingredients) != 5 {
+		t.Fatalf("Expected 5 ingredients, got %+v", ingredients)
+	}
+	if ingredients[2].Item != "krossade tomater" || ingredients[2].CartQuantity() != 1 {
+		t.Errorf("Unexpected ingredient: %+v", ingredients[2])
+	}
+	if !ingredients[4].Pantry {
+		t.Errorf("Expected salt and pepper to be pantry, got %+v", ingredients[4])
+	}
+	if q := (Ingredient{Item: "ägg", Amount: 2.5}).CartQuantity(); q != 3 {
+		t.Errorf("Expected 3 eggs, got %d", q)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		from, to string
+		expected float64
+		ok       bool
+	}{
+		{2, "dl", "ml", 200, true},
+		{1, "liter", "dl", 10, true},
+		{3, "msk", "dl", 0.45, true},
+		{0.5, "kg", "g", 500, true},
+		{2, "st", "st", 2, true},
+		{1, "dl", "g", 0, false},
+		{1, "st", "g", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Convert(tt.amount, tt.from, tt.to)
+		if ok != tt.ok || (ok && math.Abs(got-tt.expected) > 1e-9) {
+			t.Errorf("Convert(%v, %s, %s) = %v, %v; expected %v, %v", tt.amount, tt.from, tt.to, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
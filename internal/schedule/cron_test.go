@@ -0,0 +1,55 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("Expected error for %q", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	loc := time.UTC
+	// Wednesday
+	from := time.Date(2026, 10, 14, 10, 30, 0, 0, loc)
+
+	tests := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 14, 10, 45, 0, 0, loc)},
+		{"0 18 * * 0", time.Date(2026, 10, 18, 18, 0, 0, 0, loc)},
+		{"0 18 * * 7", time.Date(2026, 10, 18, 18, 0, 0, 0, loc)},
+		{"0 8 * * 1-5", time.Date(2026, 10, 15, 8, 0, 0, 0, loc)},
+		{"30 10 * * *", time.Date(2026, 10, 15, 10, 30, 0, 0, loc)},
+		{"0 0 1 * *", time.Date(2026, 11, 1, 0, 0, 0, 0, loc)},
+		{"@weekly", time.Date(2026, 10, 18, 0, 0, 0, 0, loc)},
+		// Either day field may match when both are restricted
+		{"0 9 20 * 5", time.Date(2026, 10, 16, 9, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q) failed: %v", tt.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tt.expected) {
+			t.Errorf("%q: expected %v, got %v", tt.expr, tt.expected, got)
+		}
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron failed: %v", err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Expected zero time, got %v", got)
+	}
+}
@@ -0,0 +1,227 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job states reported in JobStatus.
+const (
+	JobIdle    = "idle"
+	JobRunning = "running"
+	JobStopped = "stopped"
+)
+
+type (
+	// Job is a function run periodically by a Manager.
+	Job struct {
+		Name     string
+		Interval time.Duration
+		// Jitter moves each run by up to this fraction of Interval either way (0.1 is
+		// ±10%, capped at 0.9), so pollers started together don't hit Willys together.
+		Jitter float64
+		Run    func(ctx context.Context, now time.Time) error
+	}
+
+	// JobStatus is a snapshot of one job for list_background_jobs.
+	JobStatus struct {
+		Name      string     `json:"name"`
+		State     string     `json:"state"`
+		Interval  string     `json:"interval"`
+		StartedAt time.Time  `json:"startedAt"`
+		Runs      int        `json:"runs"`
+		Failures  int        `json:"failures"`
+		Panics    int        `json:"panics"`
+		LastRun   *time.Time `json:"lastRun,omitempty"`
+		// LastDuration is how long the last run took, e.g. "1.2s"
+		LastDuration string     `json:"lastDuration,omitempty"`
+		LastError    string     `json:"lastError,omitempty"`
+		NextRun      *time.Time `json:"nextRun,omitempty"`
+	}
+
+	// Manager supervises background jobs: each runs on its own goroutine until stopped,
+	// and a run that panics is logged and counted instead of taking the process down.
+	Manager struct {
+		mu   sync.Mutex
+		jobs map[string]*managedJob
+	}
+
+	managedJob struct {
+		Job
+		cancel context.CancelFunc
+		done   chan struct{}
+		status JobStatus
+	}
+)
+
+func NewManager() *Manager {
+	return &Manager{jobs: make(map[string]*managedJob)}
+}
+
+// Start runs job every Interval until ctx is cancelled or the job is stopped. A stopped
+// job's name can be started again; a running one can't.
+func (m *Manager) Start(ctx context.Context, job Job) error {
+	if job.Interval <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", job.Name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if existing, ok := m.jobs[job.Name]; ok && existing.status.State != JobStopped {
+		return fmt.Errorf("job %s is already running", job.Name)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	j := &managedJob{
+		Job:    job,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		status: JobStatus{
+			Name:      job.Name,
+			State:     JobIdle,
+			Interval:  job.Interval.String(),
+			StartedAt: time.Now(),
+		},
+	}
+	m.jobs[job.Name] = j
+	go m.loop(ctx, j)
+	return nil
+}
+
+// Stop stops the named job and waits for a run in progress to return. It reports
+// whether the job was running.
+func (m *Manager) Stop(name string) bool {
+	m.mu.Lock()
+	j, ok := m.jobs[name]
+	running := ok && j.status.State != JobStopped
+	m.mu.Unlock()
+	if !running {
+		return false
+	}
+	j.cancel()
+	<-j.done
+	return true
+}
+
+// StopAll stops every job and waits for them, giving up after timeout. It returns the
+// names of jobs whose runs were still going then.
+func (m *Manager) StopAll(timeout time.Duration) []string {
+	m.mu.Lock()
+	var done []chan struct{}
+	for _, j := range m.jobs {
+		j.cancel()
+		done = append(done, j.done)
+	}
+	m.mu.Unlock()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+wait:
+	for _, ch := range done {
+		select {
+		case <-ch:
+		case <-deadline.C:
+			break wait
+		}
+	}
+
+	var running []string
+	for _, status := range m.Status() {
+		if status.State == JobRunning {
+			running = append(running, status.Name)
+		}
+	}
+	return running
+}
+
+// Status returns a snapshot of every job, sorted by name.
+func (m *Manager) Status() []JobStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		statuses = append(statuses, j.status)
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
+
+func (m *Manager) loop(ctx context.Context, j *managedJob) {
+	defer close(j.done)
+	defer m.update(j, func(s *JobStatus) {
+		s.State = JobStopped
+		s.NextRun = nil
+	})
+
+	for {
+		delay := j.delay()
+		next := time.Now().Add(delay)
+		m.update(j, func(s *JobStatus) { s.NextRun = &next })
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			m.run(ctx, j, now)
+		}
+	}
+}
+
+// run makes one run of the job, recovering from a panic in it.
+func (m *Manager) run(ctx context.Context, j *managedJob, now time.Time) {
+	m.update(j, func(s *JobStatus) {
+		s.State = JobRunning
+		s.NextRun = nil
+	})
+
+	var err error
+	panicked := false
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				panicked = true
+				err = fmt.Errorf("panic: %v", r)
+				log.Printf("Background job %s panicked: %v\n%s", j.Name, r, debug.Stack())
+			}
+		}()
+		err = j.Run(ctx, now)
+	}()
+
+	finished := time.Now()
+	m.update(j, func(s *JobStatus) {
+		s.State = JobIdle
+		s.Runs++
+		s.LastRun = &now
+		s.LastDuration = finished.Sub(now).Round(time.Millisecond).String()
+		s.LastError = ""
+		if err != nil {
+			s.Failures++
+			s.LastError = err.Error()
+		}
+		if panicked {
+			s.Panics++
+		}
+	})
+}
+
+func (m *Manager) update(j *managedJob, fn func(*JobStatus)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	fn(&j.status)
+}
+
+func (j *managedJob) delay() time.Duration {
+	if j.Jitter <= 0 {
+		return j.Interval
+	}
+	spread := float64(j.Interval) * min(j.Jitter, 0.9) * (2*rand.Float64() - 1)
+	return j.Interval + time.Duration(spread)
+}
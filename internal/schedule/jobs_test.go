@@ -0,0 +1,100 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestManagerRecoversFromPanics(t *testing.T) {
+	m := NewManager()
+	var runs atomic.Int32
+	err := m.Start(context.Background(), Job{
+		Name:     "flaky",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context, now time.Time) error {
+			switch runs.Add(1) {
+			case 1:
+				panic("boom")
+			case 2:
+				return errors.New("upstream down")
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	waitFor(t, func() bool { return runs.Load() >= 3 })
+	if !m.Stop("flaky") {
+		t.Error("Expected Stop to report a running job")
+	}
+
+	status := m.Status()[0]
+	if status.State != JobStopped || status.Panics != 1 || status.Failures != 2 || status.LastError != "" || status.LastRun == nil {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+	if m.Stop("flaky") {
+		t.Error("Expected a second Stop to report nothing running")
+	}
+}
+
+func TestManagerStartAndStopAll(t *testing.T) {
+	m := NewManager()
+	ctx := context.Background()
+	block := make(chan struct{})
+	job := Job{Name: "slow", Interval: time.Millisecond, Run: func(ctx context.Context, now time.Time) error {
+		<-block
+		return nil
+	}}
+	if err := m.Start(ctx, job); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := m.Start(ctx, job); err == nil {
+		t.Error("Expected starting a running job twice to fail")
+	}
+	if err := m.Start(ctx, Job{Name: "zero"}); err == nil {
+		t.Error("Expected a zero interval to be rejected")
+	}
+
+	waitFor(t, func() bool { return m.Status()[0].State == JobRunning })
+	if running := m.StopAll(10 * time.Millisecond); len(running) != 1 || running[0] != "slow" {
+		t.Errorf("Expected slow to still be running, got %v", running)
+	}
+	close(block)
+	waitFor(t, func() bool { return m.Status()[0].State == JobStopped })
+
+	// A stopped job may be started again
+	if err := m.Start(ctx, job); err != nil {
+		t.Errorf("Expected restart after stop to work: %v", err)
+	}
+	if running := m.StopAll(time.Second); len(running) != 0 {
+		t.Errorf("Expected all jobs stopped, got %v", running)
+	}
+}
+
+func TestJobJitter(t *testing.T) {
+	j := &managedJob{Job: Job{Interval: time.Minute, Jitter: 0.1}}
+	for i := 0; i < 100; i++ {
+		if d := j.delay(); d < 54*time.Second || d > 66*time.Second {
+			t.Fatalf("Delay %s outside ±10%%", d)
+		}
+	}
+	j.Jitter = 0
+	if d := j.delay(); d != time.Minute {
+		t.Errorf("Expected no jitter, got %s", d)
+	}
+}
@@ -0,0 +1,198 @@
+// Package semantic maps free-text ingredient lines to catalog products by embedding
+// similarity, for lines that plain search doesn't find.
+package semantic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOff   = "off"
+	ProviderLocal = "local"
+	ProviderAPI   = "api"
+
+	// DefaultDimensions is the vector size of the local hashing embedder.
+	DefaultDimensions = 512
+
+	httpTimeout = 15 * time.Second
+)
+
+type (
+	// Embedder turns texts into vectors whose cosine similarity reflects how alike the
+	// texts are. Vectors are returned in input order.
+	Embedder interface {
+		Embed(ctx context.Context, texts []string) ([][]float64, error)
+	}
+
+	// HashingEmbedder is a dependency-free local embedder built from hashed character
+	// trigrams and words. It catches spelling variants ("creme fraiche", "crème fraîche")
+	// but not synonyms; use an API embedder for those.
+	HashingEmbedder struct {
+		dimensions int
+	}
+
+	// HTTPEmbedder calls an OpenAI-compatible /embeddings endpoint.
+	HTTPEmbedder struct {
+		url        string
+		apiKey     string
+		model      string
+		httpClient *http.Client
+	}
+)
+
+func ValidateProvider(provider string) error {
+	switch provider {
+	case "", ProviderOff, ProviderLocal, ProviderAPI:
+		return nil
+	default:
+		return fmt.Errorf("unsupported embedding provider: %s (use 'off', 'local', or 'api')", provider)
+	}
+}
+
+// NewEmbedder builds the embedder for provider, or returns nil for "off".
+func NewEmbedder(provider, url, apiKey, model string) (Embedder, error) {
+	switch provider {
+	case "", ProviderOff:
+		return nil, nil
+	case ProviderLocal:
+		return NewHashingEmbedder(DefaultDimensions), nil
+	case ProviderAPI:
+		if url == "" {
+			return nil, fmt.Errorf("an embeddings URL is required for the api provider")
+		}
+		return NewHTTPEmbedder(url, apiKey, model), nil
+	default:
+		return nil, ValidateProvider(provider)
+	}
+}
+
+func NewHashingEmbedder(dimensions int) *HashingEmbedder {
+	if dimensions <= 0 {
+		dimensions = DefaultDimensions
+	}
+	return &HashingEmbedder{dimensions: dimensions}
+}
+
+func (e *HashingEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashingEmbedder) embed(text string) []float64 {
+	vector := make([]float64, e.dimensions)
+	for _, word := range strings.Fields(Fold(text)) {
+		e.add(vector, "w:"+word, 2)
+		padded := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			e.add(vector, string(padded[i:i+3]), 1)
+		}
+	}
+	normalize(vector)
+	return vector
+}
+
+func (e *HashingEmbedder) add(vector []float64, feature string, weight float64) {
+	h := fnv.New32a()
+	h.Write([]byte(feature))
+	vector[h.Sum32()%uint32(e.dimensions)] += weight
+}
+
+func NewHTTPEmbedder(url, apiKey, model string) *HTTPEmbedder {
+	return &HTTPEmbedder{
+		url:        url,
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	payload, err := json.Marshal(map[string]any{"model": e.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embedding request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embedding request failed with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embedding response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range result.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+func normalize(vector []float64) {
+	var sum float64
+	for _, v := range vector {
+		sum += v * v
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
+
+// Cosine returns the cosine similarity of a and b, or 0 if either is empty or the
+// lengths differ.
+func Cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
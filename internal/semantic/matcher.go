@@ -0,0 +1,148 @@
+package semantic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+// DefaultMinConfidence is the similarity below which Best reports no match.
+const DefaultMinConfidence = 0.45
+
+// Units and filler words dropped from ingredient lines before searching. Swedish and
+// English, since recipes come in both.
+var unitWords = map[string]bool{
+	"g": true, "gr": true, "kg": true, "hg": true, "mg": true,
+	"ml": true, "cl": true, "dl": true, "l": true, "liter": true,
+	"msk": true, "tsk": true, "krm": true, "st": true, "förp": true, "pkt": true, "burk": true,
+	"tbsp": true, "tsp": true, "cup": true, "cups": true, "oz": true, "lb": true, "pcs": true,
+	"ca": true, "about": true, "of": true,
+}
+
+var foldReplacer = strings.NewReplacer(
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "á", "a", "à", "a", "â", "a",
+	"î", "i", "ï", "i", "ô", "o", "û", "u", "ü", "u", "ç", "c",
+)
+
+type (
+	Matcher struct {
+		embedder      Embedder
+		minConfidence float64
+	}
+
+	Match struct {
+		Product    willys.Product `json:"product"`
+		Confidence float64        `json:"confidence"` // cosine similarity, 0-1
+	}
+)
+
+func NewMatcher(embedder Embedder, minConfidence float64) *Matcher {
+	if minConfidence <= 0 {
+		minConfidence = DefaultMinConfidence
+	}
+	return &Matcher{embedder: embedder, minConfidence: minConfidence}
+}
+
+// Fold lower-cases text and strips French-style accents, keeping å, ä, and ö, which
+// are separate letters in Swedish.
+func Fold(text string) string {
+	return foldReplacer.Replace(strings.ToLower(text))
+}
+
+// NormalizeIngredient drops quantities, percentages, units, and punctuation from an
+// ingredient line: "2 dl crème fraiche 34%" becomes "crème fraiche".
+func NormalizeIngredient(line string) string {
+	words := strings.FieldsFunc(strings.ToLower(line), func(r rune) bool {
+		return unicode.IsSpace(r) || r == ',' || r == '(' || r == ')'
+	})
+	kept := make([]string, 0, len(words))
+	for _, w := range words {
+		if unitWords[w] || strings.ContainsFunc(w, unicode.IsDigit) {
+			continue
+		}
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " ")
+}
+
+// CandidateQueries lists progressively broader searches for line: the normalized line,
+// its accent-folded form, and its longest word.
+func CandidateQueries(line string) []string {
+	normalized := NormalizeIngredient(line)
+	if normalized == "" {
+		return nil
+	}
+
+	queries := []string{normalized}
+	if folded := Fold(normalized); folded != normalized {
+		queries = append(queries, folded)
+	}
+	longest := ""
+	for _, w := range strings.Fields(normalized) {
+		if len([]rune(w)) > len([]rune(longest)) {
+			longest = w
+		}
+	}
+	if longest != normalized && len([]rune(longest)) >= 3 {
+		queries = append(queries, longest)
+	}
+	return queries
+}
+
+func describe(p willys.Product) string {
+	parts := []string{p.Name}
+	if p.Manufacturer != "" {
+		parts = append(parts, p.Manufacturer)
+	}
+	if p.DisplayVolume != "" {
+		parts = append(parts, p.DisplayVolume)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Rank scores every candidate against line, best first.
+func (m *Matcher) Rank(ctx context.Context, line string, candidates []willys.Product) ([]Match, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	texts := make([]string, 0, len(candidates)+1)
+	texts = append(texts, NormalizeIngredient(line))
+	for _, p := range candidates {
+		texts = append(texts, describe(p))
+	}
+
+	vectors, err := m.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed products: %w", err)
+	}
+
+	matches := make([]Match, len(candidates))
+	for i, p := range candidates {
+		matches[i] = Match{Product: p, Confidence: Cosine(vectors[0], vectors[i+1])}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Confidence > matches[j].Confidence })
+	return matches, nil
+}
+
+// Best returns the in-stock candidate most similar to line, or nil if none reaches the
+// matcher's minimum confidence.
+func (m *Matcher) Best(ctx context.Context, line string, candidates []willys.Product) (*Match, error) {
+	matches, err := m.Rank(ctx, line, candidates)
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range matches {
+		if match.Confidence < m.minConfidence {
+			return nil, nil
+		}
+		if !match.Product.OutOfStock {
+			return &match, nil
+		}
+	}
+	return nil, nil
+}
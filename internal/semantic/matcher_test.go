@@ -0,0 +1,81 @@
+package semantic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestCandidateQueries(t *testing.T) {
+	if got := NormalizeIngredient("2 dl crème fraiche 34%"); got != "crème fraiche" {
+		t.Errorf("Expected 'crème fraiche', got %q", got)
+	}
+
+	expected := []string{"crème fraiche", "creme fraiche", "fraiche"}
+	if got := CandidateQueries("2 dl crème fraiche 34%"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := CandidateQueries("500 g"); got != nil {
+		t.Errorf("Expected no queries for a bare quantity, got %v", got)
+	}
+}
+
+func TestMatcherBest(t *testing.T) {
+	matcher := NewMatcher(NewHashingEmbedder(DefaultDimensions), DefaultMinConfidence)
+	candidates := []willys.Product{
+		{Code: "1", Name: "Gräddfil 12%", Manufacturer: "Arla"},
+		{Code: "2", Name: "Creme Fraiche 34%", Manufacturer: "Garant", OutOfStock: true},
+		{Code: "3", Name: "Crème Fraîche Naturell 34%", Manufacturer: "Arla", DisplayVolume: "2dl"},
+	}
+
+	match, err := matcher.Best(context.Background(), "2 dl crème fraiche 34%", candidates)
+	if err != nil {
+		t.Fatalf("Best failed: %v", err)
+	}
+	if match == nil || match.Product.Code != "3" {
+		t.Fatalf("Expected in-stock crème fraiche, got %+v", match)
+	}
+	if match.Confidence < DefaultMinConfidence || match.Confidence > 1 {
+		t.Errorf("Unexpected confidence %f", match.Confidence)
+	}
+
+	if match, _ := matcher.Best(context.Background(), "kardemumma", candidates[:1]); match != nil {
+		t.Errorf("Expected no match for unrelated products, got %+v", match)
+	}
+}
+
+func TestHTTPEmbedder(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		// Answer out of order to check that vectors follow the index field.
+		json.NewEncoder(w).Encode(map[string]any{"data": []map[string]any{
+			{"index": 1, "embedding": []float64{0, 1}},
+			{"index": 0, "embedding": []float64{1, 0}},
+		}})
+	}))
+	defer srv.Close()
+
+	vectors, err := NewHTTPEmbedder(srv.URL, "secret", "test").Embed(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if !reflect.DeepEqual(vectors, [][]float64{{1, 0}, {0, 1}}) {
+		t.Errorf("Unexpected vectors: %v", vectors)
+	}
+
+	if _, err := NewHTTPEmbedder(srv.URL, "wrong", "test").Embed(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("Expected error on unauthorized response")
+	}
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(Store) error
+}
+
+// Migrations is the ordered list of schema changes. Append new entries with the next
+// version number; never reorder or edit released ones.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "initial schema",
+		Up:      func(Store) error { return nil },
+	},
+}
+
+func SchemaVersion(s Store) (int, error) {
+	data, err := s.Get(BucketMeta, schemaVersionKey)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+func Migrate(s Store, migrations []Migration) error {
+	current, err := SchemaVersion(s)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := m.Up(s); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		if err := s.Put(BucketMeta, schemaVersionKey, []byte(strconv.Itoa(m.Version))); err != nil {
+			return fmt.Errorf("failed to record schema version %d: %w", m.Version, err)
+		}
+		current = m.Version
+	}
+
+	return nil
+}
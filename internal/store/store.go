@@ -0,0 +1,116 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	BucketMeta         = "_meta"
+	BucketPickHistory  = "pick_history"
+	BucketAuth         = "auth"
+	BucketSchedules    = "schedules"
+	BucketSlotAutobook = "slot_autobook"
+	BucketAddresses    = "addresses"
+	BucketCartOrigins  = "cart_origins"
+	BucketPantry       = "pantry"
+	BucketBaskets      = "baskets"
+	BucketTemplates    = "templates"
+	BucketDeliveries   = "deliveries"
+	BucketMembers      = "members"
+	BucketMemberTags   = "member_tags"
+
+	// KeyRefreshToken holds the latest refresh token in BucketAuth, so a restart can log
+	// in without a password. Logging out deletes it.
+	KeyRefreshToken = "refresh_token"
+
+	schemaVersionKey = "schema_version"
+	dbFileName       = "willys.db"
+)
+
+var ErrNotFound = errors.New("key not found")
+
+// Store is a small bucketed key-value store shared by the subsystems that need to
+// remember things between runs (lists, watches, snapshots, history).
+type Store interface {
+	Get(bucket, key string) ([]byte, error)
+	Put(bucket, key string, value []byte) error
+	Delete(bucket, key string) error
+	List(bucket string) (map[string][]byte, error)
+	Buckets() ([]string, error)
+	DeleteBucket(bucket string) error
+	Close() error
+}
+
+func GetJSON(s Store, bucket, key string, v any) error {
+	data, err := s.Get(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode %s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
+
+func PutJSON(s Store, bucket, key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s/%s: %w", bucket, key, err)
+	}
+	return s.Put(bucket, key, data)
+}
+
+func DefaultDataDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "willys-mcp")
+	}
+	return ".willys-mcp"
+}
+
+// Open opens the persistent store in dataDir and applies pending migrations.
+func Open(dataDir string) (Store, error) {
+	if dataDir == "" {
+		dataDir = DefaultDataDir()
+	}
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	s, err := NewBolt(filepath.Join(dataDir, dbFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Migrate(s, Migrations); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Purge removes every bucket except the schema metadata, returning the names of the
+// buckets that were removed.
+func Purge(s Store) ([]string, error) {
+	buckets, err := s.Buckets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+
+	purged := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		if bucket == BucketMeta {
+			continue
+		}
+		if err := s.DeleteBucket(bucket); err != nil {
+			return purged, fmt.Errorf("failed to purge %s: %w", bucket, err)
+		}
+		purged = append(purged, bucket)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func testStore(t *testing.T, s Store) {
+	if _, err := s.Get("lists", "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	if err := PutJSON(s, "lists", "weekly", []string{"mjölk", "bröd"}); err != nil {
+		t.Fatalf("Failed to put: %v", err)
+	}
+
+	var items []string
+	if err := GetJSON(s, "lists", "weekly", &items); err != nil {
+		t.Fatalf("Failed to get: %v", err)
+	}
+	if len(items) != 2 || items[0] != "mjölk" {
+		t.Errorf("Unexpected value: %v", items)
+	}
+
+	all, err := s.List("lists")
+	if err != nil || len(all) != 1 {
+		t.Errorf("Expected 1 entry, got %d (%v)", len(all), err)
+	}
+
+	if err := s.Delete("lists", "weekly"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if _, err := s.Get("lists", "weekly"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+
+	if err := s.DeleteBucket("lists"); err != nil {
+		t.Errorf("Failed to delete bucket: %v", err)
+	}
+	if err := s.DeleteBucket("does_not_exist"); err != nil {
+		t.Errorf("Deleting a missing bucket should not fail: %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemory())
+}
+
+func TestBoltStore(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer s.Close()
+
+	testStore(t, s)
+}
+
+func TestMigrate(t *testing.T) {
+	s, err := NewBolt(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	defer s.Close()
+
+	runs := 0
+	migrations := []Migration{
+		{Version: 1, Name: "one", Up: func(Store) error { runs++; return nil }},
+		{Version: 2, Name: "two", Up: func(Store) error { runs++; return nil }},
+	}
+
+	if err := Migrate(s, migrations); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if err := Migrate(s, migrations); err != nil {
+		t.Fatalf("Second migrate failed: %v", err)
+	}
+
+	if runs != 2 {
+		t.Errorf("Expected migrations to run once each, got %d runs", runs)
+	}
+
+	version, err := SchemaVersion(s)
+	if err != nil || version != 2 {
+		t.Errorf("Expected schema version 2, got %d (%v)", version, err)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	s := NewMemory()
+	if err := Migrate(s, Migrations); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	s.Put(BucketPickHistory, "mjölk", []byte("101233933_ST"))
+
+	purged, err := Purge(s)
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if len(purged) != 1 || purged[0] != BucketPickHistory {
+		t.Errorf("Expected only %s purged, got %v", BucketPickHistory, purged)
+	}
+
+	if _, err := s.Get(BucketPickHistory, "mjölk"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected pick history to be gone, got %v", err)
+	}
+	if version, _ := SchemaVersion(s); version == 0 {
+		t.Error("Schema version should survive a purge")
+	}
+}
@@ -0,0 +1,108 @@
+package willys
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// "portkod 1234", "Portkod: 12#34", "kod 1234"
+	doorCodePattern = regexp.MustCompile(`(?i)\b(?:port|dörr|entré)?kod\b[:\s]*([0-9A-Za-z#*]+)`)
+	// "111 51 Stockholm", "SE-11151 Stockholm", or a bare "111 51"
+	postalCityPattern = regexp.MustCompile(`^(.*?)\s*\b(?:SE-?)?(\d{3})\s?(\d{2})\b\s*(.*)$`)
+	// Parts that belong on the street line rather than in the message to the driver
+	addressDetailPattern = regexp.MustCompile(`(?i)^(lgh|lägenhet|c/o|\d+\s*(tr|trappor)\b|våning|vån\b|uppg|uppgång|port\b)`)
+)
+
+// ParseAddressText splits a free-text Swedish address such as "Drottninggatan 1, 111 51
+// Stockholm, portkod 1234" into DeliveryAddress fields. Comma-separated parts are read as
+// an optional recipient name, the street (apartment and floor details are kept with it),
+// postal code and city, and a door code; anything else becomes the message to the driver.
+// Names are left empty when the text doesn't start with one.
+func ParseAddressText(text string) (DeliveryAddress, error) {
+	var address DeliveryAddress
+
+	if m := doorCodePattern.FindStringSubmatchIndex(text); m != nil {
+		address.DoorCode = text[m[2]:m[3]]
+		text = text[:m[0]] + text[m[1]:]
+	}
+
+	var parts []string
+	for _, part := range strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		if part = strings.Join(strings.Fields(part), " "); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	var notes, details []string
+	cityNext := false
+	for i, part := range parts {
+		lower := strings.ToLower(part)
+		switch {
+		case lower == "sverige" || lower == "sweden":
+			continue
+		case cityNext:
+			address.City = part
+			cityNext = false
+			continue
+		case address.PostalCode == "":
+			if m := postalCityPattern.FindStringSubmatch(part); m != nil {
+				address.PostalCode = m[2] + m[3]
+				address.City = m[4]
+				cityNext = address.City == ""
+				if m[1] != "" && address.Address == "" {
+					address.Address = m[1]
+				}
+				continue
+			}
+		}
+
+		switch {
+		case addressDetailPattern.MatchString(part):
+			details = append(details, part)
+		case address.Address == "" && hasDigit(part):
+			address.Address = part
+		case i == 0 && !hasDigit(part) && len(parts) > 1 && hasDigit(parts[1]) && !postalCityPattern.MatchString(parts[1]):
+			address.FirstName, address.LastName = splitName(part)
+		default:
+			notes = append(notes, part)
+		}
+	}
+	if address.Address == "" && len(notes) > 0 {
+		// A street without a number, e.g. a farm name in the countryside
+		address.Address, notes = notes[0], notes[1:]
+	}
+	if len(details) > 0 {
+		address.Address = strings.Join(append([]string{address.Address}, details...), ", ")
+	}
+	address.MessageToDriver = strings.Join(notes, ". ")
+
+	switch {
+	case address.Address == "":
+		return address, NewValidationError("address_text", "no street address found (e.g., 'Drottninggatan 1, 111 51 Stockholm')")
+	case address.PostalCode == "":
+		return address, NewValidationError("address_text", "no postal code found (expected 5 digits, e.g., '111 51')")
+	case address.City == "":
+		return address, NewValidationError("address_text", "no city found after the postal code")
+	case len(address.Address) > maxAddressLength:
+		return address, NewValidationError("address_text", "street address too long")
+	case len(address.DoorCode) > maxDoorCodeLength:
+		return address, NewValidationError("address_text", "door code too long")
+	}
+	return address, nil
+}
+
+// splitName takes the last word as the last name, so "Anna Maria Svensson" is first name
+// "Anna Maria".
+func splitName(name string) (first, last string) {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return name, ""
+	}
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
+
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
@@ -0,0 +1,46 @@
+package willys
+
+import (
+	"testing"
+)
+
+func TestParseAddressText(t *testing.T) {
+	tests := []struct {
+		text string
+		want DeliveryAddress
+	}{
+		{
+			text: "Drottninggatan 1, 111 51 Stockholm, portkod 1234",
+			want: DeliveryAddress{Address: "Drottninggatan 1", PostalCode: "11151", City: "Stockholm", DoorCode: "1234"},
+		},
+		{
+			text: "Anna Maria Svensson, Storgatan 12B, lgh 1102, 3 tr, SE-41301 Göteborg, Sverige",
+			want: DeliveryAddress{FirstName: "Anna Maria", LastName: "Svensson", Address: "Storgatan 12B, lgh 1102, 3 tr", PostalCode: "41301", City: "Göteborg"},
+		},
+		{
+			text: "Kungsgatan 5 753 21 Uppsala; Portkod: 42#1; ring på hos Berg",
+			want: DeliveryAddress{Address: "Kungsgatan 5", PostalCode: "75321", City: "Uppsala", DoorCode: "42#1", MessageToDriver: "ring på hos Berg"},
+		},
+		{
+			text: "Lillgården, 123 45, Skara",
+			want: DeliveryAddress{Address: "Lillgården", PostalCode: "12345", City: "Skara"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAddressText(tt.text)
+		if err != nil {
+			t.Errorf("%q: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q:\n  expected %+v\n  got      %+v", tt.text, tt.want, got)
+		}
+	}
+
+	for _, text := range []string{"Drottninggatan 1, Stockholm", "111 51 Stockholm", "Drottninggatan 1, 111 51", ""} {
+		if _, err := ParseAddressText(text); !IsValidationError(err) {
+			t.Errorf("%q: expected a validation error, got %v", text, err)
+		}
+	}
+}
@@ -8,19 +8,49 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/go-rod/rod"
-	"github.com/go-rod/rod/lib/launcher"
 	"github.com/go-rod/rod/lib/proto"
 )
 
+// AuthStatus.State values.
+const (
+	AuthStateAuthenticated       = "AUTHENTICATED"
+	AuthStateUnauthenticated     = "UNAUTHENTICATED"
+	AuthStateVerificationPending = "VERIFICATION_PENDING"
+	AuthStateCredentialsInvalid  = "CREDENTIALS_INVALID"
+	AuthStateLockedOut           = "LOCKED_OUT"
+)
+
 type (
 	LoginRequest struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
 	}
 
+	AuthStatus struct {
+		State               string           `json:"state"`
+		Authenticated       bool             `json:"authenticated"`
+		HasCredentials      bool             `json:"hasCredentials"`
+		CSRFTokenCached     bool             `json:"csrfTokenCached"`
+		AuthAttempts        int              `json:"authAttempts"`
+		CookieCount         int              `json:"cookieCount"`
+		AuthMethod          string           `json:"authMethod"`
+		VerificationPending bool             `json:"verificationPending"`
+		VerificationPrompt  string           `json:"verificationPrompt,omitempty"`
+		LastCartMerge       *CartMergeResult `json:"lastCartMerge,omitempty"`
+		// ActiveStore is the store picked for the last delivery set up in this session
+		ActiveStore *StoreRef `json:"activeStore,omitempty"`
+		// CredentialsInvalidSince is when a re-login was rejected; automatic re-login is
+		// off until a login succeeds.
+		CredentialsInvalidSince *time.Time `json:"credentialsInvalidSince,omitempty"`
+		// LockedOutUntil is when automated logins resume after a lockout or CAPTCHA
+		LockedOutUntil *time.Time `json:"lockedOutUntil,omitempty"`
+		LockoutReason  string     `json:"lockoutReason,omitempty"`
+	}
+
 	CustomerInfo struct {
 		CustomerID   string `json:"customerId"`
 		Email        string `json:"email"`
@@ -34,7 +64,7 @@ type (
 // LoginWithBrowser uses headless browser automation because Willys requires cookie consent
 // and some dynamic page loading before login. The time.Sleep calls are necessary since
 // the page doesn't always reliably signal when elements are ready.
-func (c *Client) LoginWithBrowser(ctx context.Context, username, password string) error {
+func (c *Client) LoginWithBrowser(ctx context.Context, username, password string) (err error) {
 	if username == "" {
 		return NewValidationError("username", "username cannot be empty")
 	}
@@ -45,20 +75,15 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 		return NewValidationError("password", "password must be at least 6 characters")
 	}
 
-	path, exists := launcher.LookPath()
-	if !exists {
-		path = launcher.NewBrowser().MustGet()
+	if lockout := c.activeLockout(); lockout != nil {
+		return NewAuthenticationError("login paused", lockout)
 	}
 
-	u := launcher.New().
-		Bin(path).
-		Headless(true).
-		Devtools(false).
-		MustLaunch()
+	c.captureAnonymousCart(ctx)
 
-	browser := rod.New().ControlURL(u)
-	if err := browser.Connect(); err != nil {
-		return NewAuthenticationError("failed to connect to browser", err)
+	browser, err := c.launchBrowser(true)
+	if err != nil {
+		return NewAuthenticationError("failed to start browser", err)
 	}
 	defer browser.MustClose()
 
@@ -67,6 +92,11 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 		return NewAuthenticationError("failed to create page", err)
 	}
 	defer page.MustClose()
+	defer func() {
+		if err != nil {
+			err = c.captureDiagnostics(page, err)
+		}
+	}()
 
 	if err := page.WaitLoad(); err != nil {
 		return NewAuthenticationError("page failed to load", err)
@@ -75,14 +105,63 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 	time.Sleep(2 * time.Second) // wait for page to settle
 
 	// Try to accept cookies if the banner appears
-	acceptCookieBtn, err := page.Timeout(3*time.Second).ElementR("button", "Acceptera")
+	acceptCookieBtn, err := c.selectors.find(page.Timeout(3*time.Second), SelectorCookieAccept)
 	if err == nil {
 		if err := acceptCookieBtn.Click(proto.InputMouseButtonLeft, 1); err == nil {
 			time.Sleep(500 * time.Millisecond)
 		}
 	}
 
-	loginLink, err := page.Timeout(5*time.Second).ElementR("a", "Logga in")
+	// A persistent profile may still hold the session from the previous login
+	if c.browserProfileDir == "" || !c.hasActiveBrowserSession(page) {
+		if err := c.fillLoginForm(ctx, page, username, password); err != nil {
+			return err
+		}
+	}
+
+	cookies, err := page.Cookies(nil)
+	if err != nil {
+		return NewAuthenticationError("failed to extract cookies", err)
+	}
+
+	parsedURL, _ := url.Parse(c.baseURL)
+	httpCookies := make([]*http.Cookie, 0, len(cookies))
+
+	for _, cookie := range cookies {
+		httpCookie := &http.Cookie{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Path:     cookie.Path,
+			Domain:   cookie.Domain,
+			Expires:  time.Unix(int64(cookie.Expires), 0),
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HTTPOnly,
+			SameSite: http.SameSiteNoneMode,
+		}
+		httpCookies = append(httpCookies, httpCookie)
+	}
+
+	c.jar.SetCookies(parsedURL, httpCookies)
+
+	c.loggedIn(func(st *sessionState) {
+		st.username, st.password = username, password
+	})
+	c.authAttempts.Store(0)
+
+	_, err = c.FetchCSRFToken()
+	if err != nil {
+		return NewAuthenticationError("failed to fetch CSRF token after login", err)
+	}
+
+	c.mergeAnonymousCart(ctx)
+
+	return nil
+}
+
+// fillLoginForm opens the login dialog, submits the credentials, and handles the
+// verification code step if Willys asks for one.
+func (c *Client) fillLoginForm(ctx context.Context, page *rod.Page, username, password string) error {
+	loginLink, err := c.selectors.find(page.Timeout(5*time.Second), SelectorLoginLink)
 	if err != nil {
 		return NewAuthenticationError("failed to find login link", err)
 	}
@@ -93,12 +172,12 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 
 	time.Sleep(1 * time.Second) // dialog animation
 
-	dialog, err := page.Timeout(5 * time.Second).Element("dialog, [role='dialog']")
+	dialog, err := c.selectors.find(page.Timeout(5*time.Second), SelectorLoginDialog)
 	if err != nil {
 		return NewAuthenticationError("failed to find login dialog", err)
 	}
 
-	usernameInput, err := dialog.Timeout(5 * time.Second).Element("input[type='text']")
+	usernameInput, err := c.selectors.find(dialog.Timeout(5*time.Second), SelectorUsernameInput)
 	if err != nil {
 		return NewAuthenticationError("failed to find username input field", err)
 	}
@@ -106,7 +185,7 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 		return NewAuthenticationError("failed to input username", err)
 	}
 
-	passwordInput, err := dialog.Timeout(5 * time.Second).Element("input[type='password']")
+	passwordInput, err := c.selectors.find(dialog.Timeout(5*time.Second), SelectorPasswordInput)
 	if err != nil {
 		return NewAuthenticationError("failed to find password input field", err)
 	}
@@ -116,7 +195,7 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 
 	time.Sleep(500 * time.Millisecond) // let form validate
 
-	loginButton, err := page.Timeout(5*time.Second).ElementR("button", "^Logga in$")
+	loginButton, err := c.selectors.find(page.Timeout(5*time.Second), SelectorLoginButton)
 	if err != nil {
 		return NewAuthenticationError("failed to find login button", err)
 	}
@@ -126,47 +205,68 @@ func (c *Client) LoginWithBrowser(ctx context.Context, username, password string
 
 	time.Sleep(2 * time.Second) // wait for login response
 
-	// Check for error indicators (they use different class names)
-	hasError1, _, _ := page.Has("*[class*='error']")
-	hasError2, _, _ := page.Has("*[class*='Error']")
-	if hasError1 || hasError2 {
-		return NewAuthenticationError("invalid username or password", nil)
+	// A lockout notice usually matches login_error too, so check for it first
+	if lockout := c.detectLoginBlock(page); lockout != nil {
+		c.recordLockout(lockout)
+		return NewAuthenticationError("login blocked", lockout)
 	}
 
-	cookies, err := page.Cookies(nil)
-	if err != nil {
-		return NewAuthenticationError("failed to extract cookies", err)
+	if err := c.completeVerification(ctx, page); err != nil {
+		return err
 	}
 
-	parsedURL, _ := url.Parse(c.baseURL)
-	httpCookies := make([]*http.Cookie, 0, len(cookies))
+	if c.selectors.has(page, SelectorLoginError) {
+		return NewAuthenticationError("invalid username or password", ErrCredentialsInvalid)
+	}
 
-	for _, cookie := range cookies {
-		httpCookie := &http.Cookie{
-			Name:     cookie.Name,
-			Value:    cookie.Value,
-			Path:     cookie.Path,
-			Domain:   cookie.Domain,
-			Expires:  time.Unix(int64(cookie.Expires), 0),
-			Secure:   cookie.Secure,
-			HttpOnly: cookie.HTTPOnly,
-			SameSite: http.SameSiteNoneMode,
-		}
-		httpCookies = append(httpCookies, httpCookie)
+	return nil
+}
+
+// hasActiveBrowserSession reports whether the page shows a logged-in header, which
+// happens when a persistent profile kept the session from the previous login.
+func (c *Client) hasActiveBrowserSession(page *rod.Page) bool {
+	if _, err := c.selectors.find(page.Timeout(2*time.Second), SelectorLoggedIn); err != nil {
+		return false
 	}
+	return !c.selectors.has(page, SelectorLoginLink)
+}
 
-	c.httpClient.Jar.SetCookies(parsedURL, httpCookies)
+// completeVerification handles the emailed/SMS code step Willys sometimes shows after the
+// password. The code comes from submit_verification_code while the page stays open.
+func (c *Client) completeVerification(ctx context.Context, page *rod.Page) error {
+	codeInput, err := c.selectors.find(page.Timeout(2*time.Second), SelectorVerificationInput)
+	if err != nil {
+		return nil // no verification step
+	}
+
+	prompt := "Willys sent a verification code, submit it with submit_verification_code"
+	if text, err := c.selectors.find(page.Timeout(time.Second), SelectorVerificationPrompt); err == nil {
+		if t, err := text.Text(); err == nil && t != "" {
+			prompt = t
+		}
+	}
 
-	c.mu.Lock()
-	c.username = username
-	c.password = password
-	c.mu.Unlock()
+	code, err := c.verification.await(ctx, prompt)
+	if err != nil {
+		return NewAuthenticationError("verification code not provided", err)
+	}
 
-	c.authAttempts.Store(0)
+	if err := codeInput.Input(code); err != nil {
+		return NewAuthenticationError("failed to input verification code", err)
+	}
 
-	_, err = c.FetchCSRFToken()
+	confirmButton, err := c.selectors.find(page.Timeout(5*time.Second), SelectorVerificationSubmit)
 	if err != nil {
-		return NewAuthenticationError("failed to fetch CSRF token after login", err)
+		return NewAuthenticationError("failed to find verification confirm button", err)
+	}
+	if err := confirmButton.Click(proto.InputMouseButtonLeft, 1); err != nil {
+		return NewAuthenticationError("failed to click verification confirm button", err)
+	}
+
+	time.Sleep(2 * time.Second) // wait for verification response
+
+	if c.selectors.has(page, SelectorVerificationInput) {
+		return NewAuthenticationError("verification code was rejected", nil)
 	}
 
 	return nil
@@ -200,6 +300,12 @@ func (c *Client) Login(ctx context.Context, username, password string) error {
 		return NewValidationError("password", "password must be at least 6 characters")
 	}
 
+	if lockout := c.activeLockout(); lockout != nil {
+		return NewAuthenticationError("login paused", lockout)
+	}
+
+	c.captureAnonymousCart(ctx)
+
 	if err := c.InitializeSession(ctx); err != nil {
 		return NewAuthenticationError("failed to initialize session", err)
 	}
@@ -220,24 +326,21 @@ func (c *Client) Login(ctx context.Context, username, password string) error {
 	}
 	defer resp.Body.Close()
 
+	if lockout := lockoutFromResponse(resp, time.Now()); lockout != nil {
+		c.recordLockout(lockout)
+		return NewAuthenticationError("login refused", lockout)
+	}
 	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return NewAuthenticationError("invalid username or password", nil)
+		return NewAuthenticationError("invalid username or password", ErrCredentialsInvalid)
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		errorDetail := string(bodyBytes)
-		if errorDetail == "" {
-			errorDetail = "no additional details provided"
-		}
-		return NewAPIError(resp.StatusCode, EndpointLogin, fmt.Sprintf("login failed - %s", errorDetail), nil)
+		return responseError(ctx, resp, EndpointLogin, "login failed")
 	}
 
-	c.mu.Lock()
-	c.username = username
-	c.password = password
-	c.mu.Unlock()
-
+	c.loggedIn(func(st *sessionState) {
+		st.username, st.password = username, password
+	})
 	c.authAttempts.Store(0)
 
 	_, err = c.FetchCSRFToken()
@@ -245,13 +348,58 @@ func (c *Client) Login(ctx context.Context, username, password string) error {
 		return NewAuthenticationError("failed to fetch CSRF token after login", err)
 	}
 
+	c.mergeAnonymousCart(ctx)
+
 	return nil
 }
 
+// Logout ends the session on Willys and forgets everything that could log back in: the
+// cookie jar, CSRF token, stored credentials, and the persistent browser profile. The local state is cleared even if the
+// logout request fails, since the point is to leave nothing behind on a shared machine.
+func (c *Client) Logout(ctx context.Context) error {
+	var logoutErr error
+	resp, err := c.DoRequest(ctx, "GET", EndpointLogout, nil, false)
+	if err != nil {
+		logoutErr = newAPIError(ctx, 0, EndpointLogout, "logout request failed", err)
+	} else {
+		if resp.StatusCode >= http.StatusBadRequest {
+			logoutErr = responseError(ctx, resp, EndpointLogout, "logout failed")
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+
+	if err := c.jar.reset(); err != nil {
+		return fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	if c.browserProfileDir != "" {
+		if err := os.RemoveAll(c.browserProfileDir); err != nil && logoutErr == nil {
+			logoutErr = fmt.Errorf("failed to remove browser profile: %w", err)
+		}
+	}
+	// Login screenshots show the account too
+	if c.diagnosticsDir != "" {
+		if err := os.RemoveAll(c.diagnosticsDir); err != nil && logoutErr == nil {
+			logoutErr = fmt.Errorf("failed to remove diagnostics: %w", err)
+		}
+	}
+
+	// Only the lockout outlives a logout: it is Willys' state, not ours
+	c.session.update(func(st *sessionState) {
+		*st = sessionState{lockout: st.lockout, generation: st.generation + 1}
+	})
+	c.cartMirror.invalidate()
+	c.purchaseHistory.invalidate()
+	c.authAttempts.Store(0)
+
+	return logoutErr
+}
+
 func (c *Client) GetCustomerInfo(ctx context.Context) (*CustomerInfo, error) {
 	resp, err := c.DoRequest(ctx, "GET", EndpointCustomer, nil, false)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCustomer, "failed to get customer info", err)
+		return nil, newAPIError(ctx, 0, EndpointCustomer, "failed to get customer info", err)
 	}
 	defer resp.Body.Close()
 
@@ -260,12 +408,12 @@ func (c *Client) GetCustomerInfo(ctx context.Context) (*CustomerInfo, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, NewAPIError(resp.StatusCode, EndpointCustomer, "get customer info failed", nil)
+		return nil, responseError(ctx, resp, EndpointCustomer, "get customer info failed")
 	}
 
 	var customerInfo CustomerInfo
-	if err := json.NewDecoder(resp.Body).Decode(&customerInfo); err != nil {
-		return nil, NewAPIError(resp.StatusCode, EndpointCustomer, "failed to decode customer info", err)
+	if err := c.readJSON(resp, EndpointCustomer, &customerInfo, "email"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointCustomer, "failed to decode customer info", err)
 	}
 
 	return &customerInfo, nil
@@ -275,3 +423,59 @@ func (c *Client) IsAuthenticated() bool {
 	cookies := c.GetCookies()
 	return len(cookies) > 0
 }
+
+// setCredentialsInvalid records whether Willys rejected the stored credentials. While
+// set, DoRequest doesn't try to log in again on a 401.
+func (c *Client) setCredentialsInvalid(invalid bool) {
+	c.session.update(func(st *sessionState) {
+		switch {
+		case !invalid:
+			st.credentialsInvalidAt = time.Time{}
+		case st.credentialsInvalidAt.IsZero():
+			st.credentialsInvalidAt = time.Now()
+		}
+	})
+}
+
+func (c *Client) AuthStatus() AuthStatus {
+	st := c.session.snapshot()
+	invalidAt := st.credentialsInvalidAt
+	hasCredentials := st.canReauth()
+	authMethod := "password"
+	if st.refreshToken != "" {
+		authMethod = "refresh_token"
+	}
+
+	cookies := c.GetCookies()
+	prompt, verificationPending := c.verification.status()
+	lockout := c.activeLockout()
+
+	status := AuthStatus{
+		State:               AuthStateUnauthenticated,
+		Authenticated:       len(cookies) > 0,
+		HasCredentials:      hasCredentials,
+		CSRFTokenCached:     st.csrfToken != "",
+		AuthAttempts:        int(c.authAttempts.Load()),
+		CookieCount:         len(cookies),
+		AuthMethod:          authMethod,
+		VerificationPending: verificationPending,
+		VerificationPrompt:  prompt,
+		LastCartMerge:       st.lastCartMerge,
+		ActiveStore:         st.activeStore,
+	}
+	switch {
+	case lockout != nil:
+		status.State = AuthStateLockedOut
+		until := lockout.Until
+		status.LockedOutUntil = &until
+		status.LockoutReason = lockout.Reason
+	case !invalidAt.IsZero():
+		status.State = AuthStateCredentialsInvalid
+		status.CredentialsInvalidSince = &invalidAt
+	case verificationPending:
+		status.State = AuthStateVerificationPending
+	case status.Authenticated:
+		status.State = AuthStateAuthenticated
+	}
+	return status
+}
@@ -0,0 +1,147 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLogout(t *testing.T) {
+	loggedOut := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == EndpointLogout {
+			loggedOut = true
+		}
+	}))
+	defer srv.Close()
+
+	diagnostics := filepath.Join(t.TempDir(), "diagnostics")
+	if err := os.MkdirAll(diagnostics, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewClient(srv.URL, "199001011234", "secret123", WithDiagnosticsDir(diagnostics))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.SetCookies([]*http.Cookie{{Name: "JSESSIONID", Value: "abc"}})
+	client.session.update(func(st *sessionState) { st.csrfToken = "token" })
+	client.authAttempts.Store(1)
+
+	if err := client.Logout(context.Background()); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if !loggedOut {
+		t.Error("Expected logout endpoint to be called")
+	}
+	status := client.AuthStatus()
+	if status.Authenticated || status.HasCredentials || status.CSRFTokenCached || status.AuthAttempts != 0 {
+		t.Errorf("Expected session to be fully cleared, got %+v", status)
+	}
+	if _, err := os.Stat(diagnostics); !os.IsNotExist(err) {
+		t.Errorf("Expected the diagnostics to be removed, got %v", err)
+	}
+}
+
+func TestReauthNotifier(t *testing.T) {
+	loggedIn := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointLogin:
+			loggedIn = true
+		case EndpointCartAddProducts:
+			if !loggedIn {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "199001011234", "secret123")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	var events []ReauthEvent
+	ctx := WithReauthNotifier(context.Background(), func(e ReauthEvent) {
+		events = append(events, e)
+	})
+
+	resp, err := client.DoRequest(ctx, "POST", EndpointCartAddProducts, nil, true)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after re-auth, got %d", resp.StatusCode)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 re-auth event, got %d", len(events))
+	}
+	if events[0].Endpoint != EndpointCartAddProducts || events[0].Err != nil {
+		t.Errorf("Unexpected re-auth event: %+v", events[0])
+	}
+}
+
+func TestAuthenticationErrorDiagnostics(t *testing.T) {
+	err := NewAuthenticationError("failed to find login dialog", nil)
+	err.Diagnostics = []string{"/tmp/login.png", "/tmp/login.html"}
+
+	expected := "failed to find login dialog (diagnostics: /tmp/login.png, /tmp/login.html)"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestCredentialsInvalidStopsReauth(t *testing.T) {
+	logins, passwordUpdated := 0, false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointLogin:
+			logins++
+			if !passwordUpdated {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		case EndpointCartAddProducts:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "199001011234", "changed-elsewhere")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.DoRequest(context.Background(), "POST", EndpointCartAddProducts, nil, true)
+		if !errors.Is(err, ErrCredentialsInvalid) {
+			t.Fatalf("Request %d: expected ErrCredentialsInvalid, got %v", i, err)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("Expected a single login attempt, got %d", logins)
+	}
+
+	status := client.AuthStatus()
+	if status.State != AuthStateCredentialsInvalid || status.CredentialsInvalidSince == nil {
+		t.Errorf("Expected CREDENTIALS_INVALID, got %s (since %v)", status.State, status.CredentialsInvalidSince)
+	}
+
+	passwordUpdated = true
+	if err := client.Login(context.Background(), "199001011234", "new-secret"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if status := client.AuthStatus(); status.State == AuthStateCredentialsInvalid {
+		t.Error("Expected a successful login to clear the state")
+	}
+}
@@ -0,0 +1,147 @@
+package willys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	PickCheapestUnit   = "cheapest_unit"
+	PickPreferredBrand = "preferred_brand"
+	PickHistorical     = "historical"
+)
+
+type (
+	PickPolicy struct {
+		Strategy        string   `json:"strategy"` // "cheapest_unit" | "preferred_brand" | "historical"
+		PreferredBrands []string `json:"preferred_brands,omitempty"`
+	}
+
+	// PickHistory remembers which product was chosen for a query, so the historical
+	// policy keeps picking the same milk week after week.
+	PickHistory interface {
+		LastChoice(query string) (string, bool)
+	}
+
+	PickResult struct {
+		Query      string   `json:"query"`
+		Product    *Product `json:"product,omitempty"`
+		Reason     string   `json:"reason"`
+		Candidates int      `json:"candidates"`
+		// Confidence is set when the product was found by semantic matching (0-1)
+		Confidence float64 `json:"confidence,omitempty"`
+	}
+)
+
+func DefaultPickPolicy() PickPolicy {
+	return PickPolicy{Strategy: PickCheapestUnit}
+}
+
+func ValidatePickPolicy(policy PickPolicy) error {
+	switch policy.Strategy {
+	case "", PickCheapestUnit, PickHistorical:
+		return nil
+	case PickPreferredBrand:
+		if len(policy.PreferredBrands) == 0 {
+			return NewValidationError("preferred_brands", "required for preferred_brand strategy")
+		}
+		return nil
+	default:
+		return NewValidationError("strategy", fmt.Sprintf("unknown pick strategy: %s", policy.Strategy))
+	}
+}
+
+// AutoPick selects a single product for a query. The result is deterministic for the same
+// input: ties on unit price are broken by shelf price and then product code.
+func AutoPick(query string, products []Product, policy PickPolicy, history PickHistory) PickResult {
+	result := PickResult{Query: query}
+
+	candidates := make([]Product, 0, len(products))
+	for _, p := range products {
+		if p.OutOfStock {
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+	result.Candidates = len(candidates)
+
+	if len(candidates) == 0 {
+		result.Reason = "no in-stock products matched the query"
+		return result
+	}
+
+	sortByUnitPrice(candidates)
+
+	switch policy.Strategy {
+	case PickHistorical:
+		if history != nil {
+			if code, ok := history.LastChoice(query); ok {
+				for i := range candidates {
+					if candidates[i].Code == code {
+						result.Product = &candidates[i]
+						result.Reason = fmt.Sprintf("previously chosen for %q", query)
+						return result
+					}
+				}
+			}
+		}
+		result.Product = &candidates[0]
+		result.Reason = "no previous choice available, picked cheapest per unit"
+		return result
+
+	case PickPreferredBrand:
+		for _, brand := range policy.PreferredBrands {
+			brandLower := strings.ToLower(brand)
+			for i := range candidates {
+				if strings.Contains(strings.ToLower(candidates[i].Manufacturer), brandLower) ||
+					strings.Contains(strings.ToLower(candidates[i].Name), brandLower) {
+					result.Product = &candidates[i]
+					result.Reason = fmt.Sprintf("cheapest per unit from preferred brand %s", brand)
+					return result
+				}
+			}
+		}
+		result.Product = &candidates[0]
+		result.Reason = "no preferred brand available, picked cheapest per unit"
+		return result
+
+	default:
+		result.Product = &candidates[0]
+		result.Reason = fmt.Sprintf("cheapest per unit (%s)", formatComparePrice(candidates[0]))
+		return result
+	}
+}
+
+func sortByUnitPrice(products []Product) {
+	sort.SliceStable(products, func(i, j int) bool {
+		iPrice := unitPriceOrMax(products[i])
+		jPrice := unitPriceOrMax(products[j])
+		if iPrice != jPrice {
+			return iPrice < jPrice
+		}
+		if products[i].PriceValue.Ore != products[j].PriceValue.Ore {
+			return products[i].PriceValue.Ore < products[j].PriceValue.Ore
+		}
+		return products[i].Code < products[j].Code
+	})
+}
+
+// Products without a compare price sort last rather than first.
+func unitPriceOrMax(p Product) float64 {
+	price := p.ComparePrice.Float()
+	if price <= 0 {
+		return 1e12
+	}
+	return price
+}
+
+func formatComparePrice(p Product) string {
+	if p.ComparePrice.IsZero() {
+		return "no unit price"
+	}
+	if p.ComparePriceUnit != "" {
+		return fmt.Sprintf("%s/%s", p.ComparePrice, p.ComparePriceUnit)
+	}
+	return p.ComparePrice.String()
+}
@@ -0,0 +1,43 @@
+package willys
+
+import (
+	"testing"
+)
+
+type staticHistory map[string]string
+
+func (h staticHistory) LastChoice(query string) (string, bool) {
+	code, ok := h[query]
+	return code, ok
+}
+
+func TestAutoPick(t *testing.T) {
+	products := []Product{
+		{Code: "1_ST", Name: "Mjölk Arla", Manufacturer: "Arla", ComparePrice: SEK(1590), PriceValue: SEK(1590)},
+		{Code: "2_ST", Name: "Mjölk Garant", Manufacturer: "Garant", ComparePrice: SEK(1290), PriceValue: SEK(1290)},
+		{Code: "3_ST", Name: "Mjölk Slut", ComparePrice: SEK(990), PriceValue: SEK(990), OutOfStock: true},
+	}
+
+	pick := AutoPick("mjölk", products, DefaultPickPolicy(), nil)
+	if pick.Product == nil || pick.Product.Code != "2_ST" {
+		t.Fatalf("Expected cheapest in-stock product 2_ST, got %+v", pick)
+	}
+	if pick.Candidates != 2 {
+		t.Errorf("Expected 2 candidates, got %d", pick.Candidates)
+	}
+
+	pick = AutoPick("mjölk", products, PickPolicy{Strategy: PickPreferredBrand, PreferredBrands: []string{"arla"}}, nil)
+	if pick.Product == nil || pick.Product.Code != "1_ST" {
+		t.Errorf("Expected preferred brand product 1_ST, got %+v", pick)
+	}
+
+	pick = AutoPick("mjölk", products, PickPolicy{Strategy: PickHistorical}, staticHistory{"mjölk": "1_ST"})
+	if pick.Product == nil || pick.Product.Code != "1_ST" {
+		t.Errorf("Expected historical product 1_ST, got %+v", pick)
+	}
+
+	pick = AutoPick("mjölk", nil, DefaultPickPolicy(), nil)
+	if pick.Product != nil {
+		t.Errorf("Expected no pick for empty results, got %+v", pick.Product)
+	}
+}
@@ -0,0 +1,62 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// WithB2B switches the client to a business account: deliverability and slots are
+// requested with b2b=true, which covers the business delivery areas and slots, and
+// checkout is expected to be paid by invoice.
+func WithB2B(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.b2b = enabled
+	}
+}
+
+// B2B reports whether the client acts for a business account.
+func (c *Client) B2B() bool {
+	return c.b2b
+}
+
+func (c *Client) b2bParam() string {
+	return strconv.FormatBool(c.b2b)
+}
+
+// SetPaymentType chooses how the cart is paid at checkout. Invoice is refused up front
+// when the account isn't approved for it, rather than at the checkout page.
+func (c *Client) SetPaymentType(ctx context.Context, paymentType string) error {
+	if !slices.Contains([]string{PaymentTypeCard, PaymentTypeInvoice, PaymentTypeSwish}, paymentType) {
+		return NewValidationError("payment_type", fmt.Sprintf("unknown payment type %q (use card, invoice, or swish)", paymentType))
+	}
+	if paymentType == PaymentTypeInvoice {
+		methods, err := c.GetPaymentMethods(ctx)
+		if err != nil {
+			return err
+		}
+		if !methods.InvoiceEligible {
+			return NewValidationError("payment_type", "the account is not approved for invoice payment")
+		}
+	}
+
+	params := url.Values{}
+	params.Set("paymentType", paymentType)
+	params.Set("b2b", c.b2bParam())
+	path := fmt.Sprintf("%s?%s", EndpointCartPaymentType, params.Encode())
+
+	resp, err := c.DoRequest(ctx, "POST", path, nil, true)
+	if err != nil {
+		return newAPIError(ctx, 0, path, "set payment type request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return responseError(ctx, resp, path, "set payment type failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestB2B(t *testing.T) {
+	var b2bParams []string
+	var paymentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointSlotHomeDelivery:
+			b2bParams = append(b2bParams, r.URL.Query().Get("b2b"))
+			w.Write([]byte(`{"isocode": "SE", "slots": []}`))
+		case EndpointPaymentMethods:
+			w.Write([]byte(`{"savedCards": [], "invoiceEligible": false}`))
+		case EndpointCartPaymentType:
+			paymentType = r.URL.Query().Get("paymentType")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	consumer, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	business, err := NewClient(srv.URL, "", "", WithB2B(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	consumer.GetAvailableTimeSlots(ctx, "11151")
+	business.GetAvailableTimeSlots(ctx, "11151")
+	if len(b2bParams) != 2 || b2bParams[0] != "false" || b2bParams[1] != "true" {
+		t.Errorf("Expected b2b=false then b2b=true, got %v", b2bParams)
+	}
+
+	if err := business.SetPaymentType(ctx, PaymentTypeInvoice); !IsValidationError(err) || paymentType != "" {
+		t.Errorf("Expected invoice refused for an unapproved account, got %v", err)
+	}
+	if err := business.SetPaymentType(ctx, "cash"); !IsValidationError(err) {
+		t.Errorf("Expected an unknown payment type refused, got %v", err)
+	}
+	if err := business.SetPaymentType(ctx, PaymentTypeCard); err != nil || paymentType != PaymentTypeCard {
+		t.Errorf("Expected card selected, got %q (%v)", paymentType, err)
+	}
+}
@@ -0,0 +1,132 @@
+package willys
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// BudgetLine is one planned item with its expected cost. Estimated is set when the
+	// cost comes from past orders because search found no product; Unpriced when neither
+	// had a price.
+	BudgetLine struct {
+		Query      string `json:"query"`
+		Quantity   int    `json:"quantity"`
+		Code       string `json:"code,omitempty"`
+		Name       string `json:"name,omitempty"`
+		Category   string `json:"category"`
+		Member     string `json:"member,omitempty"` // household member the item is for
+		Cost       Money  `json:"cost"`
+		Estimated  bool   `json:"estimated,omitempty"`
+		Unpriced   bool   `json:"unpriced,omitempty"`
+		OverBudget bool   `json:"overBudget,omitempty"`
+	}
+
+	CategorySpend struct {
+		Category string  `json:"category"`
+		Spend    Money   `json:"spend"`
+		Share    float64 `json:"share"` // of the budget, 0-1
+	}
+
+	// MemberSpend is what the lines for one household member cost; lines without a
+	// member are under Member "".
+	MemberSpend struct {
+		Member string  `json:"member,omitempty"`
+		Spend  Money   `json:"spend"`
+		Share  float64 `json:"share"` // of the total, 0-1
+	}
+
+	// BudgetPlan allocates a budget across the departments of the planned items.
+	BudgetPlan struct {
+		Budget     Money           `json:"budget"`
+		Total      Money           `json:"total"`
+		Remaining  Money           `json:"remaining"`
+		OverBudget bool            `json:"overBudget"`
+		Categories []CategorySpend `json:"categories"`
+		// Members splits the total by household member, when any line has one
+		Members []MemberSpend `json:"members,omitempty"`
+		Lines   []BudgetLine  `json:"lines"`
+		// Over lists the lines that push the plan over budget: the line where the running
+		// total first exceeds it and every line after.
+		Over []BudgetLine `json:"over,omitempty"`
+	}
+)
+
+// HistoricalPrice returns the average unit price paid for products whose name contains
+// query, over all orders.
+func HistoricalPrice(orders []Order, query string) (Money, bool) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return Money{}, false
+	}
+
+	var sum int64
+	var count int
+	for _, order := range orders {
+		for _, entry := range order.Entries {
+			if entry.Price.IsZero() || !strings.Contains(strings.ToLower(entry.Name), query) {
+				continue
+			}
+			sum += entry.Price.Ore * int64(entry.Quantity)
+			count += entry.Quantity
+		}
+	}
+	if count == 0 {
+		return Money{}, false
+	}
+	return SEK(sum / int64(count)), true
+}
+
+// PlanBudget totals lines against budget, in the order given, and groups the spend by
+// department, biggest first.
+func PlanBudget(lines []BudgetLine, budget Money) BudgetPlan {
+	plan := BudgetPlan{Budget: budget, Total: SEK(0), Lines: lines}
+
+	spend := make(map[string]Money)
+	memberSpend := make(map[string]Money)
+	for i := range plan.Lines {
+		line := &plan.Lines[i]
+		plan.Total = plan.Total.Add(line.Cost)
+		spend[line.Category] = spend[line.Category].Add(line.Cost)
+		memberSpend[line.Member] = memberSpend[line.Member].Add(line.Cost)
+		if plan.Total.Ore > budget.Ore {
+			line.OverBudget = true
+			plan.Over = append(plan.Over, *line)
+		}
+	}
+	plan.Remaining = budget.Sub(plan.Total)
+	plan.OverBudget = plan.Total.Ore > budget.Ore
+
+	for category, amount := range spend {
+		share := 0.0
+		if budget.Ore > 0 {
+			share = float64(amount.Ore) / float64(budget.Ore)
+		}
+		plan.Categories = append(plan.Categories, CategorySpend{Category: category, Spend: amount, Share: share})
+	}
+	sort.Slice(plan.Categories, func(i, j int) bool {
+		if plan.Categories[i].Spend.Ore != plan.Categories[j].Spend.Ore {
+			return plan.Categories[i].Spend.Ore > plan.Categories[j].Spend.Ore
+		}
+		return plan.Categories[i].Category < plan.Categories[j].Category
+	})
+
+	if _, untagged := memberSpend[""]; len(memberSpend) > 1 || !untagged {
+		for member, amount := range memberSpend {
+			share := 0.0
+			if plan.Total.Ore > 0 {
+				share = float64(amount.Ore) / float64(plan.Total.Ore)
+			}
+			plan.Members = append(plan.Members, MemberSpend{Member: member, Spend: amount, Share: share})
+		}
+		// Named members alphabetically, then the lines without one
+		sort.Slice(plan.Members, func(i, j int) bool {
+			a, b := plan.Members[i].Member, plan.Members[j].Member
+			if a == "" || b == "" {
+				return b == ""
+			}
+			return a < b
+		})
+	}
+	return plan
+}
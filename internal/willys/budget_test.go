@@ -0,0 +1,48 @@
+package willys
+
+import "testing"
+
+func TestHistoricalPrice(t *testing.T) {
+	orders := []Order{
+		{Entries: []OrderEntry{{Name: "Mellanmjölk 1,5% 1l", Quantity: 2, Price: SEK(1790)}}},
+		{Entries: []OrderEntry{{Name: "Mellanmjölk 1,5% 1l", Quantity: 3, Price: SEK(1690)}, {Name: "Smör", Quantity: 1}}},
+	}
+
+	price, ok := HistoricalPrice(orders, "Mjölk")
+	if !ok || price != SEK(1730) {
+		t.Errorf("Expected weighted average 17,30 kr, got %v (%v)", price, ok)
+	}
+	if _, ok := HistoricalPrice(orders, "smör"); ok {
+		t.Error("Expected entries without a price to be ignored")
+	}
+}
+
+func TestPlanBudget(t *testing.T) {
+	lines := []BudgetLine{
+		{Query: "mjölk", Category: "Mejeri, ost och ägg", Cost: SEK(5000)},
+		{Query: "kyckling", Category: "Kött, chark och fågel", Cost: SEK(12000)},
+		{Query: "ost", Category: "Mejeri, ost och ägg", Cost: SEK(9000)},
+		{Query: "bröd", Category: "Bröd och kakor", Cost: SEK(3000)},
+	}
+
+	plan := PlanBudget(lines, SEK(20000))
+
+	if plan.Total != SEK(29000) || plan.Remaining != SEK(-9000) || !plan.OverBudget {
+		t.Errorf("Unexpected totals: %+v", plan)
+	}
+	if len(plan.Over) != 2 || plan.Over[0].Query != "ost" || plan.Over[1].Query != "bröd" {
+		t.Errorf("Expected ost and bröd over budget, got %+v", plan.Over)
+	}
+	if plan.Categories[0].Category != "Mejeri, ost och ägg" || plan.Categories[0].Share != 0.7 {
+		t.Errorf("Expected dairy first at 70%% of budget, got %+v", plan.Categories)
+	}
+	if plan.Members != nil {
+		t.Errorf("Expected no member split without members, got %+v", plan.Members)
+	}
+
+	lines[0].Member, lines[2].Member = "Erik", "Anna"
+	plan = PlanBudget(lines, SEK(20000))
+	if len(plan.Members) != 3 || plan.Members[0].Member != "Anna" || plan.Members[1].Spend != SEK(5000) || plan.Members[2].Member != "" || plan.Members[2].Spend != SEK(15000) {
+		t.Errorf("Expected Anna, Erik, then the rest, got %+v", plan.Members)
+	}
+}
@@ -4,28 +4,31 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"io"
 	"net/http"
-	"strconv"
 )
 
 type (
 	CartItem struct {
-		ProductCode string  `json:"code"`
-		Name        string  `json:"name"`
-		Quantity    int     `json:"quantity"`
-		Price       float64 `json:"price"`
-		TotalPrice  float64 `json:"totalPrice"`
-		ImageURL    string  `json:"imageUrl"`
+		ProductCode    string          `json:"code"`
+		Name           string          `json:"name"`
+		Quantity       int             `json:"quantity"`
+		Price          Money           `json:"price"`
+		TotalPrice     Money           `json:"totalPrice"`
+		ImageURL       string          `json:"imageUrl"`
+		Labels         []string        `json:"labels,omitempty"`
+		Sustainability *Sustainability `json:"sustainability,omitempty"`
+		Category       string          `json:"category,omitempty"` // Axfood category path, e.g. "mejeri-ost-och-agg|mjolk"
+		StockStatus    string          `json:"stockStatus,omitempty"`
 	}
 
 	CartSummary struct {
 		Items       []CartItem `json:"items"`
-		TotalPrice  float64    `json:"totalPrice"`
+		TotalPrice  Money      `json:"totalPrice"`
 		ItemCount   int        `json:"itemCount"`
-		DeliveryFee float64    `json:"deliveryFee"`
-		PickingFee  float64    `json:"pickingFee"`
-		FinalTotal  float64    `json:"finalTotal"`
+		DeliveryFee Money      `json:"deliveryFee"`
+		PickingFee  Money      `json:"pickingFee"`
+		FinalTotal  Money      `json:"finalTotal"`
+		GUID        string     `json:"guid,omitempty"`
 	}
 
 	AddToCartRequest struct {
@@ -40,26 +43,27 @@ type (
 		NoReplacementFlag   bool   `json:"noReplacementFlag"`
 	}
 
-	// Prices can be a string, number, or an object with a "value" field
-	FlexiblePrice struct {
-		value any
-	}
-
 	CartProductData struct {
-		Code     string        `json:"code"`
-		Name     string        `json:"name"`
-		Quantity int           `json:"quantity"`
-		Price    FlexiblePrice `json:"price"` // Can be string, number, or {value: number}
+		Code     string `json:"code"`
+		Name     string `json:"name"`
+		Quantity int    `json:"quantity"`
+		Price    Money  `json:"price"` // Can be string, number, or {value: number}
 		Image    struct {
 			URL string `json:"url"`
 		} `json:"image"`
+		Labels        []string `json:"labels"`
+		Category      string   `json:"googleAnalyticsCategory"`
+		OutOfStock    bool     `json:"outOfStock"`
+		LowStock      bool     `json:"lowStock"`
+		StockQuantity *int     `json:"stockQuantity"`
 	}
 
 	CartResponseData struct {
 		Products    []CartProductData `json:"products"`
-		TotalPrice  FlexiblePrice     `json:"totalPrice"`  // Can be string or number
-		DeliveryFee FlexiblePrice     `json:"deliveryFee"` // Can be string or number
-		PickingFee  FlexiblePrice     `json:"pickingFee"`  // Can be string or number
+		TotalPrice  Money             `json:"totalPrice"`  // Can be string or number
+		DeliveryFee Money             `json:"deliveryFee"` // Can be string or number
+		PickingFee  Money             `json:"pickingFee"`  // Can be string or number
+		GUID        string            `json:"guid"`
 	}
 )
 
@@ -85,12 +89,12 @@ func (c *Client) AddToCart(ctx context.Context, productCode string, quantity int
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCartAddProducts, "failed to marshal add to cart request", err)
+		return nil, newAPIError(ctx, 0, EndpointCartAddProducts, "failed to marshal add to cart request", err)
 	}
 
 	resp, err := c.DoRequest(ctx, "POST", EndpointCartAddProducts, bytes.NewReader(jsonData), true)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCartAddProducts, "add to cart request failed", err)
+		return nil, newAPIError(ctx, 0, EndpointCartAddProducts, "add to cart request failed", err)
 	}
 	defer resp.Body.Close()
 
@@ -98,101 +102,98 @@ func (c *Client) AddToCart(ctx context.Context, productCode string, quantity int
 		return nil, NewNotFoundError("product", productCode)
 	}
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, NewAPIError(resp.StatusCode, EndpointCartAddProducts, "add to cart failed", nil)
-	}
-
-	return c.GetCart(ctx)
-}
-
-func (fp *FlexiblePrice) UnmarshalJSON(data []byte) error {
-	var v any
-	if err := json.Unmarshal(data, &v); err != nil {
-		return err
+		return nil, responseError(ctx, resp, EndpointCartAddProducts, "add to cart failed")
 	}
-	fp.value = v
-	return nil
-}
 
-func (fp FlexiblePrice) Value() any {
-	return fp.value
-}
-
-func parsePrice(v any) float64 {
-	switch val := v.(type) {
-	case float64:
-		return val
-	case string:
-		if val == "" {
-			return 0
-		}
-		price, err := strconv.ParseFloat(val, 64)
-		if err != nil {
-			return 0
-		}
-		return price
-	case map[string]any:
-		if valueField, ok := val["value"]; ok {
-			return parsePrice(valueField)
-		}
-		return 0
-	default:
-		return 0
-	}
+	return c.cartFromMutation(ctx, resp)
 }
 
 func (c *Client) GetCart(ctx context.Context) (*CartSummary, error) {
+	generation := c.cartMirror.current()
 	resp, err := c.DoRequest(ctx, "GET", EndpointCart, nil, false)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCart, "get cart request failed", err)
+		return nil, newAPIError(ctx, 0, EndpointCart, "get cart request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, NewAPIError(resp.StatusCode, EndpointCart, "get cart failed", nil)
+		return nil, responseError(ctx, resp, EndpointCart, "get cart failed")
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	var cartData CartResponseData
+
+	if err := c.readJSON(resp, EndpointCart, &cartData, "products", "totalPrice", "products[].code", "products[].quantity", "products[].price"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointCart, "failed to parse cart response", err)
+	}
+
+	cart := newCartSummary(cartData)
+	c.cartMirror.store(cart, generation)
+	return cart, nil
+}
+
+// cartFromMutation returns the updated cart that addProducts sends back, saving a second
+// round trip. Responses without a cart fall back to GetCart.
+func (c *Client) cartFromMutation(ctx context.Context, resp *http.Response) (*CartSummary, error) {
+	generation := c.cartMirror.current()
+	body, err := c.readBody(resp)
 	if err != nil {
-		return nil, NewAPIError(resp.StatusCode, EndpointCart, "failed to read cart response", err)
+		return c.GetCart(ctx)
 	}
 
-	var cartData CartResponseData
+	var probe struct {
+		Products   json.RawMessage `json:"products"`
+		TotalPrice json.RawMessage `json:"totalPrice"`
+	}
+	if unmarshalJSON(body, &probe, false) != nil || !present(probe.Products) || !present(probe.TotalPrice) {
+		return c.GetCart(ctx)
+	}
 
-	if err := json.Unmarshal(body, &cartData); err != nil {
-		return nil, NewAPIError(resp.StatusCode, EndpointCart, "failed to parse cart response", err)
+	var cartData CartResponseData
+	if err := c.decodeJSON(EndpointCartAddProducts, body, &cartData, "products", "totalPrice", "products[].code", "products[].quantity", "products[].price"); err != nil {
+		return c.GetCart(ctx)
 	}
 
-	totalPrice := parsePrice(cartData.TotalPrice.Value())
-	deliveryFee := parsePrice(cartData.DeliveryFee.Value())
-	pickingFee := parsePrice(cartData.PickingFee.Value())
+	cart := newCartSummary(cartData)
+	c.cartMirror.store(cart, generation)
+	return cart, nil
+}
 
+func present(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+func newCartSummary(cartData CartResponseData) *CartSummary {
 	items := make([]CartItem, 0, len(cartData.Products))
 	itemCount := 0
 
 	for _, product := range cartData.Products {
-		itemPrice := parsePrice(product.Price.Value())
 		cartItem := CartItem{
 			product.Code,
 			product.Name,
 			product.Quantity,
-			itemPrice,
-			itemPrice * float64(product.Quantity),
+			product.Price,
+			product.Price.Mul(product.Quantity),
 			product.Image.URL,
+			product.Labels,
+			ParseSustainability(product.Labels),
+			product.Category,
+			ParseStockStatus(product.OutOfStock, product.LowStock, product.StockQuantity),
 		}
 		items = append(items, cartItem)
 		itemCount += product.Quantity
 	}
 
-	finalTotal := totalPrice + deliveryFee + pickingFee
+	finalTotal := cartData.TotalPrice.Add(cartData.DeliveryFee).Add(cartData.PickingFee)
 
 	return &CartSummary{
 		items,
-		totalPrice,
+		cartData.TotalPrice,
 		itemCount,
-		deliveryFee,
-		pickingFee,
+		cartData.DeliveryFee,
+		cartData.PickingFee,
 		finalTotal,
-	}, nil
+		cartData.GUID,
+	}
 }
 
 func (c *Client) RemoveFromCart(ctx context.Context, productCode string, quantity int) (*CartSummary, error) {
@@ -237,31 +238,31 @@ func (c *Client) RemoveFromCart(ctx context.Context, productCode string, quantit
 
 	jsonData, err := json.Marshal(req)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCartAddProducts, "failed to marshal remove from cart request", err)
+		return nil, newAPIError(ctx, 0, EndpointCartAddProducts, "failed to marshal remove from cart request", err)
 	}
 
 	resp, err := c.DoRequest(ctx, "POST", EndpointCartAddProducts, bytes.NewReader(jsonData), true)
 	if err != nil {
-		return nil, NewAPIError(0, EndpointCartAddProducts, "remove from cart request failed", err)
+		return nil, newAPIError(ctx, 0, EndpointCartAddProducts, "remove from cart request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, NewAPIError(resp.StatusCode, EndpointCartAddProducts, "remove from cart failed", nil)
+		return nil, responseError(ctx, resp, EndpointCartAddProducts, "remove from cart failed")
 	}
 
-	return c.GetCart(ctx)
+	return c.cartFromMutation(ctx, resp)
 }
 
 func (c *Client) ClearCart(ctx context.Context) error {
 	resp, err := c.DoRequest(ctx, "DELETE", EndpointCart, nil, true)
 	if err != nil {
-		return NewAPIError(0, EndpointCart, "clear cart request failed", err)
+		return newAPIError(ctx, 0, EndpointCart, "clear cart request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return NewAPIError(resp.StatusCode, EndpointCart, "clear cart failed", nil)
+		return responseError(ctx, resp, EndpointCart, "clear cart failed")
 	}
 
 	return nil
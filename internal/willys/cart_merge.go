@@ -0,0 +1,138 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type (
+	// CartMergeResult describes what happened to a guest cart when the user logged in.
+	// Merged items made it into the account cart with at least the guest quantity;
+	// conflicts are items that are missing or have a lower quantity after the merge.
+	CartMergeResult struct {
+		AnonymousGUID string              `json:"anonymousGuid"`
+		Merged        []CartItem          `json:"merged"`
+		Conflicts     []CartMergeConflict `json:"conflicts"`
+		MergedAt      time.Time           `json:"mergedAt"`
+	}
+
+	CartMergeConflict struct {
+		ProductCode       string `json:"code"`
+		Name              string `json:"name"`
+		AnonymousQuantity int    `json:"anonymousQuantity"`
+		MergedQuantity    int    `json:"mergedQuantity"`
+		Reason            string `json:"reason"`
+	}
+)
+
+// captureAnonymousCart remembers the guest cart before login replaces the session, so
+// the items can be merged into the account cart afterwards. Re-authentication of an
+// existing account session has no guest cart and is skipped; configured credentials
+// alone don't make the session an account session.
+func (c *Client) captureAnonymousCart(ctx context.Context) {
+	if c.session.snapshot().accountSession || !c.IsAuthenticated() {
+		return
+	}
+
+	cart, err := c.GetCart(ctx)
+	if err != nil || len(cart.Items) == 0 || cart.GUID == "" {
+		return
+	}
+
+	c.session.update(func(st *sessionState) { st.anonymousCart = cart })
+}
+
+// mergeAnonymousCart asks Willys to merge the captured guest cart into the account cart.
+// A failed merge doesn't fail the login; it is logged and reported in AuthStatus.
+func (c *Client) mergeAnonymousCart(ctx context.Context) {
+	var anonymous *CartSummary
+	c.session.update(func(st *sessionState) {
+		anonymous, st.anonymousCart = st.anonymousCart, nil
+	})
+
+	if anonymous == nil {
+		return
+	}
+
+	result, err := c.MergeCart(ctx, anonymous)
+	if err != nil {
+		log.Printf("Failed to merge guest cart %s: %v", anonymous.GUID, err)
+		return
+	}
+	if len(result.Conflicts) > 0 {
+		log.Printf("Merged guest cart %s with %d conflicting items", anonymous.GUID, len(result.Conflicts))
+	}
+}
+
+// MergeCart merges a guest cart into the logged-in user's cart and reports which of the
+// guest items survived the merge.
+func (c *Client) MergeCart(ctx context.Context, anonymous *CartSummary) (*CartMergeResult, error) {
+	if anonymous == nil || anonymous.GUID == "" {
+		return nil, NewValidationError("guid", "anonymous cart GUID cannot be empty")
+	}
+
+	path := fmt.Sprintf("%s?oldCartId=%s", EndpointCartMerge, url.QueryEscape(anonymous.GUID))
+	resp, err := c.DoRequest(ctx, "POST", path, nil, true)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, EndpointCartMerge, "cart merge request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, responseError(ctx, resp, EndpointCartMerge, "cart merge failed")
+	}
+
+	merged, err := c.GetCart(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := compareMergedCart(anonymous, merged)
+
+	c.session.update(func(st *sessionState) { st.lastCartMerge = result })
+
+	return result, nil
+}
+
+func compareMergedCart(anonymous, merged *CartSummary) *CartMergeResult {
+	result := &CartMergeResult{
+		AnonymousGUID: anonymous.GUID,
+		Merged:        []CartItem{},
+		Conflicts:     []CartMergeConflict{},
+		MergedAt:      time.Now(),
+	}
+
+	mergedByCode := make(map[string]CartItem, len(merged.Items))
+	for _, item := range merged.Items {
+		mergedByCode[item.ProductCode] = item
+	}
+
+	for _, item := range anonymous.Items {
+		found, ok := mergedByCode[item.ProductCode]
+		switch {
+		case !ok:
+			result.Conflicts = append(result.Conflicts, CartMergeConflict{
+				ProductCode:       item.ProductCode,
+				Name:              item.Name,
+				AnonymousQuantity: item.Quantity,
+				Reason:            "missing from cart after merge",
+			})
+		case found.Quantity < item.Quantity:
+			result.Conflicts = append(result.Conflicts, CartMergeConflict{
+				ProductCode:       item.ProductCode,
+				Name:              item.Name,
+				AnonymousQuantity: item.Quantity,
+				MergedQuantity:    found.Quantity,
+				Reason:            "quantity reduced during merge",
+			})
+		default:
+			result.Merged = append(result.Merged, found)
+		}
+	}
+
+	return result
+}
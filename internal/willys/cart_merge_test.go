@@ -0,0 +1,91 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareMergedCart(t *testing.T) {
+	anonymous := &CartSummary{
+		GUID: "guest-1",
+		Items: []CartItem{
+			{ProductCode: "1_ST", Name: "Mjölk", Quantity: 2},
+			{ProductCode: "2_ST", Name: "Bröd", Quantity: 3},
+			{ProductCode: "3_ST", Name: "Ost", Quantity: 1},
+		},
+	}
+	merged := &CartSummary{
+		Items: []CartItem{
+			{ProductCode: "1_ST", Name: "Mjölk", Quantity: 4},
+			{ProductCode: "2_ST", Name: "Bröd", Quantity: 1},
+			{ProductCode: "9_ST", Name: "Smör", Quantity: 1},
+		},
+	}
+
+	result := compareMergedCart(anonymous, merged)
+
+	if result.AnonymousGUID != "guest-1" {
+		t.Errorf("Expected GUID guest-1, got %s", result.AnonymousGUID)
+	}
+	if len(result.Merged) != 1 || result.Merged[0].ProductCode != "1_ST" || result.Merged[0].Quantity != 4 {
+		t.Errorf("Expected 1_ST merged with quantity 4, got %+v", result.Merged)
+	}
+	if len(result.Conflicts) != 2 {
+		t.Fatalf("Expected 2 conflicts, got %+v", result.Conflicts)
+	}
+	if result.Conflicts[0].ProductCode != "2_ST" || result.Conflicts[0].MergedQuantity != 1 {
+		t.Errorf("Expected reduced quantity conflict for 2_ST, got %+v", result.Conflicts[0])
+	}
+	if result.Conflicts[1].ProductCode != "3_ST" || result.Conflicts[1].Reason != "missing from cart after merge" {
+		t.Errorf("Expected missing conflict for 3_ST, got %+v", result.Conflicts[1])
+	}
+}
+
+func TestLoginMergesGuestCartWithConfiguredCredentials(t *testing.T) {
+	loggedIn, mergedFrom := false, ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointLogin:
+			loggedIn = true
+		case EndpointCartMerge:
+			mergedFrom = r.URL.Query().Get("oldCartId")
+		case EndpointCart:
+			if loggedIn {
+				w.Write([]byte(`{"guid": "account-1", "products": [{"code": "1_ST", "name": "Mjölk", "quantity": 2}]}`))
+				return
+			}
+			w.Write([]byte(`{"guid": "guest-1", "products": [{"code": "1_ST", "name": "Mjölk", "quantity": 2}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "199001011234", "secret123")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.SetCookies([]*http.Cookie{{Name: "JSESSIONID", Value: "guest"}})
+
+	if err := client.Login(context.Background(), "199001011234", "secret123"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if mergedFrom != "guest-1" {
+		t.Errorf("Expected guest cart guest-1 to be merged, got %q", mergedFrom)
+	}
+	if merge := client.AuthStatus().LastCartMerge; merge == nil || len(merge.Merged) != 1 {
+		t.Errorf("Expected the merge to be reported, got %+v", merge)
+	}
+
+	// A re-login of the account session has no guest cart to merge
+	mergedFrom = ""
+	if err := client.Login(context.Background(), "199001011234", "secret123"); err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if mergedFrom != "" {
+		t.Errorf("Expected no merge on re-login, got %q", mergedFrom)
+	}
+}
@@ -0,0 +1,137 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCartMutationUsesReturnedCart(t *testing.T) {
+	const cartJSON = `{"products": [{"code": "101233933_ST", "name": "Mellanmjölk", "quantity": 2, "price": 15.9}], "totalPrice": 31.8}`
+
+	for name, addResponse := range map[string]string{"with cart": cartJSON, "without cart": `{"success": true}`, "empty": ``} {
+		t.Run(name, func(t *testing.T) {
+			cartFetches := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case EndpointCSRFToken:
+					w.Write([]byte(`"token"`))
+				case EndpointCartAddProducts:
+					w.Write([]byte(addResponse))
+				case EndpointCart:
+					cartFetches++
+					w.Write([]byte(cartJSON))
+				}
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL, "", "")
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			cart, err := client.AddToCart(context.Background(), "101233933_ST", 2)
+			if err != nil {
+				t.Fatalf("AddToCart failed: %v", err)
+			}
+			if cart.ItemCount != 2 || cart.TotalPrice != SEK(3180) {
+				t.Errorf("Unexpected cart: %+v", cart)
+			}
+
+			wantFetches := 1
+			if addResponse == cartJSON {
+				wantFetches = 0
+			}
+			if cartFetches != wantFetches {
+				t.Errorf("Expected %d cart fetches, got %d", wantFetches, cartFetches)
+			}
+		})
+	}
+}
+
+func TestCartMirror(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointCart:
+			w.Write([]byte(`{"products": [{"code": "101233933_ST", "name": "Mellanmjölk", "quantity": 1, "price": 15.9}], "totalPrice": 15.9}`))
+		case EndpointCartAddProducts:
+			w.Write([]byte(`{"products": [{"code": "101233933_ST", "name": "Mellanmjölk", "quantity": 3, "price": 15.9}], "totalPrice": 47.7}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, _, ok := client.CachedCart(); ok {
+		t.Fatal("Expected no mirrored cart before the first fetch")
+	}
+	if _, err := client.GetCart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cart, syncedAt, ok := client.CachedCart()
+	if !ok || cart.ItemCount != 1 || syncedAt.IsZero() {
+		t.Fatalf("Expected the fetched cart to be mirrored, got %+v", cart)
+	}
+
+	if _, err := client.AddToCart(ctx, "101233933_ST", 2); err != nil {
+		t.Fatal(err)
+	}
+	if cart, _, ok = client.CachedCart(); !ok || cart.ItemCount != 3 {
+		t.Fatalf("Expected the addProducts cart to be mirrored, got %+v", cart)
+	}
+
+	resp, err := client.DoRequest(ctx, "POST", EndpointCartDeliveryMode, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	discard(resp)
+	if _, _, ok := client.CachedCart(); ok {
+		t.Error("Expected other mutations to invalidate the mirror")
+	}
+}
+
+func TestCartMirrorClearedOnLogout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointCart:
+			w.Write([]byte(`{"products": [{"code": "101233933_ST", "name": "Mellanmjölk", "quantity": 1, "price": 15.9}], "totalPrice": 15.9}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "199001011234", "secret123")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, err := client.GetCart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := client.CachedCart(); !ok {
+		t.Fatal("Expected the fetched cart to be mirrored")
+	}
+	if err := client.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := client.CachedCart(); ok {
+		t.Error("Expected logout to clear the mirrored cart")
+	}
+
+	if _, err := client.GetCart(ctx); err != nil {
+		t.Fatal(err)
+	}
+	client.loggedIn(func(*sessionState) {})
+	if _, _, ok := client.CachedCart(); ok {
+		t.Error("Expected a login to clear the mirrored cart")
+	}
+}
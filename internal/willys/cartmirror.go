@@ -0,0 +1,104 @@
+package willys
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// CartChange is a product whose quantity differs between two versions of the cart.
+	CartChange struct {
+		Code   string `json:"code"`
+		Name   string `json:"name"`
+		Before int    `json:"before"`
+		After  int    `json:"after"`
+	}
+
+	// cartMirror keeps the last cart Willys returned. Any request that may change the
+	// cart invalidates it. A fetched cart is only mirrored if nothing invalidated the
+	// mirror while it was in flight; a cart returned by a mutation is mirrored unless
+	// another request started after the response arrived.
+	cartMirror struct {
+		mu         sync.Mutex
+		cart       *CartSummary
+		syncedAt   time.Time
+		generation uint64
+	}
+)
+
+func (m *cartMirror) current() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.generation
+}
+
+func (m *cartMirror) invalidate() {
+	m.mu.Lock()
+	m.cart = nil
+	m.generation++
+	m.mu.Unlock()
+}
+
+func (m *cartMirror) store(cart *CartSummary, generation uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if generation != m.generation {
+		return
+	}
+	m.cart = copyCart(cart)
+	m.syncedAt = time.Now()
+}
+
+func (m *cartMirror) get() (*CartSummary, time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cart == nil {
+		return nil, time.Time{}, false
+	}
+	return copyCart(m.cart), m.syncedAt, true
+}
+
+func copyCart(cart *CartSummary) *CartSummary {
+	c := *cart
+	c.Items = slices.Clone(cart.Items)
+	return &c
+}
+
+// CachedCart returns the cart as of the last cart response from Willys and when that was,
+// without a request. ok is false when nothing is mirrored, for example after a request
+// that may have changed the cart.
+func (c *Client) CachedCart() (cart *CartSummary, syncedAt time.Time, ok bool) {
+	return c.cartMirror.get()
+}
+
+// DiffCarts lists the products whose quantity changed from before to after. A nil cart
+// counts as empty.
+func DiffCarts(before, after *CartSummary) []CartChange {
+	changes := make(map[string]*CartChange)
+	if before != nil {
+		for _, item := range before.Items {
+			changes[item.ProductCode] = &CartChange{Code: item.ProductCode, Name: item.Name, Before: item.Quantity}
+		}
+	}
+	if after != nil {
+		for _, item := range after.Items {
+			change, ok := changes[item.ProductCode]
+			if !ok {
+				change = &CartChange{Code: item.ProductCode, Name: item.Name}
+				changes[item.ProductCode] = change
+			}
+			change.After = item.Quantity
+		}
+	}
+
+	diff := make([]CartChange, 0)
+	for _, change := range changes {
+		if change.Before != change.After {
+			diff = append(diff, *change)
+		}
+	}
+	sort.Slice(diff, func(i, j int) bool { return diff[i].Code < diff[j].Code })
+	return diff
+}
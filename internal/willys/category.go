@@ -0,0 +1,81 @@
+package willys
+
+import (
+	"strings"
+)
+
+// CartCategory is a top-level store department. Aisle is its position in a typical Willys
+// walking order, from the entrance (fruit and vegetables) to the tills.
+type CartCategory struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Aisle int    `json:"aisle"`
+}
+
+// Keys match the first segment of Axfood's category paths (e.g. "mejeri-ost-och-agg|mjolk").
+var cartCategories = []CartCategory{
+	{"frukt-och-gront", "Frukt och grönt", 1},
+	{"brod-och-kakor", "Bröd och kakor", 2},
+	{"kott-chark-och-fagel", "Kött, chark och fågel", 3},
+	{"fisk-och-skaldjur", "Fisk och skaldjur", 4},
+	{"mejeri-ost-och-agg", "Mejeri, ost och ägg", 5},
+	{"vegetariskt", "Vegetariskt", 6},
+	{"skafferi", "Skafferi", 7},
+	{"fryst", "Fryst", 8},
+	{"dryck", "Dryck", 9},
+	{"glass-godis-och-snacks", "Glass, godis och snacks", 10},
+	{"barn", "Barn", 11},
+	{"hem-och-hushall", "Hem och hushåll", 12},
+	{"halsa-och-skonhet", "Hälsa och skönhet", 13},
+	{"djur", "Djur", 14},
+}
+
+var otherCategory = CartCategory{"ovrigt", "Övrigt", 99}
+
+// Fallback keywords for carts where Willys sends no category path; matched against the
+// lower-cased product name, first match wins, so
+// specific departments (fish, meat) come before broad words like "fil" or "ost".
+var categoryKeywords = []struct {
+	key      string
+	keywords []string
+}{
+	{"fryst", []string{"fryst", "djupfryst"}},
+	{"glass-godis-och-snacks", []string{"glass", "choklad", "godis", "chips", "snacks", "popcorn"}},
+	{"kott-chark-och-fagel", []string{"kyckling", "färs", "korv", "bacon", "skinka", "fläsk", "nöt", "kött", "kalkon"}},
+	{"fisk-och-skaldjur", []string{"lax", "torsk", "räkor", "fisk", "tonfisk", "sill"}},
+	{"mejeri-ost-och-agg", []string{"mjölk", "fil", "yoghurt", "grädde", "smör", "ost", "ägg", "kvarg", "crème fraiche"}},
+	{"frukt-och-gront", []string{"banan", "äpple", "päron", "tomat", "gurka", "sallad", "potatis", "lök", "morot", "paprika", "citron", "avokado", "apelsin"}},
+	{"brod-och-kakor", []string{"bröd", "limpa", "bulle", "kaka", "knäcke", "tortilla", "baguette"}},
+	{"dryck", []string{"juice", "läsk", "vatten", "kaffe", "te ", "öl", "saft", "dricka"}},
+	{"vegetariskt", []string{"tofu", "vegetarisk", "vegansk", "oumph", "quorn"}},
+	{"hem-och-hushall", []string{"diskmedel", "tvättmedel", "toalettpapper", "hushållspapper", "soppåsar"}},
+	{"halsa-och-skonhet", []string{"schampo", "tandkräm", "tvål", "deodorant"}},
+	{"skafferi", []string{"pasta", "ris", "mjöl", "socker", "olja", "krossade", "buljong", "konserv", "müsli", "flingor", "kryddor"}},
+}
+
+// CategorizeCartItem returns the department of item, from its category path when Willys
+// sent one and otherwise from keywords in the product name.
+func CategorizeCartItem(item CartItem) CartCategory {
+	if item.Category != "" {
+		key, _, _ := strings.Cut(item.Category, "|")
+		for _, c := range cartCategories {
+			if c.Key == key {
+				return c
+			}
+		}
+	}
+
+	name := strings.ToLower(item.Name) + " "
+	for _, entry := range categoryKeywords {
+		for _, kw := range entry.keywords {
+			if strings.Contains(name, kw) {
+				for _, c := range cartCategories {
+					if c.Key == entry.key {
+						return c
+					}
+				}
+			}
+		}
+	}
+	return otherCategory
+}
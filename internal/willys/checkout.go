@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
@@ -19,71 +20,153 @@ type (
 		City            string `json:"city"`
 		DoorCode        string `json:"doorCode,omitempty"`
 		MessageToDriver string `json:"messageToDriver,omitempty"`
+		// Set by VerifyAddress when Willys' address lookup knows the address
+		Latitude  float64 `json:"latitude,omitempty"`
+		Longitude float64 `json:"longitude,omitempty"`
+	}
+
+	Deliverability struct {
+		PostalCode  string `json:"postalCode"`
+		Deliverable bool   `json:"deliverable"`
+		// Only present when Willys names the serving store in the deliverability response
+		StoreID        string `json:"storeId,omitempty"`
+		StoreName      string `json:"storeName,omitempty"`
+		EarliestDate   string `json:"earliestDate,omitempty"`
+		MinFee         *Money `json:"minFee,omitempty"`
+		MaxFee         *Money `json:"maxFee,omitempty"`
+		AvailableSlots int    `json:"availableSlots"`
+	}
+
+	// StoreRef names the store that serves the cart's delivery address.
+	StoreRef struct {
+		ID         string `json:"id"`
+		Name       string `json:"name,omitempty"`
+		PostalCode string `json:"postalCode"`
+	}
+
+	deliverabilityResponse struct {
+		Deliverable bool   `json:"deliverable"`
+		StoreID     string `json:"storeId"`
+		StoreName   string `json:"storeName"`
 	}
 
 	TimeSlot struct {
-		SlotID           string  `json:"slotId"`
-		Date             string  `json:"date"`
-		StartTime        string  `json:"startTime"`
-		EndTime          string  `json:"endTime"`
-		Fee              float64 `json:"fee"`
-		Available        bool    `json:"available"`
-		EarliestDateTime int64   `json:"earliestDateTime"` // Unix timestamp in ms
-		LatestDateTime   int64   `json:"latestDateTime"`   // Unix timestamp in ms
-		RouteID          int     `json:"routeID"`
-		ResourceKey      string  `json:"resourceKey"`
-		ScheduleKey      string  `json:"scheduleKey"`
-		PrecedingStopId  int     `json:"precedingStopId"`
-		StopNumber       int     `json:"stopNumber"`
-		Profitability    float64 `json:"profitability"`
+		SlotID           string      `json:"slotId"`
+		Date             string      `json:"date"`
+		StartTime        string      `json:"startTime"`
+		EndTime          string      `json:"endTime"`
+		Fee              Money       `json:"fee"`
+		Available        bool        `json:"available"`
+		EarliestDateTime EpochMillis `json:"earliestDateTime,omitempty"`
+		LatestDateTime   EpochMillis `json:"latestDateTime,omitempty"`
+		RouteID          int         `json:"routeID"`
+		ResourceKey      string      `json:"resourceKey"`
+		ScheduleKey      string      `json:"scheduleKey"`
+		PrecedingStopId  int         `json:"precedingStopId"`
+		StopNumber       int         `json:"stopNumber"`
+		Profitability    float64     `json:"profitability"`
+		// Orders for this slot can be changed until CutoffTime. CutoffEstimated is set when
+		// Willys didn't send a close time and the default rule was applied instead.
+		CutoffTime      time.Time `json:"cutoffTime"`
+		CutoffEstimated bool      `json:"cutoffEstimated,omitempty"`
 	}
 	DeliveryInfo struct {
 		Address     DeliveryAddress `json:"address"`
 		TimeSlot    TimeSlot        `json:"timeSlot"`
-		PickingFee  float64         `json:"pickingFee"`
-		DeliveryFee float64         `json:"deliveryFee"`
-		TotalFee    float64         `json:"totalFee"`
+		PickingFee  Money           `json:"pickingFee"`
+		DeliveryFee Money           `json:"deliveryFee"`
+		TotalFee    Money           `json:"totalFee"`
+		// ModifiableUntil is the slot's cut-off: the last moment the order can be changed
+		ModifiableUntil time.Time `json:"modifiableUntil"`
 	}
 )
 
 func (c *Client) CheckDeliverability(ctx context.Context, postalCode string) (bool, error) {
-	if err := ValidatePostalCode(postalCode); err != nil {
+	result, err := c.fetchDeliverability(ctx, postalCode)
+	if err != nil {
 		return false, err
 	}
+	return result.Deliverable, nil
+}
 
-	path := fmt.Sprintf("%s/%s/deliverability?b2b=false", EndpointShippingDelivery, postalCode)
+// GetDeliverability reports whether postalCode gets home delivery, which store serves it,
+// and the earliest date and fee range of the currently open slots.
+func (c *Client) GetDeliverability(ctx context.Context, postalCode string) (*Deliverability, error) {
+	result, err := c.fetchDeliverability(ctx, postalCode)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Deliverability{
+		PostalCode:  postalCode,
+		Deliverable: result.Deliverable,
+		StoreID:     result.StoreID,
+		StoreName:   result.StoreName,
+	}
+	if !result.Deliverable {
+		return report, nil
+	}
+
+	slots, err := c.GetAvailableTimeSlots(ctx, postalCode)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		if !slot.Available {
+			continue
+		}
+		report.AvailableSlots++
+		if report.EarliestDate == "" || slot.Date < report.EarliestDate {
+			report.EarliestDate = slot.Date
+		}
+		if report.MinFee == nil || slot.Fee.Ore < report.MinFee.Ore {
+			fee := slot.Fee
+			report.MinFee = &fee
+		}
+		if report.MaxFee == nil || slot.Fee.Ore > report.MaxFee.Ore {
+			fee := slot.Fee
+			report.MaxFee = &fee
+		}
+	}
+
+	return report, nil
+}
+
+func (c *Client) fetchDeliverability(ctx context.Context, postalCode string) (*deliverabilityResponse, error) {
+	if err := ValidatePostalCode(postalCode); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s/deliverability?b2b=%s", EndpointShippingDelivery, postalCode, c.b2bParam())
 
 	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
 	if err != nil {
-		return false, NewAPIError(0, path, "check deliverability request failed", err)
+		return nil, newAPIError(ctx, 0, path, "check deliverability request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return false, nil
+		return &deliverabilityResponse{}, nil
 	}
 
-	var result struct {
-		Deliverable bool `json:"deliverable"`
+	var result deliverabilityResponse
+	if err := c.readJSON(resp, EndpointShippingDelivery, &result, "deliverable"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse deliverability response", err)
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, NewAPIError(resp.StatusCode, path, "failed to parse deliverability response", err)
-	}
-
-	return result.Deliverable, nil
+	return &result, nil
 }
 
 func (c *Client) SetDeliveryMode(ctx context.Context) error {
 	path := EndpointCartDeliveryMode + "?newSuggestedStoreId="
 	resp, err := c.DoRequest(ctx, "POST", path, nil, true)
 	if err != nil {
-		return NewAPIError(0, path, "set delivery mode request failed", err)
+		return newAPIError(ctx, 0, path, "set delivery mode request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return NewAPIError(resp.StatusCode, path, "set delivery mode failed", nil)
+		return responseError(ctx, resp, path, "set delivery mode failed")
 	}
 
 	return nil
@@ -102,8 +185,8 @@ func (c *Client) SetDeliveryAddress(ctx context.Context, address DeliveryAddress
 	params.Set("postalCode", address.PostalCode)
 	params.Set("town", address.City) // API uses town, not city
 	params.Set("cellphone", "")
-	params.Set("longitude", "")
-	params.Set("latitude", "")
+	params.Set("longitude", formatCoordinate(address.Longitude))
+	params.Set("latitude", formatCoordinate(address.Latitude))
 
 	if address.DoorCode != "" {
 		params.Set("doorCode", address.DoorCode)
@@ -115,100 +198,226 @@ func (c *Client) SetDeliveryAddress(ctx context.Context, address DeliveryAddress
 	path := fmt.Sprintf("%s?%s", EndpointCartDeliveryAddress, params.Encode())
 	resp, err := c.DoRequest(ctx, "POST", path, nil, true)
 	if err != nil {
-		return NewAPIError(0, path, "set delivery address request failed", err)
+		return newAPIError(ctx, 0, path, "set delivery address request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return NewAPIError(resp.StatusCode, path, "set delivery address failed", nil)
+		return responseError(ctx, resp, path, "set delivery address failed")
 	}
 
 	postalPath := fmt.Sprintf("%s?postalCode=%s", EndpointCartPostalCode, address.PostalCode)
 	postalResp, err := c.DoRequest(ctx, "POST", postalPath, nil, true)
 	if err != nil {
-		return NewAPIError(0, postalPath, "set postal code request failed", err)
+		return newAPIError(ctx, 0, postalPath, "set postal code request failed", err)
 	}
 	defer postalResp.Body.Close()
 
 	if postalResp.StatusCode != http.StatusOK && postalResp.StatusCode != http.StatusNoContent {
-		return NewAPIError(postalResp.StatusCode, postalPath, "set postal code failed", nil)
+		return responseError(ctx, postalResp, postalPath, "set postal code failed")
 	}
 
 	return nil
 }
 
+// formatCoordinate leaves unknown coordinates empty, as Willys' own form sends them.
+func formatCoordinate(degrees float64) string {
+	if degrees == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(degrees, 'f', 6, 64)
+}
+
 func (c *Client) GetAvailableTimeSlots(ctx context.Context, postalCode string) ([]TimeSlot, error) {
 	if err := ValidatePostalCode(postalCode); err != nil {
 		return nil, err
 	}
 
-	path := fmt.Sprintf("%s?postalCode=%s&b2b=false", EndpointSlotHomeDelivery, postalCode)
+	path := fmt.Sprintf("%s?postalCode=%s&b2b=%s", EndpointSlotHomeDelivery, postalCode, c.b2bParam())
+	return c.fetchTimeSlots(ctx, EndpointSlotHomeDelivery, path)
+}
 
+// fetchTimeSlots reads a slot listing; home delivery and pickup share the format.
+func (c *Client) fetchTimeSlots(ctx context.Context, endpoint, path string) ([]TimeSlot, error) {
 	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
 	if err != nil {
-		return nil, NewAPIError(0, path, "get time slots request failed", err)
+		return nil, newAPIError(ctx, 0, path, "get time slots request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, NewAPIError(resp.StatusCode, path, "get time slots failed", nil)
+		return nil, responseError(ctx, resp, path, "get time slots failed")
 	}
 
 	var result struct {
-		Isocode string `json:"isocode"`
-		Slots   []struct {
-			Code          string `json:"code"`
-			StartTime     int64  `json:"startTime"` // Unix timestamp in milliseconds
-			EndTime       int64  `json:"endTime"`   // Unix timestamp in milliseconds
-			FormattedTime string `json:"formattedTime"`
-			DeliveryCost  struct {
-				Value float64 `json:"value"`
-			} `json:"deliveryCost"`
-			Available                  bool `json:"available"`
-			TmsDeliveryWindowReference struct {
-				EarliestDateTime int64   `json:"earliestDateTime"`
-				LatestDateTime   int64   `json:"latestDateTime"`
-				RouteID          int     `json:"routeID"`
-				ResourceKey      string  `json:"resourceKey"`
-				ScheduleKey      string  `json:"scheduleKey"`
-				PrecedingStopId  int     `json:"precedingStopId"`
-				StopNumber       int     `json:"stopNumber"`
-				Profitability    float64 `json:"profitability"`
-			} `json:"tmsDeliveryWindowReference"`
-		} `json:"slots"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, NewAPIError(resp.StatusCode, path, "failed to parse time slots response", err)
+		Isocode string     `json:"isocode"`
+		Slots   []wireSlot `json:"slots"`
+	}
+
+	if err := c.readJSON(resp, endpoint, &result, "slots", "slots[].code", "slots[].startTime", "slots[].endTime"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse time slots response", err)
 	}
 
 	slots := make([]TimeSlot, 0)
 	for _, s := range result.Slots {
-		startTimeObj := time.Unix(s.StartTime/1000, 0)
-		endTimeObj := time.Unix(s.EndTime/1000, 0)
-
-		slot := TimeSlot{
-			SlotID:           s.Code,
-			Date:             startTimeObj.Format("2006-01-02"),
-			StartTime:        startTimeObj.Format("15:04"),
-			EndTime:          endTimeObj.Format("15:04"),
-			Fee:              s.DeliveryCost.Value,
-			Available:        s.Available,
-			EarliestDateTime: s.TmsDeliveryWindowReference.EarliestDateTime,
-			LatestDateTime:   s.TmsDeliveryWindowReference.LatestDateTime,
-			RouteID:          s.TmsDeliveryWindowReference.RouteID,
-			ResourceKey:      s.TmsDeliveryWindowReference.ResourceKey,
-			ScheduleKey:      s.TmsDeliveryWindowReference.ScheduleKey,
-			PrecedingStopId:  s.TmsDeliveryWindowReference.PrecedingStopId,
-			StopNumber:       s.TmsDeliveryWindowReference.StopNumber,
-			Profitability:    s.TmsDeliveryWindowReference.Profitability,
-		}
-		slots = append(slots, slot)
+		slots = append(slots, s.timeSlot())
 	}
 
 	return slots, nil
 }
 
+// wireSlot is a slot as Willys sends it, in slot listings and as the slot in the cart.
+type wireSlot struct {
+	Code                       string `json:"code"`
+	StartTime                  int64  `json:"startTime"` // Unix timestamp in milliseconds
+	EndTime                    int64  `json:"endTime"`   // Unix timestamp in milliseconds
+	FormattedTime              string `json:"formattedTime"`
+	DeliveryCost               Money  `json:"deliveryCost"`
+	Available                  bool   `json:"available"`
+	CloseTime                  int64  `json:"closeTime"` // Unix timestamp in milliseconds, 0 if absent
+	TmsDeliveryWindowReference struct {
+		EarliestDateTime int64   `json:"earliestDateTime"`
+		LatestDateTime   int64   `json:"latestDateTime"`
+		RouteID          int     `json:"routeID"`
+		ResourceKey      string  `json:"resourceKey"`
+		ScheduleKey      string  `json:"scheduleKey"`
+		PrecedingStopId  int     `json:"precedingStopId"`
+		StopNumber       int     `json:"stopNumber"`
+		Profitability    float64 `json:"profitability"`
+	} `json:"tmsDeliveryWindowReference"`
+}
+
+func (s wireSlot) timeSlot() TimeSlot {
+	startTimeObj := time.Unix(s.StartTime/1000, 0)
+	endTimeObj := time.Unix(s.EndTime/1000, 0)
+
+	slot := TimeSlot{
+		SlotID:           s.Code,
+		Date:             startTimeObj.Format("2006-01-02"),
+		StartTime:        startTimeObj.Format("15:04"),
+		EndTime:          endTimeObj.Format("15:04"),
+		Fee:              s.DeliveryCost,
+		Available:        s.Available,
+		EarliestDateTime: EpochMillis(s.TmsDeliveryWindowReference.EarliestDateTime),
+		LatestDateTime:   EpochMillis(s.TmsDeliveryWindowReference.LatestDateTime),
+		RouteID:          s.TmsDeliveryWindowReference.RouteID,
+		ResourceKey:      s.TmsDeliveryWindowReference.ResourceKey,
+		ScheduleKey:      s.TmsDeliveryWindowReference.ScheduleKey,
+		PrecedingStopId:  s.TmsDeliveryWindowReference.PrecedingStopId,
+		StopNumber:       s.TmsDeliveryWindowReference.StopNumber,
+		Profitability:    s.TmsDeliveryWindowReference.Profitability,
+	}
+	if s.CloseTime > 0 {
+		slot.CutoffTime = time.UnixMilli(s.CloseTime)
+	} else {
+		slot.CutoffTime = estimateCutoff(startTimeObj)
+		slot.CutoffEstimated = true
+	}
+	return slot
+}
+
+// GetSlotInCart returns the delivery slot reserved in the cart, or nil when there is none.
+func (c *Client) GetSlotInCart(ctx context.Context) (*TimeSlot, error) {
+	resp, err := c.DoRequest(ctx, "GET", EndpointSlotInCart, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, EndpointSlotInCart, "get slot in cart request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, EndpointSlotInCart, "get slot in cart failed")
+	}
+
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointSlotInCart, "failed to read slot in cart response", err)
+	}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) || bytes.Equal(trimmed, []byte(`""`)) {
+		return nil, nil
+	}
+
+	var wire wireSlot
+	if err := c.decodeJSON(EndpointSlotInCart, body, &wire); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointSlotInCart, "failed to parse slot in cart response", err)
+	}
+	if wire.Code == "" {
+		return nil, nil
+	}
+	slot := wire.timeSlot()
+	return &slot, nil
+}
+
+// estimateCutoff applies Willys' usual rule when a slot carries no close time: the order
+// can be changed until the end of the day before delivery.
+func estimateCutoff(deliveryStart time.Time) time.Time {
+	return time.Date(deliveryStart.Year(), deliveryStart.Month(), deliveryStart.Day(), 0, 0, 0, 0, deliveryStart.Location())
+}
+
+// timeSlotFields is TimeSlot without its JSON methods.
+type timeSlotFields TimeSlot
+
+// MarshalJSON writes StartTime and EndTime as RFC 3339 instants rather than bare HH:MM,
+// and adds "window", the HH:MM-HH:MM form select_delivery_time takes as time_slot.
+func (s TimeSlot) MarshalJSON() ([]byte, error) {
+	start, end := s.clockInstant(s.StartTime), s.clockInstant(s.EndTime)
+	var window string
+	if s.StartTime != "" && s.EndTime != "" {
+		window = s.StartTime + "-" + s.EndTime
+		if s.EndTime <= s.StartTime {
+			// A slot ending at midnight ends on the next day
+			if t, err := time.Parse(time.RFC3339, end); err == nil {
+				end = t.AddDate(0, 0, 1).Format(time.RFC3339)
+			}
+		}
+	}
+	return json.Marshal(struct {
+		timeSlotFields
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+		Window    string `json:"window,omitempty"`
+	}{timeSlotFields(s), start, end, window})
+}
+
+// UnmarshalJSON accepts StartTime and EndTime both as instants and as HH:MM, which is
+// how bookings stored by earlier versions hold them.
+func (s *TimeSlot) UnmarshalJSON(data []byte) error {
+	wire := struct {
+		*timeSlotFields
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+	}{timeSlotFields: (*timeSlotFields)(s)}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	s.StartTime, s.EndTime = clockTime(wire.StartTime), clockTime(wire.EndTime)
+	return nil
+}
+
+// clockInstant places an HH:MM time on the slot's date in local time. Values that don't
+// parse are returned as they are.
+func (s TimeSlot) clockInstant(clock string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s.Date+" "+clock, time.Local)
+	if err != nil {
+		return clock
+	}
+	return t.Format(time.RFC3339)
+}
+
+func clockTime(value string) string {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format("15:04")
+	}
+	return value
+}
+
+// CanModify reports whether an order for the slot can still be changed at now.
+func (s TimeSlot) CanModify(now time.Time) bool {
+	return s.CutoffTime.IsZero() || now.Before(s.CutoffTime)
+}
+
 func (c *Client) SelectTimeSlot(ctx context.Context, slot TimeSlot) error {
 	reqData := struct {
 		EarliestDateTime int64   `json:"earliestDateTime"`
@@ -220,8 +429,8 @@ func (c *Client) SelectTimeSlot(ctx context.Context, slot TimeSlot) error {
 		StopNumber       int     `json:"stopNumber"`
 		Profitability    float64 `json:"profitability"`
 	}{
-		EarliestDateTime: slot.EarliestDateTime,
-		LatestDateTime:   slot.LatestDateTime,
+		EarliestDateTime: int64(slot.EarliestDateTime),
+		LatestDateTime:   int64(slot.LatestDateTime),
 		RouteID:          slot.RouteID,
 		ResourceKey:      slot.ResourceKey,
 		ScheduleKey:      slot.ScheduleKey,
@@ -232,36 +441,56 @@ func (c *Client) SelectTimeSlot(ctx context.Context, slot TimeSlot) error {
 
 	jsonData, err := json.Marshal(reqData)
 	if err != nil {
-		return NewAPIError(0, EndpointSlotInCart, "failed to marshal time slot request", err)
+		return newAPIError(ctx, 0, EndpointSlotInCart, "failed to marshal time slot request", err)
 	}
 
 	path := fmt.Sprintf("%s/%s?isTmsSlot=true", EndpointSlotInCart, url.QueryEscape(slot.SlotID))
 	resp, err := c.DoRequest(ctx, "POST", path, bytes.NewReader(jsonData), true)
 	if err != nil {
-		return NewAPIError(0, path, "select time slot request failed", err)
+		return newAPIError(ctx, 0, path, "select time slot request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return responseError(ctx, resp, path, "select time slot failed")
+	}
+
+	return nil
+}
+
+// ReleaseTimeSlot removes the reserved delivery slot from the cart, so it is free for
+// others and can be selected again right away.
+func (c *Client) ReleaseTimeSlot(ctx context.Context) error {
+	resp, err := c.DoRequest(ctx, "DELETE", EndpointSlotInCart, nil, true)
+	if err != nil {
+		return newAPIError(ctx, 0, EndpointSlotInCart, "release time slot request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-		return NewAPIError(resp.StatusCode, path, "select time slot failed", nil)
+		return responseError(ctx, resp, EndpointSlotInCart, "release time slot failed")
 	}
 
 	return nil
 }
 
 func (c *Client) GetCheckoutURL() string {
-	return c.baseURL + EndpointCheckout
+	return c.url(EndpointCheckout)
 }
 
 func (c *Client) SetupDelivery(ctx context.Context, address DeliveryAddress, slot TimeSlot) (*DeliveryInfo, error) {
-	available, err := c.CheckDeliverability(ctx, address.PostalCode)
+	deliverability, err := c.fetchDeliverability(ctx, address.PostalCode)
 	if err != nil {
 		return nil, err
 	}
-	if !available {
+	if !deliverability.Deliverable {
 		return nil, NewValidationError("postal_code", fmt.Sprintf("delivery not available for postal code %s", address.PostalCode))
 	}
 
+	if address, err = c.VerifyAddress(ctx, address); err != nil {
+		return nil, err
+	}
+
 	if err := c.SetDeliveryMode(ctx); err != nil {
 		return nil, err
 	}
@@ -274,12 +503,20 @@ func (c *Client) SetupDelivery(ctx context.Context, address DeliveryAddress, slo
 		return nil, err
 	}
 
+	if deliverability.StoreID != "" {
+		store := &StoreRef{ID: deliverability.StoreID, Name: deliverability.StoreName, PostalCode: address.PostalCode}
+		c.session.update(func(st *sessionState) { st.activeStore = store })
+	}
+
+	pickingFee := MoneyFromFloat(DefaultPickingFee)
 	deliveryInfo := &DeliveryInfo{
 		Address:     address,
 		TimeSlot:    slot,
-		PickingFee:  DefaultPickingFee,
+		PickingFee:  pickingFee,
 		DeliveryFee: slot.Fee,
-		TotalFee:    DefaultPickingFee + slot.Fee,
+		TotalFee:    pickingFee.Add(slot.Fee),
+
+		ModifiableUntil: slot.CutoffTime,
 	}
 
 	return deliveryInfo, nil
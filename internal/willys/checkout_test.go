@@ -0,0 +1,41 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetSlotInCart(t *testing.T) {
+	reserved := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EndpointSlotInCart {
+			return
+		}
+		if !reserved {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"code": "slot-1", "startTime": 1792922400000, "endTime": 1792929600000, "deliveryCost": 49, "closeTime": 1792879200000}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	slot, err := client.GetSlotInCart(context.Background())
+	if err != nil {
+		t.Fatalf("GetSlotInCart failed: %v", err)
+	}
+	if slot == nil || slot.SlotID != "slot-1" || slot.Fee.Ore != 4900 || slot.CutoffEstimated {
+		t.Errorf("Unexpected slot %+v", slot)
+	}
+
+	reserved = false
+	if slot, err := client.GetSlotInCart(context.Background()); err != nil || slot != nil {
+		t.Errorf("Expected no slot, got %+v (%v)", slot, err)
+	}
+}
@@ -4,24 +4,83 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
-	"net/http/cookiejar"
 	"net/url"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/effati/willys-mcp/internal/willys")
+
 type Client struct {
-	mu           sync.RWMutex
 	httpClient   *http.Client
 	baseURL      string
-	csrfToken    string
-	username     string
-	password     string
 	authAttempts atomic.Int32
+
+	session session
+	jar     *sessionJar
+	// csrfMu lets one goroutine fetch a CSRF token while the others wait for it;
+	// loginMu does the same for re-logins and token refreshes
+	csrfMu  sync.Mutex
+	loginMu sync.Mutex
+
+	onTokenRefresh TokenRefreshHandler
+
+	cartMirror cartMirror
+
+	verification      *verificationBroker
+	browserProfileDir string
+	diagnosticsDir    string
+	selectors         Selectors
+	fallback          *browserFallback
+
+	// restSearch is set once /search has answered with its HTML page; searches then go
+	// to EndpointSearchREST
+	restSearch atomic.Bool
+
+	strictDecode bool
+	b2b          bool
+	decodeOpts   DecodeOptions
+	drift        *driftTracker
+	throttle     *throttle
+	rankers      map[string]Ranker
+
+	purchaseHistory *purchaseHistoryCache
+	respCache       *responseCache
+
+	endpoints     endpointMap
+	groupHosts    map[string]string
+	transportOpts TransportOptions
+	pool          *poolMetrics
+}
+
+type ClientOption func(*Client)
+
+// WithStrictDecode makes the client compare every decoded response against the expected
+// schema and record drift (unknown, missing, or mistyped fields).
+func WithStrictDecode(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.strictDecode = enabled
+	}
+}
+
+// WithBrowserProfileDir makes LoginWithBrowser reuse a persistent Chromium user-data
+// directory, so cookies, local storage, and consent choices survive between logins.
+func WithBrowserProfileDir(dir string) ClientOption {
+	return func(c *Client) {
+		c.browserProfileDir = dir
+	}
 }
 
 const (
@@ -30,22 +89,15 @@ const (
 	DefaultDeliveryFee   = 99.0
 	MaxAuthRetryAttempts = 2
 
-	maxIdleConns        = 100
-	maxIdleConnsPerHost = 10
-	idleConnTimeout     = 90 * time.Second
+	// maxRetryWallTime caps how long one call may spend on retries and re-login before
+	// giving up; a single attempt is bounded by DefaultTimeout.
+	maxRetryWallTime = 45 * time.Second
+	// maxDrainBytes is how much of an unread body is drained to reuse its connection;
+	// larger bodies are cheaper to drop along with the connection.
+	maxDrainBytes = 64 << 10
 )
 
-func newHTTPTransport() *http.Transport {
-	return &http.Transport{
-		MaxIdleConns:        maxIdleConns,
-		MaxIdleConnsPerHost: maxIdleConnsPerHost,
-		IdleConnTimeout:     idleConnTimeout,
-		DisableKeepAlives:   false,
-		DisableCompression:  false,
-	}
-}
-
-func NewClient(baseURL, username, password string) (*Client, error) {
+func NewClient(baseURL, username, password string, opts ...ClientOption) (*Client, error) {
 	if baseURL == "" {
 		return nil, NewValidationError("base_url", "base URL cannot be empty")
 	}
@@ -57,93 +109,119 @@ func NewClient(baseURL, username, password string) (*Client, error) {
 		return nil, NewValidationError("base_url", "base URL must use http or https scheme")
 	}
 
-	jar, err := cookiejar.New(nil)
+	jar, err := newSessionJar()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
 	}
 
 	client := &Client{
 		httpClient: &http.Client{
-			Jar:       jar,
-			Timeout:   DefaultTimeout,
-			Transport: newHTTPTransport(),
+			Jar:     jar,
+			Timeout: DefaultTimeout,
 		},
-		baseURL:  baseURL,
-		username: username,
-		password: password,
+		jar:           jar,
+		transportOpts: DefaultTransportOptions(),
+		pool:          &poolMetrics{},
+		baseURL:       baseURL,
+		drift:         newDriftTracker(),
+		verification:  newVerificationBroker(),
+		selectors:     DefaultSelectors(),
+		rankers:       defaultRankers(),
+		respCache:     newResponseCache(DefaultResponseCacheBytes),
 	}
+	client.httpClient.Transport = &decodingTransport{next: client.newHTTPTransport()}
+	client.session.state.username, client.session.state.password = username, password
 	client.authAttempts.Store(0)
+	client.purchaseHistory = &purchaseHistoryCache{load: client.GetOrderHistory}
+	client.rankers[RankHistoryWeighted] = historyRanker{cache: client.purchaseHistory}
+
+	for _, opt := range opts {
+		opt(client)
+	}
 
 	return client, nil
 }
 
 func (c *Client) GetCSRFToken() (string, error) {
-	c.mu.RLock()
-	token := c.csrfToken
-	c.mu.RUnlock()
-
-	if token != "" {
+	if token := c.session.snapshot().csrfToken; token != "" {
 		return token, nil
 	}
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.csrfMu.Lock()
+	defer c.csrfMu.Unlock()
 
-	if c.csrfToken != "" {
-		return c.csrfToken, nil
+	if token := c.session.snapshot().csrfToken; token != "" {
+		return token, nil
 	}
-
 	return c.fetchCSRFTokenLocked()
 }
 
 func (c *Client) FetchCSRFToken() (string, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.csrfMu.Lock()
+	defer c.csrfMu.Unlock()
 	return c.fetchCSRFTokenLocked()
 }
 
+// csrfTokenResponse accepts both the bare JSON string Willys normally returns and the
+// {"token": ...} object some deployments wrap it in.
+type csrfTokenResponse string
+
+func (t *csrfTokenResponse) UnmarshalJSON(data []byte) error {
+	var token string
+	if err := json.Unmarshal(data, &token); err == nil {
+		*t = csrfTokenResponse(token)
+		return nil
+	}
+	var result struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*t = csrfTokenResponse(result.Token)
+	return nil
+}
+
+// fetchCSRFTokenLocked needs csrfMu. The session lock is not held during the request, so
+// other requests go on meanwhile; the token is only stored if no login or logout
+// happened in between.
 func (c *Client) fetchCSRFTokenLocked() (string, error) {
-	resp, err := c.httpClient.Get(c.baseURL + EndpointCSRFToken)
+	generation := c.session.snapshot().generation
+	resp, err := c.httpClient.Get(c.url(EndpointCSRFToken))
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch CSRF token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		discard(resp)
 		return "", fmt.Errorf("CSRF token request failed with status %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read CSRF token response: %w", err)
-	}
-
-	var token string
-	if err := json.Unmarshal(body, &token); err != nil {
-		var result struct {
-			Token string `json:"token"`
-		}
-		if err := json.Unmarshal(body, &result); err != nil {
-			return "", fmt.Errorf("failed to parse CSRF token: %w", err)
-		}
-		token = result.Token
+	var token csrfTokenResponse
+	if err := c.readJSON(resp, EndpointCSRFToken, &token); err != nil {
+		return "", fmt.Errorf("failed to parse CSRF token: %w", err)
 	}
 
 	if token == "" {
 		return "", fmt.Errorf("empty CSRF token")
 	}
 
-	c.csrfToken = token
-	return token, nil
+	c.session.update(func(st *sessionState) {
+		if st.generation == generation {
+			st.csrfToken = string(token)
+		}
+	})
+	return string(token), nil
 }
 
 func (c *Client) createRequest(ctx context.Context, method, path string, bodyBytes []byte) (*http.Request, error) {
-	reqURL := c.baseURL + path
+	reqURL := c.url(path)
 	var req *http.Request
 	var err error
 
 	if ctx != nil {
-		req, err = http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(bodyBytes))
+		req, err = http.NewRequestWithContext(c.pool.tracePool(ctx), method, reqURL, bytes.NewReader(bodyBytes))
 	} else {
 		req, err = http.NewRequest(method, reqURL, bytes.NewReader(bodyBytes))
 	}
@@ -164,113 +242,290 @@ func (c *Client) createRequest(ctx context.Context, method, path string, bodyByt
 	req.Header.Set("Sec-Fetch-Mode", "cors")
 	req.Header.Set("Sec-Fetch-Site", "same-origin")
 
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+
+	if accessToken := c.session.snapshot().accessToken; accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
 	return req, nil
 }
 
 func (c *Client) DoRequest(ctx context.Context, method, path string, body io.Reader, needsCSRF bool) (*http.Response, error) {
-	if ctx != nil {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	endpoint, _, _ := strings.Cut(path, "?")
+	// Reserving or releasing a slot changes which slots are available
+	invalidatesSlots := method != http.MethodGet && c.respCache != nil && endpointGroup(endpoint) == EndpointGroupSlots
+	if method != http.MethodGet {
+		c.cartMirror.invalidate()
+		if invalidatesSlots {
+			c.respCache.invalidate("", EndpointSlotHomeDelivery)
+		}
+	}
+	cached, ttl := "", time.Duration(0)
+	if method == http.MethodGet && c.respCache != nil {
+		cached, ttl = cachedEndpoint(path)
+		if cached != "" && !wantsFreshResponses(ctx) {
+			if resp, ok := c.respCache.get(path, time.Now()); ok {
+				if id := CorrelationIDFromContext(ctx); id != "" {
+					log.Printf("[%s] %s %s -> %d (cached)", id, method, path, resp.StatusCode)
+				}
+				return resp, nil
+			}
 		}
 	}
+	ctx, span := tracer.Start(ctx, method+" "+endpoint, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
 
 	var bodyBytes []byte
 	if body != nil {
 		var err error
-		bodyBytes, err = io.ReadAll(body)
-		if err != nil {
+		if bodyBytes, err = io.ReadAll(body); err != nil {
 			return nil, fmt.Errorf("failed to read request body: %w", err)
 		}
 	}
 
-	req, err := c.createRequest(ctx, method, path, bodyBytes)
+	if c.throttle != nil {
+		if err := c.throttle.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	retries := 0
+	resp, err := c.doRequest(ctx, method, path, bodyBytes, needsCSRF, &retries)
+
+	if err == nil && c.shouldFallback(endpoint, resp) {
+		discard(resp)
+		span.SetAttributes(attribute.Bool("willys.browser_fallback", true))
+		resp, err = c.fetchViaBrowser(ctx, method, path, bodyBytes, needsCSRF)
+	}
+	if err == nil && c.throttle != nil {
+		c.throttle.observe(resp)
+	}
+	if err == nil && cached != "" {
+		c.respCache.store(path, cached, ttl, resp, time.Now())
+	}
+	if invalidatesSlots {
+		// A slot lookup that was in flight during the change may have cached the old slots
+		c.respCache.invalidate("", EndpointSlotHomeDelivery)
+	}
+
+	span.SetAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("willys.endpoint", endpoint),
+		attribute.Int("willys.retries", retries),
+	)
 	if err != nil {
-		return nil, err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
 	}
 
-	if needsCSRF {
-		token, err := c.GetCSRFToken()
+	if id := CorrelationIDFromContext(ctx); id != "" {
 		if err != nil {
-			return nil, fmt.Errorf("failed to get CSRF token: %w", err)
+			log.Printf("[%s] %s %s failed after %s: %v", id, method, path, time.Since(start).Round(time.Millisecond), err)
+		} else {
+			log.Printf("[%s] %s %s -> %d (%s)", id, method, path, resp.StatusCode, time.Since(start).Round(time.Millisecond))
 		}
-		req.Header.Set("X-CSRF-TOKEN", token)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	return resp, err
+}
+
+// doRequest sends a request, recovering from a stale CSRF token and then from an expired
+// session by logging in again. Every response it doesn't return is drained so its
+// connection can be reused, and no retry starts once maxRetryWallTime has passed.
+func (c *Client) doRequest(ctx context.Context, method, path string, bodyBytes []byte, needsCSRF bool, retries *int) (*http.Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := c.refreshTokenIfExpiring(ctx); err != nil {
+		return nil, NewAuthenticationError("failed to re-authenticate", err)
+	}
+
+	deadline := time.Now().Add(maxRetryWallTime)
+	resp, err := c.send(ctx, method, path, bodyBytes, needsCSRF)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	if resp.StatusCode != http.StatusUnauthorized || !needsCSRF {
+		return resp, nil
+	}
 
-	if resp.StatusCode == http.StatusUnauthorized && needsCSRF {
-		resp.Body.Close()
+	// A 401 on a CSRF-protected call usually means the token went stale
+	discard(resp)
+	if err := retryAllowed(ctx, deadline); err != nil {
+		return nil, err
+	}
+	*retries++
+	if _, err := c.FetchCSRFToken(); err != nil {
+		return nil, fmt.Errorf("failed to refresh CSRF token: %w", err)
+	}
+	// Taken before the retry so a login by another request after this point is noticed
+	seen := c.session.snapshot()
+	resp, err = c.send(ctx, method, path, bodyBytes, true)
+	if err != nil {
+		return nil, fmt.Errorf("retry request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
 
-		if _, err := c.FetchCSRFToken(); err != nil {
-			return nil, fmt.Errorf("failed to refresh CSRF token: %w", err)
-		}
+	attempts := c.authAttempts.Load()
+
+	switch {
+	case !seen.credentialsInvalidAt.IsZero():
+		// Logging in again with credentials Willys already rejected only brings the
+		// account closer to a lockout.
+		discard(resp)
+		return nil, NewAuthenticationError("stored credentials were rejected by Willys; update WILLYS_PASSWORD or WILLYS_REFRESH_TOKEN and restart", ErrCredentialsInvalid)
+	case c.activeLockout() != nil:
+		discard(resp)
+		return nil, NewAuthenticationError("session expired and re-login is paused", c.activeLockout())
+	case attempts >= MaxAuthRetryAttempts:
+		discard(resp)
+		return nil, NewAuthenticationError("maximum authentication retry attempts exceeded", nil)
+	case !seen.canReauth() || inRelogin(ctx):
+		return resp, nil
+	}
 
-		req, err = c.createRequest(ctx, method, path, bodyBytes)
-		if err != nil {
-			return nil, err
+	discard(resp)
+	if err := retryAllowed(ctx, deadline); err != nil {
+		return nil, err
+	}
+	*retries++
+
+	// When another request logged in again while this one waited, just retry
+	_, err = c.relogin(ctx, seen.generation, func(ctx context.Context, st sessionState) error {
+		attempt := c.authAttempts.Add(1)
+		endpoint, _, _ := strings.Cut(path, "?")
+		loginStart := time.Now()
+		var err error
+		if st.refreshToken != "" {
+			err = c.LoginWithToken(ctx, st.refreshToken)
+		} else {
+			err = c.Login(ctx, st.username, st.password)
+		}
+		notifyReauth(ctx, ReauthEvent{
+			Method:   method,
+			Endpoint: endpoint,
+			Attempt:  int(attempt),
+			Duration: time.Since(loginStart),
+			Err:      err,
+		})
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrCredentialsInvalid) {
+			c.setCredentialsInvalid(true)
 		}
+		var lockout *LockoutError
+		if errors.As(err, &lockout) {
+			c.recordLockout(lockout)
+		}
+		return nil, NewAuthenticationError("failed to re-authenticate", err)
+	}
+
+	if err := retryAllowed(ctx, deadline); err != nil {
+		return nil, err
+	}
+	resp, err = c.send(ctx, method, path, bodyBytes, true)
+	if err != nil {
+		return nil, fmt.Errorf("final retry request failed: %w", err)
+	}
+	return resp, nil
+}
 
+// send makes a single attempt with a fresh copy of the body and, if asked, the cached
+// CSRF token.
+func (c *Client) send(ctx context.Context, method, path string, bodyBytes []byte, withCSRF bool) (*http.Response, error) {
+	req, err := c.createRequest(ctx, method, path, bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	if withCSRF {
 		token, err := c.GetCSRFToken()
 		if err != nil {
-			return nil, fmt.Errorf("failed to get updated CSRF token: %w", err)
+			return nil, fmt.Errorf("failed to get CSRF token: %w", err)
 		}
 		req.Header.Set("X-CSRF-TOKEN", token)
+	}
+	return c.httpClient.Do(req)
+}
 
-		resp, err = c.httpClient.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("retry request failed: %w", err)
-		}
-
-		attempts := c.authAttempts.Load()
-		c.mu.RLock()
-		username := c.username
-		password := c.password
-		c.mu.RUnlock()
-
-		if resp.StatusCode == http.StatusUnauthorized && username != "" && password != "" && attempts < MaxAuthRetryAttempts {
-			resp.Body.Close()
-
-			c.authAttempts.Add(1)
-
-			if err := c.Login(ctx, username, password); err != nil {
-				return nil, NewAuthenticationError("failed to re-authenticate", err)
-			}
-
-			req, err = c.createRequest(ctx, method, path, bodyBytes)
-			if err != nil {
-				return nil, err
-			}
+// retryAllowed reports whether another attempt may start: the caller is still waiting
+// and the call hasn't used up its retry wall time.
+func retryAllowed(ctx context.Context, deadline time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if time.Now().After(deadline) {
+		return fmt.Errorf("gave up retrying after %s", maxRetryWallTime)
+	}
+	return nil
+}
 
-			token, err := c.GetCSRFToken()
-			if err != nil {
-				return nil, fmt.Errorf("failed to get CSRF token after re-auth: %w", err)
-			}
-			req.Header.Set("X-CSRF-TOKEN", token)
+// discard drains and closes a response body that won't be read, so the connection goes
+// back to the pool instead of being torn down.
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	resp.Body.Close()
+}
 
-			resp, err = c.httpClient.Do(req)
-			if err != nil {
-				return nil, fmt.Errorf("final retry request failed: %w", err)
-			}
-		} else if resp.StatusCode == http.StatusUnauthorized && attempts >= MaxAuthRetryAttempts {
-			resp.Body.Close()
-			return nil, NewAuthenticationError("maximum authentication retry attempts exceeded", nil)
-		}
-	}
+func (c *Client) StrictDecode() bool {
+	return c.strictDecode
+}
 
-	return resp, nil
+func (c *Client) DriftReports() []DriftReport {
+	return c.drift.snapshot()
 }
 
 func (c *Client) GetCookies() []*http.Cookie {
 	u, _ := url.Parse(c.baseURL)
-	return c.httpClient.Jar.Cookies(u)
+	return c.jar.Cookies(u)
 }
 
 func (c *Client) SetCookies(cookies []*http.Cookie) {
 	u, _ := url.Parse(c.baseURL)
-	c.httpClient.Jar.SetCookies(u, cookies)
+	c.jar.SetCookies(u, cookies)
+}
+
+// relogin runs login while holding loginMu, unless the session has moved past the
+// generation the caller saw, meaning another goroutine logged in (or out) meanwhile.
+// Concurrent 401s thus cause one login rather than one each, which matters because a
+// rotated refresh token only works once. It reports whether login ran.
+func (c *Client) relogin(ctx context.Context, seen uint64, login func(context.Context, sessionState) error) (bool, error) {
+	if inRelogin(ctx) {
+		return false, nil
+	}
+	c.loginMu.Lock()
+	defer c.loginMu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	current := c.session.snapshot()
+	if current.generation != seen {
+		return false, nil
+	}
+	return true, login(context.WithValue(ctx, reloginKey{}, true), current)
+}
+
+type reloginKey struct{}
+
+// inRelogin reports whether ctx belongs to a login run by relogin. Requests made by that
+// login must not start another one: loginMu is already held.
+func inRelogin(ctx context.Context) bool {
+	return ctx.Value(reloginKey{}) != nil
 }
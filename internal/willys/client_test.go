@@ -1,7 +1,14 @@
 package willys
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -22,12 +29,13 @@ func TestNewClient(t *testing.T) {
 		t.Errorf("Expected baseURL %s, got %s", baseURL, client.baseURL)
 	}
 
-	if client.username != username {
-		t.Errorf("Expected username %s, got %s", username, client.username)
+	st := client.session.snapshot()
+	if st.username != username {
+		t.Errorf("Expected username %s, got %s", username, st.username)
 	}
 
-	if client.password != password {
-		t.Errorf("Expected password %s, got %s", password, client.password)
+	if st.password != password {
+		t.Errorf("Expected password %s, got %s", password, st.password)
 	}
 
 	if client.httpClient == nil {
@@ -49,3 +57,81 @@ func TestIsAuthenticated(t *testing.T) {
 		t.Error("New client should not be authenticated")
 	}
 }
+
+func TestShouldFallback(t *testing.T) {
+	client, err := NewClient("https://www.willys.se", "", "", WithBrowserFallback(EndpointSearch))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	blocked := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{"Content-Type": {"text/html; charset=utf-8"}}}
+	denied := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{"Content-Type": {"application/json"}}}
+
+	if !client.shouldFallback(EndpointSearch, blocked) {
+		t.Error("Expected fallback for blocked search")
+	}
+	if client.shouldFallback(EndpointCart, blocked) {
+		t.Error("Expected no fallback for endpoint not configured")
+	}
+	if client.shouldFallback(EndpointSearch, denied) {
+		t.Error("Expected no fallback for a JSON 403")
+	}
+}
+
+func TestDoRequestReusesConnectionAcrossRetries(t *testing.T) {
+	var newConns atomic.Int32
+	stale := true
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointCart:
+			if stale {
+				stale = false
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(strings.Repeat(`{"error":"invalid csrf token"}`, 100)))
+				return
+			}
+			w.Write([]byte(`{}`))
+		}
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	resp, err := client.DoRequest(context.Background(), "DELETE", EndpointCart, nil, true)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	discard(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after refreshing the CSRF token, got %d", resp.StatusCode)
+	}
+	if n := newConns.Load(); n != 1 {
+		t.Errorf("Expected the retry to reuse the connection, got %d connections", n)
+	}
+}
+
+func TestRetryAllowed(t *testing.T) {
+	if err := retryAllowed(context.Background(), time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("Expected a retry within the budget, got %v", err)
+	}
+	if err := retryAllowed(context.Background(), time.Now().Add(-time.Second)); err == nil {
+		t.Error("Expected no retry past the deadline")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := retryAllowed(ctx, time.Now().Add(time.Minute)); err == nil {
+		t.Error("Expected no retry once the caller gave up")
+	}
+}
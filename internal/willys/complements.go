@@ -0,0 +1,118 @@
+package willys
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// minCoPurchaseOrders is how many past orders must contain both a cart product and
+	// another product before the pair counts as a habit rather than chance.
+	minCoPurchaseOrders = 2
+
+	// relatedWeight scores the top "others also bought" product against a habit seen in
+	// every order (1.0); later recommendations score less.
+	relatedWeight = 0.5
+)
+
+// Complement is a product that usually goes with something in the cart but isn't in it.
+type Complement struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Price   Money    `json:"price"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// SuggestComplements ranks products that are missing from the cart but were bought
+// together with its items in the customer's past orders, or that other customers buy with
+// them (related, keyed by cart product code). It returns at most limit suggestions.
+func SuggestComplements(items []CartItem, orders []Order, related map[string][]Product, limit int) []Complement {
+	inCart := make(map[string]string, len(items))
+	for _, item := range items {
+		inCart[item.ProductCode] = item.Name
+	}
+
+	candidates := make(map[string]*Complement)
+	candidate := func(code, name string, price Money) *Complement {
+		c, ok := candidates[code]
+		if !ok {
+			c = &Complement{Code: code, Name: name, Price: price}
+			candidates[code] = c
+		}
+		if c.Name == "" {
+			c.Name = name
+		}
+		if c.Price.IsZero() {
+			c.Price = price
+		}
+		return c
+	}
+
+	for _, item := range items {
+		withItem := 0
+		together := make(map[string]int)
+		entries := make(map[string]OrderEntry)
+		for _, order := range orders {
+			if !containsEntry(order, item.ProductCode) {
+				continue
+			}
+			withItem++
+			seen := make(map[string]bool, len(order.Entries))
+			for _, entry := range order.Entries {
+				if _, ok := inCart[entry.Code]; ok || seen[entry.Code] {
+					continue
+				}
+				seen[entry.Code] = true
+				together[entry.Code]++
+				entries[entry.Code] = entry
+			}
+		}
+
+		for code, n := range together {
+			if n < minCoPurchaseOrders {
+				continue
+			}
+			entry := entries[code]
+			c := candidate(code, entry.Name, entry.Price)
+			c.Score = max(c.Score, float64(n)/float64(withItem))
+			c.Reasons = append(c.Reasons, fmt.Sprintf("bought with %s in %d of %d orders", item.Name, n, withItem))
+		}
+	}
+
+	for _, item := range items {
+		for rank, p := range related[item.ProductCode] {
+			if _, ok := inCart[p.Code]; ok {
+				continue
+			}
+			c := candidate(p.Code, p.Name, p.PriceValue)
+			c.Score += relatedWeight / float64(rank+1)
+			c.Reasons = append(c.Reasons, fmt.Sprintf("others who buy %s also buy it", item.Name))
+		}
+	}
+
+	suggestions := make([]Complement, 0, len(candidates))
+	for _, c := range candidates {
+		sort.Strings(c.Reasons)
+		suggestions = append(suggestions, *c)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Code < suggestions[j].Code
+	})
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+func containsEntry(order Order, code string) bool {
+	for _, entry := range order.Entries {
+		if entry.Code == code {
+			return true
+		}
+	}
+	return false
+}
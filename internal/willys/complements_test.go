@@ -0,0 +1,46 @@
+package willys
+
+import "testing"
+
+func TestSuggestComplements(t *testing.T) {
+	order := func(codes ...string) Order {
+		o := Order{}
+		for _, code := range codes {
+			o.Entries = append(o.Entries, OrderEntry{Code: code, Name: "name " + code, Quantity: 1, Price: SEK(1000)})
+		}
+		return o
+	}
+	orders := []Order{
+		order("taco_ST", "salsa_ST", "cheese_ST"),
+		order("taco_ST", "salsa_ST"),
+		order("taco_ST", "salsa_ST", "milk_ST", "cheese_ST"),
+		order("taco_ST", "milk_ST"),
+		order("bread_ST", "milk_ST"),
+	}
+	items := []CartItem{{ProductCode: "taco_ST", Name: "Tacoskal"}, {ProductCode: "cheese_ST", Name: "Ost"}}
+	related := map[string][]Product{
+		"taco_ST": {{Code: "cheese_ST", Name: "Ost"}, {Code: "guac_ST", Name: "Guacamole", PriceValue: SEK(2990)}},
+	}
+
+	suggestions := SuggestComplements(items, orders, related, 0)
+
+	if len(suggestions) != 3 {
+		t.Fatalf("Expected salsa, milk and guacamole, got %+v", suggestions)
+	}
+	if suggestions[0].Code != "salsa_ST" || suggestions[0].Score != 1 {
+		t.Errorf("Expected salsa, bought with cheese in every order, first; got %+v", suggestions[0])
+	}
+	if len(suggestions[0].Reasons) != 2 {
+		t.Errorf("Expected a reason per cart item, got %v", suggestions[0].Reasons)
+	}
+	if suggestions[1].Code != "milk_ST" || suggestions[1].Score != 0.5 {
+		t.Errorf("Expected milk (2 of 4 taco orders) second, got %+v", suggestions[1])
+	}
+	if suggestions[2].Code != "guac_ST" || suggestions[2].Price != SEK(2990) {
+		t.Errorf("Expected the related product with its price last, got %+v", suggestions[2])
+	}
+
+	if limited := SuggestComplements(items, orders, related, 1); len(limited) != 1 {
+		t.Errorf("Expected the limit to apply, got %d", len(limited))
+	}
+}
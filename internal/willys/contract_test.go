@@ -0,0 +1,361 @@
+package willys
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Fixtures in testdata/ are sanitized captures of real Axfood API responses. If the API
+// changes shape, update the fixture and these expectations together.
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("Failed to read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func newFixtureClient(t *testing.T, routes map[string]string) *Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fixture, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(loadFixture(t, fixture))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(srv.URL, "", "", WithStrictDecode(true))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	return client
+}
+
+func assertNoMissingFields(t *testing.T, client *Client) {
+	t.Helper()
+	for _, r := range client.DriftReports() {
+		if len(r.MissingFields) > 0 || len(r.TypeMismatches) > 0 || r.DecodeErrors > 0 {
+			t.Errorf("Fixture for %s drifted from schema: missing=%v mismatched=%v decode_errors=%d",
+				r.Endpoint, r.MissingFields, r.TypeMismatches, r.DecodeErrors)
+		}
+	}
+}
+
+func TestSearchFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointSearch: "search.json"})
+
+	products, err := client.SearchProducts(context.Background(), "mjölk", 0, 10, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(products) != 2 {
+		t.Fatalf("Expected 2 products, got %d", len(products))
+	}
+
+	p := products[1]
+	if p.Code != "101205823_ST" || p.Manufacturer != "Arla Ko" || p.PriceValue != SEK(1950) {
+		t.Errorf("Unexpected product: %+v", p)
+	}
+	if p.SavingsAmount == nil || *p.SavingsAmount != SEK(250) {
+		t.Errorf("Expected savings 2.5, got %v", p.SavingsAmount)
+	}
+	if products[0].SavingsAmount != nil {
+		t.Errorf("Expected nil savings for null, got %v", *products[0].SavingsAmount)
+	}
+	if p.Sustainability == nil || p.Sustainability.Score == 0 {
+		t.Errorf("Expected sustainability from eco labels, got %+v", p.Sustainability)
+	}
+	if p.ComparePrice.Float() != 13.0 {
+		t.Errorf("Expected compare price 13.0, got %q", p.ComparePrice)
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestSearchFixtureFacets(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointSearch: "search.json"})
+
+	result, err := client.SearchWithFacets(context.Background(), "mjölk", 0, 10, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(result.Facets) != 1 {
+		t.Fatalf("Expected the hidden facet to be skipped, got %+v", result.Facets)
+	}
+	brands := result.Facets.Brands()
+	if len(brands) != 2 || brands[0].Name != "Garant" || brands[0].Count != 31 {
+		t.Errorf("Unexpected brands: %+v", brands)
+	}
+	if brands[1].Query != "mjölk:relevance:brand:Arla Ko" {
+		t.Errorf("Expected the refinement query, got %q", brands[1].Query)
+	}
+	if result.Facets.Find("BRAND") == nil || result.Facets.Find(FacetCategory) != nil {
+		t.Error("Expected Find to match facet codes case-insensitively")
+	}
+}
+
+func TestCartFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointCart: "cart.json"})
+
+	cart, err := client.GetCart(context.Background())
+	if err != nil {
+		t.Fatalf("Get cart failed: %v", err)
+	}
+
+	if len(cart.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %d", len(cart.Items))
+	}
+
+	// Each product uses a different price encoding: number, {value}, and string
+	expectedPrices := []Money{SEK(1590), SEK(1950), SEK(2490)}
+	for i, expected := range expectedPrices {
+		if cart.Items[i].Price != expected {
+			t.Errorf("Item %d: expected price %s, got %s", i, expected, cart.Items[i].Price)
+		}
+	}
+
+	if cart.Items[0].TotalPrice != SEK(3180) {
+		t.Errorf("Expected line total 31,80 kr, got %s", cart.Items[0].TotalPrice)
+	}
+	if cart.ItemCount != 4 {
+		t.Errorf("Expected item count 4, got %d", cart.ItemCount)
+	}
+	if cart.TotalPrice != SEK(7620) || cart.DeliveryFee != SEK(9900) || cart.PickingFee != SEK(5900) {
+		t.Errorf("Unexpected totals: total=%s delivery=%s picking=%s", cart.TotalPrice, cart.DeliveryFee, cart.PickingFee)
+	}
+	if cart.FinalTotal != SEK(23420) {
+		t.Errorf("Expected final total 234,20 kr, got %s", cart.FinalTotal)
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestTimeSlotsFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointSlotHomeDelivery: "slots.json"})
+
+	slots, err := client.GetAvailableTimeSlots(context.Background(), "11151")
+	if err != nil {
+		t.Fatalf("Get time slots failed: %v", err)
+	}
+
+	if len(slots) != 2 {
+		t.Fatalf("Expected 2 slots, got %d", len(slots))
+	}
+
+	start := time.UnixMilli(1741960800000)
+	s := slots[0]
+	if s.SlotID != "2025-03-14T15:00:00_2025-03-14T17:00:00_R12" {
+		t.Errorf("Unexpected slot ID: %s", s.SlotID)
+	}
+	if s.Date != start.Format("2006-01-02") || s.StartTime != start.Format("15:04") {
+		t.Errorf("Unexpected slot date/time: %s %s", s.Date, s.StartTime)
+	}
+	if s.Fee != SEK(4900) || !s.Available {
+		t.Errorf("Unexpected fee/availability: %s %v", s.Fee, s.Available)
+	}
+	if s.RouteID != 1234 || s.ResourceKey != "RES-0001" || s.ScheduleKey != "SCH-0001" ||
+		s.PrecedingStopId != 17 || s.StopNumber != 18 || s.Profitability != 0.75 {
+		t.Errorf("TMS reference not parsed: %+v", s)
+	}
+	if slots[1].Available {
+		t.Error("Second slot should be unavailable")
+	}
+	if !s.CutoffTime.Equal(time.UnixMilli(1741903200000)) || s.CutoffEstimated {
+		t.Errorf("Unexpected cut-off: %v (estimated=%v)", s.CutoffTime, s.CutoffEstimated)
+	}
+	second := time.UnixMilli(1741968000000)
+	if !slots[1].CutoffEstimated || !slots[1].CutoffTime.Equal(time.Date(second.Year(), second.Month(), second.Day(), 0, 0, 0, 0, second.Location())) {
+		t.Errorf("Expected cut-off estimated as midnight before delivery, got %v", slots[1].CutoffTime)
+	}
+	if !s.CanModify(s.CutoffTime.Add(-time.Minute)) || s.CanModify(s.CutoffTime) {
+		t.Error("CanModify should flip at the cut-off")
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestCustomerFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointCustomer: "customer.json"})
+
+	info, err := client.GetCustomerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("Get customer info failed: %v", err)
+	}
+
+	expected := CustomerInfo{
+		CustomerID:   "0000000000",
+		Email:        "test.user@example.com",
+		FirstName:    "Test",
+		LastName:     "User",
+		PhoneNumber:  "0700000000",
+		PlusCustomer: true,
+	}
+	if *info != expected {
+		t.Errorf("Expected %+v, got %+v", expected, *info)
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestPaymentMethodsFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointPaymentMethods: "payment_methods.json"})
+
+	methods, err := client.GetPaymentMethods(context.Background())
+	if err != nil {
+		t.Fatalf("Get payment methods failed: %v", err)
+	}
+
+	if len(methods.SavedCards) != 2 {
+		t.Fatalf("Expected 2 saved cards, got %d", len(methods.SavedCards))
+	}
+	if methods.InvoiceEligible {
+		t.Error("Expected account not to be invoice eligible")
+	}
+	if !reflect.DeepEqual(methods.AvailableTypes, []string{PaymentTypeCard, PaymentTypeSwish}) {
+		t.Errorf("Unexpected payment types: %v", methods.AvailableTypes)
+	}
+	if card := methods.DefaultCard(); card == nil || card.MaskedNumber != "************1234" {
+		t.Errorf("Expected default card ending 1234, got %+v", card)
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestDeliverabilityFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		EndpointShippingDelivery + "/11151/deliverability": "deliverability.json",
+	})
+
+	ok, err := client.CheckDeliverability(context.Background(), "11151")
+	if err != nil {
+		t.Fatalf("Check deliverability failed: %v", err)
+	}
+	if !ok {
+		t.Error("Expected postal code to be deliverable")
+	}
+}
+
+func TestDeliverabilityReportFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		EndpointShippingDelivery + "/11151/deliverability": "deliverability.json",
+		EndpointSlotHomeDelivery:                           "slots.json",
+	})
+
+	report, err := client.GetDeliverability(context.Background(), "11151")
+	if err != nil {
+		t.Fatalf("Get deliverability failed: %v", err)
+	}
+
+	if !report.Deliverable || report.AvailableSlots != 1 {
+		t.Errorf("Expected deliverable with 1 open slot, got %+v", report)
+	}
+	if report.EarliestDate != time.UnixMilli(1741960800000).Format("2006-01-02") {
+		t.Errorf("Unexpected earliest date: %s", report.EarliestDate)
+	}
+	if report.MinFee == nil || *report.MinFee != SEK(4900) || *report.MaxFee != SEK(4900) {
+		t.Errorf("Unexpected fee range: %v-%v", report.MinFee, report.MaxFee)
+	}
+}
+
+func TestProductDetailFixture(t *testing.T) {
+	var p Product
+	if err := json.Unmarshal(loadFixture(t, "product.json"), &p); err != nil {
+		t.Fatalf("Failed to decode product detail: %v", err)
+	}
+
+	if p.Code != "101233933_ST" || p.Image.URL == "" || len(p.Labels) != 2 {
+		t.Errorf("Unexpected product detail: %+v", p)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	savings := SEK(250)
+	values := []any{
+		&Product{Code: "1_ST", Name: "Mjölk", PriceValue: SEK(1590), Labels: []string{"krav"}, SavingsAmount: &savings,
+			Sustainability: &Sustainability{Labels: []string{"KRAV"}, Score: 3}},
+		&CartSummary{Items: []CartItem{{ProductCode: "1_ST", Name: "Mjölk", Quantity: 2, Price: SEK(1590), TotalPrice: SEK(3180)}},
+			TotalPrice: SEK(3180), ItemCount: 2, DeliveryFee: SEK(9900), PickingFee: SEK(5900), FinalTotal: SEK(18980)},
+		&TimeSlot{SlotID: "s1", Date: "2025-03-14", StartTime: "15:00", EndTime: "17:00", Fee: SEK(4900), Available: true,
+			EarliestDateTime: 1741960800000, LatestDateTime: 1741968000000, RouteID: 1, ResourceKey: "r", ScheduleKey: "s"},
+		&DeliveryInfo{Address: DeliveryAddress{FirstName: "Test", LastName: "User", Address: "Gatan 1", PostalCode: "11151", City: "Stockholm"},
+			TimeSlot: TimeSlot{SlotID: "s1"}, PickingFee: SEK(5900), DeliveryFee: SEK(4900), TotalFee: SEK(10800)},
+		&CustomerInfo{CustomerID: "1", Email: "a@example.com", PlusCustomer: true},
+		&SearchPreferences{SortBy: "cheapest", RequiredLabels: []string{"krav"}, MaxPricePerUnit: 20},
+	}
+
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Failed to marshal %T: %v", v, err)
+		}
+
+		decoded := reflect.New(reflect.TypeOf(v).Elem()).Interface()
+		if err := json.Unmarshal(data, decoded); err != nil {
+			t.Fatalf("Failed to unmarshal %T: %v", v, err)
+		}
+
+		if !reflect.DeepEqual(v, decoded) {
+			t.Errorf("%T did not round-trip:\n  before: %+v\n  after:  %+v", v, v, decoded)
+		}
+	}
+}
+
+func TestNewProductsFixture(t *testing.T) {
+	// Category listings share the search response shape.
+	client := newFixtureClient(t, map[string]string{EndpointNewProducts: "search.json"})
+
+	products, err := client.GetNewProducts(context.Background())
+	if err != nil {
+		t.Fatalf("Get new products failed: %v", err)
+	}
+	if len(products) != 2 || products[1].Code != "101205823_ST" {
+		t.Errorf("Unexpected new products: %+v", products)
+	}
+
+	assertNoMissingFields(t, client)
+}
+
+func TestOrderHistoryFixture(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{EndpointOrderHistory: "order_history.json"})
+
+	orders, err := client.GetOrderHistory(context.Background())
+	if err != nil {
+		t.Fatalf("Get order history failed: %v", err)
+	}
+
+	if len(orders) != 2 {
+		t.Fatalf("Expected 2 orders, got %d", len(orders))
+	}
+	first := orders[0]
+	if first.Code != "3124567890" || first.Total != SEK(64250) || len(first.Entries) != 2 {
+		t.Errorf("Unexpected order: %+v", first)
+	}
+	if first.PlacedAt.IsZero() || first.Entries[0].Manufacturer != "Arla Ko" || first.Entries[0].Price != SEK(1790) {
+		t.Errorf("Unexpected order details: %+v", first)
+	}
+
+	history, err := client.PurchaseHistory(context.Background())
+	if err != nil {
+		t.Fatalf("Purchase history failed: %v", err)
+	}
+	if history.Products["101205823_ST"] != 5 || history.Brands["bregott"] != 1 || history.LastPrices["101205823_ST"] != SEK(1790) {
+		t.Errorf("Unexpected purchase history: %+v", history)
+	}
+
+	assertNoMissingFields(t, client)
+}
@@ -0,0 +1,92 @@
+package willys
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultMaxResponseBytes caps how much of a successful response is buffered. The largest
+// real responses (search pages, order history) are a few hundred KB.
+const defaultMaxResponseBytes = 16 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds DecodeOptions.MaxBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// DecodeOptions controls how response bodies are read and decoded. Zero values keep the
+// defaults.
+type DecodeOptions struct {
+	// MaxBytes caps the size of a response body; 0 means 16 MB
+	MaxBytes int64
+	// DisallowUnknownFields fails decoding when a response has fields the target type
+	// doesn't, instead of only reporting them as drift
+	DisallowUnknownFields bool
+}
+
+// WithDecodeOptions sets the limits and strictness used when decoding responses.
+func WithDecodeOptions(opts DecodeOptions) ClientOption {
+	return func(c *Client) {
+		c.decodeOpts = opts
+	}
+}
+
+// readJSON reads resp's body and decodes it into v, see decodeJSON.
+func (c *Client) readJSON(resp *http.Response, endpoint string, v any, required ...string) error {
+	body, err := c.readBody(resp)
+	if err != nil {
+		return err
+	}
+	return c.decodeJSON(endpoint, body, v, required...)
+}
+
+// readBody reads a response body up to the configured size limit.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	limit := c.decodeOpts.MaxBytes
+	if limit <= 0 {
+		limit = defaultMaxResponseBytes
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, limit)
+	}
+	return body, nil
+}
+
+// decodeJSON unmarshals an upstream response body and, in strict mode, records any schema
+// drift against v. Required paths are reported as missing when absent.
+func (c *Client) decodeJSON(endpoint string, body []byte, v any, required ...string) error {
+	if err := unmarshalJSON(body, v, c.decodeOpts.DisallowUnknownFields); err != nil {
+		if c.strictDecode {
+			c.drift.recordDecodeError(endpoint)
+		}
+		return err
+	}
+	if c.strictDecode {
+		c.drift.record(endpoint, checkDrift(body, v, required))
+	}
+	return nil
+}
+
+// unmarshalJSON is json.Unmarshal with numbers in untyped values kept as json.Number, so
+// large IDs and öre amounts don't round through float64.
+func unmarshalJSON(body []byte, v any, disallowUnknown bool) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if disallowUnknown {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
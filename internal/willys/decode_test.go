@@ -0,0 +1,63 @@
+package willys
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadJSON(t *testing.T) {
+	response := func(body string) *http.Response {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body))}
+	}
+
+	var raw map[string]any
+	c := &Client{drift: newDriftTracker()}
+	if err := c.readJSON(response(`{"code": 1234567890123456789}`), EndpointCart, &raw); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+	if n, ok := raw["code"].(json.Number); !ok || n.String() != "1234567890123456789" {
+		t.Errorf("Expected large numbers to be kept exact, got %#v", raw["code"])
+	}
+
+	var v struct {
+		Code string `json:"code"`
+	}
+	if err := c.readJSON(response(`{"code": "a"} {"code": "b"}`), EndpointCart, &v); err == nil {
+		t.Error("Expected trailing data to be rejected")
+	}
+	if err := c.readJSON(response(`{"code": "a", "extra": true}`), EndpointCart, &v); err != nil {
+		t.Errorf("Expected unknown fields to be accepted by default, got %v", err)
+	}
+
+	strict := &Client{drift: newDriftTracker(), strictDecode: true, decodeOpts: DecodeOptions{MaxBytes: 32, DisallowUnknownFields: true}}
+	if err := strict.readJSON(response(`{"code": "a", "extra": true}`), EndpointCart, &v); err == nil {
+		t.Error("Expected unknown fields to be rejected")
+	}
+	if reports := strict.DriftReports(); len(reports) != 1 || reports[0].DecodeErrors != 1 {
+		t.Errorf("Expected the rejection to be recorded as a decode error, got %+v", reports)
+	}
+	if err := strict.readJSON(response(`{"code": "`+strings.Repeat("a", 64)+`"}`), EndpointCart, &v); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("Expected ErrResponseTooLarge, got %v", err)
+	}
+}
+
+func TestCheckDriftIntegers(t *testing.T) {
+	var target struct {
+		Quantity int `json:"quantity"`
+	}
+	result := checkDrift([]byte(`{"quantity": 1.5}`), &target, nil)
+	assertContains(t, "mismatched", result.mismatched, "quantity (expected integer)")
+}
+
+func TestCSRFTokenResponse(t *testing.T) {
+	for _, body := range []string{`"abc"`, `{"token": "abc"}`} {
+		var token csrfTokenResponse
+		if err := json.Unmarshal([]byte(body), &token); err != nil || token != "abc" {
+			t.Errorf("Expected %s to decode to abc, got %q (%v)", body, token, err)
+		}
+	}
+}
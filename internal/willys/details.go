@@ -0,0 +1,110 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// productDetailsConcurrency bounds the detail requests GetProductsDetails runs at once.
+// Willys has no endpoint for several codes, so each product is a request of its own.
+const productDetailsConcurrency = 4
+
+type (
+	// ProductDetails is the full product page: what search returns plus the description,
+	// ingredients, and nutrition facts.
+	ProductDetails struct {
+		Product
+		Description     string          `json:"description,omitempty"`
+		Ingredients     string          `json:"ingredients,omitempty"`
+		NutritionFacts  []NutritionFact `json:"nutritionsFactList,omitempty"`
+		CountryOfOrigin string          `json:"tradeItemCountryOfOrigin,omitempty"`
+	}
+
+	// NutritionFact is one line of the nutrition table, per 100 g or 100 ml.
+	NutritionFact struct {
+		TypeCode string `json:"typeCode"` // e.g. "Energi", "Fett", "Socker"
+		Value    string `json:"value"`
+		UnitCode string `json:"unitCode"`
+	}
+)
+
+// GetProductDetails fetches the product page for productCode. A product Willys doesn't
+// know yields a nil result without an error.
+func (c *Client) GetProductDetails(ctx context.Context, productCode string) (*ProductDetails, error) {
+	if err := ValidateProductCode(productCode); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("%s/%s", EndpointProduct, url.PathEscape(productCode))
+	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, path, "product details request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		discard(resp)
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, path, "get product details failed")
+	}
+
+	var details ProductDetails
+	if err := c.readJSON(resp, EndpointProduct, &details, "code", "name"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse product details", err)
+	}
+	details.Sustainability = ParseSustainability(details.Labels)
+	details.StockStatus = ParseStockStatus(details.OutOfStock, details.LowStock, details.StockQuantity)
+	return &details, nil
+}
+
+// GetProductsDetails fetches the details of several products, productDetailsConcurrency
+// at a time, and maps them by code. Duplicate codes are fetched once and unknown
+// products are left out. When some lookups fail, the others are still returned along
+// with an error joining the failures.
+func (c *Client) GetProductsDetails(ctx context.Context, productCodes []string) (map[string]*ProductDetails, error) {
+	var (
+		mu      sync.Mutex
+		details = make(map[string]*ProductDetails, len(productCodes))
+		errs    []error
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, productDetailsConcurrency)
+		seen    = make(map[string]bool, len(productCodes))
+	)
+	for _, code := range productCodes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs = append(errs, ctx.Err())
+			mu.Unlock()
+			wg.Wait()
+			return details, errors.Join(errs...)
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			d, err := c.GetProductDetails(ctx, code)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("%s: %w", code, err))
+			case d != nil:
+				details[code] = d
+			}
+		}()
+	}
+	wg.Wait()
+	return details, errors.Join(errs...)
+}
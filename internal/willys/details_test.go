@@ -0,0 +1,73 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetProductsDetails(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		fetches  = map[string]int{}
+		inFlight atomic.Int32
+		peak     atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code := strings.TrimPrefix(r.URL.Path, EndpointProduct+"/")
+		mu.Lock()
+		fetches[code]++
+		mu.Unlock()
+
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch code {
+		case "404_ST":
+			w.WriteHeader(http.StatusNotFound)
+		case "500_ST":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.Write([]byte(`{"code": "` + code + `", "name": "Produkt", "ingredients": "Vatten", "nutritionsFactList": [{"typeCode": "Socker", "value": "4,5", "unitCode": "gram"}]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	codes := []string{"101_ST", "102_ST", "103_ST", "104_ST", "105_ST", "106_ST", "101_ST", "404_ST", "500_ST"}
+	details, err := client.GetProductsDetails(context.Background(), codes)
+	if err == nil || !strings.Contains(err.Error(), "500_ST") {
+		t.Errorf("Expected the failed lookup to be reported, got %v", err)
+	}
+	if len(details) != 6 {
+		t.Fatalf("Expected details for the 6 known products, got %d", len(details))
+	}
+	if d := details["101_ST"]; d.Ingredients != "Vatten" || len(d.NutritionFacts) != 1 || d.NutritionFacts[0].TypeCode != "Socker" {
+		t.Errorf("Unexpected details: %+v", d)
+	}
+	if fetches["101_ST"] != 1 {
+		t.Errorf("Expected a duplicate code to be fetched once, got %d", fetches["101_ST"])
+	}
+	if p := peak.Load(); p > productDetailsConcurrency {
+		t.Errorf("Expected at most %d requests at once, got %d", productDetailsConcurrency, p)
+	}
+
+	if _, err := client.GetProductsDetails(context.Background(), []string{"101_ST", "102_ST"}); err != nil {
+		t.Fatal(err)
+	}
+	if fetches["101_ST"] != 1 || fetches["102_ST"] != 1 {
+		t.Errorf("Expected details to be served from the cache, got %v", fetches)
+	}
+}
@@ -0,0 +1,160 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+const (
+	BlockerEmptyCart       = "empty_cart"
+	BlockerMissingPhone    = "missing_phone_number"
+	BlockerUnverifiedEmail = "unverified_email"
+	BlockerNoTimeSlot      = "no_time_slot"
+	BlockerMissingAddress  = "missing_address"
+	BlockerBelowMinimum    = "below_minimum_order"
+	BlockerNotLoggedIn     = "not_logged_in"
+	BlockerRedirected      = "redirected"
+	BlockerNoInvoice       = "invoice_not_approved"
+	BlockerUnknown         = "unknown"
+)
+
+type (
+	CheckoutBlocker struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Fix     string `json:"fix"`
+	}
+
+	CheckoutDiagnosis struct {
+		Ready       bool              `json:"ready"`
+		CheckoutURL string            `json:"checkoutUrl"`
+		FinalURL    string            `json:"finalUrl,omitempty"`
+		Blockers    []CheckoutBlocker `json:"blockers"`
+	}
+
+	blockerRule struct {
+		pattern *regexp.Regexp
+		code    string
+		fix     string
+	}
+)
+
+// Ordered: the first matching rule classifies a message
+var blockerRules = []blockerRule{
+	{regexp.MustCompile(`(?i)telefon|mobilnummer`), BlockerMissingPhone, "Add a mobile number under Mina sidor on willys.se"},
+	{regexp.MustCompile(`(?i)(e-post|mejl).*(verifiera|bekräfta)|(verifiera|bekräfta).*(e-post|mejl)`), BlockerUnverifiedEmail, "Confirm the email address from the verification email Willys sent"},
+	{regexp.MustCompile(`(?i)leveranstid|tidslucka|välj (en )?tid`), BlockerNoTimeSlot, "Pick a slot with get_available_time_slots and select_delivery_time"},
+	{regexp.MustCompile(`(?i)minsta|minimibelopp|lägsta ordervärde`), BlockerBelowMinimum, "Add more items to reach the minimum order value"},
+	{regexp.MustCompile(`(?i)adress|postnummer`), BlockerMissingAddress, "Set the delivery address with select_delivery_time"},
+	{regexp.MustCompile(`(?i)logga in`), BlockerNotLoggedIn, "The session has expired; restart the server or check admin_auth_status"},
+}
+
+// classifyBlocker maps a message shown on the checkout page to a blocker code the agent
+// can act on. Unrecognized messages are passed through as "unknown".
+func classifyBlocker(message string) CheckoutBlocker {
+	message = strings.Join(strings.Fields(message), " ")
+	for _, rule := range blockerRules {
+		if rule.pattern.MatchString(message) {
+			return CheckoutBlocker{Code: rule.code, Message: message, Fix: rule.fix}
+		}
+	}
+	return CheckoutBlocker{Code: BlockerUnknown, Message: message, Fix: "Open the checkout page and resolve the message manually"}
+}
+
+// DiagnoseCheckout checks the things the checkout page needs (items, contact details,
+// a delivery slot) and then renders /kassa headlessly to collect any blocking messages.
+func (c *Client) DiagnoseCheckout(ctx context.Context) (*CheckoutDiagnosis, error) {
+	diagnosis := &CheckoutDiagnosis{
+		CheckoutURL: c.GetCheckoutURL(),
+		Blockers:    []CheckoutBlocker{},
+	}
+	seen := make(map[string]bool)
+	add := func(b CheckoutBlocker) {
+		if !seen[b.Code+b.Message] && (b.Code == BlockerUnknown || !seen[b.Code]) {
+			seen[b.Code] = true
+			seen[b.Code+b.Message] = true
+			diagnosis.Blockers = append(diagnosis.Blockers, b)
+		}
+	}
+
+	cart, err := c.GetCart(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(cart.Items) == 0 {
+		add(CheckoutBlocker{Code: BlockerEmptyCart, Message: "The cart is empty", Fix: "Add products with add_to_cart or list_to_cart"})
+	}
+
+	if customer, err := c.GetCustomerInfo(ctx); err == nil && customer.PhoneNumber == "" {
+		add(CheckoutBlocker{Code: BlockerMissingPhone, Message: "The account has no phone number", Fix: "Add a mobile number under Mina sidor on willys.se"})
+	}
+	if c.b2b {
+		if methods, err := c.GetPaymentMethods(ctx); err == nil && !methods.InvoiceEligible {
+			add(CheckoutBlocker{Code: BlockerNoInvoice, Message: "The business account is not approved for invoice payment", Fix: "Apply for invoice payment with Willys, or pay by card with payment_type card"})
+		}
+	}
+
+	messages, finalURL, err := c.renderCheckout(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render checkout page: %w", err)
+	}
+	diagnosis.FinalURL = finalURL
+	if u, err := url.Parse(finalURL); err == nil && u.Path != EndpointCheckout {
+		add(CheckoutBlocker{Code: BlockerRedirected, Message: "Checkout redirected to " + u.Path, Fix: "Resolve the other blockers and try again"})
+	}
+	for _, msg := range messages {
+		add(classifyBlocker(msg))
+	}
+
+	diagnosis.Ready = len(diagnosis.Blockers) == 0
+	return diagnosis, nil
+}
+
+func (c *Client) renderCheckout(ctx context.Context) ([]string, string, error) {
+	browser, err := c.launchBrowser(false)
+	if err != nil {
+		return nil, "", err
+	}
+	defer browser.Close()
+
+	page, err := browser.Context(ctx).Timeout(30 * time.Second).Page(proto.TargetCreateTarget{})
+	if err != nil {
+		return nil, "", err
+	}
+	if err := c.syncCookiesToBrowser(page); err != nil {
+		return nil, "", err
+	}
+	if err := page.Navigate(c.GetCheckoutURL()); err != nil {
+		return nil, "", err
+	}
+	if err := page.WaitLoad(); err != nil {
+		return nil, "", err
+	}
+
+	time.Sleep(2 * time.Second) // checkout renders its warnings client-side
+
+	var messages []string
+	elements, err := page.Elements(c.selectors[SelectorCheckoutBlocker].CSS)
+	if err == nil {
+		for _, el := range elements {
+			if visible, _ := el.Visible(); !visible {
+				continue
+			}
+			if text, err := el.Text(); err == nil && strings.TrimSpace(text) != "" {
+				messages = append(messages, text)
+			}
+		}
+	}
+
+	info, err := page.Info()
+	if err != nil {
+		return messages, "", nil
+	}
+	return messages, info.URL, nil
+}
@@ -0,0 +1,24 @@
+package willys
+
+import (
+	"testing"
+)
+
+func TestClassifyBlocker(t *testing.T) {
+	tests := map[string]string{
+		"Du måste ange ett mobilnummer":                BlockerMissingPhone,
+		"Verifiera din e-postadress för att gå vidare": BlockerUnverifiedEmail,
+		"Välj en leveranstid":                          BlockerNoTimeSlot,
+		"Minsta ordervärde är 500 kr":                  BlockerBelowMinimum,
+		"Något gick fel":                               BlockerUnknown,
+	}
+	for message, expected := range tests {
+		if got := classifyBlocker(message); got.Code != expected {
+			t.Errorf("classifyBlocker(%q): expected %s, got %s", message, expected, got.Code)
+		}
+	}
+
+	if got := classifyBlocker("  Välj\n en   leveranstid "); got.Message != "Välj en leveranstid" {
+		t.Errorf("Expected whitespace to be collapsed, got %q", got.Message)
+	}
+}
@@ -0,0 +1,66 @@
+package willys
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// WithDiagnosticsDir makes browser automation failures save a screenshot and the page HTML
+// to dir. The paths are attached to the returned AuthenticationError.
+func WithDiagnosticsDir(dir string) ClientOption {
+	return func(c *Client) {
+		c.diagnosticsDir = dir
+	}
+}
+
+// captureDiagnostics snapshots the page a browser step failed on. It never replaces the
+// original error; if the capture itself fails the error is returned as is.
+func (c *Client) captureDiagnostics(page *rod.Page, err error) error {
+	var authErr *AuthenticationError
+	if c.diagnosticsDir == "" || page == nil || !errors.As(err, &authErr) {
+		return err
+	}
+
+	if mkErr := os.MkdirAll(c.diagnosticsDir, 0o700); mkErr != nil {
+		log.Printf("Failed to create diagnostics directory: %v", mkErr)
+		return err
+	}
+
+	base := filepath.Join(c.diagnosticsDir, "login-"+time.Now().Format("20060102-150405.000"))
+
+	if screenshot, shotErr := page.Screenshot(true, &proto.PageCaptureScreenshot{Format: proto.PageCaptureScreenshotFormatPng}); shotErr == nil {
+		if writeErr := os.WriteFile(base+".png", screenshot, 0o600); writeErr == nil {
+			authErr.Diagnostics = append(authErr.Diagnostics, base+".png")
+		}
+	} else {
+		log.Printf("Failed to capture screenshot: %v", shotErr)
+	}
+
+	if html, htmlErr := page.HTML(); htmlErr == nil {
+		if writeErr := os.WriteFile(base+".html", []byte(html), 0o600); writeErr == nil {
+			authErr.Diagnostics = append(authErr.Diagnostics, base+".html")
+		}
+	} else {
+		log.Printf("Failed to capture page HTML: %v", htmlErr)
+	}
+
+	if len(authErr.Diagnostics) > 0 {
+		log.Printf("Saved browser diagnostics: %v", authErr.Diagnostics)
+	}
+	return err
+}
+
+func formatDiagnostics(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (diagnostics: %s)", strings.Join(paths, ", "))
+}
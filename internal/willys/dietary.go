@@ -0,0 +1,141 @@
+package willys
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	DietVegetarian  = "vegetarian"
+	DietVegan       = "vegan"
+	DietLactoseFree = "lactose_free"
+	DietGlutenFree  = "gluten_free"
+)
+
+type DietaryConflict struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	Diet string `json:"diet"`
+	// Ingredient is set when the conflict was found in the ingredient list
+	Ingredient string `json:"ingredient,omitempty"`
+}
+
+var (
+	// Words that mark a product as made for a diet even when its name also contains
+	// a conflicting word ("Vegetarisk färs", "Glutenfri pasta").
+	plantBasedWords  = []string{"vegetarisk", "vegansk", "vegan", "växtbaserad", "oumph", "quorn", "tofu"}
+	veganWords       = []string{"vegansk", "vegan", "växtbaserad", "havre", "soja", "oumph", "tofu"}
+	lactoseFreeWords = []string{"laktosfri", "laktosfritt"}
+	glutenFreeWords  = []string{"glutenfri", "glutenfritt"}
+	glutenWords      = []string{"pasta", "vete", "dinkel", "bulgur", "couscous", "råg", "ströbröd"}
+
+	// Ingredients that rule a product out for a diet, checked when product details are
+	// at hand. Traces ("kan innehålla spår av ...") don't count. Words starting with ^
+	// only match the start of a word, so "ost" isn't found in "rostad" nor "korn" in
+	// "majskorn".
+	meatIngredients    = []string{"kött", "fläsk", "kyckling", "fisk", "ansjovis", "räkor", "gelatin"}
+	animalIngredients  = append([]string{"mjölk", "grädde", "smör", "^ost", "vassle", "kasein", "^ägg", "honung"}, meatIngredients...)
+	dairyIngredients   = []string{"mjölk", "grädde", "smör", "^ost", "vassle", "laktos"}
+	glutenIngredients  = []string{"vete", "råg", "^korn", "dinkel", "gluten"}
+	ingredientsByDiet  = map[string][]string{DietVegetarian: meatIngredients, DietVegan: animalIngredients, DietLactoseFree: dairyIngredients, DietGlutenFree: glutenIngredients}
+	dietFriendlyByDiet = map[string][]string{DietVegetarian: plantBasedWords, DietVegan: veganWords, DietLactoseFree: lactoseFreeWords, DietGlutenFree: glutenFreeWords}
+
+	// Plant-based ingredients named after the dairy they replace. They are removed from
+	// the ingredient list before it is checked, so "kakaosmör" isn't read as butter.
+	plantDairyIngredients = []string{
+		"kakaosmör", "sheasmör", "jordnötssmör", "nötsmör", "mandelsmör", "cashewsmör",
+		"kokosmjölk", "havremjölk", "mandelmjölk", "sojamjölk", "rismjölk", "kokosgrädde",
+		"havregrädde", "sojagrädde",
+	}
+)
+
+func ValidateDiet(diets []string) error {
+	for _, d := range diets {
+		switch d {
+		case DietVegetarian, DietVegan, DietLactoseFree, DietGlutenFree:
+		default:
+			return fmt.Errorf("unknown diet %q, expected %s, %s, %s, or %s", d, DietVegetarian, DietVegan, DietLactoseFree, DietGlutenFree)
+		}
+	}
+	return nil
+}
+
+// mentions reports whether the item's name or labels contain any of words.
+func mentions(item CartItem, words []string) bool {
+	text := strings.ToLower(item.Name + " " + strings.Join(item.Labels, " "))
+	for _, w := range words {
+		if strings.Contains(text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func conflictsWith(item CartItem, diet string) bool {
+	category := CategorizeCartItem(item).Key
+	meatOrFish := category == "kott-chark-och-fagel" || category == "fisk-och-skaldjur"
+
+	switch diet {
+	case DietVegetarian:
+		return meatOrFish && !mentions(item, plantBasedWords)
+	case DietVegan:
+		animal := meatOrFish || category == "mejeri-ost-och-agg" || mentions(item, []string{"honung"})
+		return animal && !mentions(item, veganWords)
+	case DietLactoseFree:
+		dairy := category == "mejeri-ost-och-agg" && !mentions(item, []string{"ägg"})
+		return dairy && !mentions(item, append(lactoseFreeWords, veganWords...))
+	case DietGlutenFree:
+		gluten := category == "brod-och-kakor" || mentions(item, glutenWords)
+		return gluten && !mentions(item, glutenFreeWords)
+	}
+	return false
+}
+
+// conflictingIngredient returns the first ingredient that rules the product out for
+// diet, or "" if there is none or the product is made for the diet.
+func conflictingIngredient(item CartItem, details *ProductDetails, diet string) string {
+	if details == nil || details.Ingredients == "" || mentions(item, dietFriendlyByDiet[diet]) {
+		return ""
+	}
+	ingredients := strings.ToLower(details.Ingredients)
+	ingredients, _, _ = strings.Cut(ingredients, "kan innehålla")
+	for _, plant := range plantDairyIngredients {
+		ingredients = strings.ReplaceAll(ingredients, plant, " ")
+	}
+	for _, friendly := range dietFriendlyByDiet[diet] {
+		ingredients = strings.ReplaceAll(ingredients, friendly, " ")
+	}
+	words := strings.FieldsFunc(ingredients, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, ingredient := range ingredientsByDiet[diet] {
+		prefix, atStart := strings.CutPrefix(ingredient, "^")
+		for _, word := range words {
+			if atStart && strings.HasPrefix(word, prefix) || !atStart && strings.Contains(word, prefix) {
+				return prefix
+			}
+		}
+	}
+	return ""
+}
+
+// DietaryConflicts lists the items that don't suit one of diets. It works from names,
+// labels, and departments, so it is a reminder to check rather than a guarantee.
+func DietaryConflicts(items []CartItem, diets []string) []DietaryConflict {
+	return DietaryConflictsWithDetails(items, diets, nil)
+}
+
+// DietaryConflictsWithDetails is DietaryConflicts that also reads the ingredient lists in
+// details, keyed by product code, which catches products whose name gives nothing away.
+func DietaryConflictsWithDetails(items []CartItem, diets []string, details map[string]*ProductDetails) []DietaryConflict {
+	var conflicts []DietaryConflict
+	for _, item := range items {
+		for _, diet := range diets {
+			if conflictsWith(item, diet) {
+				conflicts = append(conflicts, DietaryConflict{Code: item.ProductCode, Name: item.Name, Diet: diet})
+			} else if ingredient := conflictingIngredient(item, details[item.ProductCode], diet); ingredient != "" {
+				conflicts = append(conflicts, DietaryConflict{Code: item.ProductCode, Name: item.Name, Diet: diet, Ingredient: ingredient})
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,90 @@
+package willys
+
+import "testing"
+
+func TestDietaryConflicts(t *testing.T) {
+	items := []CartItem{
+		{ProductCode: "mince", Name: "Nötfärs 12%"},
+		{ProductCode: "veggie", Name: "Vegetarisk färs"},
+		{ProductCode: "milk", Name: "Mellanmjölk 1,5%"},
+		{ProductCode: "lf-milk", Name: "Mellanmjölkdryck", Labels: []string{"Laktosfri"}, Category: "mejeri-ost-och-agg|mjolk"},
+		{ProductCode: "eggs", Name: "Ägg 12-pack"},
+		{ProductCode: "pasta", Name: "Pasta Penne"},
+		{ProductCode: "gf-pasta", Name: "Glutenfri pasta"},
+	}
+
+	tests := []struct {
+		diet     string
+		expected []string
+	}{
+		{DietVegetarian, []string{"mince"}},
+		{DietVegan, []string{"mince", "veggie", "milk", "lf-milk", "eggs"}}, // vegetarian isn't vegan
+		{DietLactoseFree, []string{"milk"}},
+		{DietGlutenFree, []string{"pasta"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, c := range DietaryConflicts(items, []string{tt.diet}) {
+			got = append(got, c.Code)
+		}
+		if len(got) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.diet, tt.expected, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.expected[i] {
+				t.Errorf("%s: expected %v, got %v", tt.diet, tt.expected, got)
+				break
+			}
+		}
+	}
+
+	if err := ValidateDiet([]string{"keto"}); err == nil {
+		t.Error("Expected unknown diet to be rejected")
+	}
+}
+
+func TestDietaryConflictsWithDetails(t *testing.T) {
+	items := []CartItem{
+		{ProductCode: "crisps", Name: "Chips Sourcream & Onion"},
+		{ProductCode: "muesli", Name: "Müsli Jordgubb"},
+		{ProductCode: "nuts", Name: "Nötmix Rostad", Category: "glass-godis-och-snacks|notter"},
+		{ProductCode: "gf-bread", Name: "Glutenfritt Bröd"},
+		{ProductCode: "dark-choc", Name: "Mörk Choklad 70%"},
+		{ProductCode: "pb-bar", Name: "Proteinbar Crunch"},
+		{ProductCode: "curry", Name: "Currysås Röd"},
+		{ProductCode: "milk-choc", Name: "Mjölkchoklad"},
+	}
+	details := map[string]*ProductDetails{
+		"crisps":    {Ingredients: "Potatis, rapsolja, vassle (mjölk), lök. Kan innehålla spår av vete."},
+		"muesli":    {Ingredients: "Havregryn, majskorn, korngryn, torkad jordgubb"},
+		"nuts":      {Ingredients: "Hasselnötter, cashewnötter, rostad mandel, salt"},
+		"gf-bread":  {Ingredients: "Glutenfri vetestärkelse, vatten"},
+		"dark-choc": {Ingredients: "Kakaomassa, socker, kakaosmör, emulgeringsmedel (sojalecitin)"},
+		"pb-bar":    {Ingredients: "Jordnötssmör (40%), dadlar, salt"},
+		"curry":     {Ingredients: "Kokosmjölk, tomat, lök, kryddor"},
+		"milk-choc": {Ingredients: "Socker, kakaosmör, mjölkpulver, kakaomassa"},
+	}
+
+	conflicts := DietaryConflictsWithDetails(items, []string{DietLactoseFree, DietGlutenFree, DietVegetarian, DietVegan}, details)
+	got := make(map[string]string)
+	for _, c := range conflicts {
+		got[c.Code+"/"+c.Diet] = c.Ingredient
+	}
+	want := map[string]string{
+		"crisps/" + DietLactoseFree:    "mjölk",
+		"muesli/" + DietGlutenFree:     "korn",
+		"crisps/" + DietVegan:          "mjölk",
+		"milk-choc/" + DietLactoseFree: "mjölk",
+		"milk-choc/" + DietVegan:       "mjölk",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for key, ingredient := range want {
+		if got[key] != ingredient {
+			t.Errorf("%s: expected %q, got %q", key, ingredient, got[key])
+		}
+	}
+}
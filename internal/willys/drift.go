@@ -0,0 +1,288 @@
+package willys
+
+import (
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// DriftReport collects the differences seen between an endpoint's responses and the
+	// structs we decode them into. The Axfood API changes without notice and a renamed
+	// field otherwise just decodes as a zero value.
+	DriftReport struct {
+		Endpoint       string    `json:"endpoint"`
+		Responses      int       `json:"responses"`
+		DriftResponses int       `json:"driftResponses"`
+		DecodeErrors   int       `json:"decodeErrors"`
+		UnknownFields  []string  `json:"unknownFields"`
+		MissingFields  []string  `json:"missingFields"`
+		TypeMismatches []string  `json:"typeMismatches"`
+		LastDrift      time.Time `json:"lastDrift,omitempty"`
+	}
+
+	driftTracker struct {
+		mu      sync.Mutex
+		reports map[string]*driftEntry
+	}
+
+	driftEntry struct {
+		report     DriftReport
+		unknown    map[string]bool
+		missing    map[string]bool
+		mismatched map[string]bool
+	}
+
+	driftResult struct {
+		unknown    []string
+		missing    []string
+		mismatched []string
+	}
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func newDriftTracker() *driftTracker {
+	return &driftTracker{reports: make(map[string]*driftEntry)}
+}
+
+func (d *driftTracker) entry(endpoint string) *driftEntry {
+	e, ok := d.reports[endpoint]
+	if !ok {
+		e = &driftEntry{
+			report:     DriftReport{Endpoint: endpoint},
+			unknown:    make(map[string]bool),
+			missing:    make(map[string]bool),
+			mismatched: make(map[string]bool),
+		}
+		d.reports[endpoint] = e
+	}
+	return e
+}
+
+func (d *driftTracker) recordDecodeError(endpoint string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	e := d.entry(endpoint)
+	e.report.Responses++
+	e.report.DecodeErrors++
+	e.report.LastDrift = time.Now()
+}
+
+// record merges a check result and logs only drift that hasn't been seen before for the
+// endpoint, so a renamed field produces one warning rather than one per request.
+func (d *driftTracker) record(endpoint string, result driftResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e := d.entry(endpoint)
+	e.report.Responses++
+	if len(result.unknown) == 0 && len(result.missing) == 0 && len(result.mismatched) == 0 {
+		return
+	}
+	e.report.DriftResponses++
+	e.report.LastDrift = time.Now()
+
+	newUnknown := mergeNew(e.unknown, result.unknown)
+	newMissing := mergeNew(e.missing, result.missing)
+	newMismatched := mergeNew(e.mismatched, result.mismatched)
+
+	if len(newUnknown)+len(newMissing)+len(newMismatched) > 0 {
+		log.Printf("schema drift endpoint=%s unknown=%v missing=%v type_mismatch=%v",
+			endpoint, newUnknown, newMissing, newMismatched)
+	}
+}
+
+func (d *driftTracker) snapshot() []DriftReport {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reports := make([]DriftReport, 0, len(d.reports))
+	for _, e := range d.reports {
+		r := e.report
+		r.UnknownFields = sortedKeys(e.unknown)
+		r.MissingFields = sortedKeys(e.missing)
+		r.TypeMismatches = sortedKeys(e.mismatched)
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Endpoint < reports[j].Endpoint
+	})
+	return reports
+}
+
+func mergeNew(seen map[string]bool, values []string) []string {
+	var added []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			added = append(added, v)
+		}
+	}
+	return added
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkDrift compares raw JSON against the Go type it is decoded into. Required paths use
+// the same notation as reported fields, e.g. "results[].code".
+func checkDrift(body []byte, target any, required []string) driftResult {
+	var raw any
+	if err := unmarshalJSON(body, &raw, false); err != nil {
+		return driftResult{}
+	}
+
+	var result driftResult
+	walkDrift(raw, reflect.TypeOf(target), "", &result)
+
+	for _, path := range required {
+		if !hasPath(raw, strings.Split(path, ".")) {
+			result.missing = append(result.missing, path)
+		}
+	}
+
+	return result
+}
+
+func walkDrift(raw any, t reflect.Type, path string, result *driftResult) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || raw == nil {
+		return
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || t.Kind() == reflect.Interface {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected object)")
+			return
+		}
+		fields := jsonFields(t)
+		for key, value := range obj {
+			field, ok := fields[key]
+			if !ok {
+				result.unknown = append(result.unknown, joinPath(path, key))
+				continue
+			}
+			walkDrift(value, field, joinPath(path, key), result)
+		}
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := raw.([]any)
+		if !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected array)")
+			return
+		}
+		// Elements share a shape; inspecting the first keeps large result lists cheap
+		if len(arr) > 0 {
+			walkDrift(arr[0], t.Elem(), path+"[]", result)
+		}
+
+	case reflect.String:
+		if _, ok := raw.(string); !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected string)")
+		}
+
+	case reflect.Bool:
+		if _, ok := raw.(bool); !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected bool)")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := raw.(json.Number)
+		if !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected number)")
+		} else if _, err := n.Int64(); err != nil {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected integer)")
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, ok := raw.(json.Number); !ok {
+			result.mismatched = append(result.mismatched, pathOrRoot(path)+" (expected number)")
+		}
+	}
+}
+
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func hasPath(raw any, parts []string) bool {
+	if len(parts) == 0 {
+		return true
+	}
+	key := parts[0]
+	isArray := strings.HasSuffix(key, "[]")
+	key = strings.TrimSuffix(key, "[]")
+
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return false
+	}
+	value, ok := obj[key]
+	if !ok {
+		return false
+	}
+	if !isArray {
+		return hasPath(value, parts[1:])
+	}
+
+	arr, ok := value.([]any)
+	if !ok {
+		return false
+	}
+	// An empty list can't be missing fields
+	if len(arr) == 0 {
+		return true
+	}
+	return hasPath(arr[0], parts[1:])
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
@@ -0,0 +1,55 @@
+package willys
+
+import (
+	"testing"
+)
+
+func TestCheckDrift(t *testing.T) {
+	var target struct {
+		Results []Product `json:"results"`
+	}
+	body := []byte(`{
+		"results": [{"code": "101_ST", "name": "Mjölk", "outOfStock": "no", "newField": 1}],
+		"pagination": {}
+	}`)
+
+	result := checkDrift(body, &target, []string{"results[].code", "results[].manufacturer"})
+
+	assertContains(t, "unknown", result.unknown, "results[].newField")
+	assertContains(t, "unknown", result.unknown, "pagination")
+	assertContains(t, "missing", result.missing, "results[].manufacturer")
+	assertContains(t, "mismatched", result.mismatched, "results[].outOfStock (expected bool)")
+
+	if len(result.missing) != 1 {
+		t.Errorf("Expected only one missing field, got %v", result.missing)
+	}
+}
+
+func TestDriftTrackerSnapshot(t *testing.T) {
+	d := newDriftTracker()
+	d.record("/search", driftResult{unknown: []string{"a"}})
+	d.record("/search", driftResult{unknown: []string{"a", "b"}})
+	d.record("/search", driftResult{})
+
+	reports := d.snapshot()
+	if len(reports) != 1 {
+		t.Fatalf("Expected 1 report, got %d", len(reports))
+	}
+	r := reports[0]
+	if r.Responses != 3 || r.DriftResponses != 2 {
+		t.Errorf("Expected 3 responses with 2 drifting, got %d/%d", r.Responses, r.DriftResponses)
+	}
+	if len(r.UnknownFields) != 2 {
+		t.Errorf("Expected unknown fields to be deduplicated, got %v", r.UnknownFields)
+	}
+}
+
+func assertContains(t *testing.T, name string, values []string, want string) {
+	t.Helper()
+	for _, v := range values {
+		if v == want {
+			return
+		}
+	}
+	t.Errorf("Expected %s fields to contain %q, got %v", name, want, values)
+}
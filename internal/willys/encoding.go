@@ -0,0 +1,125 @@
+package willys
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// acceptEncoding is what the client asks Willys for. Brotli is included because Willys'
+// CDN sometimes sends it to browser-like clients whether or not it was asked for.
+const acceptEncoding = "gzip, deflate, br"
+
+// decodingTransport manages Accept-Encoding itself instead of leaving it to net/http,
+// which only handles gzip and passes anything else through undecoded. Responses come
+// back decompressed, and a gzip body without a Content-Encoding header is detected by
+// its magic bytes.
+type decodingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *decodingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Accept-Encoding") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeBody(resp); err != nil {
+		discard(resp)
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections lets http.Client.CloseIdleConnections reach the pool underneath.
+func (t *decodingTransport) CloseIdleConnections() {
+	if closer, ok := t.next.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func encoding(resp *http.Response) string {
+	return strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+}
+
+// decodeBody replaces resp.Body with its decompressed form and drops the headers that no
+// longer describe it.
+func decodeBody(resp *http.Response) error {
+	var reader io.ReadCloser
+	var err error
+
+	switch enc := encoding(resp); enc {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		reader, err = newDeflateReader(resp.Body)
+	case "br":
+		reader = io.NopCloser(brotli.NewReader(resp.Body))
+	case "", "identity":
+		// Some endpoints gzip without saying so; check the magic bytes
+		buffered := bufio.NewReader(resp.Body)
+		if magic, _ := buffered.Peek(2); !bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+			resp.Body = readCloser{buffered, resp.Body}
+			return nil
+		}
+		reader, err = gzip.NewReader(buffered)
+	default:
+		return fmt.Errorf("unsupported Content-Encoding %q from %s", enc, resp.Request.URL.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decompress %s response: %w", resp.Request.URL.Path, err)
+	}
+
+	resp.Body = readCloser{reader, closers{reader, resp.Body}}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// newDeflateReader handles both the zlib-wrapped stream HTTP's "deflate" means and the
+// raw deflate some servers send instead.
+func newDeflateReader(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+	return flate.NewReader(buffered), nil
+}
+
+type (
+	readCloser struct {
+		io.Reader
+		io.Closer
+	}
+
+	closers []io.Closer
+)
+
+func (c closers) Close() error {
+	var first error
+	for _, closer := range c {
+		if err := closer.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
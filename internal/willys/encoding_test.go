@@ -0,0 +1,90 @@
+package willys
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+)
+
+func compress(t *testing.T, newWriter func(io.Writer) io.WriteCloser, body string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := newWriter(&buf)
+	if _, err := w.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestResponseDecoding(t *testing.T) {
+	const body = `{"totalItems":2}`
+	gzipped := compress(t, func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }, body)
+	deflated := compress(t, func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }, body)
+	brotlied := compress(t, func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }, body)
+
+	var accepted []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept-Encoding")
+		accepted = append(accepted, accept)
+		switch r.URL.Query().Get("as") {
+		case "gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(gzipped)
+		case "deflate":
+			w.Header().Set("Content-Encoding", "deflate")
+			w.Write(deflated)
+		case "unlabelled":
+			w.Write(gzipped)
+		case "br":
+			w.Header().Set("Content-Encoding", "br")
+			w.Write(brotlied)
+		case "zstd":
+			w.Header().Set("Content-Encoding", "zstd")
+			w.Write([]byte{0x28, 0xb5})
+		default:
+			w.Write([]byte(body))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for _, as := range []string{"plain", "gzip", "deflate", "unlabelled", "br", "br-post"} {
+		accepted = nil
+		method, query := "GET", as
+		if as == "br-post" {
+			method, query = "POST", "br"
+		}
+		resp, err := client.DoRequest(context.Background(), method, EndpointCart+"?as="+query, strings.NewReader(`{}`), false)
+		if err != nil {
+			t.Fatalf("%s: request failed: %v", as, err)
+		}
+		got, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil || string(got) != body {
+			t.Errorf("%s: expected %s, got %q (%v)", as, body, got, err)
+		}
+		if resp.Header.Get("Content-Encoding") != "" {
+			t.Errorf("%s: expected Content-Encoding to be removed after decoding", as)
+		}
+		if len(accepted) != 1 || accepted[0] != acceptEncoding {
+			t.Errorf("%s: expected one request accepting %q, got %q", as, acceptEncoding, accepted)
+		}
+	}
+
+	if _, err := client.DoRequest(context.Background(), "GET", EndpointCart+"?as=zstd", nil, false); err == nil || !strings.Contains(err.Error(), "zstd") {
+		t.Errorf("Expected an unsupported encoding error, got %v", err)
+	}
+}
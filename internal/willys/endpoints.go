@@ -0,0 +1,258 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// restPrefix is the root of Willys' REST API. Axfood has moved it before; the probe
+// tries restPrefixCandidates when it stops answering.
+const restPrefix = "/axfood/rest"
+
+var restPrefixCandidates = []string{"/axfood/rest/v2", "/axfood/rest/v1", "/api/axfood/rest"}
+
+// Endpoint groups, for sending part of the API to another host such as the mobile-app
+// gateway. The checkout page always stays on the web host.
+const (
+	EndpointGroupAuth    = "auth"
+	EndpointGroupAccount = "account"
+	EndpointGroupSearch  = "search"
+	EndpointGroupCart    = "cart"
+	EndpointGroupSlots   = "slots"
+)
+
+var endpointGroups = map[string]string{
+	EndpointLogin:               EndpointGroupAuth,
+	EndpointLogout:              EndpointGroupAuth,
+	EndpointOAuthToken:          EndpointGroupAuth,
+	EndpointCSRFToken:           EndpointGroupAuth,
+	EndpointCustomer:            EndpointGroupAccount,
+	EndpointPaymentMethods:      EndpointGroupAccount,
+	EndpointOrderHistory:        EndpointGroupAccount,
+	EndpointSearch:              EndpointGroupSearch,
+	EndpointSearchREST:          EndpointGroupSearch,
+	EndpointNewProducts:         EndpointGroupSearch,
+	EndpointRelatedProducts:     EndpointGroupSearch,
+	EndpointProduct:             EndpointGroupSearch,
+	EndpointCart:                EndpointGroupCart,
+	EndpointCartAddProducts:     EndpointGroupCart,
+	EndpointCartMerge:           EndpointGroupCart,
+	EndpointCartDeliveryMode:    EndpointGroupCart,
+	EndpointCartDeliveryAddress: EndpointGroupCart,
+	EndpointCartPostalCode:      EndpointGroupCart,
+	EndpointCartPaymentType:     EndpointGroupCart,
+	EndpointSlotHomeDelivery:    EndpointGroupSlots,
+	EndpointSlotInCart:          EndpointGroupSlots,
+	EndpointShippingDelivery:    EndpointGroupSlots,
+	EndpointAddressSuggestions:  EndpointGroupSlots,
+	EndpointStoreSearch:         EndpointGroupSlots,
+	EndpointSlotPickup:          EndpointGroupSlots,
+}
+
+// ValidateEndpointGroups checks group names as used by WILLYS_MOBILE_API_GROUPS.
+func ValidateEndpointGroups(groups []string) error {
+	for _, group := range groups {
+		switch group {
+		case EndpointGroupAuth, EndpointGroupAccount, EndpointGroupSearch, EndpointGroupCart, EndpointGroupSlots:
+		default:
+			return NewValidationError("endpoint_group", fmt.Sprintf("unknown endpoint group %q (use auth, account, search, cart, or slots)", group))
+		}
+	}
+	return nil
+}
+
+// WithGroupHost sends the endpoints of the given groups to baseURL instead of the main
+// host. Requests there authenticate with the OAuth access token, since the session
+// cookies belong to the web host; without one, after a password or browser login, the
+// groups stay on the main host.
+func WithGroupHost(baseURL string, groups ...string) ClientOption {
+	return func(c *Client) {
+		if baseURL == "" {
+			return
+		}
+		if c.groupHosts == nil {
+			c.groupHosts = make(map[string]string)
+		}
+		for _, group := range groups {
+			c.groupHosts[group] = strings.TrimSuffix(baseURL, "/")
+		}
+	}
+}
+
+type (
+	// EndpointOverrides maps endpoint paths, or prefixes of them, to the paths Willys
+	// serves them under now. The longest matching prefix wins, so "/axfood/rest" moves
+	// every REST endpoint while "/axfood/rest/cart" moves only the cart.
+	EndpointOverrides map[string]string
+
+	// EndpointProbe is the outcome of ProbeEndpoints.
+	EndpointProbe struct {
+		RESTPrefix string         `json:"restPrefix"`
+		Changed    bool           `json:"changed"`
+		Statuses   map[string]int `json:"statuses"` // probed prefix -> HTTP status, 0 on network errors
+	}
+
+	endpointMap struct {
+		mu        sync.RWMutex
+		overrides EndpointOverrides
+	}
+)
+
+// ParseEndpointOverrides reads "from=to" pairs separated by commas, as used by
+// WILLYS_ENDPOINTS.
+func ParseEndpointOverrides(value string) (EndpointOverrides, error) {
+	overrides := make(EndpointOverrides)
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !ok || !strings.HasPrefix(from, "/") || !strings.HasPrefix(to, "/") {
+			return nil, fmt.Errorf("invalid endpoint override %q (expected /from=/to)", pair)
+		}
+		overrides[strings.TrimSuffix(from, "/")] = strings.TrimSuffix(to, "/")
+	}
+	return overrides, nil
+}
+
+// WithEndpointOverrides sends requests for the given paths elsewhere, so a moved endpoint
+// can be fixed in configuration.
+func WithEndpointOverrides(overrides EndpointOverrides) ClientOption {
+	return func(c *Client) {
+		for from, to := range overrides {
+			c.endpoints.set(from, to)
+		}
+	}
+}
+
+func (m *endpointMap) set(from, to string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.overrides == nil {
+		m.overrides = make(EndpointOverrides)
+	}
+	m.overrides[from] = to
+}
+
+// resolve rewrites path by the longest override matching whole path segments. The query
+// string is kept.
+func (m *endpointMap) resolve(path string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.overrides) == 0 {
+		return path
+	}
+
+	endpoint, query, hasQuery := strings.Cut(path, "?")
+	best := ""
+	for from := range m.overrides {
+		if len(from) > len(best) && (endpoint == from || strings.HasPrefix(endpoint, from+"/")) {
+			best = from
+		}
+	}
+	if best == "" {
+		return path
+	}
+
+	resolved := m.overrides[best] + strings.TrimPrefix(endpoint, best)
+	if hasQuery {
+		resolved += "?" + query
+	}
+	return resolved
+}
+
+func (m *endpointMap) snapshot() EndpointOverrides {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return maps.Clone(m.overrides)
+}
+
+// url is the absolute URL of an endpoint path, on its group's host and after overrides.
+func (c *Client) url(path string) string {
+	return c.hostFor(path) + c.endpoints.resolve(path)
+}
+
+func (c *Client) hostFor(path string) string {
+	if len(c.groupHosts) == 0 {
+		return c.baseURL
+	}
+	host, ok := c.groupHosts[endpointGroup(path)]
+	if !ok {
+		return c.baseURL
+	}
+	// The token endpoint is how the access token is obtained in the first place
+	if endpoint, _, _ := strings.Cut(path, "?"); endpoint != EndpointOAuthToken && c.session.snapshot().accessToken == "" {
+		return c.baseURL
+	}
+	return host
+}
+
+// endpointGroup finds the group of a path, including paths below a known endpoint such
+// as a slot ID after EndpointSlotInCart.
+func endpointGroup(path string) string {
+	endpoint, _, _ := strings.Cut(path, "?")
+	for endpoint != "" {
+		if group, ok := endpointGroups[endpoint]; ok {
+			return group
+		}
+		i := strings.LastIndex(endpoint, "/")
+		if i < 0 {
+			break
+		}
+		endpoint = endpoint[:i]
+	}
+	return ""
+}
+
+// EndpointOverrides returns the overrides in effect, from configuration and probing.
+func (c *Client) EndpointOverrides() EndpointOverrides {
+	return c.endpoints.snapshot()
+}
+
+// ProbeEndpoints checks that the REST API still answers where the client expects it, by
+// fetching the CSRF token. If it doesn't, the known alternative prefixes are tried and
+// the first that answers becomes the override for restPrefix.
+func (c *Client) ProbeEndpoints(ctx context.Context) (*EndpointProbe, error) {
+	probe := &EndpointProbe{Statuses: make(map[string]int)}
+
+	current := strings.TrimSuffix(c.endpoints.resolve(EndpointCSRFToken), strings.TrimPrefix(EndpointCSRFToken, restPrefix))
+	for _, prefix := range append([]string{current}, restPrefixCandidates...) {
+		if _, probed := probe.Statuses[prefix]; probed {
+			continue
+		}
+		status := c.probeStatus(ctx, prefix+strings.TrimPrefix(EndpointCSRFToken, restPrefix))
+		probe.Statuses[prefix] = status
+		if status != http.StatusOK {
+			continue
+		}
+
+		probe.RESTPrefix = prefix
+		if prefix != current {
+			probe.Changed = true
+			c.endpoints.set(restPrefix, prefix)
+			log.Printf("Willys REST API moved from %s to %s", current, prefix)
+		}
+		return probe, nil
+	}
+
+	return probe, fmt.Errorf("no REST API prefix answered (tried %d)", len(probe.Statuses))
+}
+
+func (c *Client) probeStatus(ctx context.Context, path string) int {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.hostFor(EndpointCSRFToken)+path, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0
+	}
+	discard(resp)
+	return resp.StatusCode
+}
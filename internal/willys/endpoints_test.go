@@ -0,0 +1,149 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseEndpointOverrides(t *testing.T) {
+	overrides, err := ParseEndpointOverrides(" /axfood/rest=/axfood/rest/v2/ , /search=/sok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overrides["/axfood/rest"] != "/axfood/rest/v2" || overrides["/search"] != "/sok" {
+		t.Errorf("Unexpected overrides: %v", overrides)
+	}
+
+	for _, invalid := range []string{"/axfood/rest", "axfood=/x", "/a=b"} {
+		if _, err := ParseEndpointOverrides(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestEndpointResolve(t *testing.T) {
+	var m endpointMap
+	m.set("/axfood/rest", "/axfood/rest/v2")
+	m.set("/axfood/rest/cart", "/api/cart")
+
+	cases := map[string]string{
+		EndpointCustomer:               "/axfood/rest/v2/customer",
+		EndpointCart:                   "/api/cart",
+		EndpointCartAddProducts:        "/api/cart/addProducts",
+		EndpointSearch + "?q=mjölk":    "/search?q=mjölk",
+		"/axfood/restaurants":          "/axfood/restaurants",
+		EndpointCSRFToken + "?x=1&y=2": "/axfood/rest/v2/csrf-token?x=1&y=2",
+	}
+	for path, want := range cases {
+		if got := m.resolve(path); got != want {
+			t.Errorf("resolve(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestProbeEndpoints(t *testing.T) {
+	var cartPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/axfood/rest/v1/csrf-token":
+			w.Write([]byte(`"token"`))
+		case "/axfood/rest/v1/cart":
+			cartPath = r.URL.Path
+			w.Write([]byte(`{"products": [], "totalPrice": 0}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	probe, err := client.ProbeEndpoints(context.Background())
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !probe.Changed || probe.RESTPrefix != "/axfood/rest/v1" || probe.Statuses["/axfood/rest"] != http.StatusNotFound {
+		t.Errorf("Unexpected probe: %+v", probe)
+	}
+
+	if _, err := client.GetCart(context.Background()); err != nil {
+		t.Fatalf("GetCart failed after the probe: %v", err)
+	}
+	if cartPath != "/axfood/rest/v1/cart" {
+		t.Errorf("Expected the cart to be fetched under the new prefix, got %q", cartPath)
+	}
+
+	probe, err = client.ProbeEndpoints(context.Background())
+	if err != nil || probe.Changed || len(probe.Statuses) != 1 {
+		t.Errorf("Expected a second probe to confirm the prefix, got %+v (%v)", probe, err)
+	}
+}
+
+func TestGroupHosts(t *testing.T) {
+	var webPaths, mobilePaths []string
+	web := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webPaths = append(webPaths, r.URL.Path)
+		if r.URL.Path == EndpointCart {
+			w.Write([]byte(`{"products": [], "totalPrice": 0}`))
+			return
+		}
+		w.Write([]byte(`"token"`))
+	}))
+	defer web.Close()
+	mobile := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mobilePaths = append(mobilePaths, r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer access" {
+			t.Errorf("Expected the access token on the mobile host, got %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`{"products": [], "totalPrice": 0}`))
+	}))
+	defer mobile.Close()
+
+	client, err := NewClient(web.URL, "", "", WithGroupHost(mobile.URL+"/", EndpointGroupCart, EndpointGroupSlots))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := client.GetCart(context.Background()); err != nil {
+		t.Fatalf("GetCart failed: %v", err)
+	}
+	if len(mobilePaths) != 0 || len(webPaths) != 1 {
+		t.Errorf("Expected the cart on the web host without an access token, got mobile %v, web %v", mobilePaths, webPaths)
+	}
+
+	webPaths = nil
+	client.session.update(func(st *sessionState) { st.accessToken = "access" })
+
+	if _, err := client.GetCart(context.Background()); err != nil {
+		t.Fatalf("GetCart failed: %v", err)
+	}
+	if len(mobilePaths) != 1 || mobilePaths[0] != EndpointCart {
+		t.Errorf("Expected the cart on the mobile host, got %v", mobilePaths)
+	}
+	if len(webPaths) != 0 {
+		t.Errorf("Expected nothing from the web host, got %v", webPaths)
+	}
+
+	auth, err := NewClient(web.URL, "", "", WithGroupHost(mobile.URL, EndpointGroupAuth))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if got := auth.url(EndpointOAuthToken); got != mobile.URL+EndpointOAuthToken {
+		t.Errorf("Expected the token exchange on the mobile host before there is a token, got %s", got)
+	}
+
+	if got := client.url(EndpointSlotInCart + "/slot-1?isTmsSlot=true"); got != mobile.URL+EndpointSlotInCart+"/slot-1?isTmsSlot=true" {
+		t.Errorf("Expected paths below a slot endpoint on the mobile host, got %s", got)
+	}
+	if got := client.GetCheckoutURL(); got != web.URL+EndpointCheckout {
+		t.Errorf("Expected checkout to stay on the web host, got %s", got)
+	}
+
+	if err := ValidateEndpointGroups([]string{"cart", "basket"}); err == nil {
+		t.Error("Expected an unknown group to be rejected")
+	}
+}
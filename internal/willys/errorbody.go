@@ -0,0 +1,92 @@
+package willys
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// errorBodyReadLimit caps how much of an error response is read.
+	errorBodyReadLimit = 64 << 10
+	// errorBodyLimit caps the raw body kept on an APIError.
+	errorBodyLimit = 512
+)
+
+// upstreamError covers the error shapes the Axfood endpoints use: the Hybris "errors"
+// list, OAuth's error/error_description, and flat code/message objects.
+type upstreamError struct {
+	Errors []struct {
+		Type    string `json:"type"`
+		Reason  string `json:"reason"`
+		Message string `json:"message"`
+	} `json:"errors"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Code             string `json:"code"`
+	ErrorCode        string `json:"errorCode"`
+	Message          string `json:"message"`
+	ErrorMessage     string `json:"errorMessage"`
+}
+
+// responseError builds the APIError for a non-2xx response, reading the body for the
+// reason Willys gave. The body is consumed.
+func responseError(ctx context.Context, resp *http.Response, endpoint, message string) *APIError {
+	err := newAPIError(ctx, resp.StatusCode, endpoint, message, nil)
+
+	defer classifyAPIError(err)
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyReadLimit))
+	body := strings.TrimSpace(string(data))
+	if body == "" {
+		return err
+	}
+
+	err.UpstreamCode, err.UpstreamMessage = parseUpstreamError(body)
+	if err.UpstreamCode == "" && err.UpstreamMessage == "" && !strings.HasPrefix(body, "<") {
+		// Skip HTML error pages; they are long and say nothing the status doesn't
+		err.Body = truncateRunes(redactSecrets(body), errorBodyLimit)
+	}
+	return err
+}
+
+func parseUpstreamError(body string) (code, message string) {
+	var e upstreamError
+	if json.Unmarshal([]byte(body), &e) != nil {
+		return "", ""
+	}
+
+	if len(e.Errors) > 0 {
+		first := e.Errors[0]
+		code = first.Reason
+		if code == "" {
+			code = first.Type
+		}
+		message = first.Message
+	}
+	for _, c := range []string{e.Error, e.Code, e.ErrorCode} {
+		if code == "" {
+			code = c
+		}
+	}
+	for _, m := range []string{e.ErrorDescription, e.Message, e.ErrorMessage} {
+		if message == "" {
+			message = m
+		}
+	}
+	return truncateRunes(redactSecrets(code), errorBodyLimit), truncateRunes(redactSecrets(message), errorBodyLimit)
+}
+
+func truncateRunes(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,77 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseErrorBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		wantCode    string
+		wantMessage string
+		wantBody    string
+	}{
+		{
+			name:        "hybris",
+			body:        `{"errors":[{"type":"CommerceCartModificationError","reason":"notSellableOnline","message":"Varan kan inte köpas online"}]}`,
+			wantCode:    "notSellableOnline",
+			wantMessage: "Varan kan inte köpas online",
+		},
+		{
+			name:        "oauth",
+			body:        `{"error":"invalid_grant","error_description":"Invalid refresh token"}`,
+			wantCode:    "invalid_grant",
+			wantMessage: "Invalid refresh token",
+		},
+		{name: "html", body: "<html><body>Bad Request</body></html>"},
+		{
+			name:     "text",
+			body:     `quantity out of range for {"email":"a@example.com"}`,
+			wantBody: `quantity out of range for {"email":"[redacted]"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == EndpointCSRFToken {
+					w.Write([]byte(`"token"`))
+					return
+				}
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL, "", "")
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			_, err = client.AddToCart(context.Background(), "101233933_ST", 1)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected APIError, got %v", err)
+			}
+			if apiErr.StatusCode != http.StatusBadRequest || apiErr.UpstreamCode != tt.wantCode ||
+				apiErr.UpstreamMessage != tt.wantMessage || apiErr.Body != tt.wantBody {
+				t.Errorf("Unexpected error fields: %+v", apiErr)
+			}
+			if tt.wantMessage != "" && !strings.Contains(err.Error(), tt.wantMessage) {
+				t.Errorf("Expected the upstream reason in %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("smörgås", 3); got != "sm..." {
+		t.Errorf("Expected the cut to back off to a rune boundary, got %q", got)
+	}
+}
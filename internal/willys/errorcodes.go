@@ -0,0 +1,95 @@
+package willys
+
+import (
+	"net/http"
+	"regexp"
+)
+
+// Normalized error codes set on APIError.Code. Willys reports the same condition with
+// different reasons and Swedish messages depending on the endpoint; these codes don't.
+const (
+	ErrorCodeSlotExpired        = "slot_expired"
+	ErrorCodeSlotFull           = "slot_full"
+	ErrorCodeNotSellableOnline  = "not_sellable_online"
+	ErrorCodeOutOfStock         = "out_of_stock"
+	ErrorCodeQuantityLimit      = "quantity_limit"
+	ErrorCodeUnknownProduct     = "unknown_product"
+	ErrorCodeCartNotFound       = "cart_not_found"
+	ErrorCodeInvalidPostalCode  = "invalid_postal_code"
+	ErrorCodeNotDeliverable     = "not_deliverable"
+	ErrorCodeBelowMinimum       = "below_minimum_order"
+	ErrorCodeInvalidCredentials = "invalid_credentials"
+	ErrorCodeSessionExpired     = "session_expired"
+	ErrorCodeRateLimited        = "rate_limited"
+	ErrorCodeUnavailable        = "upstream_unavailable"
+)
+
+type errorRule struct {
+	pattern *regexp.Regexp // matched against the upstream code and message
+	code    string
+	hint    string
+}
+
+// Ordered: the first matching rule classifies an error. Slot rules come before the
+// generic "no longer available" wording that products share.
+var errorRules = []errorRule{
+	{regexp.MustCompile(`(?i)slot ?expired|(time ?slot|reservation).*(expired|invalid|not ?found)|(tid|tidslucka|reservation)(en)? .*(har )?(gått|löpt) ut`), ErrorCodeSlotExpired,
+		"The reserved delivery slot has expired; call get_available_time_slots again and book a new slot with select_delivery_time"},
+	{regexp.MustCompile(`(?i)slot ?(is )?(full|unavailable)|fullbokad|tid(en|sluckan) är inte längre tillgänglig`), ErrorCodeSlotFull,
+		"That delivery slot is fully booked; pick another one from get_available_time_slots"},
+	{regexp.MustCompile(`(?i)not ?sellable ?online|kan inte köpas online|säljs (inte|ej) online`), ErrorCodeNotSellableOnline,
+		"This product can't be bought online; find an alternative with search_groceries or related_products"},
+	{regexp.MustCompile(`(?i)no ?stock|out ?of ?stock|slutsåld|slut i lager|finns (inte|ej) i lager`), ErrorCodeOutOfStock,
+		"The product is out of stock; find an alternative with related_products or search_groceries"},
+	{regexp.MustCompile(`(?i)max(imum)? ?(order)? ?quantity|quantity ?(limit|out of range)|max(imalt)? antal`), ErrorCodeQuantityLimit,
+		"Lower the quantity; Willys limits how many of this product one order can have"},
+	{regexp.MustCompile(`(?i)unknown ?identifier|product ?not ?found|produkten (finns inte|hittades inte)`), ErrorCodeUnknownProduct,
+		"The product code is unknown; look the product up again with search_groceries"},
+	{regexp.MustCompile(`(?i)cart ?not ?found|varukorgen (finns inte|hittades inte)`), ErrorCodeCartNotFound,
+		"The cart no longer exists; call view_cart to start a new one and add the items again"},
+	{regexp.MustCompile(`(?i)(invalid|unknown) ?postal ?code|ogiltigt postnummer`), ErrorCodeInvalidPostalCode,
+		"Check the postal code: five digits, e.g. 11151"},
+	{regexp.MustCompile(`(?i)not ?deliverable|levererar (inte|ej)|ingen hemleverans`), ErrorCodeNotDeliverable,
+		"Willys doesn't deliver to this postal code; confirm with check_deliverability or use another address"},
+	{regexp.MustCompile(`(?i)minimum ?order|minsta|minimibelopp|lägsta ordervärde`), ErrorCodeBelowMinimum,
+		"Add more items to reach the minimum order value"},
+	{regexp.MustCompile(`(?i)invalid_grant|bad ?credentials|felaktigt (lösenord|användarnamn)`), ErrorCodeInvalidCredentials,
+		"Willys rejected the credentials; update WILLYS_USERNAME and WILLYS_PASSWORD, then check admin_auth_status"},
+	{regexp.MustCompile(`(?i)invalid_token|token ?expired|logga in`), ErrorCodeSessionExpired,
+		"The Willys session expired; retry the call once and check admin_auth_status if it fails again"},
+}
+
+// statusRules classify errors whose body matched no rule by their HTTP status. Rate
+// limiting and 5xx statuses are checked first: their body is usually a proxy or
+// maintenance page whose wording ("Logga in", "minsta") says nothing about the error.
+var statusRules = map[int]errorRule{
+	http.StatusUnauthorized: {code: ErrorCodeSessionExpired,
+		hint: "The Willys session expired; retry the call once and check admin_auth_status if it fails again"},
+	http.StatusTooManyRequests: {code: ErrorCodeRateLimited,
+		hint: "Willys is rate limiting requests; wait a minute before retrying"},
+	http.StatusBadGateway: {code: ErrorCodeUnavailable,
+		hint: "Willys is having problems; retry in a few minutes and check api_health_report"},
+	http.StatusServiceUnavailable: {code: ErrorCodeUnavailable,
+		hint: "Willys is having problems; retry in a few minutes and check api_health_report"},
+	http.StatusGatewayTimeout: {code: ErrorCodeUnavailable,
+		hint: "Willys is having problems; retry in a few minutes and check api_health_report"},
+}
+
+// classifyAPIError sets e.Code and e.Hint from the reason Willys gave or, failing that,
+// the status. Unrecognized errors are left unclassified.
+func classifyAPIError(e *APIError) {
+	if rule, ok := statusRules[e.StatusCode]; ok && (e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError) {
+		e.Code, e.Hint = rule.code, rule.hint
+		return
+	}
+	text := e.UpstreamCode + " " + e.UpstreamMessage + " " + e.Body
+	for _, rule := range errorRules {
+		if rule.pattern.MatchString(text) {
+			e.Code, e.Hint = rule.code, rule.hint
+			return
+		}
+	}
+	if rule, ok := statusRules[e.StatusCode]; ok {
+		e.Code, e.Hint = rule.code, rule.hint
+	}
+}
@@ -0,0 +1,54 @@
+package willys
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestClassifyAPIError(t *testing.T) {
+	tests := []struct {
+		err      APIError
+		wantCode string
+	}{
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamCode: "notSellableOnline", UpstreamMessage: "Varan kan inte köpas online"}, ErrorCodeNotSellableOnline},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamMessage: "Tiden för din reservation har gått ut"}, ErrorCodeSlotExpired},
+		{APIError{StatusCode: http.StatusConflict, UpstreamCode: "TimeSlotExpired"}, ErrorCodeSlotExpired},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamMessage: "Leveranstiden är fullbokad"}, ErrorCodeSlotFull},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamCode: "noStock", UpstreamMessage: "Varan är slutsåld"}, ErrorCodeOutOfStock},
+		{APIError{StatusCode: http.StatusBadRequest, Body: "quantity out of range"}, ErrorCodeQuantityLimit},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamCode: "UnknownIdentifierError"}, ErrorCodeUnknownProduct},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamMessage: "Ogiltigt postnummer"}, ErrorCodeInvalidPostalCode},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamCode: "invalid_grant", UpstreamMessage: "Bad credentials"}, ErrorCodeInvalidCredentials},
+		{APIError{StatusCode: http.StatusUnauthorized}, ErrorCodeSessionExpired},
+		{APIError{StatusCode: http.StatusTooManyRequests}, ErrorCodeRateLimited},
+		{APIError{StatusCode: http.StatusServiceUnavailable}, ErrorCodeUnavailable},
+		{APIError{StatusCode: http.StatusServiceUnavailable, Body: "<html>Underhåll pågår. Logga in senare.</html>"}, ErrorCodeUnavailable},
+		{APIError{StatusCode: http.StatusBadGateway, Body: "<p>Det minsta vi kan göra är att be om ursäkt</p>"}, ErrorCodeUnavailable},
+		{APIError{StatusCode: http.StatusTooManyRequests, Body: "Logga in igen"}, ErrorCodeRateLimited},
+		{APIError{StatusCode: http.StatusBadRequest, UpstreamMessage: "Något gick fel"}, ""},
+	}
+
+	for _, tt := range tests {
+		err := tt.err
+		classifyAPIError(&err)
+		if err.Code != tt.wantCode {
+			t.Errorf("%q %q %q: expected %q, got %q", tt.err.UpstreamCode, tt.err.UpstreamMessage, tt.err.Body, tt.wantCode, err.Code)
+		}
+		if (err.Hint != "") != (tt.wantCode != "") {
+			t.Errorf("%q: hint %q doesn't match code %q", tt.err.UpstreamMessage, err.Hint, err.Code)
+		}
+	}
+}
+
+func TestAPIErrorHint(t *testing.T) {
+	err := &APIError{StatusCode: http.StatusBadRequest, UpstreamMessage: "Tiden för din reservation har gått ut", CorrelationID: "c1"}
+	classifyAPIError(err)
+	msg := err.Error()
+	if !strings.Contains(msg, "[error_code=slot_expired] The reserved delivery slot has expired; call get_available_time_slots") {
+		t.Errorf("Expected code and hint in %q", msg)
+	}
+	if !strings.HasSuffix(msg, "[correlation_id=c1]") {
+		t.Errorf("Expected correlation ID last in %q", msg)
+	}
+}
@@ -1,8 +1,11 @@
 package willys
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 type ValidationError struct {
@@ -21,16 +24,21 @@ func NewValidationError(field, message string) *ValidationError {
 	return &ValidationError{Field: field, Message: message}
 }
 
+// ErrCredentialsInvalid means Willys rejected the stored password or refresh token, as
+// opposed to an expired session. Retrying won't help and may lock the account.
+var ErrCredentialsInvalid = errors.New("credentials invalid")
+
 type AuthenticationError struct {
-	Message string
-	Cause   error
+	Message     string
+	Cause       error
+	Diagnostics []string // screenshot/HTML paths captured when a browser step failed
 }
 
 func (e *AuthenticationError) Error() string {
 	if e.Cause != nil {
-		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+		return fmt.Sprintf("%s: %v%s", e.Message, e.Cause, formatDiagnostics(e.Diagnostics))
 	}
-	return e.Message
+	return e.Message + formatDiagnostics(e.Diagnostics)
 }
 
 func (e *AuthenticationError) Unwrap() error {
@@ -41,11 +49,43 @@ func NewAuthenticationError(message string, cause error) *AuthenticationError {
 	return &AuthenticationError{Message: message, Cause: cause}
 }
 
+// LockoutError means Willys refuses logins for now, either because the account is locked
+// after failed attempts or because it wants a CAPTCHA solved. Logging in again before
+// RetryAfter has passed tends to extend the lockout.
+type LockoutError struct {
+	Reason     string // LockoutReasonLocked or LockoutReasonCaptcha
+	Message    string // text shown by Willys, if any
+	RetryAfter time.Duration
+	Until      time.Time
+}
+
+func (e *LockoutError) Error() string {
+	msg := "account temporarily locked by Willys"
+	if e.Reason == LockoutReasonCaptcha {
+		msg = "Willys asked for a CAPTCHA"
+	}
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s (%q)", msg, e.Message)
+	}
+	return fmt.Sprintf("%s; not logging in again until %s", msg, e.Until.Format("15:04"))
+}
+
 type APIError struct {
-	StatusCode int
-	Message    string
-	Endpoint   string
-	Cause      error
+	StatusCode    int
+	Message       string
+	Endpoint      string
+	CorrelationID string
+	// UpstreamCode and UpstreamMessage are the reason Willys gave in the error body,
+	// e.g. "notSellableOnline" and "Varan kan inte köpas online"
+	UpstreamCode    string
+	UpstreamMessage string
+	// Body is the start of the error body, sanitized, when it had no recognizable reason
+	Body string
+	// Code is the normalized error code (one of the ErrorCode constants) and Hint what an
+	// agent can do about it; both are empty when the error wasn't recognized
+	Code  string
+	Hint  string
+	Cause error
 }
 
 func (e *APIError) Error() string {
@@ -56,9 +96,25 @@ func (e *APIError) Error() string {
 	if e.Message != "" {
 		msg = fmt.Sprintf("%s: %s", msg, e.Message)
 	}
+	switch {
+	case e.UpstreamMessage != "" && e.UpstreamCode != "":
+		msg = fmt.Sprintf("%s: %s (%s)", msg, e.UpstreamMessage, e.UpstreamCode)
+	case e.UpstreamMessage != "":
+		msg = fmt.Sprintf("%s: %s", msg, e.UpstreamMessage)
+	case e.UpstreamCode != "":
+		msg = fmt.Sprintf("%s: %s", msg, e.UpstreamCode)
+	case e.Body != "":
+		msg = fmt.Sprintf("%s: %s", msg, e.Body)
+	}
 	if e.Cause != nil {
 		msg = fmt.Sprintf("%s: %v", msg, e.Cause)
 	}
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s [error_code=%s] %s", msg, e.Code, e.Hint)
+	}
+	if e.CorrelationID != "" {
+		msg = fmt.Sprintf("%s [correlation_id=%s]", msg, e.CorrelationID)
+	}
 	return msg
 }
 
@@ -75,6 +131,12 @@ func NewAPIError(statusCode int, endpoint, message string, cause error) *APIErro
 	}
 }
 
+func newAPIError(ctx context.Context, statusCode int, endpoint, message string, cause error) *APIError {
+	err := NewAPIError(statusCode, endpoint, message, cause)
+	err.CorrelationID = CorrelationIDFromContext(ctx)
+	return err
+}
+
 type NotFoundError struct {
 	Resource string
 	ID       string
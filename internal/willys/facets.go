@@ -0,0 +1,107 @@
+package willys
+
+import "strings"
+
+// Well-known facet codes in Willys search responses.
+const (
+	FacetBrand    = "brand"
+	FacetCategory = "category"
+	FacetLabels   = "labels"
+)
+
+type (
+	// SearchResult is a page of search results together with the refinements Willys
+	// offers for the query.
+	SearchResult struct {
+		Products []Product `json:"products"`
+		Facets   Facets    `json:"facets,omitempty"`
+	}
+
+	// Facets are the refinement sections of a search, such as brands or categories.
+	Facets []Facet
+
+	Facet struct {
+		Code        string       `json:"code"`
+		Name        string       `json:"name"`
+		MultiSelect bool         `json:"multiSelect,omitempty"`
+		Values      []FacetValue `json:"values"`
+	}
+
+	FacetValue struct {
+		Code     string `json:"code"`
+		Name     string `json:"name"`
+		Count    int    `json:"count"`
+		Selected bool   `json:"selected,omitempty"`
+		// Query is the search query that applies this refinement, e.g.
+		// "mjölk:relevance:brand:Garant"
+		Query string `json:"query,omitempty"`
+	}
+
+	searchFacetJSON struct {
+		Code        string `json:"code"`
+		Name        string `json:"name"`
+		MultiSelect bool   `json:"multiSelect"`
+		Visible     *bool  `json:"visible"`
+		Values      []struct {
+			Code     string `json:"code"`
+			Name     string `json:"name"`
+			Count    int    `json:"count"`
+			Selected bool   `json:"selected"`
+			Query    struct {
+				Query struct {
+					Value string `json:"value"`
+				} `json:"query"`
+			} `json:"query"`
+		} `json:"values"`
+	}
+)
+
+// parseFacets converts the facets of a search response, skipping hidden and empty ones.
+func parseFacets(raw []searchFacetJSON) Facets {
+	facets := make(Facets, 0, len(raw))
+	for _, f := range raw {
+		if (f.Visible != nil && !*f.Visible) || len(f.Values) == 0 {
+			continue
+		}
+		facet := Facet{
+			Code:        f.Code,
+			Name:        f.Name,
+			MultiSelect: f.MultiSelect,
+			Values:      make([]FacetValue, 0, len(f.Values)),
+		}
+		for _, v := range f.Values {
+			name := v.Name
+			if name == "" {
+				name = v.Code
+			}
+			facet.Values = append(facet.Values, FacetValue{
+				Code:     v.Code,
+				Name:     name,
+				Count:    v.Count,
+				Selected: v.Selected,
+				Query:    v.Query.Query.Value,
+			})
+		}
+		facets = append(facets, facet)
+	}
+	return facets
+}
+
+// Find returns the facet with the given code, matched case-insensitively, or nil.
+func (f Facets) Find(code string) *Facet {
+	for i := range f {
+		if strings.EqualFold(f[i].Code, code) {
+			return &f[i]
+		}
+	}
+	return nil
+}
+
+// Brands returns the brand values offered for the search, most common first as Willys
+// orders them.
+func (f Facets) Brands() []FacetValue {
+	if facet := f.Find(FacetBrand); facet != nil {
+		return facet.Values
+	}
+	return nil
+}
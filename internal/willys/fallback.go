@@ -0,0 +1,223 @@
+package willys
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// fetchInPageJS replays a request from inside the Willys page, so it carries the browser's
+// TLS fingerprint, cookies, and anti-bot tokens.
+const fetchInPageJS = `async (method, url, body, headers) => {
+	const resp = await fetch(url, {
+		method: method,
+		headers: headers,
+		body: body === "" ? undefined : body,
+		credentials: "include",
+	});
+	return {
+		status: resp.status,
+		contentType: resp.headers.get("content-type") || "",
+		body: await resp.text(),
+	};
+}`
+
+type (
+	// browserFallback keeps one headless browser open for requests that the REST API
+	// refuses because of bot protection. It is started on first use.
+	browserFallback struct {
+		mu        sync.Mutex
+		endpoints map[string]bool
+		browser   *rod.Browser
+		page      *rod.Page
+	}
+
+	fetchResult struct {
+		Status      int    `json:"status"`
+		ContentType string `json:"contentType"`
+		Body        string `json:"body"`
+	}
+)
+
+// WithBrowserFallback lets requests to the given endpoints (e.g. "/search") be retried
+// through the headless browser when the direct call is blocked by bot protection.
+func WithBrowserFallback(endpoints ...string) ClientOption {
+	return func(c *Client) {
+		if len(endpoints) == 0 {
+			return
+		}
+		set := make(map[string]bool, len(endpoints))
+		for _, e := range endpoints {
+			set[e] = true
+		}
+		c.fallback = &browserFallback{endpoints: set}
+	}
+}
+
+// isBotBlocked recognizes the challenge pages bot protection serves instead of JSON.
+func isBotBlocked(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if resp.Header.Get("cf-mitigated") != "" || strings.Contains(resp.Header.Get("Server"), "AkamaiGHost") {
+		return true
+	}
+	return strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html")
+}
+
+func (c *Client) shouldFallback(endpoint string, resp *http.Response) bool {
+	return c.fallback != nil && c.fallback.endpoints[endpoint] && isBotBlocked(resp)
+}
+
+// fetchViaBrowser performs the request with fetch() inside a Willys page and converts the
+// result back into an *http.Response for the normal decoding path.
+func (c *Client) fetchViaBrowser(ctx context.Context, method, path string, body []byte, needsCSRF bool) (*http.Response, error) {
+	page, err := c.fallbackPage()
+	if err != nil {
+		return nil, fmt.Errorf("browser fallback unavailable: %w", err)
+	}
+
+	if err := c.syncCookiesToBrowser(page); err != nil {
+		return nil, fmt.Errorf("failed to copy session cookies to browser: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json, text/plain, */*",
+	}
+	if needsCSRF {
+		token, err := c.GetCSRFToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get CSRF token: %w", err)
+		}
+		headers["X-CSRF-TOKEN"] = token
+	}
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		headers[CorrelationIDHeader] = id
+	}
+
+	res, err := page.Context(ctx).Timeout(DefaultTimeout).Eval(fetchInPageJS, method, c.endpoints.resolve(path), string(body), headers)
+	if err != nil {
+		return nil, fmt.Errorf("in-page fetch failed: %w", err)
+	}
+
+	var result fetchResult
+	if err := res.Value.Unmarshal(&result); err != nil {
+		return nil, fmt.Errorf("failed to read in-page fetch result: %w", err)
+	}
+
+	log.Printf("Browser fallback %s %s -> %d", method, path, result.Status)
+
+	header := make(http.Header)
+	header.Set("Content-Type", result.ContentType)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", result.Status, http.StatusText(result.Status)),
+		StatusCode:    result.Status,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(result.Body))),
+		ContentLength: int64(len(result.Body)),
+	}, nil
+}
+
+func (c *Client) fallbackPage() (*rod.Page, error) {
+	f := c.fallback
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.page != nil {
+		return f.page, nil
+	}
+
+	browser, err := c.launchBrowser(false)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := browser.Timeout(30 * time.Second).Page(proto.TargetCreateTarget{URL: c.baseURL})
+	if err != nil {
+		browser.Close()
+		return nil, err
+	}
+	if err := page.WaitLoad(); err != nil {
+		browser.Close()
+		return nil, err
+	}
+
+	f.browser = browser
+	f.page = page
+	return page, nil
+}
+
+func (c *Client) syncCookiesToBrowser(page *rod.Page) error {
+	cookies := c.GetCookies()
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, cookie := range cookies {
+		params = append(params, &proto.NetworkCookieParam{
+			Name:  cookie.Name,
+			Value: cookie.Value,
+			URL:   c.baseURL,
+		})
+	}
+	return page.SetCookies(params)
+}
+
+// launchBrowser starts a headless Chromium. Only login uses the persistent profile, if one
+// is configured: Chromium locks a profile to one process, and the long-lived fallback
+// browser would otherwise keep login from starting. Browsers without it get a throwaway
+// profile and the session's cookies from syncCookiesToBrowser.
+func (c *Client) launchBrowser(persistentProfile bool) (*rod.Browser, error) {
+	path, exists := launcher.LookPath()
+	if !exists {
+		path = launcher.NewBrowser().MustGet()
+	}
+
+	l := launcher.New().
+		Bin(path).
+		Headless(true).
+		Devtools(false)
+	if persistentProfile && c.browserProfileDir != "" {
+		if err := os.MkdirAll(c.browserProfileDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create browser profile directory: %w", err)
+		}
+		l = l.UserDataDir(c.browserProfileDir)
+	}
+
+	u, err := l.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch browser: %w", err)
+	}
+
+	browser := rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		return nil, fmt.Errorf("failed to connect to browser: %w", err)
+	}
+	return browser, nil
+}
+
+// Close releases the fallback browser, if one was started.
+func (c *Client) Close() error {
+	if c.fallback == nil {
+		return nil
+	}
+	c.fallback.mu.Lock()
+	defer c.fallback.mu.Unlock()
+
+	if c.fallback.browser == nil {
+		return nil
+	}
+	err := c.fallback.browser.Close()
+	c.fallback.browser = nil
+	c.fallback.page = nil
+	return err
+}
@@ -0,0 +1,65 @@
+package willys
+
+import "time"
+
+// DayFees summarizes one day's delivery slots. MinFee and MaxFee are only set when the
+// day has an available slot.
+type DayFees struct {
+	Date      string   `json:"date"` // YYYY-MM-DD
+	Weekday   string   `json:"weekday"`
+	Available int      `json:"available"`
+	Total     int      `json:"total"`
+	MinFee    *Money   `json:"minFee,omitempty"`
+	MaxFee    *Money   `json:"maxFee,omitempty"`
+	Holiday   *Holiday `json:"holiday,omitempty"`
+}
+
+// FeeCalendar summarizes slots per day for days days starting at from, including days
+// without any slot so gaps in the calendar stay visible.
+func FeeCalendar(slots []TimeSlot, from time.Time, days int) []DayFees {
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	calendar := make([]DayFees, days)
+	index := make(map[string]int, days)
+	for i := range calendar {
+		date := start.AddDate(0, 0, i)
+		calendar[i] = DayFees{Date: date.Format("2006-01-02"), Weekday: date.Weekday().String()}
+		if holiday, ok := HolidayOn(date); ok {
+			calendar[i].Holiday = &holiday
+		}
+		index[calendar[i].Date] = i
+	}
+
+	for _, slot := range slots {
+		i, ok := index[slot.Date]
+		if !ok {
+			continue
+		}
+		day := &calendar[i]
+		day.Total++
+		if !slot.Available {
+			continue
+		}
+		day.Available++
+		fee := slot.Fee
+		if day.MinFee == nil || fee.Ore < day.MinFee.Ore {
+			day.MinFee = &fee
+		}
+		if day.MaxFee == nil || fee.Ore > day.MaxFee.Ore {
+			day.MaxFee = &fee
+		}
+	}
+	return calendar
+}
+
+// CheapestDay returns the day with the lowest minimum fee, preferring the earlier day on
+// a tie, or nil if no day has an available slot.
+func CheapestDay(calendar []DayFees) *DayFees {
+	var cheapest *DayFees
+	for i := range calendar {
+		day := &calendar[i]
+		if day.MinFee != nil && (cheapest == nil || day.MinFee.Ore < cheapest.MinFee.Ore) {
+			cheapest = day
+		}
+	}
+	return cheapest
+}
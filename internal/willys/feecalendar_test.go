@@ -0,0 +1,43 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFeeCalendar(t *testing.T) {
+	// Thursday 2026-10-29; Saturday is Alla helgons dag
+	now := time.Date(2026, 10, 29, 9, 0, 0, 0, time.UTC)
+	slots := []TimeSlot{
+		{Date: "2026-10-29", StartTime: "18:00", Fee: SEK(7900), Available: true},
+		{Date: "2026-10-29", StartTime: "20:00", Fee: SEK(4900), Available: true},
+		{Date: "2026-10-29", StartTime: "08:00", Fee: SEK(0), Available: false},
+		{Date: "2026-10-30", StartTime: "10:00", Fee: SEK(2900), Available: false},
+		{Date: "2026-10-31", StartTime: "10:00", Fee: SEK(1000), Available: true},
+		{Date: "2026-11-01", StartTime: "10:00", Fee: SEK(1000), Available: true},
+		{Date: "2026-11-20", StartTime: "10:00", Fee: SEK(0), Available: true}, // outside the range
+	}
+
+	calendar := FeeCalendar(slots, now, 4)
+	if len(calendar) != 4 {
+		t.Fatalf("Expected 4 days, got %d", len(calendar))
+	}
+
+	thu := calendar[0]
+	if thu.Weekday != "Thursday" || thu.Available != 2 || thu.Total != 3 || thu.MinFee.Ore != 4900 || thu.MaxFee.Ore != 7900 {
+		t.Errorf("Unexpected Thursday: %+v", thu)
+	}
+	if fri := calendar[1]; fri.Available != 0 || fri.Total != 1 || fri.MinFee != nil {
+		t.Errorf("Expected a fully booked Friday without fees, got %+v", fri)
+	}
+	if sat := calendar[2]; sat.Holiday == nil || sat.Holiday.Name != "Alla helgons dag" {
+		t.Errorf("Expected Saturday to be marked as a holiday, got %+v", sat)
+	}
+
+	if cheapest := CheapestDay(calendar); cheapest == nil || cheapest.Date != "2026-10-31" {
+		t.Errorf("Expected the earlier of the equally cheap days, got %+v", cheapest)
+	}
+	if CheapestDay(FeeCalendar(nil, now, 3)) != nil {
+		t.Error("Expected no cheapest day without slots")
+	}
+}
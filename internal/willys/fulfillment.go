@@ -0,0 +1,131 @@
+package willys
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FulfillmentHomeDelivery = "home_delivery"
+	FulfillmentPickup       = "pickup"
+)
+
+type (
+	// FulfillmentOption is one way to get the order this week: home delivery, or pickup
+	// at one store.
+	FulfillmentOption struct {
+		Mode         string       `json:"mode"`
+		Store        *PickupStore `json:"store,omitempty"`
+		Available    bool         `json:"available"`
+		EarliestSlot *TimeSlot    `json:"earliestSlot,omitempty"`
+		CheapestSlot *TimeSlot    `json:"cheapestSlot,omitempty"`
+		PickingFee   Money        `json:"pickingFee"`
+		// MinFee is the picking fee plus the cheapest slot's fee, and OrderTotal the cart
+		// with MinFee added
+		MinFee     *Money `json:"minFee,omitempty"`
+		OrderTotal *Money `json:"orderTotal,omitempty"`
+		Error      string `json:"error,omitempty"` // why the option couldn't be checked
+	}
+
+	FulfillmentPlan struct {
+		CartTotal      Money               `json:"cartTotal"`
+		Until          string              `json:"until"` // last date considered
+		Options        []FulfillmentOption `json:"options"`
+		Cheapest       *FulfillmentOption  `json:"cheapest,omitempty"`
+		Fastest        *FulfillmentOption  `json:"fastest,omitempty"`
+		Recommendation string              `json:"recommendation"`
+	}
+)
+
+// NewFulfillmentOption summarizes the open slots of one mode up to and including the
+// date until (YYYY-MM-DD). Willys charges the same picking fee whichever way the order
+// leaves the store.
+func NewFulfillmentOption(mode string, store *PickupStore, slots []TimeSlot, until string, cartTotal Money) FulfillmentOption {
+	option := FulfillmentOption{Mode: mode, Store: store, PickingFee: MoneyFromFloat(DefaultPickingFee)}
+	for i := range slots {
+		slot := slots[i]
+		if !slot.Available || slot.Date > until {
+			continue
+		}
+		if option.EarliestSlot == nil || slotStartsBefore(slot, *option.EarliestSlot) {
+			option.EarliestSlot = &slot
+		}
+		if option.CheapestSlot == nil || slot.Fee.Ore < option.CheapestSlot.Fee.Ore ||
+			(slot.Fee.Ore == option.CheapestSlot.Fee.Ore && slotStartsBefore(slot, *option.CheapestSlot)) {
+			option.CheapestSlot = &slot
+		}
+	}
+	if option.CheapestSlot != nil {
+		option.Available = true
+		fee := option.PickingFee.Add(option.CheapestSlot.Fee)
+		total := cartTotal.Add(fee)
+		option.MinFee, option.OrderTotal = &fee, &total
+	}
+	return option
+}
+
+func slotStartsBefore(a, b TimeSlot) bool {
+	return a.Date+" "+a.StartTime < b.Date+" "+b.StartTime
+}
+
+// PlanFulfillment compares the options and recommends one: the cheapest, unless another
+// is sooner by at least a day, in which case both are named.
+func PlanFulfillment(cartTotal Money, until string, options []FulfillmentOption) FulfillmentPlan {
+	plan := FulfillmentPlan{CartTotal: cartTotal, Until: until, Options: options}
+	for i := range options {
+		option := &options[i]
+		if !option.Available {
+			continue
+		}
+		if plan.Cheapest == nil || option.MinFee.Ore < plan.Cheapest.MinFee.Ore {
+			plan.Cheapest = option
+		}
+		if plan.Fastest == nil || slotStartsBefore(*option.EarliestSlot, *plan.Fastest.EarliestSlot) {
+			plan.Fastest = option
+		}
+	}
+
+	switch {
+	case plan.Cheapest == nil:
+		plan.Recommendation = fmt.Sprintf("Neither home delivery nor pickup has an open slot until %s", until)
+	case plan.Cheapest == plan.Fastest:
+		plan.Recommendation = fmt.Sprintf("%s is both the cheapest (%s in fees) and the fastest (%s)",
+			plan.Cheapest.label(), plan.Cheapest.MinFee, plan.Cheapest.EarliestSlot.describe())
+	case daysBetween(plan.Fastest.EarliestSlot.Date, plan.Cheapest.EarliestSlot.Date) >= 1:
+		plan.Recommendation = fmt.Sprintf("%s is cheapest (%s in fees, first slot %s); %s is sooner (%s) for %s more",
+			plan.Cheapest.label(), plan.Cheapest.MinFee, plan.Cheapest.EarliestSlot.describe(),
+			lowerFirst(plan.Fastest.label()), plan.Fastest.EarliestSlot.describe(), plan.Fastest.MinFee.Sub(*plan.Cheapest.MinFee))
+	default:
+		plan.Recommendation = fmt.Sprintf("%s is cheapest (%s in fees) and just as quick (%s)",
+			plan.Cheapest.label(), plan.Cheapest.MinFee, plan.Cheapest.EarliestSlot.describe())
+	}
+	return plan
+}
+
+func (o *FulfillmentOption) label() string {
+	if o.Mode == FulfillmentPickup && o.Store != nil {
+		return "Pickup at " + o.Store.Name
+	}
+	return "Home delivery"
+}
+
+func (s *TimeSlot) describe() string {
+	return fmt.Sprintf("%s %s-%s", s.Date, s.StartTime, s.EndTime)
+}
+
+func daysBetween(from, to string) int {
+	a, errA := time.Parse("2006-01-02", from)
+	b, errB := time.Parse("2006-01-02", to)
+	if errA != nil || errB != nil {
+		return 0
+	}
+	return int(b.Sub(a).Hours() / 24)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,87 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFindPickupStores(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointStoreSearch:
+			w.Write([]byte(`{"results": [
+				{"storeId": "2110", "name": "Willys Hornsberg", "address": {"line1": "Lindhagensgatan 120", "postalCode": "112 51", "town": "Stockholm"}, "clickAndCollect": true, "distance": 1.2},
+				{"storeId": "2120", "name": "Willys Express", "clickAndCollect": false, "distance": 0.4}
+			]}`))
+		case EndpointSlotPickup:
+			if r.URL.Query().Get("storeId") != "2110" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"isocode": "SE", "slots": [{"code": "p1", "formattedTime": "16:00-18:00", "startTime": 1792245600000, "endTime": 1792252800000, "available": true}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	stores, err := client.FindPickupStores(ctx, "112 51")
+	if err != nil || len(stores) != 1 || stores[0].ID != "2110" || stores[0].PostalCode != "11251" {
+		t.Fatalf("Expected only the click-and-collect store, got %+v (%v)", stores, err)
+	}
+	if _, err := client.FindPickupStores(ctx, "abc"); !IsValidationError(err) {
+		t.Errorf("Expected a validation error for a bad postal code, got %v", err)
+	}
+
+	slots, err := client.GetPickupTimeSlots(ctx, "2110")
+	if err != nil || len(slots) != 1 || !slots[0].Available {
+		t.Errorf("Expected one pickup slot, got %+v (%v)", slots, err)
+	}
+}
+
+func TestPlanFulfillment(t *testing.T) {
+	cart := SEK(50000)
+	home := NewFulfillmentOption(FulfillmentHomeDelivery, nil, []TimeSlot{
+		{Date: "2026-10-17", StartTime: "17:00", EndTime: "19:00", Fee: SEK(4900), Available: true},
+		{Date: "2026-10-19", StartTime: "08:00", EndTime: "10:00", Fee: SEK(0), Available: false},
+		{Date: "2026-10-20", StartTime: "08:00", EndTime: "10:00", Fee: SEK(1900), Available: true},
+		{Date: "2026-10-30", StartTime: "08:00", EndTime: "10:00", Fee: SEK(0), Available: true},
+	}, "2026-10-22", cart)
+	if home.EarliestSlot.Date != "2026-10-17" || home.CheapestSlot.Date != "2026-10-20" {
+		t.Errorf("Expected unavailable and out-of-range slots skipped, got %+v", home)
+	}
+	if home.MinFee.Ore != 7800 || home.OrderTotal.Ore != 57800 {
+		t.Errorf("Expected picking fee plus cheapest slot, got %v and %v", home.MinFee, home.OrderTotal)
+	}
+
+	store := &PickupStore{ID: "2110", Name: "Willys Hornsberg"}
+	pickup := NewFulfillmentOption(FulfillmentPickup, store, []TimeSlot{
+		{Date: "2026-10-18", StartTime: "16:00", EndTime: "18:00", Available: true},
+	}, "2026-10-22", cart)
+	closed := NewFulfillmentOption(FulfillmentPickup, &PickupStore{ID: "2130"}, nil, "2026-10-22", cart)
+	if closed.Available || closed.MinFee != nil {
+		t.Errorf("Expected a store without slots to be unavailable, got %+v", closed)
+	}
+
+	plan := PlanFulfillment(cart, "2026-10-22", []FulfillmentOption{home, pickup, closed})
+	if plan.Cheapest.Mode != FulfillmentPickup || plan.Fastest.Mode != FulfillmentHomeDelivery {
+		t.Errorf("Expected pickup cheapest and delivery fastest, got %+v and %+v", plan.Cheapest, plan.Fastest)
+	}
+	if !strings.HasPrefix(plan.Recommendation, "Pickup at Willys Hornsberg is cheapest") || !strings.Contains(plan.Recommendation, "home delivery is sooner") {
+		t.Errorf("Unexpected recommendation: %s", plan.Recommendation)
+	}
+
+	plan = PlanFulfillment(cart, "2026-10-22", []FulfillmentOption{closed})
+	if plan.Cheapest != nil || !strings.Contains(plan.Recommendation, "Neither") {
+		t.Errorf("Expected no recommendation without slots, got %+v", plan)
+	}
+}
@@ -0,0 +1,113 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// AddressSuggestion is one match from Willys' address lookup, the service behind the
+// address field on willys.se.
+type AddressSuggestion struct {
+	Street     string  `json:"street"`
+	PostalCode string  `json:"postalCode"`
+	City       string  `json:"city"`
+	Latitude   float64 `json:"latitude,omitempty"`
+	Longitude  float64 `json:"longitude,omitempty"`
+}
+
+type addressSuggestionsResponse struct {
+	Suggestions []struct {
+		StreetName   string  `json:"streetName"`
+		StreetNumber string  `json:"streetNumber"`
+		PostalCode   string  `json:"postalCode"`
+		Town         string  `json:"town"`
+		Latitude     float64 `json:"latitude"`
+		Longitude    float64 `json:"longitude"`
+	} `json:"suggestions"`
+}
+
+// SuggestAddresses looks up addresses matching query, e.g. "Drottninggatan 1, 11151".
+func (c *Client) SuggestAddresses(ctx context.Context, query string) ([]AddressSuggestion, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	path := fmt.Sprintf("%s?%s", EndpointAddressSuggestions, params.Encode())
+
+	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, path, "address lookup request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, path, "address lookup failed")
+	}
+
+	var result addressSuggestionsResponse
+	if err := c.readJSON(resp, EndpointAddressSuggestions, &result, "suggestions"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse address suggestions", err)
+	}
+
+	suggestions := make([]AddressSuggestion, 0, len(result.Suggestions))
+	for _, s := range result.Suggestions {
+		suggestions = append(suggestions, AddressSuggestion{
+			Street:     strings.TrimSpace(s.StreetName + " " + s.StreetNumber),
+			PostalCode: compactPostalCode(s.PostalCode),
+			City:       s.Town,
+			Latitude:   s.Latitude,
+			Longitude:  s.Longitude,
+		})
+	}
+	return suggestions, nil
+}
+
+// VerifyAddress checks the street against the postal code with Willys' address lookup
+// and fills in the coordinates. A street Willys places under another postal code, or
+// doesn't know while suggesting others, is a validation error naming the alternatives.
+// If the lookup itself fails or finds nothing, the address is returned unverified: the
+// lookup is a safeguard against typos, not a reason to block delivery.
+func (c *Client) VerifyAddress(ctx context.Context, address DeliveryAddress) (DeliveryAddress, error) {
+	street, _, _ := strings.Cut(address.Address, ",") // drop "lgh 1102" and similar
+	postalCode := compactPostalCode(address.PostalCode)
+
+	suggestions, err := c.SuggestAddresses(ctx, street+", "+postalCode)
+	if err != nil {
+		log.Printf("Address lookup failed, using the address unverified: %v", err)
+		return address, nil
+	}
+	if len(suggestions) == 0 {
+		return address, nil
+	}
+
+	var sameStreet []AddressSuggestion
+	for _, s := range suggestions {
+		if !sameStreetName(s.Street, street) {
+			continue
+		}
+		if s.PostalCode == postalCode {
+			address.Latitude, address.Longitude = s.Latitude, s.Longitude
+			return address, nil
+		}
+		sameStreet = append(sameStreet, s)
+	}
+
+	if len(sameStreet) > 0 {
+		s := sameStreet[0]
+		return address, NewValidationError("postal_code", fmt.Sprintf("%s is in %s %s, not %s; check the postal code", s.Street, s.PostalCode, s.City, address.PostalCode))
+	}
+	alternatives := make([]string, 0, 3)
+	for _, s := range suggestions[:min(3, len(suggestions))] {
+		alternatives = append(alternatives, fmt.Sprintf("%s, %s %s", s.Street, s.PostalCode, s.City))
+	}
+	return address, NewValidationError("address", fmt.Sprintf("Willys doesn't recognize %q in %s; did you mean %s?", street, address.PostalCode, strings.Join(alternatives, " or ")))
+}
+
+func sameStreetName(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	return normalize(a) == normalize(b)
+}
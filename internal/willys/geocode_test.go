@@ -0,0 +1,52 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerifyAddress(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != EndpointAddressSuggestions {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"suggestions": [
+			{"streetName": "Drottninggatan", "streetNumber": "1", "postalCode": "111 51", "town": "Stockholm", "latitude": 59.3306, "longitude": 18.0586},
+			{"streetName": "Drottninggatan", "streetNumber": "10", "postalCode": "111 51", "town": "Stockholm"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	address := DeliveryAddress{FirstName: "Test", LastName: "User", Address: "drottninggatan  1, lgh 1102", PostalCode: "111 51", City: "Stockholm"}
+
+	verified, err := client.VerifyAddress(ctx, address)
+	if err != nil || verified.Latitude != 59.3306 || verified.Longitude != 18.0586 {
+		t.Errorf("Expected coordinates, got %+v (%v)", verified, err)
+	}
+
+	address.PostalCode = "11152"
+	if _, err := client.VerifyAddress(ctx, address); !IsValidationError(err) || !strings.Contains(err.Error(), "11151 Stockholm") {
+		t.Errorf("Expected postal code mismatch, got %v", err)
+	}
+
+	address.PostalCode, address.Address = "11151", "Drottningatan 1"
+	if _, err := client.VerifyAddress(ctx, address); !IsValidationError(err) || !strings.Contains(err.Error(), "did you mean Drottninggatan 1") {
+		t.Errorf("Expected suggestions for a typo, got %v", err)
+	}
+
+	status = http.StatusInternalServerError
+	if verified, err := client.VerifyAddress(ctx, address); err != nil || verified != address {
+		t.Errorf("Expected the address unverified when the lookup fails, got %+v (%v)", verified, err)
+	}
+}
@@ -0,0 +1,169 @@
+package willys
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RankHistoryWeighted = "history_weighted"
+
+	// purchaseHistoryTTL is how long the order history behind history_weighted is reused
+	// before it is fetched again; orders are placed days apart.
+	purchaseHistoryTTL = 6 * time.Hour
+
+	// A product bought before outranks any number of purchases of its brand.
+	productPurchaseWeight = 1000
+
+	// DefaultPriceAlertPercent is how much more than last time a product has to cost
+	// before its price rise is flagged.
+	DefaultPriceAlertPercent = 5.0
+)
+
+type (
+	// PurchaseHistory counts how many units of each product and brand the customer has
+	// ordered, and the unit price each product was last bought for, and when. Brand keys
+	// are lower-cased manufacturers.
+	PurchaseHistory struct {
+		Products   map[string]int       `json:"products"`
+		Brands     map[string]int       `json:"brands"`
+		LastPrices map[string]Money     `json:"lastPrices"`
+		LastBought map[string]time.Time `json:"lastBought"`
+	}
+
+	// PurchasePriceChange is how a cart item's price moved since it was last bought.
+	// Alert is set when it rose by more than the alert threshold.
+	PurchasePriceChange struct {
+		PriceChange
+		LastBought time.Time `json:"lastBought"`
+		Alert      bool      `json:"alert,omitempty"`
+	}
+
+	// Preparer is implemented by rankers that need data before they can compare, such as
+	// the order history. sortProducts calls Prepare once per search.
+	Preparer interface {
+		Prepare(ctx context.Context) error
+	}
+
+	purchaseHistoryCache struct {
+		mu       sync.Mutex
+		load     func(ctx context.Context) ([]Order, error)
+		history  *PurchaseHistory
+		loadedAt time.Time
+	}
+
+	historyRanker struct {
+		cache *purchaseHistoryCache
+	}
+)
+
+func BuildPurchaseHistory(orders []Order) *PurchaseHistory {
+	history := &PurchaseHistory{
+		Products:   make(map[string]int),
+		Brands:     make(map[string]int),
+		LastPrices: make(map[string]Money),
+		LastBought: make(map[string]time.Time),
+	}
+	pricedAt := make(map[string]time.Time)
+	for _, order := range orders {
+		for _, entry := range order.Entries {
+			history.Products[entry.Code] += entry.Quantity
+			if order.PlacedAt.After(history.LastBought[entry.Code]) {
+				history.LastBought[entry.Code] = order.PlacedAt
+			}
+			if !entry.Price.IsZero() && (pricedAt[entry.Code].IsZero() || order.PlacedAt.After(pricedAt[entry.Code])) {
+				history.LastPrices[entry.Code] = entry.Price
+				pricedAt[entry.Code] = order.PlacedAt
+			}
+			if brand := strings.ToLower(strings.TrimSpace(entry.Manufacturer)); brand != "" {
+				history.Brands[brand] += entry.Quantity
+			}
+		}
+	}
+	return history
+}
+
+// Score is higher for products the customer buys more often, first by the product
+// itself and then by its brand.
+func (h *PurchaseHistory) Score(p Product) int {
+	if h == nil {
+		return 0
+	}
+	return h.Products[p.Code]*productPurchaseWeight + h.Brands[strings.ToLower(strings.TrimSpace(p.Manufacturer))]
+}
+
+// PriceChangesSinceLastPurchase compares the cart items with the price each was last
+// bought for, the biggest rise first. Items never bought at a known price, or whose price
+// is unchanged, are left out.
+func (h *PurchaseHistory) PriceChangesSinceLastPurchase(items []CartItem, alertPercent float64) []PurchasePriceChange {
+	changes := make([]PurchasePriceChange, 0)
+	if h == nil {
+		return changes
+	}
+	for _, item := range items {
+		last, ok := h.LastPrices[item.ProductCode]
+		if !ok || item.Price.IsZero() || item.Price.Ore == last.Ore {
+			continue
+		}
+		change := PurchasePriceChange{
+			PriceChange: newPriceChange(item.ProductCode, item.Name, last, item.Price),
+			LastBought:  h.LastBought[item.ProductCode],
+		}
+		change.Alert = change.ChangePercent > alertPercent
+		changes = append(changes, change)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].ChangePercent > changes[j].ChangePercent })
+	return changes
+}
+
+func (c *purchaseHistoryCache) get(ctx context.Context) (*PurchaseHistory, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.history != nil && time.Since(c.loadedAt) < purchaseHistoryTTL {
+		return c.history, nil
+	}
+	orders, err := c.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.history = BuildPurchaseHistory(orders)
+	c.loadedAt = time.Now()
+	return c.history, nil
+}
+
+func (c *purchaseHistoryCache) current() *PurchaseHistory {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.history
+}
+
+func (c *purchaseHistoryCache) invalidate() {
+	c.mu.Lock()
+	c.history = nil
+	c.mu.Unlock()
+}
+
+// Prepare loads the order history if the cache is empty or stale. Without history the
+// ranker falls back to the cheapest unit price.
+func (r historyRanker) Prepare(ctx context.Context) error {
+	_, err := r.cache.get(ctx)
+	return err
+}
+
+func (r historyRanker) Less(a, b Product) bool {
+	history := r.cache.current()
+	if as, bs := history.Score(a), history.Score(b); as != bs {
+		return as > bs
+	}
+	return unitPriceOrMax(a) < unitPriceOrMax(b)
+}
+
+// PurchaseHistory returns the cached order history behind the history_weighted ranker,
+// loading it if needed.
+func (c *Client) PurchaseHistory(ctx context.Context) (*PurchaseHistory, error) {
+	return c.purchaseHistory.get(ctx)
+}
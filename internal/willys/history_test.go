@@ -0,0 +1,41 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceChangesSinceLastPurchase(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	history := BuildPurchaseHistory([]Order{
+		{PlacedAt: now.Add(-week), Entries: []OrderEntry{
+			{Code: "mince", Name: "Nötfärs", Quantity: 1, Price: SEK(5490)},
+			{Code: "milk", Name: "Mjölk", Quantity: 2, Price: SEK(1790)},
+		}},
+		{PlacedAt: now.Add(-2 * week), Entries: []OrderEntry{
+			{Code: "milk", Name: "Mjölk", Quantity: 2, Price: SEK(1590)},
+			{Code: "coffee", Name: "Kaffe", Quantity: 1, Price: SEK(8900)},
+		}},
+	})
+	if !history.LastBought["milk"].Equal(now.Add(-week)) {
+		t.Errorf("Expected milk last bought a week ago, got %v", history.LastBought["milk"])
+	}
+
+	cart := []CartItem{
+		{ProductCode: "milk", Name: "Mjölk", Price: SEK(1840)},
+		{ProductCode: "mince", Name: "Nötfärs", Price: SEK(5990)},
+		{ProductCode: "coffee", Name: "Kaffe", Price: SEK(7900)},
+		{ProductCode: "new", Name: "Nyhet", Price: SEK(2000)},
+	}
+	changes := history.PriceChangesSinceLastPurchase(cart, DefaultPriceAlertPercent)
+	if len(changes) != 3 || changes[0].Code != "mince" || changes[1].Code != "milk" || changes[2].Code != "coffee" {
+		t.Fatalf("Expected the biggest rise first and never-bought items left out, got %+v", changes)
+	}
+	if !changes[0].Alert || changes[1].Alert || changes[2].Alert {
+		t.Errorf("Expected only the 9%% rise flagged, got %+v", changes)
+	}
+	if changes[1].Was != SEK(1790) || !changes[1].LastBought.Equal(now.Add(-week)) {
+		t.Errorf("Expected milk compared with the latest purchase, got %+v", changes[1])
+	}
+}
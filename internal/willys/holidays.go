@@ -0,0 +1,129 @@
+package willys
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// HolidayRedDay is a public holiday ("röd dag").
+	HolidayRedDay = "red_day"
+	// HolidayEve is the day before a major holiday. Not an official holiday, but most
+	// people are off and stores run reduced hours, so delivery slots are just as scarce.
+	HolidayEve = "eve"
+)
+
+// Holiday is a Swedish public holiday or holiday eve.
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// SwedishHolidays returns the red days and major holiday eves of year in date order.
+func SwedishHolidays(year int) []Holiday {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	easter := easterSunday(year)
+	// Midsommardagen and Alla helgons dag are the Saturdays in a fixed week
+	midsummer := firstWeekdayFrom(date(time.June, 20), time.Saturday)
+	allSaints := firstWeekdayFrom(date(time.October, 31), time.Saturday)
+
+	days := []struct {
+		at   time.Time
+		name string
+		kind string
+	}{
+		{date(time.January, 1), "Nyårsdagen", HolidayRedDay},
+		{date(time.January, 6), "Trettondedag jul", HolidayRedDay},
+		{easter.AddDate(0, 0, -2), "Långfredagen", HolidayRedDay},
+		{easter.AddDate(0, 0, -1), "Påskafton", HolidayEve},
+		{easter, "Påskdagen", HolidayRedDay},
+		{easter.AddDate(0, 0, 1), "Annandag påsk", HolidayRedDay},
+		{date(time.May, 1), "Första maj", HolidayRedDay},
+		{easter.AddDate(0, 0, 39), "Kristi himmelsfärdsdag", HolidayRedDay},
+		{easter.AddDate(0, 0, 49), "Pingstdagen", HolidayRedDay},
+		{date(time.June, 6), "Sveriges nationaldag", HolidayRedDay},
+		{midsummer.AddDate(0, 0, -1), "Midsommarafton", HolidayEve},
+		{midsummer, "Midsommardagen", HolidayRedDay},
+		{allSaints, "Alla helgons dag", HolidayRedDay},
+		{date(time.December, 24), "Julafton", HolidayEve},
+		{date(time.December, 25), "Juldagen", HolidayRedDay},
+		{date(time.December, 26), "Annandag jul", HolidayRedDay},
+		{date(time.December, 31), "Nyårsafton", HolidayEve},
+	}
+
+	holidays := make([]Holiday, 0, len(days))
+	for _, d := range days {
+		holidays = append(holidays, Holiday{Date: d.at.Format("2006-01-02"), Name: d.name, Kind: d.kind})
+	}
+	// Första maj and Kristi himmelsfärdsdag can fall on the same day
+	sort.SliceStable(holidays, func(i, j int) bool { return holidays[i].Date < holidays[j].Date })
+	return holidays
+}
+
+// HolidayOn reports the holiday on t's calendar date, if any.
+func HolidayOn(t time.Time) (Holiday, bool) {
+	date := t.Format("2006-01-02")
+	for _, h := range SwedishHolidays(t.Year()) {
+		if h.Date == date {
+			return h, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// HolidaysBetween returns the holidays from one date to another, both inclusive, given
+// as YYYY-MM-DD.
+func HolidaysBetween(from, to string) []Holiday {
+	start, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		return nil
+	}
+	end, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		return nil
+	}
+
+	var holidays []Holiday
+	for year := start.Year(); year <= end.Year(); year++ {
+		for _, h := range SwedishHolidays(year) {
+			if h.Date >= from && h.Date <= to {
+				holidays = append(holidays, h)
+			}
+		}
+	}
+	return holidays
+}
+
+// lastDayBeforeHoliday steps back from t to the closest earlier day that is not a holiday.
+func lastDayBeforeHoliday(t time.Time) time.Time {
+	for {
+		t = t.AddDate(0, 0, -1)
+		if _, ok := HolidayOn(t); !ok {
+			return t
+		}
+	}
+}
+
+// easterSunday computes Easter in the Gregorian calendar (the anonymous algorithm, also
+// known as Meeus/Jones/Butcher).
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func firstWeekdayFrom(t time.Time, weekday time.Weekday) time.Time {
+	return t.AddDate(0, 0, (int(weekday)-int(t.Weekday())+7)%7)
+}
@@ -0,0 +1,76 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwedishHolidays(t *testing.T) {
+	tests := []struct {
+		date string
+		name string
+		kind string
+	}{
+		{"2026-04-03", "Långfredagen", HolidayRedDay},
+		{"2026-04-05", "Påskdagen", HolidayRedDay},
+		{"2026-05-14", "Kristi himmelsfärdsdag", HolidayRedDay},
+		{"2026-06-19", "Midsommarafton", HolidayEve},
+		{"2026-06-20", "Midsommardagen", HolidayRedDay},
+		{"2026-10-31", "Alla helgons dag", HolidayRedDay},
+		{"2026-12-24", "Julafton", HolidayEve},
+		{"2027-03-28", "Påskdagen", HolidayRedDay},
+		{"2027-11-06", "Alla helgons dag", HolidayRedDay},
+	}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		holiday, ok := HolidayOn(date)
+		if !ok || holiday.Name != tt.name || holiday.Kind != tt.kind {
+			t.Errorf("%s: expected %s (%s), got %+v", tt.date, tt.name, tt.kind, holiday)
+		}
+	}
+
+	if _, ok := HolidayOn(time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)); ok {
+		t.Error("Expected an ordinary Friday not to be a holiday")
+	}
+
+	between := HolidaysBetween("2026-12-20", "2027-01-07")
+	var names []string
+	for _, h := range between {
+		names = append(names, h.Name)
+	}
+	expected := []string{"Julafton", "Juldagen", "Annandag jul", "Nyårsafton", "Nyårsdagen", "Trettondedag jul"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, names)
+		}
+	}
+}
+
+func TestSlotSpecHolidayFallback(t *testing.T) {
+	// Wednesday before Easter 2026; Friday is Långfredagen
+	now := time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+	spec, err := ParseSlotSpec("friday", now)
+	if err != nil {
+		t.Fatalf("ParseSlotSpec failed: %v", err)
+	}
+	if len(spec.Holidays) != 1 || spec.Holidays[0].Name != "Långfredagen" {
+		t.Fatalf("Expected Långfredagen to be flagged, got %+v", spec.Holidays)
+	}
+
+	slots := []TimeSlot{
+		{SlotID: "thu", Date: "2026-04-02", StartTime: "18:00", EndTime: "20:00", Available: true},
+		{SlotID: "sat", Date: "2026-04-04", StartTime: "10:00", EndTime: "12:00", Available: true},
+	}
+	slot, err := spec.Resolve(slots)
+	if err != nil || slot.SlotID != "thu" {
+		t.Fatalf("Expected the day before the holiday, got %+v, %v", slot, err)
+	}
+
+	slots = append(slots, TimeSlot{SlotID: "fri", Date: "2026-04-03", StartTime: "12:00", EndTime: "14:00", Available: true})
+	if slot, _ := spec.Resolve(slots); slot == nil || slot.SlotID != "fri" {
+		t.Errorf("Expected the holiday itself when it has a slot, got %+v", slot)
+	}
+}
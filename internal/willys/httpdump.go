@@ -0,0 +1,218 @@
+package willys
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHTTPDumpMaxBytes is the size at which the dump file is rotated.
+	DefaultHTTPDumpMaxBytes = 5 << 20
+	// DefaultHTTPDumpKeep is how many rotated dump files are kept next to the current one.
+	DefaultHTTPDumpKeep = 3
+
+	httpDumpBodyLimit = 4 << 10
+	redacted          = "[redacted]"
+)
+
+var (
+	// Headers carrying session credentials are never written to the dump.
+	httpDumpSecretHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-Csrf-Token"}
+
+	// Personal details and credentials inside JSON bodies, form bodies, and query strings.
+	httpDumpSecretJSON = regexp.MustCompile(`(?i)("(?:[a-z_]*password|username|[a-z_]*token|email|phone[a-z]*|ssn|personnummer|firstName|lastName|street[a-z]*|address(?:line[0-9])?)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	httpDumpSecretForm = regexp.MustCompile(`(?i)((?:^|[?&])(?:[a-z_]*password|username|[a-z_]*token|email)=)[^&]*`)
+)
+
+type (
+	// HTTPDumpEntry is one request/response pair in the debug dump, written as a JSON line.
+	HTTPDumpEntry struct {
+		Time           time.Time         `json:"time"`
+		Method         string            `json:"method"`
+		Path           string            `json:"path"`
+		Status         int               `json:"status,omitempty"`
+		DurationMs     int64             `json:"durationMs"`
+		RequestHeader  map[string]string `json:"requestHeader,omitempty"`
+		RequestBody    string            `json:"requestBody,omitempty"`
+		ResponseHeader map[string]string `json:"responseHeader,omitempty"`
+		ResponseBody   string            `json:"responseBody,omitempty"`
+		Error          string            `json:"error,omitempty"`
+	}
+
+	// dumpTransport records every upstream exchange for debugging the undocumented API.
+	dumpTransport struct {
+		next http.RoundTripper
+		out  io.Writer
+		mu   sync.Mutex
+	}
+
+	// rotatingFile appends to path and, past maxBytes, shifts it to path.1, path.1 to
+	// path.2, and so on, dropping the oldest beyond keep.
+	rotatingFile struct {
+		mu       sync.Mutex
+		path     string
+		maxBytes int64
+		keep     int
+		file     *os.File
+		size     int64
+	}
+)
+
+// WithHTTPDump writes sanitized request/response pairs to a rotating file at path.
+// Credentials, cookies, and personal details are redacted and bodies are truncated.
+func WithHTTPDump(path string, maxBytes int64, keep int) ClientOption {
+	return func(c *Client) {
+		out, err := openRotatingFile(path, maxBytes, keep)
+		if err != nil {
+			log.Printf("HTTP debug dump disabled: %v", err)
+			return
+		}
+		c.httpClient.Transport = &dumpTransport{next: c.httpClient.Transport, out: out}
+		log.Printf("Writing HTTP debug dump to %s", path)
+	}
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	entry := HTTPDumpEntry{
+		Time:          time.Now(),
+		Method:        req.Method,
+		Path:          redactSecrets(req.URL.RequestURI()),
+		RequestHeader: dumpHeader(req.Header),
+	}
+
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			entry.RequestBody = dumpBody(data)
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	entry.DurationMs = time.Since(entry.Time).Milliseconds()
+	if err != nil {
+		entry.Error = err.Error()
+		t.write(entry)
+		return resp, err
+	}
+
+	entry.Status = resp.StatusCode
+	entry.ResponseHeader = dumpHeader(resp.Header)
+	data, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	entry.ResponseBody = dumpBody(data)
+	if req.URL.Path == EndpointCSRFToken {
+		entry.ResponseBody = redacted // the token is the whole body
+	}
+	if readErr != nil {
+		entry.Error = readErr.Error()
+	}
+	t.write(entry)
+	return resp, nil
+}
+
+func (t *dumpTransport) write(entry HTTPDumpEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := t.out.Write(append(line, '\n')); err != nil {
+		log.Printf("Failed to write HTTP debug dump: %v", err)
+	}
+}
+
+func dumpHeader(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for key, values := range h {
+		if len(values) > 0 {
+			out[key] = values[0]
+		}
+	}
+	for _, key := range httpDumpSecretHeaders {
+		if _, ok := out[http.CanonicalHeaderKey(key)]; ok {
+			out[http.CanonicalHeaderKey(key)] = redacted
+		}
+	}
+	return out
+}
+
+func dumpBody(data []byte) string {
+	s := redactSecrets(string(data))
+	if len(s) > httpDumpBodyLimit {
+		s = fmt.Sprintf("%s... (%d bytes truncated)", s[:httpDumpBodyLimit], len(s)-httpDumpBodyLimit)
+	}
+	return s
+}
+
+func redactSecrets(s string) string {
+	s = httpDumpSecretJSON.ReplaceAllString(s, `$1"`+redacted+`"`)
+	return httpDumpSecretForm.ReplaceAllString(s, "${1}"+redacted)
+}
+
+func openRotatingFile(path string, maxBytes int64, keep int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create dump directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxBytes: maxBytes, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dump file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat dump file: %w", err)
+	}
+	r.file, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.keep > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
@@ -0,0 +1,93 @@
+package willys
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHTTPDump(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"csrf-secret"`))
+		case EndpointCustomer:
+			http.SetCookie(w, &http.Cookie{Name: "JSESSIONID", Value: "session-secret"})
+			w.Write([]byte(`{"email":"a@example.com","firstName":"Anna","customerId":"42"}`))
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "http-debug.log")
+	client, err := NewClient(srv.URL, "", "", WithHTTPDump(path, DefaultHTTPDumpMaxBytes, DefaultHTTPDumpKeep))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	info, err := client.GetCustomerInfo(context.Background())
+	if err != nil || info.Email != "a@example.com" {
+		t.Fatalf("Expected the response body to survive the dump, got %+v (%v)", info, err)
+	}
+	resp, err := client.DoRequest(context.Background(), "POST", EndpointLogin+"?refresh_token=abc",
+		strings.NewReader(`{"username":"199001011234","password":"hunter22"}`), true)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dump: %v", err)
+	}
+	for _, secret := range []string{"a@example.com", "Anna", "session-secret", "csrf-secret", "hunter22", "199001011234", "abc"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("Dump leaks %q:\n%s", secret, data)
+		}
+	}
+
+	var entries []HTTPDumpEntry
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		var e HTTPDumpEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("Invalid dump line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected customer, CSRF, and login entries, got %d", len(entries))
+	}
+	if e := entries[0]; e.Method != "GET" || e.Path != EndpointCustomer || e.Status != 200 || !strings.Contains(e.ResponseBody, `"customerId":"42"`) {
+		t.Errorf("Unexpected customer entry: %+v", e)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dump.log")
+	r, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.file.Close()
+
+	for name, want := range map[string]string{"dump.log": "dddddddd\n", "dump.log.1": "cccccccc\n", "dump.log.2": "bbbbbbbb\n"} {
+		got, _ := os.ReadFile(filepath.Join(filepath.Dir(path), name))
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected only two rotated files to be kept")
+	}
+}
@@ -4,21 +4,35 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"time"
 )
 
 const (
 	EndpointLogin               = "/login"
+	EndpointLogout              = "/logout"
+	EndpointOAuthToken          = "/authorizationserver/oauth/token"
 	EndpointCSRFToken           = "/axfood/rest/csrf-token"
 	EndpointCustomer            = "/axfood/rest/customer"
+	EndpointPaymentMethods      = "/axfood/rest/customer/payment-methods"
+	EndpointOrderHistory        = "/axfood/rest/order/history"
 	EndpointCart                = "/axfood/rest/cart"
 	EndpointCartAddProducts     = "/axfood/rest/cart/addProducts"
+	EndpointCartMerge           = "/axfood/rest/cart/merge"
 	EndpointCartDeliveryMode    = "/axfood/rest/cart/delivery-mode/homeDelivery"
 	EndpointCartDeliveryAddress = "/axfood/rest/cart/delivery-address"
 	EndpointCartPostalCode      = "/axfood/rest/cart/postal-code"
+	EndpointCartPaymentType     = "/axfood/rest/cart/payment-type"
 	EndpointSearch              = "/search"
+	EndpointSearchREST          = "/axfood/rest/search"
+	EndpointNewProducts         = "/c/nyheter"
+	EndpointRelatedProducts     = "/axfood/rest/recommendation/also-bought"
+	EndpointProduct             = "/axfood/rest/p"
 	EndpointSlotHomeDelivery    = "/axfood/rest/slot/homeDelivery"
 	EndpointSlotInCart          = "/axfood/rest/slot/slotInCart"
 	EndpointShippingDelivery    = "/axfood/rest/shipping/delivery"
+	EndpointAddressSuggestions  = "/axfood/rest/address/suggestions"
+	EndpointStoreSearch         = "/axfood/rest/search/store"
+	EndpointSlotPickup          = "/axfood/rest/slot/pickInStore"
 	EndpointCheckout            = "/kassa"
 )
 
@@ -28,23 +42,51 @@ type HTTPDoer interface {
 
 type WillysAPI interface {
 	Login(ctx context.Context, username, password string) error
+	LoginWithToken(ctx context.Context, refreshToken string) error
+	SubmitVerificationCode(code string) error
+	Logout(ctx context.Context) error
 	GetCustomerInfo(ctx context.Context) (*CustomerInfo, error)
 	IsAuthenticated() bool
+	AuthStatus() AuthStatus
 
 	SearchProducts(ctx context.Context, query string, page, size int, prefs *SearchPreferences) ([]Product, error)
+	SearchWithFacets(ctx context.Context, query string, page, size int, prefs *SearchPreferences) (*SearchResult, error)
+	GetNewProducts(ctx context.Context) ([]Product, error)
+	GetRelatedProducts(ctx context.Context, productCode string) ([]Product, error)
+	GetProductDetails(ctx context.Context, productCode string) (*ProductDetails, error)
+	GetProductsDetails(ctx context.Context, productCodes []string) (map[string]*ProductDetails, error)
 
 	AddToCart(ctx context.Context, productCode string, quantity int) (*CartSummary, error)
 	GetCart(ctx context.Context) (*CartSummary, error)
+	CachedCart() (cart *CartSummary, syncedAt time.Time, ok bool)
 	RemoveFromCart(ctx context.Context, productCode string, quantity int) (*CartSummary, error)
 	ClearCart(ctx context.Context) error
 
 	CheckDeliverability(ctx context.Context, postalCode string) (bool, error)
+	GetDeliverability(ctx context.Context, postalCode string) (*Deliverability, error)
 	SetDeliveryMode(ctx context.Context) error
 	SetDeliveryAddress(ctx context.Context, address DeliveryAddress) error
 	GetAvailableTimeSlots(ctx context.Context, postalCode string) ([]TimeSlot, error)
+	FindPickupStores(ctx context.Context, postalCode string) ([]PickupStore, error)
+	GetPickupTimeSlots(ctx context.Context, storeID string) ([]TimeSlot, error)
+	GetSlotInCart(ctx context.Context) (*TimeSlot, error)
 	SelectTimeSlot(ctx context.Context, slot TimeSlot) error
+	ReleaseTimeSlot(ctx context.Context) error
 	SetupDelivery(ctx context.Context, address DeliveryAddress, slot TimeSlot) (*DeliveryInfo, error)
 	GetCheckoutURL() string
+	DiagnoseCheckout(ctx context.Context) (*CheckoutDiagnosis, error)
+	GetPaymentMethods(ctx context.Context) (*PaymentMethods, error)
+	SetPaymentType(ctx context.Context, paymentType string) error
+	GetOrderHistory(ctx context.Context) ([]Order, error)
+	PurchaseHistory(ctx context.Context) (*PurchaseHistory, error)
+
+	StrictDecode() bool
+	B2B() bool
+	DriftReports() []DriftReport
+	PoolStats() PoolStats
+	ResponseCacheStats() ResponseCacheStats
+	InvalidateResponseCache(postalCode, endpoint string) int
+	EndpointOverrides() EndpointOverrides
 
 	GetCSRFToken() (string, error)
 	FetchCSRFToken() (string, error)
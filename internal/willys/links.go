@@ -0,0 +1,36 @@
+package willys
+
+import (
+	"net/url"
+	"strings"
+)
+
+// CheckoutLinks are the ways into checkout. Willys keeps the cart and the reserved slot
+// on its side, tied to the account, so neither link needs to carry them: whatever opens
+// the link only has to be logged in to the same account.
+type CheckoutLinks struct {
+	Web string `json:"web"`
+	// App opens the Willys app where it is installed: a universal link to the public
+	// site, or a link in the app's own URL scheme when one is configured
+	App string `json:"app"`
+}
+
+// NewCheckoutLinks derives the app link from the web checkout URL by moving its path
+// onto appBase, e.g. "https://www.willys.se" or "willys://".
+func NewCheckoutLinks(webURL, appBase string) CheckoutLinks {
+	links := CheckoutLinks{Web: webURL, App: webURL}
+	if appBase == "" {
+		return links
+	}
+
+	target := webURL
+	if parsed, err := url.Parse(webURL); err == nil {
+		target = parsed.RequestURI()
+	}
+	if strings.HasSuffix(appBase, "://") {
+		links.App = appBase + strings.TrimPrefix(target, "/")
+	} else {
+		links.App = strings.TrimSuffix(appBase, "/") + target
+	}
+	return links
+}
@@ -0,0 +1,19 @@
+package willys
+
+import "testing"
+
+func TestNewCheckoutLinks(t *testing.T) {
+	tests := []struct {
+		web, appBase, expected string
+	}{
+		{"https://www.willys.se/kassa", "", "https://www.willys.se/kassa"},
+		{"https://web.example/kassa?step=1", "https://www.willys.se/", "https://www.willys.se/kassa?step=1"},
+		{"https://www.willys.se/kassa", "willys://", "willys://kassa"},
+	}
+	for _, tt := range tests {
+		links := NewCheckoutLinks(tt.web, tt.appBase)
+		if links.Web != tt.web || links.App != tt.expected {
+			t.Errorf("NewCheckoutLinks(%q, %q) = %+v, expected app link %s", tt.web, tt.appBase, links, tt.expected)
+		}
+	}
+}
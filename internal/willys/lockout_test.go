@@ -0,0 +1,81 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewLockoutError(t *testing.T) {
+	now := time.Date(2025, 3, 14, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		reason, message string
+		want            time.Duration
+	}{
+		{LockoutReasonLocked, "Ditt konto är låst. Försök igen om 20 minuter.", 20 * time.Minute},
+		{LockoutReasonLocked, "Too many login attempts, try again in 2 hours", 2 * time.Hour},
+		{LockoutReasonLocked, "Kontot är tillfälligt spärrat i 1 timme", time.Hour},
+		{LockoutReasonLocked, "Kontot är spärrat", DefaultLockoutWait},
+		{LockoutReasonCaptcha, "", DefaultCaptchaWait},
+	}
+	for _, tt := range tests {
+		got := newLockoutError(tt.reason, tt.message, now)
+		if got.RetryAfter != tt.want || !got.Until.Equal(now.Add(tt.want)) {
+			t.Errorf("newLockoutError(%q) waits %s until %s, want %s", tt.message, got.RetryAfter, got.Until, tt.want)
+		}
+	}
+}
+
+func TestLockoutPausesReauth(t *testing.T) {
+	logins := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointLogin:
+			logins++
+			w.Header().Set("Retry-After", "600")
+			w.WriteHeader(http.StatusLocked)
+		case EndpointCartAddProducts:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "199001011234", "secret123")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := client.DoRequest(context.Background(), "POST", EndpointCartAddProducts, nil, true)
+		var lockout *LockoutError
+		if !errors.As(err, &lockout) || lockout.RetryAfter != 10*time.Minute {
+			t.Fatalf("Request %d: expected a 10 minute lockout, got %v", i, err)
+		}
+	}
+	if logins != 1 {
+		t.Errorf("Expected a single login attempt, got %d", logins)
+	}
+	if err := client.Login(context.Background(), "199001011234", "secret123"); err == nil || logins != 1 {
+		t.Errorf("Expected explicit logins to be refused during the lockout too, got %v after %d attempts", err, logins)
+	}
+
+	status := client.AuthStatus()
+	if status.State != AuthStateLockedOut || status.LockoutReason != LockoutReasonLocked || status.LockedOutUntil == nil {
+		t.Errorf("Expected LOCKED_OUT, got %+v", status)
+	}
+
+	client.session.update(func(st *sessionState) {
+		expired := *st.lockout
+		expired.Until = time.Now().Add(-time.Second)
+		st.lockout = &expired
+	})
+	if client.activeLockout() != nil {
+		t.Error("Expected the lockout to end at Until")
+	}
+}
@@ -0,0 +1,218 @@
+package willys
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	DefaultCurrency = "SEK"
+
+	LocaleSwedish = "sv"
+	LocaleEnglish = "en"
+)
+
+// priceLocale controls the "display" string in Money's JSON. It is process-wide because
+// MarshalJSON has no way to receive per-call options.
+var priceLocale atomic.Value
+
+// Money is an amount in öre (1/100 SEK). Keeping amounts as integers avoids the float
+// drift that crept into cart totals when summing line prices.
+//
+// Upstream prices come as numbers, strings ("15,90 kr", "76.20"), or objects with a
+// "value" field; UnmarshalJSON accepts all of them as well as Money's own encoding.
+type Money struct {
+	Ore      int64
+	Currency string // empty means SEK
+}
+
+// MoneyJSON is how every amount is encoded in tool output: the exact amount in öre, the
+// currency, and a display string formatted for the configured price locale. There is
+// deliberately no float field; agents doing arithmetic should use AmountOre.
+type MoneyJSON struct {
+	AmountOre int64  `json:"amount_ore"`
+	Currency  string `json:"currency"`
+	Display   string `json:"display"`
+}
+
+func ValidateLocale(locale string) error {
+	switch locale {
+	case LocaleSwedish, LocaleEnglish:
+		return nil
+	default:
+		return NewValidationError("locale", fmt.Sprintf("unsupported price locale: %s", locale))
+	}
+}
+
+// SetPriceLocale selects how prices are formatted in tool output: "sv" (1 234,50 kr) or
+// "en" (SEK 1,234.50).
+func SetPriceLocale(locale string) error {
+	if err := ValidateLocale(locale); err != nil {
+		return err
+	}
+	priceLocale.Store(locale)
+	return nil
+}
+
+func PriceLocale() string {
+	if locale, ok := priceLocale.Load().(string); ok {
+		return locale
+	}
+	return LocaleSwedish
+}
+
+func SEK(ore int64) Money {
+	return Money{Ore: ore}
+}
+
+func MoneyFromFloat(kronor float64) Money {
+	return SEK(int64(math.Round(kronor * 100)))
+}
+
+// ParseMoney parses Swedish and plain decimal price strings such as "1 234,50 kr",
+// "15,90", "15.90", or "25:-".
+func ParseMoney(s string) (Money, error) {
+	s, _, _ = strings.Cut(s, "/") // "15,90 kr/st"
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, ":-")
+	s = strings.TrimSuffix(s, "kr")
+	s = strings.TrimSuffix(s, DefaultCurrency)
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "").Replace(s)
+	if s == "" {
+		return SEK(0), nil
+	}
+
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return SEK(0), fmt.Errorf("invalid price %q: %w", s, err)
+	}
+	return MoneyFromFloat(value), nil
+}
+
+func (m Money) Float() float64 {
+	return float64(m.Ore) / 100
+}
+
+func (m Money) IsZero() bool {
+	return m.Ore == 0
+}
+
+func (m Money) Add(other Money) Money {
+	return Money{Ore: m.Ore + other.Ore, Currency: m.Currency}
+}
+
+func (m Money) Sub(other Money) Money {
+	return Money{Ore: m.Ore - other.Ore, Currency: m.Currency}
+}
+
+func (m Money) Mul(n int) Money {
+	return Money{Ore: m.Ore * int64(n), Currency: m.Currency}
+}
+
+func (m Money) currency() string {
+	if m.Currency == "" {
+		return DefaultCurrency
+	}
+	return m.Currency
+}
+
+// String formats the amount in the configured price locale.
+func (m Money) String() string {
+	return m.Format(PriceLocale())
+}
+
+// Format renders the amount as Willys displays it ("1 234,50 kr") or, for English,
+// as "SEK 1,234.50". Unknown locales fall back to Swedish.
+func (m Money) Format(locale string) string {
+	ore := m.Ore
+	sign := ""
+	if ore < 0 {
+		sign = "-"
+		ore = -ore
+	}
+
+	if locale == LocaleEnglish {
+		return fmt.Sprintf("%s%s %s.%02d", sign, m.currency(), groupThousands(ore/100, ','), ore%100)
+	}
+
+	unit := "kr"
+	if m.currency() != DefaultCurrency {
+		unit = m.currency()
+	}
+	return fmt.Sprintf("%s%s,%02d %s", sign, groupThousands(ore/100, ' '), ore%100, unit)
+}
+
+func groupThousands(n int64, sep byte) string {
+	digits := strconv.FormatInt(n, 10)
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(sep)
+		}
+		grouped.WriteRune(digit)
+	}
+	return grouped.String()
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(MoneyJSON{
+		AmountOre: m.Ore,
+		Currency:  m.currency(),
+		Display:   m.String(),
+	})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	// Unparseable prices decode as zero so one odd price doesn't fail the whole cart,
+	// but are logged so a format change upstream doesn't go unnoticed
+	parsed, err := moneyFromAny(v)
+	if err != nil {
+		log.Printf("Decoding unparseable price %s as 0: %v", data, err)
+	}
+	*m = parsed
+	return nil
+}
+
+func moneyFromAny(v any) (Money, error) {
+	switch val := v.(type) {
+	case nil:
+		return SEK(0), nil
+	case float64:
+		return MoneyFromFloat(val), nil
+	case string:
+		return ParseMoney(val)
+	case map[string]any:
+		ore, ok := val["amount_ore"].(float64)
+		if !ok {
+			ore, ok = val["amountOre"].(float64) // encoding before the output format was unified
+		}
+		if ok {
+			m := SEK(int64(ore))
+			if currency, ok := val["currency"].(string); ok && currency != DefaultCurrency {
+				m.Currency = currency
+			}
+			return m, nil
+		}
+		if valueField, ok := val["value"]; ok {
+			return moneyFromAny(valueField)
+		}
+		return SEK(0), nil
+	default:
+		return SEK(0), fmt.Errorf("unsupported price type %T", v)
+	}
+}
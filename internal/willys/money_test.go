@@ -0,0 +1,122 @@
+package willys
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"15,90 kr", 1590},
+		{"15.90", 1590},
+		{"1 234,50 kr", 123450},
+		{"1.234,50", 123450},
+		{"25:-", 2500},
+		{"12,90 kr/kg", 1290},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		m, err := ParseMoney(tt.input)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if m.Ore != tt.expected {
+			t.Errorf("ParseMoney(%q): expected %d öre, got %d", tt.input, tt.expected, m.Ore)
+		}
+	}
+
+	if _, err := ParseMoney("gratis"); err == nil {
+		t.Error("Expected error for non-numeric price")
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := map[int64]string{
+		1590:    "15,90 kr",
+		123450:  "1 234,50 kr",
+		-500:    "-5,00 kr",
+		1000000: "10 000,00 kr",
+	}
+	for ore, expected := range tests {
+		if got := SEK(ore).String(); got != expected {
+			t.Errorf("SEK(%d).String(): expected %q, got %q", ore, expected, got)
+		}
+	}
+}
+
+func TestMoneyFormatEnglish(t *testing.T) {
+	tests := map[int64]string{
+		1590:   "SEK 15.90",
+		123450: "SEK 1,234.50",
+		-500:   "-SEK 5.00",
+	}
+	for ore, expected := range tests {
+		if got := SEK(ore).Format(LocaleEnglish); got != expected {
+			t.Errorf("SEK(%d).Format(en): expected %q, got %q", ore, expected, got)
+		}
+	}
+
+	if err := SetPriceLocale("de"); err == nil {
+		t.Error("Expected error for unsupported locale")
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 style drift must not leak into totals
+	total := SEK(0)
+	for i := 0; i < 10; i++ {
+		total = total.Add(MoneyFromFloat(0.1))
+	}
+	if total != SEK(100) {
+		t.Errorf("Expected 1,00 kr, got %s", total)
+	}
+	if got := SEK(1590).Mul(3).Sub(SEK(70)); got != SEK(4700) {
+		t.Errorf("Expected 47,00 kr, got %s", got)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(SEK(1590))
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected := `{"amount_ore":1590,"currency":"SEK","display":"15,90 kr"}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	inputs := []string{`15.9`, `"15,90 kr"`, `{"value": 15.9}`, `{"value": "15.90"}`, `{"amount":15.9,"amountOre":1590,"currency":"SEK","formatted":"15,90 kr"}`, expected}
+	for _, input := range inputs {
+		var m Money
+		if err := json.Unmarshal([]byte(input), &m); err != nil {
+			t.Errorf("Failed to unmarshal %s: %v", input, err)
+			continue
+		}
+		if m != SEK(1590) {
+			t.Errorf("Unmarshal %s: expected 15,90 kr, got %s", input, m)
+		}
+	}
+}
+
+func TestMoneyUnmarshalUnparseable(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	var m Money
+	if err := json.Unmarshal([]byte(`"ca 15 kr"`), &m); err != nil || !m.IsZero() {
+		t.Errorf("Expected an unparseable price to decode as zero, got %s (%v)", m, err)
+	}
+	if !strings.Contains(logged.String(), "ca 15 kr") {
+		t.Errorf("Expected the unparseable price to be logged, got %q", logged.String())
+	}
+}
@@ -0,0 +1,120 @@
+package willys
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+type (
+	// CostSwap proposes replacing a cart item with a cheaper product sold by the same unit.
+	// ReplacementQuantity is the number of replacement packs holding at least as much as
+	// the current ones, so a bigger pack means fewer of them. Savings is what the cart total
+	// drops by when Quantity packs are swapped for ReplacementQuantity.
+	CostSwap struct {
+		ProductCode         string         `json:"code"`
+		Name                string         `json:"name"`
+		Quantity            int            `json:"quantity"`
+		Current             CompactProduct `json:"current"`
+		Replacement         CompactProduct `json:"replacement"`
+		ReplacementQuantity int            `json:"replacementQuantity"`
+		Savings             Money          `json:"savings"`
+		Reason              string         `json:"reason"`
+		Applied             bool           `json:"applied,omitempty"`
+		Error               string         `json:"error,omitempty"`
+	}
+
+	CartCostReport struct {
+		Swaps        []CostSwap `json:"swaps"`
+		Unchanged    []string   `json:"unchanged,omitempty"` // cart items without a cheaper equivalent
+		TotalSavings Money      `json:"totalSavings"`
+	}
+)
+
+// FindCheaperEquivalent returns the candidate with the lowest unit price that is sold by
+// the same unit as current, carries at least the same eco labels, and lowers the cart
+// total for the same amount of goods, or nil when there is none. ownBrand restricts
+// ("only") or biases ("prefer") the replacement towards Axfood's own brands.
+func FindCheaperEquivalent(item CartItem, current Product, candidates []Product, ownBrand string) *CostSwap {
+	currentUnit := current.ComparePrice.Float()
+	if currentUnit <= 0 || current.ComparePriceUnit == "" {
+		return nil
+	}
+	required := ParseSustainability(current.Labels).Labels
+
+	comparable := make([]Product, 0, len(candidates))
+	for _, p := range candidates {
+		if p.Code == current.Code || p.OutOfStock {
+			continue
+		}
+		if ownBrand == OwnBrandOnly && !IsOwnBrand(p) {
+			continue
+		}
+		if !strings.EqualFold(p.ComparePriceUnit, current.ComparePriceUnit) {
+			continue
+		}
+		if !hasLabels(ParseSustainability(p.Labels).Labels, required) {
+			continue
+		}
+		if p.ComparePrice.Ore <= 0 || p.ComparePrice.Float() >= currentUnit {
+			continue
+		}
+		comparable = append(comparable, p)
+	}
+	if len(comparable) == 0 {
+		return nil
+	}
+
+	sortByUnitPrice(comparable)
+	var swap *CostSwap
+	for _, p := range comparable {
+		candidate := costSwap(item, current, p)
+		if candidate.Savings.Ore <= 0 {
+			continue
+		}
+		if swap == nil {
+			swap = candidate
+		}
+		if ownBrand != OwnBrandPrefer || IsOwnBrand(p) {
+			swap = candidate
+			break
+		}
+	}
+	if swap == nil {
+		return nil
+	}
+	if len(required) > 0 {
+		swap.Reason += fmt.Sprintf(", keeps %s", strings.Join(required, ", "))
+	}
+	return swap
+}
+
+// costSwap swaps item's packs of current for enough packs of replacement to hold the same
+// amount. A pack's content is its shelf price over its unit price.
+func costSwap(item CartItem, current, replacement Product) *CostSwap {
+	currentContent := current.PriceValue.Float() / current.ComparePrice.Float()
+	replacementContent := replacement.PriceValue.Float() / replacement.ComparePrice.Float()
+	// The epsilon keeps float error from adding a pack when the contents divide evenly
+	quantity := int(math.Ceil(float64(item.Quantity)*currentContent/replacementContent - 1e-9))
+
+	return &CostSwap{
+		ProductCode:         item.ProductCode,
+		Name:                item.Name,
+		Quantity:            item.Quantity,
+		Current:             current.Compact(),
+		Replacement:         replacement.Compact(),
+		ReplacementQuantity: quantity,
+		Savings:             current.PriceValue.Mul(item.Quantity).Sub(replacement.PriceValue.Mul(quantity)),
+		Reason:              fmt.Sprintf("%s instead of %s", formatComparePrice(replacement), formatComparePrice(current)),
+	}
+}
+
+func hasLabels(labels, required []string) bool {
+	for _, r := range required {
+		if !slices.Contains(labels, r) {
+			return false
+		}
+	}
+	return true
+}
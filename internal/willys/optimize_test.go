@@ -0,0 +1,59 @@
+package willys
+
+import "testing"
+
+func TestFindCheaperEquivalent(t *testing.T) {
+	item := CartItem{ProductCode: "101_ST", Name: "Eko Mjölk 1l", Quantity: 2}
+	current := Product{Code: "101_ST", PriceValue: SEK(2000), ComparePrice: SEK(2000), ComparePriceUnit: "l", Labels: []string{"krav"}}
+
+	candidates := []Product{
+		current,
+		{Code: "102_ST", PriceValue: SEK(1200), ComparePrice: SEK(1200), ComparePriceUnit: "l"},                                        // not eco
+		{Code: "103_ST", PriceValue: SEK(1000), ComparePrice: SEK(500), ComparePriceUnit: "kg", Labels: []string{"krav"}},              // other unit
+		{Code: "104_ST", PriceValue: SEK(3600), ComparePrice: SEK(1800), ComparePriceUnit: "l", Labels: []string{"krav", "fairtrade"}}, // 2 l pack
+		{Code: "105_ST", PriceValue: SEK(1500), ComparePrice: SEK(1500), ComparePriceUnit: "l", Labels: []string{"krav"}, OutOfStock: true},
+	}
+
+	swap := FindCheaperEquivalent(item, current, candidates, OwnBrandOff)
+	if swap == nil {
+		t.Fatal("Expected a swap")
+	}
+	if swap.Replacement.Code != "104_ST" {
+		t.Errorf("Expected replacement 104_ST, got %s", swap.Replacement.Code)
+	}
+	// One 2 l pack for 36 kr instead of two 1 l packs for 20 kr.
+	if swap.ReplacementQuantity != 1 || swap.Savings.Ore != 400 {
+		t.Errorf("Expected 1 pack saving 400 öre, got %d packs saving %d", swap.ReplacementQuantity, swap.Savings.Ore)
+	}
+
+	// A single 1 l pack can't be swapped for a 2 l one without buying more
+	single := item
+	single.Quantity = 1
+	if swap := FindCheaperEquivalent(single, current, candidates, OwnBrandOff); swap != nil {
+		t.Errorf("Expected no swap that doubles the amount bought, got %+v", swap)
+	}
+
+	if swap := FindCheaperEquivalent(item, current, candidates[:2], OwnBrandOff); swap != nil {
+		t.Errorf("Expected no swap without an eco equivalent, got %+v", swap)
+	}
+}
+
+func TestFindCheaperEquivalentOwnBrand(t *testing.T) {
+	item := CartItem{ProductCode: "201_ST", Name: "Krossade tomater", Quantity: 1}
+	current := Product{Code: "201_ST", Manufacturer: "Mutti", PriceValue: SEK(2500), ComparePrice: SEK(5000), ComparePriceUnit: "kg"}
+
+	candidates := []Product{
+		{Code: "202_ST", Manufacturer: "Zeta", PriceValue: SEK(1000), ComparePrice: SEK(2000), ComparePriceUnit: "kg"},
+		{Code: "203_ST", Manufacturer: "Garant", PriceValue: SEK(1200), ComparePrice: SEK(2400), ComparePriceUnit: "kg"},
+	}
+
+	if swap := FindCheaperEquivalent(item, current, candidates, OwnBrandOff); swap.Replacement.Code != "202_ST" {
+		t.Errorf("Expected cheapest 202_ST without own-brand mode, got %s", swap.Replacement.Code)
+	}
+	if swap := FindCheaperEquivalent(item, current, candidates, OwnBrandPrefer); swap.Replacement.Code != "203_ST" {
+		t.Errorf("Expected own brand 203_ST when preferred, got %s", swap.Replacement.Code)
+	}
+	if swap := FindCheaperEquivalent(item, current, candidates[:1], OwnBrandOnly); swap != nil {
+		t.Errorf("Expected no swap when only own brands are allowed, got %+v", swap)
+	}
+}
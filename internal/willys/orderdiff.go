@@ -0,0 +1,165 @@
+package willys
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// minComparableEntries is the smallest order compared against by default; smaller ones
+// are usually top-ups that would make every usual item look missing.
+const minComparableEntries = 5
+
+type (
+	// DiffItem is a product in only one of the cart and the order, or in both with
+	// different quantities. Usual marks a staple (see ForgottenStaples).
+	DiffItem struct {
+		Code          string `json:"code"`
+		Name          string `json:"name"`
+		CartQuantity  int    `json:"cartQuantity"`
+		OrderQuantity int    `json:"orderQuantity"`
+		Usual         bool   `json:"usual,omitempty"`
+	}
+
+	PriceChange struct {
+		Code          string  `json:"code"`
+		Name          string  `json:"name"`
+		Was           Money   `json:"was"`
+		Now           Money   `json:"now"`
+		Change        Money   `json:"change"`
+		ChangePercent float64 `json:"changePercent"`
+	}
+
+	// OrderDiff compares the cart with a past order. OrderTotal is what the order's
+	// products cost then, without fees, so TotalDelta compares like with like;
+	// PriceEffect is the part of it caused by price changes on products in both.
+	OrderDiff struct {
+		OrderCode       string        `json:"orderCode"`
+		OrderPlacedAt   time.Time     `json:"orderPlacedAt"`
+		Missing         []DiffItem    `json:"missing"`
+		New             []DiffItem    `json:"new"`
+		QuantityChanged []DiffItem    `json:"quantityChanged"`
+		PriceChanges    []PriceChange `json:"priceChanges"`
+		CartTotal       Money         `json:"cartTotal"`
+		OrderTotal      Money         `json:"orderTotal"`
+		TotalDelta      Money         `json:"totalDelta"`
+		PriceEffect     Money         `json:"priceEffect"`
+	}
+)
+
+// newPriceChange describes a move from was to now; was must not be zero.
+func newPriceChange(code, name string, was, now Money) PriceChange {
+	change := now.Sub(was)
+	return PriceChange{
+		Code:          code,
+		Name:          name,
+		Was:           was,
+		Now:           now,
+		Change:        change,
+		ChangePercent: float64(change.Ore) / float64(was.Ore) * 100,
+	}
+}
+
+// LastComparableOrder returns the newest order that wasn't cancelled and has at least
+// minComparableEntries products, or failing that the newest one that wasn't cancelled.
+func LastComparableOrder(orders []Order) *Order {
+	var newest, newestFull *Order
+	for i := range orders {
+		order := &orders[i]
+		if len(order.Entries) == 0 || isCancelled(order.Status) {
+			continue
+		}
+		if newest == nil || order.PlacedAt.After(newest.PlacedAt) {
+			newest = order
+		}
+		if len(order.Entries) >= minComparableEntries && (newestFull == nil || order.PlacedAt.After(newestFull.PlacedAt)) {
+			newestFull = order
+		}
+	}
+	if newestFull != nil {
+		return newestFull
+	}
+	return newest
+}
+
+func isCancelled(status string) bool {
+	status = strings.ToLower(status)
+	return strings.Contains(status, "makuler") || strings.Contains(status, "avbe") || strings.Contains(status, "cancel")
+}
+
+// DiffOrder compares the cart items with order. orders, the whole history, is only used
+// to tell usual items from one-offs.
+func DiffOrder(items []CartItem, order Order, orders []Order) OrderDiff {
+	diff := OrderDiff{
+		OrderCode:       order.Code,
+		OrderPlacedAt:   order.PlacedAt,
+		Missing:         []DiffItem{},
+		New:             []DiffItem{},
+		QuantityChanged: []DiffItem{},
+		PriceChanges:    []PriceChange{},
+		CartTotal:       SEK(0),
+		OrderTotal:      SEK(0),
+		PriceEffect:     SEK(0),
+	}
+
+	usual := make(map[string]bool)
+	for _, staple := range ForgottenStaples(nil, orders) {
+		usual[staple.Code] = true
+	}
+
+	ordered := make(map[string]OrderEntry, len(order.Entries))
+	for _, entry := range order.Entries {
+		if existing, ok := ordered[entry.Code]; ok {
+			entry.Quantity += existing.Quantity
+		}
+		ordered[entry.Code] = entry
+		diff.OrderTotal = diff.OrderTotal.Add(entry.Price.Mul(entry.Quantity))
+	}
+
+	inCart := make(map[string]bool, len(items))
+	for _, item := range items {
+		inCart[item.ProductCode] = true
+		diff.CartTotal = diff.CartTotal.Add(item.TotalPrice)
+
+		entry, ok := ordered[item.ProductCode]
+		if !ok {
+			diff.New = append(diff.New, DiffItem{Code: item.ProductCode, Name: item.Name, CartQuantity: item.Quantity, Usual: usual[item.ProductCode]})
+			continue
+		}
+		if item.Quantity != entry.Quantity {
+			diff.QuantityChanged = append(diff.QuantityChanged, DiffItem{Code: item.ProductCode, Name: item.Name, CartQuantity: item.Quantity, OrderQuantity: entry.Quantity, Usual: usual[item.ProductCode]})
+		}
+		if !item.Price.IsZero() && !entry.Price.IsZero() && item.Price.Ore != entry.Price.Ore {
+			change := newPriceChange(item.ProductCode, item.Name, entry.Price, item.Price)
+			diff.PriceChanges = append(diff.PriceChanges, change)
+			diff.PriceEffect = diff.PriceEffect.Add(change.Change.Mul(item.Quantity))
+		}
+	}
+
+	for _, entry := range order.Entries {
+		if inCart[entry.Code] {
+			continue
+		}
+		inCart[entry.Code] = true // list each missing product once
+		diff.Missing = append(diff.Missing, DiffItem{Code: entry.Code, Name: entry.Name, OrderQuantity: ordered[entry.Code].Quantity, Usual: usual[entry.Code]})
+	}
+	diff.TotalDelta = diff.CartTotal.Sub(diff.OrderTotal)
+
+	// Usual items first, then by name; the biggest price moves first
+	byUsual := func(items []DiffItem) {
+		sort.SliceStable(items, func(i, j int) bool {
+			if items[i].Usual != items[j].Usual {
+				return items[i].Usual
+			}
+			return items[i].Name < items[j].Name
+		})
+	}
+	byUsual(diff.Missing)
+	byUsual(diff.New)
+	byUsual(diff.QuantityChanged)
+	sort.SliceStable(diff.PriceChanges, func(i, j int) bool {
+		a, b := diff.PriceChanges[i].Change.Ore, diff.PriceChanges[j].Change.Ore
+		return max(a, -a) > max(b, -b)
+	})
+	return diff
+}
@@ -0,0 +1,56 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiffOrder(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	staples := []OrderEntry{
+		{Code: "mjolk", Name: "Mjölk", Quantity: 2, Price: SEK(1590)},
+		{Code: "smor", Name: "Smör", Quantity: 1, Price: SEK(5490)},
+		{Code: "brod", Name: "Bröd", Quantity: 1, Price: SEK(3290)},
+		{Code: "agg", Name: "Ägg", Quantity: 1, Price: SEK(4290)},
+	}
+	orders := []Order{
+		{Code: "topup", PlacedAt: now.Add(-2 * 24 * time.Hour), Entries: []OrderEntry{{Code: "glass", Name: "Glass", Quantity: 1, Price: SEK(4990)}}},
+		{Code: "cancelled", PlacedAt: now.Add(-3 * 24 * time.Hour), Status: "Makulerad", Entries: append(staples, OrderEntry{Code: "x", Name: "X", Quantity: 1})},
+		{Code: "last", PlacedAt: now.Add(-week), Entries: append(append([]OrderEntry{}, staples...), OrderEntry{Code: "tacos", Name: "Tacoskal", Quantity: 1, Price: SEK(2490)})},
+		{Code: "before", PlacedAt: now.Add(-2 * week), Entries: staples},
+		{Code: "oldest", PlacedAt: now.Add(-3 * week), Entries: staples},
+	}
+
+	last := LastComparableOrder(orders)
+	if last == nil || last.Code != "last" {
+		t.Fatalf("Expected the newest full order that wasn't cancelled, got %+v", last)
+	}
+	if fallback := LastComparableOrder(orders[:1]); fallback == nil || fallback.Code != "topup" {
+		t.Errorf("Expected a small order when there is nothing else, got %+v", fallback)
+	}
+
+	cart := []CartItem{
+		{ProductCode: "mjolk", Name: "Mjölk", Quantity: 3, Price: SEK(1690), TotalPrice: SEK(5070)},
+		{ProductCode: "brod", Name: "Bröd", Quantity: 1, Price: SEK(3290), TotalPrice: SEK(3290)},
+		{ProductCode: "agg", Name: "Ägg", Quantity: 1, Price: SEK(4290), TotalPrice: SEK(4290)},
+		{ProductCode: "kaffe", Name: "Kaffe", Quantity: 1, Price: SEK(8900), TotalPrice: SEK(8900)},
+	}
+	diff := DiffOrder(cart, *last, orders)
+
+	if len(diff.Missing) != 2 || diff.Missing[0].Code != "smor" || !diff.Missing[0].Usual || diff.Missing[1].Usual {
+		t.Errorf("Expected the usual butter before the one-off taco shells, got %+v", diff.Missing)
+	}
+	if len(diff.New) != 1 || diff.New[0].Code != "kaffe" {
+		t.Errorf("Expected coffee as new, got %+v", diff.New)
+	}
+	if len(diff.QuantityChanged) != 1 || diff.QuantityChanged[0].CartQuantity != 3 || diff.QuantityChanged[0].OrderQuantity != 2 {
+		t.Errorf("Expected the milk quantity change, got %+v", diff.QuantityChanged)
+	}
+	if len(diff.PriceChanges) != 1 || diff.PriceChanges[0].Change != SEK(100) {
+		t.Errorf("Expected milk up 1 kr, got %+v", diff.PriceChanges)
+	}
+	if diff.OrderTotal != SEK(18740) || diff.CartTotal != SEK(21550) || diff.TotalDelta != SEK(2810) || diff.PriceEffect != SEK(300) {
+		t.Errorf("Unexpected totals: order %v, cart %v, delta %v, price effect %v", diff.OrderTotal, diff.CartTotal, diff.TotalDelta, diff.PriceEffect)
+	}
+}
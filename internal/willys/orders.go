@@ -0,0 +1,90 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type (
+	OrderEntry struct {
+		Code         string `json:"code"`
+		Name         string `json:"name"`
+		Manufacturer string `json:"manufacturer,omitempty"`
+		Quantity     int    `json:"quantity"`
+		Price        Money  `json:"price"` // unit price paid
+	}
+
+	Order struct {
+		Code     string       `json:"code"`
+		PlacedAt time.Time    `json:"placedAt"`
+		Status   string       `json:"status"`
+		Total    Money        `json:"total"`
+		Entries  []OrderEntry `json:"entries"`
+	}
+
+	orderHistoryResponse struct {
+		Orders []struct {
+			Code          string `json:"code"`
+			Placed        int64  `json:"placed"` // Unix timestamp in ms
+			StatusDisplay string `json:"statusDisplay"`
+			TotalPrice    Money  `json:"totalPrice"`
+			Entries       []struct {
+				Product struct {
+					Code         string `json:"code"`
+					Name         string `json:"name"`
+					Manufacturer string `json:"manufacturer"`
+				} `json:"product"`
+				Quantity  int   `json:"quantity"`
+				BasePrice Money `json:"basePrice"`
+			} `json:"entries"`
+		} `json:"orders"`
+	}
+)
+
+// GetOrderHistory returns the account's past orders with their product lines, newest first.
+func (c *Client) GetOrderHistory(ctx context.Context) ([]Order, error) {
+	resp, err := c.DoRequest(ctx, "GET", EndpointOrderHistory, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, EndpointOrderHistory, "failed to get order history", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, NewAuthenticationError("not authenticated", nil)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, EndpointOrderHistory, "get order history failed")
+	}
+
+	var history orderHistoryResponse
+	if err := c.readJSON(resp, EndpointOrderHistory, &history, "orders", "orders[].code", "orders[].entries"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointOrderHistory, "failed to decode order history", err)
+	}
+
+	orders := make([]Order, 0, len(history.Orders))
+	for _, o := range history.Orders {
+		order := Order{
+			Code:    o.Code,
+			Status:  o.StatusDisplay,
+			Total:   o.TotalPrice,
+			Entries: make([]OrderEntry, 0, len(o.Entries)),
+		}
+		if o.Placed > 0 {
+			order.PlacedAt = time.UnixMilli(o.Placed)
+		}
+		for _, e := range o.Entries {
+			order.Entries = append(order.Entries, OrderEntry{
+				Code:         e.Product.Code,
+				Name:         e.Product.Name,
+				Manufacturer: e.Product.Manufacturer,
+				Quantity:     e.Quantity,
+				Price:        e.BasePrice,
+			})
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
@@ -0,0 +1,36 @@
+package willys
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	OwnBrandOff    = "off"
+	OwnBrandPrefer = "prefer"
+	OwnBrandOnly   = "only"
+)
+
+// ownBrands are Axfood's private labels, matched against the manufacturer and the name.
+var ownBrands = []string{"garant", "eldorado", "fixa"}
+
+func ValidateOwnBrand(mode string) error {
+	switch mode {
+	case "", OwnBrandOff, OwnBrandPrefer, OwnBrandOnly:
+		return nil
+	default:
+		return NewValidationError("own_brand", fmt.Sprintf("unsupported own-brand mode: %s (use 'off', 'prefer', or 'only')", mode))
+	}
+}
+
+// IsOwnBrand reports whether p is sold under one of Axfood's own brands.
+func IsOwnBrand(p Product) bool {
+	manufacturer := strings.ToLower(p.Manufacturer)
+	name := strings.ToLower(p.Name)
+	for _, brand := range ownBrands {
+		if strings.Contains(manufacturer, brand) || strings.HasPrefix(name, brand) {
+			return true
+		}
+	}
+	return false
+}
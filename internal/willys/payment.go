@@ -0,0 +1,67 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	PaymentTypeCard    = "card"
+	PaymentTypeInvoice = "invoice"
+	PaymentTypeSwish   = "swish"
+)
+
+type (
+	SavedCard struct {
+		ID           string `json:"id"`
+		CardType     string `json:"cardType"`
+		MaskedNumber string `json:"maskedNumber"`
+		ExpiryMonth  string `json:"expiryMonth"`
+		ExpiryYear   string `json:"expiryYear"`
+		Default      bool   `json:"defaultCard"`
+		Expired      bool   `json:"expired"`
+	}
+
+	PaymentMethods struct {
+		SavedCards      []SavedCard `json:"savedCards"`
+		InvoiceEligible bool        `json:"invoiceEligible"`
+		// Payment types offered at checkout for this account, e.g. "card", "invoice", "swish"
+		AvailableTypes []string `json:"availablePaymentTypes"`
+		DefaultType    string   `json:"defaultPaymentType"`
+	}
+)
+
+// DefaultCard returns the card that will be charged unless another one is picked at
+// checkout, or nil if no usable card is saved.
+func (p *PaymentMethods) DefaultCard() *SavedCard {
+	for i := range p.SavedCards {
+		if p.SavedCards[i].Default && !p.SavedCards[i].Expired {
+			return &p.SavedCards[i]
+		}
+	}
+	return nil
+}
+
+// GetPaymentMethods lists the saved cards and invoice eligibility of the logged-in account.
+func (c *Client) GetPaymentMethods(ctx context.Context) (*PaymentMethods, error) {
+	resp, err := c.DoRequest(ctx, "GET", EndpointPaymentMethods, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, EndpointPaymentMethods, "failed to get payment methods", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, NewAuthenticationError("not authenticated", nil)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, EndpointPaymentMethods, "get payment methods failed")
+	}
+
+	var methods PaymentMethods
+	if err := c.readJSON(resp, EndpointPaymentMethods, &methods, "savedCards", "invoiceEligible"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, EndpointPaymentMethods, "failed to decode payment methods", err)
+	}
+
+	return &methods, nil
+}
@@ -0,0 +1,84 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// PickupStore is a store offering click-and-collect ("Hämta i butik").
+type PickupStore struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	Address    string  `json:"address,omitempty"`
+	PostalCode string  `json:"postalCode,omitempty"`
+	City       string  `json:"city,omitempty"`
+	DistanceKm float64 `json:"distanceKm,omitempty"`
+}
+
+type storeSearchResponse struct {
+	Results []struct {
+		StoreID string `json:"storeId"`
+		Name    string `json:"name"`
+		Address struct {
+			Line1      string `json:"line1"`
+			PostalCode string `json:"postalCode"`
+			Town       string `json:"town"`
+		} `json:"address"`
+		ClickAndCollect bool    `json:"clickAndCollect"`
+		Distance        float64 `json:"distance"` // km from the searched location
+	} `json:"results"`
+}
+
+// FindPickupStores lists the click-and-collect stores near postalCode, nearest first.
+func (c *Client) FindPickupStores(ctx context.Context, postalCode string) ([]PickupStore, error) {
+	if err := ValidatePostalCode(postalCode); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("q", compactPostalCode(postalCode))
+	params.Set("clickAndCollect", "true")
+	path := fmt.Sprintf("%s?%s", EndpointStoreSearch, params.Encode())
+
+	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, path, "store search request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, path, "store search failed")
+	}
+
+	var result storeSearchResponse
+	if err := c.readJSON(resp, EndpointStoreSearch, &result, "results", "results[].storeId"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse store search", err)
+	}
+
+	stores := make([]PickupStore, 0, len(result.Results))
+	for _, s := range result.Results {
+		if !s.ClickAndCollect {
+			continue
+		}
+		stores = append(stores, PickupStore{
+			ID:         s.StoreID,
+			Name:       s.Name,
+			Address:    s.Address.Line1,
+			PostalCode: compactPostalCode(s.Address.PostalCode),
+			City:       s.Address.Town,
+			DistanceKm: s.Distance,
+		})
+	}
+	return stores, nil
+}
+
+// GetPickupTimeSlots lists the click-and-collect slots of a store.
+func (c *Client) GetPickupTimeSlots(ctx context.Context, storeID string) ([]TimeSlot, error) {
+	if storeID == "" {
+		return nil, NewValidationError("store_id", "cannot be empty")
+	}
+	path := fmt.Sprintf("%s?storeId=%s&b2b=%s", EndpointSlotPickup, url.QueryEscape(storeID), c.b2bParam())
+	return c.fetchTimeSlots(ctx, EndpointSlotPickup, path)
+}
@@ -0,0 +1,157 @@
+package willys
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+)
+
+// Built-in ranker names, accepted as SearchPreferences.SortBy.
+const (
+	RankCheapest        = "cheapest"
+	RankBestValue       = "best_value"
+	RankHighestQuality  = "highest_quality"
+	RankMostSustainable = "most_sustainable"
+)
+
+type (
+	// Ranker orders search results for a SearchPreferences.SortBy name. Register custom
+	// rankers with WithRanker.
+	Ranker interface {
+		// Less reports whether a should be listed before b.
+		Less(a, b Product) bool
+	}
+
+	// RankerFunc adapts a plain comparison function to Ranker.
+	RankerFunc func(a, b Product) bool
+
+	// ValueWeights tune the best_value score: points per krona below 100 kr per unit,
+	// per quality label, and per krona saved on a promotion.
+	ValueWeights struct {
+		UnitPrice     float64  `json:"unitPrice"`
+		QualityLabel  float64  `json:"qualityLabel"`
+		Savings       float64  `json:"savings"`
+		QualityLabels []string `json:"qualityLabels"`
+	}
+
+	valueRanker struct {
+		weights ValueWeights
+	}
+)
+
+func (f RankerFunc) Less(a, b Product) bool {
+	return f(a, b)
+}
+
+func DefaultValueWeights() ValueWeights {
+	return ValueWeights{
+		UnitPrice:     100,
+		QualityLabel:  10,
+		Savings:       0.5,
+		QualityLabels: []string{"krav", "ekologisk", "nyckelhål", "svensk"},
+	}
+}
+
+// Score is higher for better value. Products without a unit price get no price points.
+func (w ValueWeights) Score(p Product) float64 {
+	score := 0.0
+
+	if comparePrice := p.ComparePrice.Float(); comparePrice > 0 {
+		score += w.UnitPrice / comparePrice
+	}
+
+	for _, label := range p.Labels {
+		labelLower := strings.ToLower(label)
+		for _, quality := range w.QualityLabels {
+			if strings.Contains(labelLower, quality) {
+				score += w.QualityLabel
+				break
+			}
+		}
+	}
+
+	if p.SavingsAmount != nil && p.SavingsAmount.Ore > 0 {
+		score += p.SavingsAmount.Float() * w.Savings
+	}
+
+	return score
+}
+
+// NewValueRanker ranks by ValueWeights.Score, highest first.
+func NewValueRanker(weights ValueWeights) Ranker {
+	return valueRanker{weights: weights}
+}
+
+func (r valueRanker) Less(a, b Product) bool {
+	return r.weights.Score(a) > r.weights.Score(b)
+}
+
+func defaultRankers() map[string]Ranker {
+	return map[string]Ranker{
+		RankCheapest: RankerFunc(func(a, b Product) bool {
+			return a.ComparePrice.Float() < b.ComparePrice.Float()
+		}),
+		RankBestValue: NewValueRanker(DefaultValueWeights()),
+		RankHighestQuality: RankerFunc(func(a, b Product) bool {
+			if len(a.Labels) != len(b.Labels) {
+				return len(a.Labels) > len(b.Labels)
+			}
+			return a.ComparePrice.Float() < b.ComparePrice.Float()
+		}),
+		RankMostSustainable: RankerFunc(func(a, b Product) bool {
+			if as, bs := sustainabilityScore(a), sustainabilityScore(b); as != bs {
+				return as > bs
+			}
+			return a.ComparePrice.Float() < b.ComparePrice.Float()
+		}),
+	}
+}
+
+// WithRanker registers r under name, making it available as sort_by. Registering a
+// built-in name replaces it, e.g. best_value with different ValueWeights.
+func WithRanker(name string, r Ranker) ClientOption {
+	return func(c *Client) {
+		c.rankers[name] = r
+	}
+}
+
+// RankerNames lists the sort_by values this client understands.
+func (c *Client) RankerNames() []string {
+	names := make([]string, 0, len(c.rankers))
+	for name := range c.rankers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortProducts orders products by the ranker named in prefs.SortBy, keeping the API's
+// order for unknown names and ties. Own brands go first when preferred.
+func (c *Client) sortProducts(ctx context.Context, products []Product, prefs *SearchPreferences) []Product {
+	ranker := c.rankers[prefs.SortBy]
+	if ranker == nil && prefs.OwnBrand != OwnBrandPrefer {
+		return products
+	}
+	if preparer, ok := ranker.(Preparer); ok {
+		if err := preparer.Prepare(ctx); err != nil {
+			log.Printf("Ranker %s could not load its data, ranking without it: %v", prefs.SortBy, err)
+		}
+	}
+
+	sort.SliceStable(products, func(i, j int) bool {
+		pi, pj := products[i], products[j]
+
+		if prefs.OwnBrand == OwnBrandPrefer {
+			if iOwn, jOwn := IsOwnBrand(pi), IsOwnBrand(pj); iOwn != jOwn {
+				return iOwn
+			}
+		}
+		if ranker == nil {
+			return false
+		}
+		return ranker.Less(pi, pj)
+	})
+
+	return products
+}
@@ -0,0 +1,82 @@
+package willys
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func productCodes(products []Product) []string {
+	result := make([]string, len(products))
+	for i, p := range products {
+		result[i] = p.Code
+	}
+	return result
+}
+
+func TestSortProducts(t *testing.T) {
+	products := func() []Product {
+		return []Product{
+			{Code: "a", ComparePrice: SEK(3000), Labels: []string{"krav"}},
+			{Code: "b", ComparePrice: SEK(1000)},
+			{Code: "c", ComparePrice: SEK(2000), Labels: []string{"krav", "nyckelhål"}},
+		}
+	}
+
+	client, err := NewClient("https://www.willys.se", "", "",
+		WithRanker("code_desc", RankerFunc(func(a, b Product) bool { return a.Code > b.Code })),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		sortBy   string
+		expected string
+	}{
+		{RankCheapest, "b,c,a"},
+		{RankHighestQuality, "c,a,b"},
+		{RankBestValue, "c,a,b"},
+		{"code_desc", "c,b,a"},
+		{"unknown", "a,b,c"},
+	}
+	for _, tt := range tests {
+		got := productCodes(client.sortProducts(context.Background(), products(), &SearchPreferences{SortBy: tt.sortBy}))
+		if joined := strings.Join(got, ","); joined != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.sortBy, tt.expected, joined)
+		}
+	}
+
+	// Without label points, unit price alone decides.
+	client, _ = NewClient("https://www.willys.se", "", "",
+		WithRanker(RankBestValue, NewValueRanker(ValueWeights{UnitPrice: 100})),
+	)
+	if got := strings.Join(productCodes(client.sortProducts(context.Background(), products(), &SearchPreferences{SortBy: RankBestValue})), ","); got != "b,c,a" {
+		t.Errorf("Expected custom weights to rank b,c,a, got %s", got)
+	}
+}
+
+func TestHistoryWeightedRanker(t *testing.T) {
+	client, err := NewClient("https://www.willys.se", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	client.purchaseHistory.load = func(ctx context.Context) ([]Order, error) {
+		return []Order{{Entries: []OrderEntry{
+			{Code: "arla", Manufacturer: "Arla Ko", Quantity: 2},
+			{Code: "skanemejerier-fil", Manufacturer: "Skånemejerier", Quantity: 6},
+		}}}, nil
+	}
+
+	products := []Product{
+		{Code: "garant", Manufacturer: "Garant", ComparePrice: SEK(1100)},
+		{Code: "skanemejerier", Manufacturer: "Skånemejerier", ComparePrice: SEK(1500)},
+		{Code: "arla", Manufacturer: "Arla Ko", ComparePrice: SEK(1700)},
+		{Code: "eldorado", Manufacturer: "Eldorado", ComparePrice: SEK(1000)},
+	}
+
+	sorted := client.sortProducts(context.Background(), products, &SearchPreferences{SortBy: RankHistoryWeighted})
+	if got := strings.Join(productCodes(sorted), ","); got != "arla,skanemejerier,eldorado,garant" {
+		t.Errorf("Expected bought product, then bought brand, then cheapest, got %s", got)
+	}
+}
@@ -0,0 +1,37 @@
+package willys
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// ReauthEvent is reported when a request hits an expired session and the client logs
+	// in again before retrying it.
+	ReauthEvent struct {
+		Method   string        `json:"method"`
+		Endpoint string        `json:"endpoint"`
+		Attempt  int           `json:"attempt"`
+		Duration time.Duration `json:"duration"`
+		Err      error         `json:"-"`
+	}
+
+	ReauthNotifier func(ReauthEvent)
+
+	reauthNotifierKey struct{}
+)
+
+// WithReauthNotifier attaches a callback that is invoked whenever a request made with ctx
+// triggers a silent re-login.
+func WithReauthNotifier(ctx context.Context, notify ReauthNotifier) context.Context {
+	return context.WithValue(ctx, reauthNotifierKey{}, notify)
+}
+
+func notifyReauth(ctx context.Context, event ReauthEvent) {
+	if ctx == nil {
+		return
+	}
+	if notify, ok := ctx.Value(reauthNotifierKey{}).(ReauthNotifier); ok && notify != nil {
+		notify(event)
+	}
+}
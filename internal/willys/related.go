@@ -0,0 +1,57 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// relatedProductsSize is how many recommendations GetRelatedProducts asks for.
+const relatedProductsSize = 20
+
+// GetRelatedProducts lists what other customers bought together with productCode, from
+// Willys' "others also bought" recommendations. A product without recommendations yields
+// an empty list.
+func (c *Client) GetRelatedProducts(ctx context.Context, productCode string) ([]Product, error) {
+	if err := ValidateProductCode(productCode); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("productCode", productCode)
+	params.Set("size", fmt.Sprintf("%d", relatedProductsSize))
+	path := fmt.Sprintf("%s?%s", EndpointRelatedProducts, params.Encode())
+
+	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, path, "related products request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		discard(resp)
+		return []Product{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, path, "get related products failed")
+	}
+
+	var listing struct {
+		Results []Product `json:"results"`
+	}
+	if err := c.readJSON(resp, EndpointRelatedProducts, &listing, "results", "results[].code", "results[].name", "results[].priceValue"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse related products", err)
+	}
+
+	products := make([]Product, 0, len(listing.Results))
+	for _, p := range listing.Results {
+		if p.Code == productCode {
+			continue
+		}
+		p.Sustainability = ParseSustainability(p.Labels)
+		p.StockStatus = ParseStockStatus(p.OutOfStock, p.LowStock, p.StockQuantity)
+		products = append(products, p)
+	}
+	return products, nil
+}
@@ -0,0 +1,52 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetRelatedProducts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointRelatedProducts:
+			if r.URL.Query().Get("productCode") != "101233933_ST" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"results": [
+				{"code": "101233933_ST", "name": "Tacoskal", "priceValue": 22.9},
+				{"code": "101290455_ST", "name": "Taco Sauce Medium", "priceValue": 24.5, "outOfStock": true}
+			]}`))
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	products, err := client.GetRelatedProducts(context.Background(), "101233933_ST")
+	if err != nil {
+		t.Fatalf("GetRelatedProducts failed: %v", err)
+	}
+	if len(products) != 1 || products[0].Code != "101290455_ST" {
+		t.Fatalf("Expected the product itself to be left out, got %+v", products)
+	}
+	if products[0].StockStatus != StockOutOfStock {
+		t.Errorf("Expected stock status to be derived, got %q", products[0].StockStatus)
+	}
+
+	products, err = client.GetRelatedProducts(context.Background(), "101000000_ST")
+	if err != nil || len(products) != 0 {
+		t.Errorf("Expected no recommendations for an unknown product, got %v (%v)", products, err)
+	}
+
+	if _, err := client.GetRelatedProducts(context.Background(), "taco"); err == nil {
+		t.Error("Expected an invalid product code to be rejected")
+	}
+}
@@ -0,0 +1,272 @@
+package willys
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultResponseCacheBytes bounds the response cache unless WithResponseCache says otherwise.
+const DefaultResponseCacheBytes = 4 << 20
+
+// responseCacheTTLs are the endpoints whose GET responses are cached, and for how long.
+// Their answers depend only on the path: a postal code or a product code. Slots sell
+// out, so they are kept briefly; which store serves a postal code rarely changes, and
+// product details (ingredients, nutrition) hardly ever do.
+var responseCacheTTLs = map[string]time.Duration{
+	EndpointShippingDelivery: 10 * time.Minute,
+	EndpointSlotHomeDelivery: time.Minute,
+	EndpointProduct:          30 * time.Minute,
+}
+
+type (
+	// ResponseCacheStats describes the response cache for admin_cache_stats.
+	ResponseCacheStats struct {
+		Entries   int               `json:"entries"`
+		Bytes     int64             `json:"bytes"`
+		MaxBytes  int64             `json:"maxBytes"`
+		Hits      uint64            `json:"hits"`
+		Misses    uint64            `json:"misses"`
+		Evictions uint64            `json:"evictions"`
+		TTLs      map[string]string `json:"ttls"`
+	}
+
+	// responseCache is an LRU of GET response bodies bounded by their total size. Entries
+	// expire after their endpoint's TTL and are dropped when a request that may change
+	// them (selecting or releasing a slot) goes out.
+	responseCache struct {
+		mu       sync.Mutex
+		maxBytes int64
+		bytes    int64
+		lru      *list.List // of *cachedResponse, most recently used first
+		entries  map[string]*list.Element
+
+		hits, misses, evictions uint64
+	}
+
+	cachedResponse struct {
+		key        string
+		endpoint   string
+		postalCode string
+		header     http.Header
+		body       []byte
+		expires    time.Time
+	}
+)
+
+func newResponseCache(maxBytes int64) *responseCache {
+	return &responseCache{
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// WithResponseCache caches deliverability and slot lookups in up to maxBytes of memory.
+// Zero turns the cache off.
+func WithResponseCache(maxBytes int64) ClientOption {
+	return func(c *Client) {
+		c.respCache = nil
+		if maxBytes > 0 {
+			c.respCache = newResponseCache(maxBytes)
+		}
+	}
+}
+
+type freshResponsesKey struct{}
+
+// WithFreshResponses makes requests made with ctx skip cached responses, for callers
+// such as the slot auto-booker that must see a change as soon as it happens. The fresh
+// responses are still cached for everyone else.
+func WithFreshResponses(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshResponsesKey{}, true)
+}
+
+func wantsFreshResponses(ctx context.Context) bool {
+	fresh, _ := ctx.Value(freshResponsesKey{}).(bool)
+	return fresh
+}
+
+// cachedEndpoint finds the cached endpoint a path belongs to, like endpointGroup.
+func cachedEndpoint(path string) (string, time.Duration) {
+	endpoint, _, _ := strings.Cut(path, "?")
+	for endpoint != "" {
+		if ttl, ok := responseCacheTTLs[endpoint]; ok {
+			return endpoint, ttl
+		}
+		i := strings.LastIndex(endpoint, "/")
+		if i < 0 {
+			break
+		}
+		endpoint = endpoint[:i]
+	}
+	return "", 0
+}
+
+// pathPostalCode finds the postal code a cached path is for, without spaces: the
+// postalCode query parameter or a path segment that is one.
+func pathPostalCode(path string) string {
+	endpoint, query, _ := strings.Cut(path, "?")
+	if values, err := url.ParseQuery(query); err == nil && values.Get("postalCode") != "" {
+		return compactPostalCode(values.Get("postalCode"))
+	}
+	for _, segment := range strings.Split(endpoint, "/") {
+		if segment, err := url.PathUnescape(segment); err == nil && segment != "" && ValidatePostalCode(segment) == nil {
+			return compactPostalCode(segment)
+		}
+	}
+	return ""
+}
+
+func compactPostalCode(postalCode string) string {
+	return strings.ReplaceAll(postalCode, " ", "")
+}
+
+// get returns a fresh response for the cached path, if any.
+func (rc *responseCache) get(path string, now time.Time) (*http.Response, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	elem, ok := rc.entries[path]
+	if !ok {
+		rc.misses++
+		return nil, false
+	}
+	entry := elem.Value.(*cachedResponse)
+	if !now.Before(entry.expires) {
+		rc.remove(elem)
+		rc.misses++
+		return nil, false
+	}
+	rc.lru.MoveToFront(elem)
+	rc.hits++
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(entry.body)),
+		ContentLength: int64(len(entry.body)),
+	}, true
+}
+
+// store caches resp for path if it is a complete 200 response that fits, and gives the
+// caller a response whose body can still be read.
+func (rc *responseCache) store(path, endpoint string, ttl time.Duration, resp *http.Response, now time.Time) {
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, rc.maxBytes+1))
+	if err != nil || int64(len(body)) > rc.maxBytes {
+		// Hand back what was read followed by the rest, and leave the cache alone
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cachedResponse{
+		key:        path,
+		endpoint:   endpoint,
+		postalCode: pathPostalCode(path),
+		header:     resp.Header.Clone(),
+		body:       body,
+		expires:    now.Add(ttl),
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if elem, ok := rc.entries[path]; ok {
+		rc.remove(elem)
+	}
+	rc.entries[path] = rc.lru.PushFront(entry)
+	rc.bytes += entry.size()
+	for rc.bytes > rc.maxBytes {
+		rc.remove(rc.lru.Back())
+		rc.evictions++
+	}
+}
+
+// invalidate drops the entries for postalCode and endpoint; an empty value matches any.
+// It returns how many entries were dropped.
+func (rc *responseCache) invalidate(postalCode, endpoint string) int {
+	postalCode = compactPostalCode(postalCode)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	dropped := 0
+	for elem := rc.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*cachedResponse)
+		if (postalCode == "" || entry.postalCode == postalCode) && (endpoint == "" || entry.endpoint == endpoint) {
+			rc.remove(elem)
+			dropped++
+		}
+		elem = next
+	}
+	return dropped
+}
+
+func (rc *responseCache) stats() ResponseCacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	ttls := make(map[string]string, len(responseCacheTTLs))
+	for endpoint, ttl := range responseCacheTTLs {
+		ttls[endpoint] = ttl.String()
+	}
+	return ResponseCacheStats{
+		Entries:   rc.lru.Len(),
+		Bytes:     rc.bytes,
+		MaxBytes:  rc.maxBytes,
+		Hits:      rc.hits,
+		Misses:    rc.misses,
+		Evictions: rc.evictions,
+		TTLs:      ttls,
+	}
+}
+
+// remove drops elem; callers hold mu.
+func (rc *responseCache) remove(elem *list.Element) {
+	entry := rc.lru.Remove(elem).(*cachedResponse)
+	delete(rc.entries, entry.key)
+	rc.bytes -= entry.size()
+}
+
+// size approximates the memory an entry holds.
+func (e *cachedResponse) size() int64 {
+	n := len(e.key) + len(e.body)
+	for k, values := range e.header {
+		n += len(k)
+		for _, v := range values {
+			n += len(v)
+		}
+	}
+	return int64(n)
+}
+
+// ResponseCacheStats reports the response cache's size and hit rate; the zero value
+// means the cache is off.
+func (c *Client) ResponseCacheStats() ResponseCacheStats {
+	if c.respCache == nil {
+		return ResponseCacheStats{}
+	}
+	return c.respCache.stats()
+}
+
+// InvalidateResponseCache drops cached responses for postalCode and endpoint, where an
+// empty value matches any, and returns how many were dropped.
+func (c *Client) InvalidateResponseCache(postalCode, endpoint string) int {
+	if c.respCache == nil {
+		return 0
+	}
+	return c.respCache.invalidate(postalCode, endpoint)
+}
@@ -0,0 +1,142 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	slotFetches := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case r.URL.Path == EndpointSlotHomeDelivery:
+			slotFetches[r.URL.Query().Get("postalCode")]++
+			w.Write([]byte(`{"slots": [{"code": "s1", "startTime": 1790000000000, "endTime": 1790003600000, "available": true}]}`))
+		case strings.HasPrefix(r.URL.Path, EndpointSlotInCart):
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	fetch := func(ctx context.Context, postalCode string) {
+		t.Helper()
+		if slots, err := client.GetAvailableTimeSlots(ctx, postalCode); err != nil || len(slots) != 1 {
+			t.Fatalf("GetAvailableTimeSlots(%s) = %v, %v", postalCode, slots, err)
+		}
+	}
+
+	fetch(ctx, "11151")
+	fetch(ctx, "11151")
+	fetch(ctx, "41301")
+	if slotFetches["11151"] != 1 || slotFetches["41301"] != 1 {
+		t.Errorf("Expected one upstream fetch per postal code, got %v", slotFetches)
+	}
+
+	fetch(WithFreshResponses(ctx), "11151")
+	if slotFetches["11151"] != 2 {
+		t.Errorf("Expected WithFreshResponses to skip the cache, got %d fetches", slotFetches["11151"])
+	}
+
+	if n := client.InvalidateResponseCache("111 51", ""); n != 1 {
+		t.Errorf("Expected one entry dropped for 111 51, got %d", n)
+	}
+	fetch(ctx, "11151")
+	if slotFetches["11151"] != 3 {
+		t.Errorf("Expected a fetch after invalidation, got %d", slotFetches["11151"])
+	}
+
+	if err := client.ReleaseTimeSlot(ctx); err != nil {
+		t.Fatalf("ReleaseTimeSlot failed: %v", err)
+	}
+	fetch(ctx, "41301")
+	if slotFetches["41301"] != 2 {
+		t.Errorf("Expected releasing a slot to drop cached slots, got %d fetches", slotFetches["41301"])
+	}
+
+	stats := client.ResponseCacheStats()
+	if stats.Entries != 1 || stats.Hits != 1 || stats.Bytes <= 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestResponseCacheSlotLookupDuringRelease(t *testing.T) {
+	var client *Client
+	slotFetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case r.URL.Path == EndpointSlotHomeDelivery:
+			slotFetches++
+			w.Write([]byte(`{"slots": [{"code": "s1", "startTime": 1790000000000, "endTime": 1790003600000, "available": true}]}`))
+		case strings.HasPrefix(r.URL.Path, EndpointSlotInCart):
+			// A lookup answered while the slot is being released caches the old slots
+			if _, err := client.GetAvailableTimeSlots(context.Background(), "11151"); err != nil {
+				t.Errorf("GetAvailableTimeSlots failed: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	var err error
+	if client, err = NewClient(srv.URL, "", ""); err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	ctx := context.Background()
+	if err := client.ReleaseTimeSlot(ctx); err != nil {
+		t.Fatalf("ReleaseTimeSlot failed: %v", err)
+	}
+	if _, err := client.GetAvailableTimeSlots(ctx, "11151"); err != nil {
+		t.Fatalf("GetAvailableTimeSlots failed: %v", err)
+	}
+	if slotFetches != 2 {
+		t.Errorf("Expected slots fetched again after the release, got %d fetches", slotFetches)
+	}
+}
+
+func TestResponseCacheEviction(t *testing.T) {
+	cache := newResponseCache(300)
+	now := time.Now()
+	for i := range 3 {
+		path := fmt.Sprintf("%s?postalCode=1115%d", EndpointSlotHomeDelivery, i)
+		resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+		resp.Body = readCloser{strings.NewReader(strings.Repeat("x", 100)), http.NoBody}
+		cache.store(path, EndpointSlotHomeDelivery, time.Minute, resp, now)
+	}
+	if _, ok := cache.get(EndpointSlotHomeDelivery+"?postalCode=11150", now); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok := cache.get(EndpointSlotHomeDelivery+"?postalCode=11152", now); !ok {
+		t.Error("Expected the newest entry to be cached")
+	}
+	if _, ok := cache.get(EndpointSlotHomeDelivery+"?postalCode=11152", now.Add(time.Minute)); ok {
+		t.Error("Expected the entry to expire after its TTL")
+	}
+	if stats := cache.stats(); stats.Bytes > stats.MaxBytes || stats.Evictions == 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	big := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	big.Body = readCloser{strings.NewReader(strings.Repeat("y", 400)), http.NoBody}
+	cache.store(EndpointSlotHomeDelivery+"?postalCode=11159", EndpointSlotHomeDelivery, time.Minute, big, now)
+	if body, err := io.ReadAll(big.Body); err != nil || len(body) != 400 {
+		t.Errorf("Expected an oversized body to be passed through whole, got %d bytes (%v)", len(body), err)
+	}
+	if _, ok := cache.get(EndpointSlotHomeDelivery+"?postalCode=11159", now); ok {
+		t.Error("Expected an oversized body not to be cached")
+	}
+}
@@ -1,46 +1,100 @@
 package willys
 
 import (
+	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
+	"log"
+	"mime"
 	"net/http"
 	"net/url"
-	"sort"
-	"strconv"
 	"strings"
+	"unicode"
 )
 
 type (
 	Product struct {
 		Code             string   `json:"code"`
 		Name             string   `json:"name"`
-		PriceValue       float64  `json:"priceValue"`
-		Price            string   `json:"price"`
-		ComparePrice     string   `json:"comparePrice"`
+		PriceValue       Money    `json:"priceValue"`
+		Price            Money    `json:"price"`        // Willys sends this formatted, e.g. "15,90 kr"
+		ComparePrice     Money    `json:"comparePrice"` // per ComparePriceUnit
 		ComparePriceUnit string   `json:"comparePriceUnit"`
 		DisplayVolume    string   `json:"displayVolume"`
 		Manufacturer     string   `json:"manufacturer"`
 		Labels           []string `json:"labels"`
 		Online           bool     `json:"online"`
 		OutOfStock       bool     `json:"outOfStock"`
-		SavingsAmount    *float64 `json:"savingsAmount"`
+		LowStock         bool     `json:"lowStock,omitempty"`
+		StockQuantity    *int     `json:"stockQuantity,omitempty"` // store quantity, when exposed
+		StockStatus      string   `json:"stockStatus,omitempty"`   // derived: "in_stock" | "low_stock" | "out_of_stock"
+		SavingsAmount    *Money   `json:"savingsAmount"`
 		Image            struct {
 			URL string `json:"url"`
 		} `json:"image"`
+		Sustainability *Sustainability `json:"sustainability,omitempty"`
+	}
+
+	// CompactProduct is the projection of Product used when tool output is set to
+	// compact, keeping only what an agent needs to choose and add a product.
+	CompactProduct struct {
+		Code          string `json:"code"`
+		Name          string `json:"name"`
+		Price         Money  `json:"price"`
+		UnitPrice     *Money `json:"unitPrice,omitempty"`
+		UnitPriceUnit string `json:"unitPriceUnit,omitempty"` // e.g. "l" for a price per litre
 	}
 
 	SearchPreferences struct {
-		PriceSensitivity string   `json:"price_sensitivity"` // "cheapest" | "balanced" | "quality"
-		MaxPricePerUnit  float64  `json:"max_price_per_unit"`
-		RequiredLabels   []string `json:"required_labels"`
-		PreferredLabels  []string `json:"preferred_labels"`
-		SortBy           string   `json:"sort_by"` // "cheapest" | "best_value" | "highest_quality"
+		PriceSensitivity  string   `json:"price_sensitivity"` // "cheapest" | "balanced" | "quality"
+		MaxPricePerUnit   float64  `json:"max_price_per_unit"`
+		RequiredLabels    []string `json:"required_labels"`
+		PreferredLabels   []string `json:"preferred_labels"`
+		SortBy            string   `json:"sort_by"` // "cheapest" | "best_value" | "highest_quality" | "most_sustainable"
+		MinSustainability int      `json:"min_sustainability"`
+		OwnBrand          string   `json:"own_brand"` // "off" | "prefer" | "only"
 	}
 )
 
+const (
+	OutputDetailFull    = "full"
+	OutputDetailCompact = "compact"
+)
+
+func ValidateOutputDetail(detail string) error {
+	switch detail {
+	case OutputDetailFull, OutputDetailCompact:
+		return nil
+	default:
+		return NewValidationError("output_detail", fmt.Sprintf("unsupported output detail: %s (use 'full' or 'compact')", detail))
+	}
+}
+
+func (p Product) Compact() CompactProduct {
+	compact := CompactProduct{
+		Code:  p.Code,
+		Name:  p.Name,
+		Price: p.PriceValue,
+	}
+	if !p.ComparePrice.IsZero() {
+		unitPrice := p.ComparePrice
+		compact.UnitPrice = &unitPrice
+		compact.UnitPriceUnit = p.ComparePriceUnit
+	}
+	return compact
+}
+
 func (c *Client) SearchProducts(ctx context.Context, query string, page, size int, prefs *SearchPreferences) ([]Product, error) {
+	result, err := c.SearchWithFacets(ctx, query, page, size, prefs)
+	if err != nil {
+		return nil, err
+	}
+	return result.Products, nil
+}
+
+// SearchWithFacets is SearchProducts that also returns the query's facets. Preferences
+// filter the products only; facet counts are as reported by Willys.
+func (c *Client) SearchWithFacets(ctx context.Context, query string, page, size int, prefs *SearchPreferences) (*SearchResult, error) {
 	if query == "" {
 		return nil, NewValidationError("query", "search query cannot be empty")
 	}
@@ -56,38 +110,98 @@ func (c *Client) SearchProducts(ctx context.Context, query string, page, size in
 	params.Set("page", fmt.Sprintf("%d", page))
 	params.Set("size", fmt.Sprintf("%d", size))
 
-	searchPath := fmt.Sprintf("%s?%s", EndpointSearch, params.Encode())
+	result, err := c.searchResults(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	products := result.Products
+	for i := range products {
+		products[i].Sustainability = ParseSustainability(products[i].Labels)
+		products[i].StockStatus = ParseStockStatus(products[i].OutOfStock, products[i].LowStock, products[i].StockQuantity)
+	}
+
+	if prefs != nil {
+		products = c.filterProducts(products, prefs)
+		products = c.sortProducts(ctx, products, prefs)
+	}
+	result.Products = products
+
+	return result, nil
+}
+
+// searchResults fetches one page of results. /search normally answers with JSON, but
+// depending on headers and cookies Willys can serve the server-rendered page instead. The
+// client then switches to the REST endpoint behind it, and stays there.
+func (c *Client) searchResults(ctx context.Context, params url.Values) (*SearchResult, error) {
+	if !c.restSearch.Load() {
+		result, isHTML, err := c.fetchSearch(ctx, EndpointSearch, params)
+		if !isHTML {
+			return result, err
+		}
+		if !c.restSearch.Swap(true) {
+			log.Printf("%s returned an HTML page, switching to %s", EndpointSearch, EndpointSearchREST)
+		}
+	}
+
+	result, isHTML, err := c.fetchSearch(ctx, EndpointSearchREST, params)
+	if isHTML {
+		return nil, newAPIError(ctx, http.StatusOK, EndpointSearchREST, "search returned an HTML page instead of JSON", nil)
+	}
+	return result, err
+}
+
+func (c *Client) fetchSearch(ctx context.Context, endpoint string, params url.Values) (result *SearchResult, isHTML bool, err error) {
+	searchPath := fmt.Sprintf("%s?%s", endpoint, params.Encode())
 
 	resp, err := c.DoRequest(ctx, "GET", searchPath, nil, false)
 	if err != nil {
-		return nil, NewAPIError(0, searchPath, "search request failed", err)
+		return nil, false, newAPIError(ctx, 0, searchPath, "search request failed", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, NewAPIError(resp.StatusCode, searchPath, "search failed", nil)
+		return nil, false, responseError(ctx, resp, searchPath, "search failed")
 	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, NewAPIError(resp.StatusCode, searchPath, "failed to read search response", err)
+	if isHTMLResponse(resp) {
+		discard(resp)
+		return nil, true, nil
 	}
 
 	var searchResponse struct {
-		Results []Product `json:"results"`
+		Results []Product         `json:"results"`
+		Facets  []searchFacetJSON `json:"facets"`
 	}
-	if err := json.Unmarshal(body, &searchResponse); err != nil {
-		return nil, NewAPIError(resp.StatusCode, searchPath, "failed to parse search results", err)
+	if err := c.readJSON(resp, endpoint, &searchResponse, "results", "results[].code", "results[].name", "results[].priceValue"); err != nil {
+		return nil, false, newAPIError(ctx, resp.StatusCode, searchPath, "failed to parse search results", err)
 	}
+	return &SearchResult{Products: searchResponse.Results, Facets: parseFacets(searchResponse.Facets)}, false, nil
+}
 
-	products := searchResponse.Results
-
-	if prefs != nil {
-		products = c.filterProducts(products, prefs)
-		products = c.sortProducts(products, prefs)
+// isHTMLResponse reports whether resp is a web page rather than an API response, going by
+// the Content-Type or, when that is missing or generic, the first byte of the body.
+func isHTMLResponse(resp *http.Response) bool {
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		switch {
+		case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+			return true
+		case strings.HasSuffix(mediaType, "json"):
+			return false
+		}
 	}
 
-	return products, nil
+	body := bufio.NewReader(resp.Body)
+	resp.Body = readCloser{body, resp.Body}
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return false
+		}
+		if !unicode.IsSpace(rune(b)) {
+			_ = body.UnreadByte()
+			return b == '<'
+		}
+	}
 }
 
 func (c *Client) filterProducts(products []Product, prefs *SearchPreferences) []Product {
@@ -99,13 +213,21 @@ func (c *Client) filterProducts(products []Product, prefs *SearchPreferences) []
 	}
 
 	for _, p := range products {
+		if prefs.OwnBrand == OwnBrandOnly && !IsOwnBrand(p) {
+			continue
+		}
+
 		if prefs.MaxPricePerUnit > 0 {
-			comparePrice := parseComparePriceToFloat(p.ComparePrice)
+			comparePrice := p.ComparePrice.Float()
 			if comparePrice > prefs.MaxPricePerUnit {
 				continue
 			}
 		}
 
+		if prefs.MinSustainability > 0 && sustainabilityScore(p) < prefs.MinSustainability {
+			continue
+		}
+
 		if len(lowercaseRequired) > 0 {
 			productLabelsLower := make([]string, len(p.Labels))
 			for i, label := range p.Labels {
@@ -136,71 +258,3 @@ func (c *Client) filterProducts(products []Product, prefs *SearchPreferences) []
 
 	return filtered
 }
-
-func parseComparePriceToFloat(priceStr string) float64 {
-	priceStr = strings.TrimSuffix(priceStr, " kr")
-	priceStr = strings.ReplaceAll(priceStr, ",", ".")
-	price, _ := strconv.ParseFloat(priceStr, 64)
-	return price
-}
-
-func (c *Client) sortProducts(products []Product, prefs *SearchPreferences) []Product {
-	sort.Slice(products, func(i, j int) bool {
-		pi, pj := products[i], products[j]
-
-		switch prefs.SortBy {
-		case "cheapest":
-			iPrice := parseComparePriceToFloat(pi.ComparePrice)
-			jPrice := parseComparePriceToFloat(pj.ComparePrice)
-			return iPrice < jPrice
-
-		case "best_value":
-
-			iScore := c.calculateValueScore(pi)
-			jScore := c.calculateValueScore(pj)
-			return iScore > jScore
-
-		case "highest_quality":
-			iLabels := len(pi.Labels)
-			jLabels := len(pj.Labels)
-			if iLabels != jLabels {
-				return iLabels > jLabels
-			}
-			iPrice := parseComparePriceToFloat(pi.ComparePrice)
-			jPrice := parseComparePriceToFloat(pj.ComparePrice)
-			return iPrice < jPrice
-
-		default:
-
-			return false
-		}
-	})
-
-	return products
-}
-
-func (c *Client) calculateValueScore(p Product) float64 {
-	score := 0.0
-
-	comparePrice := parseComparePriceToFloat(p.ComparePrice)
-	if comparePrice > 0 {
-		score += 100.0 / comparePrice
-	}
-
-	qualityLabels := []string{"krav", "ekologisk", "nyckelhål", "svensk"}
-	for _, label := range p.Labels {
-		labelLower := strings.ToLower(label)
-		for _, quality := range qualityLabels {
-			if strings.Contains(labelLower, quality) {
-				score += 10.0
-				break
-			}
-		}
-	}
-
-	if p.SavingsAmount != nil && *p.SavingsAmount > 0 {
-		score += *p.SavingsAmount * 0.5
-	}
-
-	return score
-}
@@ -0,0 +1,91 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const searchJSON = `{"results": [{"code": "101_ST", "name": "Mjölk", "priceValue": 14.9}]}`
+
+func TestSearchProductsJSON(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == EndpointCSRFToken {
+			w.Write([]byte(`"token"`))
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(searchJSON))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	products, err := client.SearchProducts(context.Background(), "mjölk", 0, 10, nil)
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(products) != 1 || products[0].Code != "101_ST" {
+		t.Errorf("Expected one product, got %+v", products)
+	}
+	if len(paths) != 1 || paths[0] != EndpointSearch {
+		t.Errorf("Expected a single request to %s, got %v", EndpointSearch, paths)
+	}
+}
+
+func TestSearchProductsHTMLFallsBackToREST(t *testing.T) {
+	for name, contentType := range map[string]string{"labelled": "text/html; charset=utf-8", "unlabelled": ""} {
+		t.Run(name, func(t *testing.T) {
+			var paths []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case EndpointCSRFToken:
+					w.Write([]byte(`"token"`))
+				case EndpointSearch:
+					paths = append(paths, r.URL.Path)
+					w.Header().Set("Content-Type", contentType)
+					w.Write([]byte("\n<!DOCTYPE html><html><body>Sök</body></html>"))
+				case EndpointSearchREST:
+					paths = append(paths, r.URL.Path)
+					if r.URL.Query().Get("q") != "mjölk" || r.URL.Query().Get("size") != "10" {
+						t.Errorf("Expected the query to be passed on, got %s", r.URL.RawQuery)
+					}
+					w.Write([]byte(searchJSON))
+				}
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL, "", "")
+			if err != nil {
+				t.Fatalf("Failed to create client: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				products, err := client.SearchProducts(context.Background(), "mjölk", 0, 10, nil)
+				if err != nil {
+					t.Fatalf("Search failed: %v", err)
+				}
+				if len(products) != 1 || products[0].Name != "Mjölk" {
+					t.Errorf("Expected one product, got %+v", products)
+				}
+			}
+
+			want := []string{EndpointSearch, EndpointSearchREST, EndpointSearchREST}
+			if len(paths) != len(want) {
+				t.Fatalf("Expected requests to %v, got %v", want, paths)
+			}
+			for i := range want {
+				if paths[i] != want[i] {
+					t.Errorf("Expected requests to %v, got %v", want, paths)
+					break
+				}
+			}
+		})
+	}
+}
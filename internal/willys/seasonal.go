@@ -0,0 +1,95 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// newProductsPageSize is how many items GetNewProducts asks for; the category is small.
+const newProductsPageSize = 50
+
+type (
+	// Season is a recurring Swedish food season and the searches that find its products.
+	// From and To are inclusive month-days; a season may wrap around new year.
+	Season struct {
+		Name    string   `json:"name"`
+		Queries []string `json:"queries"`
+		From    MonthDay `json:"-"`
+		To      MonthDay `json:"-"`
+	}
+
+	MonthDay struct {
+		Month time.Month
+		Day   int
+	}
+)
+
+// Seasons is a rough calendar; Easter moves, so påsk covers its whole possible range.
+var Seasons = []Season{
+	{"Semlor", []string{"semla", "mandelmassa"}, MonthDay{time.January, 1}, MonthDay{time.February, 28}},
+	{"Påsk", []string{"påskmust", "påskägg", "ägg"}, MonthDay{time.March, 15}, MonthDay{time.April, 25}},
+	{"Valborg", []string{"grillkorv"}, MonthDay{time.April, 20}, MonthDay{time.April, 30}},
+	{"Midsommar", []string{"sill", "jordgubbar", "färskpotatis"}, MonthDay{time.June, 1}, MonthDay{time.June, 26}},
+	{"Kräftskiva", []string{"kräftor", "västerbottensost"}, MonthDay{time.August, 1}, MonthDay{time.September, 10}},
+	{"Surströmming", []string{"surströmming", "tunnbröd"}, MonthDay{time.August, 15}, MonthDay{time.September, 15}},
+	{"Kanelbullens dag", []string{"kanelbullar"}, MonthDay{time.September, 25}, MonthDay{time.October, 4}},
+	{"Lucia", []string{"lussekatter", "saffran", "pepparkakor"}, MonthDay{time.November, 25}, MonthDay{time.December, 13}},
+	{"Jul", []string{"julmust", "julskinka", "glögg"}, MonthDay{time.December, 1}, MonthDay{time.December, 24}},
+}
+
+func (d MonthDay) before(other MonthDay) bool {
+	if d.Month != other.Month {
+		return d.Month < other.Month
+	}
+	return d.Day < other.Day
+}
+
+// Active reports whether t falls within the season.
+func (s Season) Active(t time.Time) bool {
+	day := MonthDay{t.Month(), t.Day()}
+	if s.To.before(s.From) {
+		return !day.before(s.From) || !s.To.before(day)
+	}
+	return !day.before(s.From) && !s.To.before(day)
+}
+
+func ActiveSeasons(t time.Time) []Season {
+	var active []Season
+	for _, s := range Seasons {
+		if s.Active(t) {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// GetNewProducts lists the products in Willys' "Nyheter" category.
+func (c *Client) GetNewProducts(ctx context.Context) ([]Product, error) {
+	path := fmt.Sprintf("%s?page=0&size=%d", EndpointNewProducts, newProductsPageSize)
+
+	resp, err := c.DoRequest(ctx, "GET", path, nil, false)
+	if err != nil {
+		return nil, newAPIError(ctx, 0, path, "new products request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(ctx, resp, path, "get new products failed")
+	}
+
+	var listing struct {
+		Results []Product `json:"results"`
+	}
+	if err := c.readJSON(resp, EndpointNewProducts, &listing, "results", "results[].code", "results[].name", "results[].priceValue"); err != nil {
+		return nil, newAPIError(ctx, resp.StatusCode, path, "failed to parse new products", err)
+	}
+
+	products := listing.Results
+	for i := range products {
+		products[i].Sustainability = ParseSustainability(products[i].Labels)
+		products[i].StockStatus = ParseStockStatus(products[i].OutOfStock, products[i].LowStock, products[i].StockQuantity)
+	}
+	return products, nil
+}
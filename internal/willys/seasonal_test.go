@@ -0,0 +1,39 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActiveSeasons(t *testing.T) {
+	tests := []struct {
+		date     time.Time
+		expected []string
+	}{
+		{time.Date(2026, 8, 20, 12, 0, 0, 0, time.Local), []string{"Kräftskiva", "Surströmming"}},
+		{time.Date(2026, 2, 10, 12, 0, 0, 0, time.Local), []string{"Semlor"}},
+		{time.Date(2026, 12, 24, 12, 0, 0, 0, time.Local), []string{"Jul"}},
+		{time.Date(2026, 7, 10, 12, 0, 0, 0, time.Local), nil},
+	}
+
+	for _, tt := range tests {
+		var names []string
+		for _, s := range ActiveSeasons(tt.date) {
+			names = append(names, s.Name)
+		}
+		if len(names) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.date.Format(time.DateOnly), tt.expected, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expected[i] {
+				t.Errorf("%s: expected %v, got %v", tt.date.Format(time.DateOnly), tt.expected, names)
+			}
+		}
+	}
+
+	wrapping := Season{From: MonthDay{time.December, 20}, To: MonthDay{time.January, 6}}
+	if !wrapping.Active(time.Date(2027, 1, 2, 0, 0, 0, 0, time.Local)) || wrapping.Active(time.Date(2027, 1, 7, 0, 0, 0, 0, time.Local)) {
+		t.Error("Expected season wrapping new year to cover Dec 20 - Jan 6 only")
+	}
+}
@@ -0,0 +1,115 @@
+package willys
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-rod/rod"
+)
+
+// Keys of the browser selectors used by the login and checkout flows
+const (
+	SelectorCookieAccept       = "cookie_accept"
+	SelectorLoginLink          = "login_link"
+	SelectorLoginDialog        = "login_dialog"
+	SelectorUsernameInput      = "username_input"
+	SelectorPasswordInput      = "password_input"
+	SelectorLoginButton        = "login_button"
+	SelectorLoginError         = "login_error"
+	SelectorLoginLockout       = "login_lockout"
+	SelectorCaptcha            = "captcha"
+	SelectorLoggedIn           = "logged_in"
+	SelectorVerificationInput  = "verification_input"
+	SelectorVerificationPrompt = "verification_prompt"
+	SelectorVerificationSubmit = "verification_submit"
+	SelectorCheckoutBlocker    = "checkout_blocker"
+)
+
+//go:embed selectors.json
+var defaultSelectorsJSON []byte
+
+type (
+	// Selector locates an element by CSS and, optionally, a JS regex on its text
+	// ("Logga in", "/kod/i").
+	Selector struct {
+		CSS  string `json:"css"`
+		Text string `json:"text,omitempty"`
+	}
+
+	// Selectors maps the steps of the browser flows to the elements they act on. Willys
+	// changes its DOM now and then; an override file lets users patch a selector without
+	// rebuilding.
+	Selectors map[string]Selector
+
+	elementQuerier interface {
+		Element(selector string) (*rod.Element, error)
+		ElementR(selector, jsRegex string) (*rod.Element, error)
+	}
+)
+
+func DefaultSelectors() Selectors {
+	var s Selectors
+	if err := json.Unmarshal(defaultSelectorsJSON, &s); err != nil {
+		panic(fmt.Sprintf("invalid embedded selectors.json: %v", err))
+	}
+	return s
+}
+
+// LoadSelectors returns the default selectors with the entries from the JSON file at path
+// laid over them. An empty path returns the defaults.
+func LoadSelectors(path string) (Selectors, error) {
+	selectors := DefaultSelectors()
+	if path == "" {
+		return selectors, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selectors file: %w", err)
+	}
+
+	var overrides Selectors
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse selectors file: %w", err)
+	}
+
+	for key, sel := range overrides {
+		if _, ok := selectors[key]; !ok {
+			return nil, NewValidationError("selectors", fmt.Sprintf("unknown selector %q", key))
+		}
+		if sel.CSS == "" {
+			return nil, NewValidationError("selectors", fmt.Sprintf("selector %q has no css", key))
+		}
+		selectors[key] = sel
+	}
+
+	return selectors, nil
+}
+
+// WithSelectors replaces the selectors used by the browser login flow.
+func WithSelectors(selectors Selectors) ClientOption {
+	return func(c *Client) {
+		c.selectors = selectors
+	}
+}
+
+func (s Selectors) find(q elementQuerier, key string) (*rod.Element, error) {
+	sel := s[key]
+	if sel.Text != "" {
+		return q.ElementR(sel.CSS, sel.Text)
+	}
+	return q.Element(sel.CSS)
+}
+
+func (s Selectors) has(page *rod.Page, key string) bool {
+	sel := s[key]
+	var found bool
+	if sel.Text != "" {
+		found, _, _ = page.HasR(sel.CSS, sel.Text)
+	} else {
+		found, _, _ = page.Has(sel.CSS)
+	}
+	return found
+}
@@ -0,0 +1,40 @@
+package willys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSelectors(t *testing.T) {
+	defaults, err := LoadSelectors("")
+	if err != nil {
+		t.Fatalf("Failed to load default selectors: %v", err)
+	}
+	for _, key := range []string{SelectorCookieAccept, SelectorLoginLink, SelectorLoginDialog, SelectorUsernameInput,
+		SelectorPasswordInput, SelectorLoginButton, SelectorLoginError, SelectorLoginLockout, SelectorCaptcha, SelectorLoggedIn,
+		SelectorVerificationInput, SelectorVerificationPrompt, SelectorVerificationSubmit, SelectorCheckoutBlocker} {
+		if defaults[key].CSS == "" {
+			t.Errorf("Expected default selector for %s", key)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "selectors.json")
+	os.WriteFile(path, []byte(`{"login_button": {"css": "button[type='submit']"}}`), 0o600)
+
+	selectors, err := LoadSelectors(path)
+	if err != nil {
+		t.Fatalf("Failed to load overrides: %v", err)
+	}
+	if got := selectors[SelectorLoginButton]; got.CSS != "button[type='submit']" || got.Text != "" {
+		t.Errorf("Expected overridden login button, got %+v", got)
+	}
+	if selectors[SelectorLoginLink] != defaults[SelectorLoginLink] {
+		t.Errorf("Expected untouched selectors to keep defaults")
+	}
+
+	os.WriteFile(path, []byte(`{"login_buton": {"css": "button"}}`), 0o600)
+	if _, err := LoadSelectors(path); err == nil {
+		t.Error("Expected error for unknown selector key")
+	}
+}
@@ -0,0 +1,119 @@
+package willys
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// session is the client's mutable login state. Requests, token refreshes, re-logins,
+// and background pollers all touch it from different goroutines, so nothing reads the
+// fields directly: snapshot returns a copy and update changes them under the lock.
+// Pointer fields are replaced, never modified in place, so a snapshot stays valid.
+type session struct {
+	mu    sync.RWMutex
+	state sessionState
+}
+
+type sessionState struct {
+	csrfToken string
+	username  string
+	password  string
+
+	accessToken  string
+	refreshToken string
+	tokenExpiry  time.Time
+
+	// credentialsInvalidAt is set when a re-login was rejected, see ErrCredentialsInvalid
+	credentialsInvalidAt time.Time
+	// lockout pauses automated logins after Willys locked the account or asked for a
+	// CAPTCHA
+	lockout *LockoutError
+
+	// accountSession is set once a login succeeded and cleared on logout. A session
+	// without it holds a guest cart, whatever credentials are configured.
+	accountSession bool
+
+	anonymousCart *CartSummary
+	lastCartMerge *CartMergeResult
+	activeStore   *StoreRef
+
+	// generation changes on every login and logout. A CSRF token fetched under an older
+	// generation is not stored, and a re-login can tell that another goroutine already
+	// did it.
+	generation uint64
+}
+
+func (s *session) snapshot() sessionState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.state
+}
+
+func (s *session) update(fn func(*sessionState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.state)
+}
+
+// loggedIn records a successful login with fn's changes and starts a new generation.
+func (s *session) loggedIn(fn func(*sessionState)) {
+	s.update(func(st *sessionState) {
+		fn(st)
+		st.accountSession = true
+		st.generation++
+		st.credentialsInvalidAt = time.Time{}
+	})
+}
+
+// loggedIn records a successful login on the session and drops the mirrored cart, which
+// belongs to the guest or to whoever was logged in before.
+func (c *Client) loggedIn(fn func(*sessionState)) {
+	c.session.loggedIn(fn)
+	c.cartMirror.invalidate()
+}
+
+// canReauth reports whether a stored refresh token or password allows logging in again.
+func (st sessionState) canReauth() bool {
+	return st.refreshToken != "" || (st.username != "" && st.password != "")
+}
+
+// sessionJar is the client's cookie jar. Logout swaps an empty jar in behind it instead
+// of replacing http.Client.Jar, which requests in flight read without a lock.
+type sessionJar struct {
+	mu  sync.RWMutex
+	jar http.CookieJar
+}
+
+func newSessionJar() (*sessionJar, error) {
+	j := &sessionJar{}
+	return j, j.reset()
+}
+
+func (j *sessionJar) current() http.CookieJar {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar
+}
+
+func (j *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.current().SetCookies(u, cookies)
+}
+
+func (j *sessionJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.current().Cookies(u)
+}
+
+// reset forgets all cookies.
+func (j *sessionJar) reset() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	j.jar = jar
+	j.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,156 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// rotatingTokenServer issues a new refresh token on every refresh and accepts only the
+// latest one, like Willys' OAuth server. Cart calls need the latest access token.
+type rotatingTokenServer struct {
+	mu        sync.Mutex
+	issued    int
+	refresh   string
+	access    string
+	expiresIn int
+	refreshes atomic.Int32
+}
+
+func (s *rotatingTokenServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.URL.Path {
+	case EndpointOAuthToken:
+		if r.FormValue("refresh_token") != s.refresh {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.refreshes.Add(1)
+		s.issued++
+		s.refresh = fmt.Sprintf("refresh-%d", s.issued)
+		s.access = fmt.Sprintf("access-%d", s.issued)
+		fmt.Fprintf(w, `{"access_token":%q,"refresh_token":%q,"expires_in":%d}`, s.access, s.refresh, s.expiresIn)
+	case EndpointCSRFToken:
+		w.Write([]byte(`"csrf"`))
+	case EndpointCart:
+		if r.Header.Get("Authorization") != "Bearer "+s.access {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"products":[]}`))
+	}
+}
+
+// expire makes the server forget the current access token, as when a session times out.
+func (s *rotatingTokenServer) expire() {
+	s.mu.Lock()
+	s.access = "expired"
+	s.mu.Unlock()
+}
+
+func newRotatingClient(t *testing.T, expiresIn int) (*Client, *rotatingTokenServer) {
+	t.Helper()
+	tokens := &rotatingTokenServer{refresh: "refresh-0", expiresIn: expiresIn}
+	srv := httptest.NewServer(tokens)
+	t.Cleanup(srv.Close)
+
+	client, err := NewClient(srv.URL, "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := client.LoginWithToken(context.Background(), "refresh-0"); err != nil {
+		t.Fatalf("LoginWithToken failed: %v", err)
+	}
+	return client, tokens
+}
+
+func concurrently(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestConcurrentReloginRunsOnce(t *testing.T) {
+	client, tokens := newRotatingClient(t, 3600)
+	tokens.expire()
+
+	errs := concurrently(20, func(int) error {
+		resp, err := client.DoRequest(context.Background(), "POST", EndpointCart, nil, true)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Request failed: %v", err)
+		}
+	}
+	// One initial login and one re-login; a second re-login would have used a refresh
+	// token that was already rotated away
+	if got := tokens.refreshes.Load(); got != 2 {
+		t.Errorf("Expected 2 token refreshes, got %d", got)
+	}
+}
+
+func TestConcurrentRefreshOfExpiringToken(t *testing.T) {
+	// expires_in below tokenRefreshMargin: every request finds the token expiring
+	client, tokens := newRotatingClient(t, 1)
+
+	errs := concurrently(20, func(int) error {
+		return client.refreshTokenIfExpiring(context.Background())
+	})
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("Refresh failed: %v", err)
+		}
+	}
+	if got := tokens.refreshes.Load(); got != 2 {
+		t.Errorf("Expected one refresh after login, got %d refreshes", got-1)
+	}
+}
+
+// TestSessionStress interleaves logins, logouts, CSRF fetches, and requests. It asserts
+// little on its own; run with -race to catch unguarded session state.
+func TestSessionStress(t *testing.T) {
+	client, _ := newRotatingClient(t, 3600)
+	ctx := context.Background()
+
+	concurrently(40, func(i int) error {
+		switch i % 5 {
+		case 0:
+			resp, err := client.DoRequest(ctx, "POST", EndpointCart, nil, true)
+			if err == nil {
+				resp.Body.Close()
+			}
+		case 1:
+			client.FetchCSRFToken()
+		case 2:
+			client.AuthStatus()
+			client.SetCookies([]*http.Cookie{{Name: "JSESSIONID", Value: fmt.Sprint(i)}})
+		case 3:
+			client.Logout(ctx)
+		case 4:
+			client.refreshTokenIfExpiring(ctx)
+			client.IsAuthenticated()
+		}
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package willys
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	ExpiryExpired  = "expired"
+	ExpiryExpiring = "expiring"
+	ExpiryRunOut   = "running_out"
+
+	// expiryLookback limits estimates to recent purchases; anything perishable bought
+	// earlier has been eaten or thrown away.
+	expiryLookback = 60 * 24 * time.Hour
+)
+
+// ShelfLifeDays is a typical number of days from delivery until products in each
+// department go off. Departments without an entry, such as household goods, don't expire.
+var ShelfLifeDays = map[string]int{
+	"frukt-och-gront":        7,
+	"brod-och-kakor":         5,
+	"kott-chark-och-fagel":   4,
+	"fisk-och-skaldjur":      2,
+	"mejeri-ost-och-agg":     10,
+	"vegetariskt":            10,
+	"skafferi":               365,
+	"fryst":                  180,
+	"dryck":                  90,
+	"glass-godis-och-snacks": 120,
+	"barn":                   180,
+	"djur":                   365,
+}
+
+// ExpiryEstimate is a previously ordered product that is likely to expire or run out.
+// ExpiresAt comes from the department's shelf life; RunsOutAt from the average time
+// between orders of the product and is only set when it has been bought more than once.
+type ExpiryEstimate struct {
+	Code       string     `json:"code"`
+	Name       string     `json:"name"`
+	Category   string     `json:"category"`
+	Quantity   int        `json:"quantity"`
+	LastBought time.Time  `json:"lastBought"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	RunsOutAt  *time.Time `json:"runsOutAt,omitempty"`
+	DaysLeft   int        `json:"daysLeft"`
+	Status     string     `json:"status"`
+}
+
+// EstimateExpiry returns the products from orders that expire or run out within horizon
+// of now, soonest first.
+func EstimateExpiry(orders []Order, now time.Time, horizon time.Duration) []ExpiryEstimate {
+	type purchases struct {
+		entry OrderEntry
+		dates []time.Time
+	}
+	byCode := make(map[string]*purchases)
+	for _, order := range orders {
+		if order.PlacedAt.IsZero() {
+			continue
+		}
+		for _, entry := range order.Entries {
+			p, ok := byCode[entry.Code]
+			if !ok {
+				p = &purchases{}
+				byCode[entry.Code] = p
+			}
+			if len(p.dates) == 0 || order.PlacedAt.After(p.dates[len(p.dates)-1]) {
+				p.entry = entry
+			}
+			p.dates = append(p.dates, order.PlacedAt)
+			sort.Slice(p.dates, func(i, j int) bool { return p.dates[i].Before(p.dates[j]) })
+		}
+	}
+
+	deadline := now.Add(horizon)
+	var estimates []ExpiryEstimate
+	for code, p := range byCode {
+		last := p.dates[len(p.dates)-1]
+		if now.Sub(last) > expiryLookback {
+			continue
+		}
+
+		category := CategorizeCartItem(CartItem{Name: p.entry.Name})
+		estimate := ExpiryEstimate{
+			Code:       code,
+			Name:       p.entry.Name,
+			Category:   category.Name,
+			Quantity:   p.entry.Quantity,
+			LastBought: last,
+		}
+		var due time.Time
+		if days, ok := ShelfLifeDays[category.Key]; ok {
+			expires := last.AddDate(0, 0, days)
+			estimate.ExpiresAt = &expires
+			due = expires
+		}
+		runsOut := false
+		if len(p.dates) > 1 {
+			interval := last.Sub(p.dates[0]) / time.Duration(len(p.dates)-1)
+			runsOutAt := last.Add(interval)
+			estimate.RunsOutAt = &runsOutAt
+			if due.IsZero() || runsOutAt.Before(due) {
+				due = runsOutAt
+				runsOut = true
+			}
+		}
+		if due.IsZero() || due.After(deadline) {
+			continue
+		}
+
+		estimate.DaysLeft = int(due.Sub(now).Hours() / 24)
+		switch {
+		case runsOut:
+			estimate.Status = ExpiryRunOut
+		case due.Before(now):
+			estimate.Status = ExpiryExpired
+		default:
+			estimate.Status = ExpiryExpiring
+		}
+		estimates = append(estimates, estimate)
+	}
+
+	sort.Slice(estimates, func(i, j int) bool {
+		if estimates[i].DaysLeft != estimates[j].DaysLeft {
+			return estimates[i].DaysLeft < estimates[j].DaysLeft
+		}
+		return estimates[i].Name < estimates[j].Name
+	})
+	return estimates
+}
@@ -0,0 +1,45 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateExpiry(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	daysAgo := func(n int) time.Time { return now.AddDate(0, 0, -n) }
+
+	orders := []Order{
+		{PlacedAt: daysAgo(2), Entries: []OrderEntry{
+			{Code: "milk", Name: "Mellanmjölk 1,5%", Quantity: 2},
+			{Code: "pasta", Name: "Pasta Penne", Quantity: 1},
+			{Code: "soap", Name: "Diskmedel Sensitive", Quantity: 1},
+		}},
+		{PlacedAt: daysAgo(9), Entries: []OrderEntry{
+			{Code: "bread", Name: "Rågbröd", Quantity: 1},
+			{Code: "soap", Name: "Diskmedel Sensitive", Quantity: 1},
+		}},
+		{PlacedAt: daysAgo(90), Entries: []OrderEntry{
+			{Code: "salmon", Name: "Laxfilé", Quantity: 1},
+		}},
+	}
+
+	estimates := EstimateExpiry(orders, now, 5*24*time.Hour)
+
+	byCode := make(map[string]ExpiryEstimate)
+	for _, e := range estimates {
+		byCode[e.Code] = e
+	}
+	if len(estimates) != 2 {
+		t.Fatalf("Expected bread and dish soap only, got %+v", estimates)
+	}
+	if e := byCode["bread"]; e.Status != ExpiryExpired || e.DaysLeft != -4 {
+		t.Errorf("Expected bread expired 4 days ago, got %+v", e)
+	}
+	if e := byCode["soap"]; e.Status != ExpiryRunOut || e.ExpiresAt != nil || e.DaysLeft != 5 {
+		t.Errorf("Expected dish soap running out in 5 days, got %+v", e)
+	}
+	if estimates[0].Code != "bread" {
+		t.Errorf("Expected soonest first, got %+v", estimates)
+	}
+}
@@ -0,0 +1,265 @@
+package willys
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SlotOrderEarliest = "earliest"
+	SlotOrderCheapest = "cheapest"
+	SlotOrderLatest   = "latest"
+)
+
+// SlotSpec is a parsed fuzzy slot request such as "cheapest this weekend" or
+// "tomorrow evening". Zero fields mean no restriction.
+type SlotSpec struct {
+	Dates    map[string]bool // YYYY-MM-DD
+	From, To string          // slot start must be in [From, To)
+	Order    string
+
+	// Holidays are the requested dates that are holidays. When a weekday lands on one,
+	// the last ordinary day before it goes into Fallback and is used if the holiday
+	// itself has no matching slot.
+	Holidays []Holiday
+	Fallback map[string]bool
+}
+
+var (
+	slotOrderWords = map[string]string{
+		"earliest": SlotOrderEarliest, "first": SlotOrderEarliest, "soonest": SlotOrderEarliest, "tidigast": SlotOrderEarliest,
+		"cheapest": SlotOrderCheapest, "billigast": SlotOrderCheapest,
+		"latest": SlotOrderLatest, "last": SlotOrderLatest, "senast": SlotOrderLatest,
+	}
+
+	slotPartsOfDay = map[string][2]string{
+		"morning": {"00:00", "12:00"}, "förmiddag": {"00:00", "12:00"}, "morgon": {"00:00", "12:00"},
+		"afternoon": {"12:00", "17:00"}, "eftermiddag": {"12:00", "17:00"},
+		"evening": {"17:00", "24:00"}, "kväll": {"17:00", "24:00"}, "kvällen": {"17:00", "24:00"},
+	}
+
+	slotWeekdays = map[string]time.Weekday{
+		"monday": time.Monday, "måndag": time.Monday,
+		"tuesday": time.Tuesday, "tisdag": time.Tuesday,
+		"wednesday": time.Wednesday, "onsdag": time.Wednesday,
+		"thursday": time.Thursday, "torsdag": time.Thursday,
+		"friday": time.Friday, "fredag": time.Friday,
+		"saturday": time.Saturday, "lördag": time.Saturday,
+		"sunday": time.Sunday, "söndag": time.Sunday,
+	}
+
+	// Filler words that carry no meaning for slot selection
+	slotFillerWords = map[string]bool{
+		"this": true, "on": true, "in": true, "the": true, "slot": true, "delivery": true,
+		"available": true, "possible": true, "på": true, "i": true, "nu": true,
+	}
+)
+
+// ParseSlotSpec parses spec relative to now. Days may be "today", "tomorrow", a weekday,
+// "weekend", or a YYYY-MM-DD date; parts of day "morning", "afternoon", "evening";
+// ordering "earliest" (default), "cheapest", or "latest". Swedish equivalents work too.
+func ParseSlotSpec(spec string, now time.Time) (*SlotSpec, error) {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(spec, ",", " ")))
+	if len(words) == 0 {
+		return nil, NewValidationError("slot_spec", "cannot be empty")
+	}
+
+	parsed := &SlotSpec{Order: SlotOrderEarliest}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	addDate := func(t time.Time) {
+		if parsed.Dates == nil {
+			parsed.Dates = make(map[string]bool)
+		}
+		parsed.Dates[t.Format("2006-01-02")] = true
+		if holiday, ok := HolidayOn(t); ok {
+			parsed.Holidays = append(parsed.Holidays, holiday)
+		}
+	}
+
+	for _, word := range words {
+		if order, ok := slotOrderWords[word]; ok {
+			parsed.Order = order
+			continue
+		}
+		if window, ok := slotPartsOfDay[word]; ok {
+			parsed.From, parsed.To = window[0], window[1]
+			continue
+		}
+		if weekday, ok := slotWeekdays[strings.TrimSuffix(word, "s")]; ok {
+			date := today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)
+			addDate(date)
+			// "Friday" in Easter week most likely means before the long weekend
+			if _, ok := HolidayOn(date); ok {
+				if parsed.Fallback == nil {
+					parsed.Fallback = make(map[string]bool)
+				}
+				parsed.Fallback[lastDayBeforeHoliday(date).Format("2006-01-02")] = true
+			}
+			continue
+		}
+		switch word {
+		case "today", "idag":
+			addDate(today)
+		case "tomorrow", "imorgon":
+			addDate(today.AddDate(0, 0, 1))
+		case "weekend", "helgen", "helg":
+			// The coming Saturday and Sunday; on a Sunday only today is left
+			if today.Weekday() == time.Sunday {
+				addDate(today)
+				break
+			}
+			saturday := today.AddDate(0, 0, int(time.Saturday-today.Weekday()))
+			addDate(saturday)
+			addDate(saturday.AddDate(0, 0, 1))
+		default:
+			if date, err := time.Parse("2006-01-02", word); err == nil {
+				addDate(date)
+				continue
+			}
+			if slotFillerWords[word] {
+				continue
+			}
+			return nil, NewValidationError("slot_spec", fmt.Sprintf(
+				"unrecognized word %q (use e.g. 'earliest', 'cheapest', 'latest', 'today', 'tomorrow', 'saturday', 'weekend', 'YYYY-MM-DD', 'morning', 'afternoon', 'evening')", word))
+		}
+	}
+
+	return parsed, nil
+}
+
+func (s *SlotSpec) matches(slot TimeSlot, dates map[string]bool) bool {
+	if !slot.Available {
+		return false
+	}
+	if dates != nil && !dates[slot.Date] {
+		return false
+	}
+	if s.From != "" && (slot.StartTime < s.From || slot.StartTime >= s.To) {
+		return false
+	}
+	return true
+}
+
+// Resolve picks the slot the spec refers to. Ties are broken explicitly: cheapest falls
+// back to the earliest start, earliest and latest fall back to the lower fee, and the
+// slot ID settles anything left so the choice is deterministic. The Fallback dates are
+// only considered when no requested date has a matching slot.
+func (s *SlotSpec) Resolve(slots []TimeSlot) (*TimeSlot, error) {
+	var candidates []TimeSlot
+	for _, dates := range []map[string]bool{s.Dates, s.Fallback} {
+		for _, slot := range slots {
+			if s.matches(slot, dates) {
+				candidates = append(candidates, slot)
+			}
+		}
+		if len(candidates) > 0 || s.Fallback == nil {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, NewNotFoundError("time slot", "no available slot matches the spec")
+	}
+
+	start := func(t TimeSlot) string { return t.Date + " " + t.StartTime }
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch s.Order {
+		case SlotOrderCheapest:
+			if a.Fee.Ore != b.Fee.Ore {
+				return a.Fee.Ore < b.Fee.Ore
+			}
+			if start(a) != start(b) {
+				return start(a) < start(b)
+			}
+		case SlotOrderLatest:
+			if start(a) != start(b) {
+				return start(a) > start(b)
+			}
+			if a.Fee.Ore != b.Fee.Ore {
+				return a.Fee.Ore < b.Fee.Ore
+			}
+		default:
+			if start(a) != start(b) {
+				return start(a) < start(b)
+			}
+			if a.Fee.Ore != b.Fee.Ore {
+				return a.Fee.Ore < b.Fee.Ore
+			}
+		}
+		return a.SlotID < b.SlotID
+	})
+
+	return &candidates[0], nil
+}
+
+const (
+	AlternativeSameDay  = "same_day"
+	AlternativeSameTime = "same_time"
+	AlternativeNearest  = "nearest"
+)
+
+// SlotAlternative is an available slot offered when the requested one doesn't exist.
+type SlotAlternative struct {
+	Slot            TimeSlot `json:"slot"`
+	Reason          string   `json:"reason"`
+	DistanceMinutes int      `json:"distanceMinutes"`
+}
+
+// NearestSlots ranks available slots by how close their start is to the requested date and
+// start time, preferring the same day and then the same time on other days, and the lower
+// fee among equally close slots. Other slots are only offered if neither kind exists.
+func NearestSlots(slots []TimeSlot, date, startTime string, limit int) []SlotAlternative {
+	requested, err := time.Parse("2006-01-02 15:04", date+" "+startTime)
+	if err != nil {
+		return nil
+	}
+
+	var preferred, others []SlotAlternative
+	for _, slot := range slots {
+		if !slot.Available {
+			continue
+		}
+		start, err := time.Parse("2006-01-02 15:04", slot.Date+" "+slot.StartTime)
+		if err != nil {
+			continue
+		}
+		distance := start.Sub(requested)
+		if distance < 0 {
+			distance = -distance
+		}
+		alt := SlotAlternative{Slot: slot, DistanceMinutes: int(distance.Minutes())}
+		switch {
+		case slot.Date == date:
+			alt.Reason = AlternativeSameDay
+			preferred = append(preferred, alt)
+		case slot.StartTime == startTime:
+			alt.Reason = AlternativeSameTime
+			preferred = append(preferred, alt)
+		default:
+			alt.Reason = AlternativeNearest
+			others = append(others, alt)
+		}
+	}
+
+	alternatives := preferred
+	if len(alternatives) == 0 {
+		alternatives = others
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		a, b := alternatives[i], alternatives[j]
+		if (a.Reason == AlternativeSameDay) != (b.Reason == AlternativeSameDay) {
+			return a.Reason == AlternativeSameDay
+		}
+		if a.DistanceMinutes != b.DistanceMinutes {
+			return a.DistanceMinutes < b.DistanceMinutes
+		}
+		return a.Slot.Fee.Ore < b.Slot.Fee.Ore
+	})
+
+	if limit > 0 && len(alternatives) > limit {
+		alternatives = alternatives[:limit]
+	}
+	return alternatives
+}
@@ -0,0 +1,99 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSlotSpecResolve(t *testing.T) {
+	// Friday 2026-10-16, 10:00
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	slots := []TimeSlot{
+		{SlotID: "fri-morning", Date: "2026-10-16", StartTime: "08:00", EndTime: "10:00", Fee: SEK(4900), Available: false},
+		{SlotID: "fri-evening", Date: "2026-10-16", StartTime: "18:00", EndTime: "20:00", Fee: SEK(7900), Available: true},
+		{SlotID: "sat-morning", Date: "2026-10-17", StartTime: "09:00", EndTime: "11:00", Fee: SEK(2900), Available: true},
+		{SlotID: "sat-evening", Date: "2026-10-17", StartTime: "17:00", EndTime: "19:00", Fee: SEK(4900), Available: true},
+		{SlotID: "sun-b", Date: "2026-10-18", StartTime: "10:00", EndTime: "12:00", Fee: SEK(2900), Available: true},
+		{SlotID: "sun-a", Date: "2026-10-18", StartTime: "10:00", EndTime: "12:00", Fee: SEK(2900), Available: true},
+		{SlotID: "mon-evening", Date: "2026-10-19", StartTime: "19:00", EndTime: "21:00", Fee: SEK(0), Available: true},
+	}
+
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{"earliest", "fri-evening"},
+		{"tomorrow evening", "sat-evening"},
+		{"cheapest this weekend", "sat-morning"},
+		{"latest on the weekend", "sun-a"},
+		{"cheapest", "mon-evening"},
+		{"söndag", "sun-a"},
+		{"billigast imorgon kväll", "sat-evening"},
+		{"2026-10-19", "mon-evening"},
+	}
+
+	for _, tt := range tests {
+		spec, err := ParseSlotSpec(tt.spec, now)
+		if err != nil {
+			t.Fatalf("ParseSlotSpec(%q) failed: %v", tt.spec, err)
+		}
+		slot, err := spec.Resolve(slots)
+		if err != nil {
+			t.Errorf("%q: resolve failed: %v", tt.spec, err)
+			continue
+		}
+		if slot.SlotID != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.spec, tt.expected, slot.SlotID)
+		}
+	}
+}
+
+func TestSlotSpecErrors(t *testing.T) {
+	now := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	if _, err := ParseSlotSpec("whenever you like", now); err == nil {
+		t.Error("Expected error for unrecognized words")
+	}
+
+	spec, err := ParseSlotSpec("today morning", now)
+	if err != nil {
+		t.Fatalf("ParseSlotSpec failed: %v", err)
+	}
+	if _, err := spec.Resolve([]TimeSlot{{Date: "2026-10-16", StartTime: "18:00", Available: true}}); !IsNotFoundError(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+func TestNearestSlots(t *testing.T) {
+	slots := []TimeSlot{
+		{SlotID: "fri-15", Date: "2026-10-16", StartTime: "15:00", EndTime: "17:00", Fee: SEK(4900), Available: true},
+		{SlotID: "fri-19", Date: "2026-10-16", StartTime: "19:00", EndTime: "21:00", Fee: SEK(2900), Available: true},
+		{SlotID: "sat-17", Date: "2026-10-17", StartTime: "17:00", EndTime: "19:00", Fee: SEK(2900), Available: true},
+		{SlotID: "sat-09", Date: "2026-10-17", StartTime: "09:00", EndTime: "11:00", Fee: SEK(0), Available: true},
+		{SlotID: "fri-17-full", Date: "2026-10-16", StartTime: "17:00", EndTime: "19:00", Fee: SEK(0), Available: false},
+	}
+
+	alternatives := NearestSlots(slots, "2026-10-16", "17:00", 5)
+	var got []string
+	for _, a := range alternatives {
+		got = append(got, a.Slot.SlotID)
+	}
+	// Same-day slots 2h away tie on distance and are ranked by fee; other days follow
+	expected := []string{"fri-19", "fri-15", "sat-17"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+	if alternatives[2].Reason != AlternativeSameTime || alternatives[2].DistanceMinutes != 24*60 {
+		t.Errorf("Unexpected same-time alternative: %+v", alternatives[2])
+	}
+
+	// Nothing on the day or at the time: fall back to the closest slots overall
+	alternatives = NearestSlots(slots, "2026-10-18", "12:00", 1)
+	if len(alternatives) != 1 || alternatives[0].Slot.SlotID != "sat-17" || alternatives[0].Reason != AlternativeNearest {
+		t.Errorf("Unexpected fallback alternatives: %+v", alternatives)
+	}
+}
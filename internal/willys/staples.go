@@ -0,0 +1,72 @@
+package willys
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// stapleOrderWindow is how many of the most recent orders are checked for staples.
+	stapleOrderWindow = 10
+
+	// A product is a staple when it was in at least stapleMinOrders of the recent orders,
+	// and in at least stapleMinShare of them.
+	stapleMinOrders = 3
+	stapleMinShare  = 0.5
+)
+
+// Staple is a product the customer buys in most orders.
+type Staple struct {
+	Code       string    `json:"code"`
+	Name       string    `json:"name"`
+	Orders     int       `json:"orders"`   // recent orders containing the product
+	OfOrders   int       `json:"ofOrders"` // recent orders considered
+	LastBought time.Time `json:"lastBought"`
+}
+
+// ForgottenStaples lists staples from the recent orders that aren't in the cart, the most
+// regular first.
+func ForgottenStaples(items []CartItem, orders []Order) []Staple {
+	recent := append([]Order(nil), orders...)
+	sort.Slice(recent, func(i, j int) bool { return recent[i].PlacedAt.After(recent[j].PlacedAt) })
+	recent = recent[:min(len(recent), stapleOrderWindow)]
+
+	inCart := make(map[string]bool, len(items))
+	for _, item := range items {
+		inCart[item.ProductCode] = true
+	}
+
+	counts := make(map[string]*Staple)
+	for _, order := range recent {
+		seen := make(map[string]bool, len(order.Entries))
+		for _, entry := range order.Entries {
+			if inCart[entry.Code] || seen[entry.Code] {
+				continue
+			}
+			seen[entry.Code] = true
+			s, ok := counts[entry.Code]
+			if !ok {
+				s = &Staple{Code: entry.Code, Name: entry.Name, OfOrders: len(recent)}
+				counts[entry.Code] = s
+			}
+			s.Orders++
+			if order.PlacedAt.After(s.LastBought) {
+				s.LastBought = order.PlacedAt
+			}
+		}
+	}
+
+	staples := make([]Staple, 0)
+	for _, s := range counts {
+		if s.Orders >= stapleMinOrders && float64(s.Orders) >= stapleMinShare*float64(s.OfOrders) {
+			staples = append(staples, *s)
+		}
+	}
+	sort.Slice(staples, func(i, j int) bool {
+		if staples[i].Orders != staples[j].Orders {
+			return staples[i].Orders > staples[j].Orders
+		}
+		return staples[i].Name < staples[j].Name
+	})
+	return staples
+}
@@ -0,0 +1,40 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestForgottenStaples(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	order := func(daysAgo int, codes ...string) Order {
+		o := Order{PlacedAt: now.AddDate(0, 0, -daysAgo)}
+		for _, code := range codes {
+			o.Entries = append(o.Entries, OrderEntry{Code: code, Name: code, Quantity: 1})
+		}
+		return o
+	}
+	orders := []Order{
+		order(7, "milk", "bread", "coffee"),
+		order(14, "milk", "bread"),
+		order(21, "milk", "coffee", "candles"),
+		order(28, "milk", "candles"),
+		order(35, "bread"),
+		order(42, "candles"),
+	}
+	items := []CartItem{{ProductCode: "bread"}}
+
+	staples := ForgottenStaples(items, orders)
+
+	// milk: 4 of 6, candles: 3 of 6, coffee: only 2
+	if len(staples) != 2 || staples[0].Code != "milk" || staples[1].Code != "candles" {
+		t.Fatalf("Expected milk and candles, got %+v", staples)
+	}
+	if staples[0].Orders != 4 || staples[0].OfOrders != 6 || !staples[0].LastBought.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("Unexpected staple: %+v", staples[0])
+	}
+
+	if got := ForgottenStaples(items, orders[:2]); len(got) != 0 {
+		t.Errorf("Expected no staples from two orders, got %+v", got)
+	}
+}
@@ -0,0 +1,73 @@
+package willys
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StockInStock    = "in_stock"
+	StockLow        = "low_stock"
+	StockOutOfStock = "out_of_stock"
+
+	// lowStockQuantity is the store quantity at or below which an item counts as low
+	// stock when Willys reports a number instead of a flag.
+	lowStockQuantity = 5
+)
+
+// StockWarning flags a cart item that may not be delivered as ordered.
+type StockWarning struct {
+	ProductCode string `json:"code"`
+	Name        string `json:"name"`
+	StockStatus string `json:"stockStatus"`
+	Message     string `json:"message"`
+}
+
+// ParseStockStatus derives a stock status from whatever the API sent for the active
+// store. It returns "" when there is nothing to go on, so unknown isn't reported as
+// in stock.
+func ParseStockStatus(outOfStock, lowStock bool, quantity *int) string {
+	switch {
+	case outOfStock || (quantity != nil && *quantity <= 0):
+		return StockOutOfStock
+	case lowStock || (quantity != nil && *quantity <= lowStockQuantity):
+		return StockLow
+	case quantity != nil:
+		return StockInStock
+	default:
+		return ""
+	}
+}
+
+// StockWarnings lists cart items that are out of stock, or low on stock when delivery
+// isn't today: stock is for the store right now and may run out before a later day.
+// A zero deliveryDate means the day isn't known and low stock is always reported.
+func StockWarnings(items []CartItem, deliveryDate, now time.Time) []StockWarning {
+	var warnings []StockWarning
+	sameDay := !deliveryDate.IsZero() && deliveryDate.Format(time.DateOnly) == now.Format(time.DateOnly)
+
+	for _, item := range items {
+		var message string
+		switch item.StockStatus {
+		case StockOutOfStock:
+			message = "out of stock in the store; it will be replaced or left out"
+		case StockLow:
+			if sameDay {
+				continue
+			}
+			message = "low stock in the store; it may sell out before delivery"
+			if !deliveryDate.IsZero() {
+				message = fmt.Sprintf("low stock in the store; it may sell out before delivery on %s", deliveryDate.Format(time.DateOnly))
+			}
+		default:
+			continue
+		}
+		warnings = append(warnings, StockWarning{
+			ProductCode: item.ProductCode,
+			Name:        item.Name,
+			StockStatus: item.StockStatus,
+			Message:     message,
+		})
+	}
+	return warnings
+}
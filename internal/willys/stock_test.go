@@ -0,0 +1,48 @@
+package willys
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStockStatus(t *testing.T) {
+	two, ten, zero := 2, 10, 0
+
+	tests := []struct {
+		outOfStock, lowStock bool
+		quantity             *int
+		expected             string
+	}{
+		{false, false, nil, ""},
+		{true, false, nil, StockOutOfStock},
+		{false, true, nil, StockLow},
+		{false, false, &zero, StockOutOfStock},
+		{false, false, &two, StockLow},
+		{false, false, &ten, StockInStock},
+	}
+
+	for _, tt := range tests {
+		if got := ParseStockStatus(tt.outOfStock, tt.lowStock, tt.quantity); got != tt.expected {
+			t.Errorf("ParseStockStatus(%v, %v, %v) = %q, expected %q", tt.outOfStock, tt.lowStock, tt.quantity, got, tt.expected)
+		}
+	}
+}
+
+func TestStockWarnings(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	items := []CartItem{
+		{ProductCode: "1", Name: "Mjölk", StockStatus: StockInStock},
+		{ProductCode: "2", Name: "Kräftor", StockStatus: StockLow},
+		{ProductCode: "3", Name: "Semlor", StockStatus: StockOutOfStock},
+	}
+
+	if warnings := StockWarnings(items, time.Time{}, now); len(warnings) != 2 {
+		t.Errorf("Expected 2 warnings without delivery date, got %+v", warnings)
+	}
+	if warnings := StockWarnings(items, now.Add(48*time.Hour), now); len(warnings) != 2 || warnings[0].ProductCode != "2" {
+		t.Errorf("Expected low-stock warning for later delivery, got %+v", warnings)
+	}
+	if warnings := StockWarnings(items, now, now); len(warnings) != 1 || warnings[0].ProductCode != "3" {
+		t.Errorf("Expected only out-of-stock warning for same-day delivery, got %+v", warnings)
+	}
+}
@@ -0,0 +1,134 @@
+package willys
+
+import (
+	"sort"
+	"strings"
+)
+
+type (
+	// Sustainability is derived from the eco and climate labels Willys attaches to products.
+	// Score is a rough ranking aid, not an official climate figure.
+	Sustainability struct {
+		Labels []string `json:"labels"`
+		Score  int      `json:"score"`
+	}
+
+	ClimateReport struct {
+		ItemCount       int            `json:"itemCount"`
+		EcoItemCount    int            `json:"ecoItemCount"`
+		EcoShareOfValue float64        `json:"ecoShareOfValue"` // 0-1, share of cart value with at least one eco label
+		AverageScore    float64        `json:"averageScore"`
+		LabelCounts     map[string]int `json:"labelCounts"`
+		ItemsWithoutEco []string       `json:"itemsWithoutEco"`
+		TopScoringItems []string       `json:"topScoringItems"`
+	}
+
+	ecoLabel struct {
+		match  []string
+		name   string
+		weight int
+	}
+)
+
+// Label keys as they appear in the Axfood API (e.g. "krav", "eu_ecological") as well as the
+// Swedish display names used on the website.
+var ecoLabels = []ecoLabel{
+	{[]string{"krav"}, "KRAV", 3},
+	{[]string{"eu_ecological", "ekologisk", "eu-ekologisk", "organic"}, "EU-ekologisk", 2},
+	{[]string{"fairtrade"}, "Fairtrade", 2},
+	{[]string{"svanen", "nordic_swan"}, "Svanen", 2},
+	{[]string{"msc"}, "MSC", 2},
+	{[]string{"asc"}, "ASC", 2},
+	{[]string{"rainforest"}, "Rainforest Alliance", 1},
+	{[]string{"svenskt_sigill", "sigill"}, "Svenskt Sigill", 1},
+	{[]string{"klimat", "climate"}, "Klimatmärkt", 1},
+}
+
+func ParseSustainability(labels []string) *Sustainability {
+	s := &Sustainability{Labels: []string{}}
+	seen := make(map[string]bool)
+
+	for _, label := range labels {
+		labelLower := strings.ToLower(label)
+		for _, eco := range ecoLabels {
+			if seen[eco.name] {
+				continue
+			}
+			for _, m := range eco.match {
+				if strings.Contains(labelLower, m) {
+					seen[eco.name] = true
+					s.Labels = append(s.Labels, eco.name)
+					s.Score += eco.weight
+					break
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+func sustainabilityScore(p Product) int {
+	if p.Sustainability == nil {
+		return ParseSustainability(p.Labels).Score
+	}
+	return p.Sustainability.Score
+}
+
+func BuildClimateReport(cart *CartSummary) *ClimateReport {
+	report := &ClimateReport{
+		LabelCounts:     make(map[string]int),
+		ItemsWithoutEco: []string{},
+		TopScoringItems: []string{},
+	}
+	if cart == nil {
+		return report
+	}
+
+	var totalValue, ecoValue int64
+	totalScore := 0
+	type scored struct {
+		name  string
+		score int
+	}
+	scoredItems := make([]scored, 0, len(cart.Items))
+
+	for _, item := range cart.Items {
+		s := item.Sustainability
+		if s == nil {
+			s = ParseSustainability(item.Labels)
+		}
+
+		report.ItemCount++
+		totalValue += item.TotalPrice.Ore
+		totalScore += s.Score
+
+		if len(s.Labels) == 0 {
+			report.ItemsWithoutEco = append(report.ItemsWithoutEco, item.Name)
+			continue
+		}
+
+		report.EcoItemCount++
+		ecoValue += item.TotalPrice.Ore
+		for _, label := range s.Labels {
+			report.LabelCounts[label]++
+		}
+		scoredItems = append(scoredItems, scored{item.Name, s.Score})
+	}
+
+	if totalValue > 0 {
+		report.EcoShareOfValue = float64(ecoValue) / float64(totalValue)
+	}
+	if report.ItemCount > 0 {
+		report.AverageScore = float64(totalScore) / float64(report.ItemCount)
+	}
+
+	sort.SliceStable(scoredItems, func(i, j int) bool {
+		return scoredItems[i].score > scoredItems[j].score
+	})
+	for i := 0; i < len(scoredItems) && i < 5; i++ {
+		report.TopScoringItems = append(report.TopScoringItems, scoredItems[i].name)
+	}
+
+	return report
+}
@@ -0,0 +1,42 @@
+package willys
+
+import (
+	"testing"
+)
+
+func TestParseSustainability(t *testing.T) {
+	s := ParseSustainability([]string{"krav", "eu_ecological", "swedish_flag", "KRAV"})
+
+	if len(s.Labels) != 2 {
+		t.Fatalf("Expected 2 eco labels, got %v", s.Labels)
+	}
+	if s.Score != 5 {
+		t.Errorf("Expected score 5, got %d", s.Score)
+	}
+
+	none := ParseSustainability(nil)
+	if none.Score != 0 || len(none.Labels) != 0 {
+		t.Errorf("Expected empty sustainability, got %+v", none)
+	}
+}
+
+func TestBuildClimateReport(t *testing.T) {
+	cart := &CartSummary{
+		Items: []CartItem{
+			{Name: "Mjölk", TotalPrice: SEK(3000), Labels: []string{"krav"}},
+			{Name: "Bröd", TotalPrice: SEK(1000)},
+		},
+	}
+
+	report := BuildClimateReport(cart)
+
+	if report.EcoItemCount != 1 {
+		t.Errorf("Expected 1 eco item, got %d", report.EcoItemCount)
+	}
+	if report.EcoShareOfValue != 0.75 {
+		t.Errorf("Expected eco share 0.75, got %.2f", report.EcoShareOfValue)
+	}
+	if len(report.ItemsWithoutEco) != 1 || report.ItemsWithoutEco[0] != "Bröd" {
+		t.Errorf("Expected Bröd without eco labels, got %v", report.ItemsWithoutEco)
+	}
+}
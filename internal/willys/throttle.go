@@ -0,0 +1,79 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Polite mode spaces upstream requests out and backs off hard when Willys signals
+// overload, for users who would rather be slow than risk their account being flagged.
+const (
+	PoliteRequestInterval = 2 * time.Second
+	PoliteBackoff         = 30 * time.Second
+	politeMaxBackoff      = 10 * time.Minute
+)
+
+// throttle enforces a minimum interval between requests. After a 429 or 503 it holds
+// all requests for Retry-After or the current backoff, doubling the backoff each time
+// until a request succeeds.
+type throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	backoff  time.Duration
+	current  time.Duration
+	next     time.Time
+}
+
+// WithThrottle spaces upstream requests at least interval apart and pauses for backoff
+// (doubling on repeats) when Willys answers 429 or 503.
+func WithThrottle(interval, backoff time.Duration) ClientOption {
+	return func(c *Client) {
+		c.throttle = &throttle{interval: interval, backoff: backoff, current: backoff}
+	}
+}
+
+func (t *throttle) wait(ctx context.Context) error {
+	t.mu.Lock()
+	now := time.Now()
+	at := t.next
+	if at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(t.interval)
+	t.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *throttle) observe(resp *http.Response) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		t.current = t.backoff
+		return
+	}
+
+	pause := t.current
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && time.Duration(seconds)*time.Second > pause {
+		pause = time.Duration(seconds) * time.Second
+	}
+	if until := time.Now().Add(pause); until.After(t.next) {
+		t.next = until
+	}
+	t.current = min(t.current*2, politeMaxBackoff)
+}
@@ -0,0 +1,42 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	th := &throttle{interval: 20 * time.Millisecond, backoff: 50 * time.Millisecond, current: 50 * time.Millisecond}
+	ctx := context.Background()
+
+	start := time.Now()
+	for range 3 {
+		if err := th.wait(ctx); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected requests spaced 20ms apart, three took %s", elapsed)
+	}
+
+	th.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if th.current != 100*time.Millisecond {
+		t.Errorf("Expected backoff to double to 100ms, got %s", th.current)
+	}
+	if until := time.Until(th.next); until < 40*time.Millisecond {
+		t.Errorf("Expected requests paused for the backoff, next in %s", until)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := th.wait(cancelled); err == nil {
+		t.Error("Expected wait to return when the context is cancelled")
+	}
+
+	th.observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	if th.current != th.backoff {
+		t.Errorf("Expected backoff reset after success, got %s", th.current)
+	}
+}
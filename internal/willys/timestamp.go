@@ -0,0 +1,46 @@
+package willys
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// EpochMillis is a Unix timestamp in milliseconds, the form Willys uses on the wire. It
+// encodes as an RFC 3339 string with offset so tool output carries one date format
+// throughout, and decodes from either form.
+type EpochMillis int64
+
+func (ms EpochMillis) Time() time.Time {
+	return time.UnixMilli(int64(ms))
+}
+
+func (ms EpochMillis) MarshalJSON() ([]byte, error) {
+	if ms == 0 {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ms.Time().Format(time.RFC3339Nano))
+}
+
+func (ms *EpochMillis) UnmarshalJSON(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	switch val := v.(type) {
+	case nil:
+		*ms = 0
+	case float64:
+		*ms = EpochMillis(val)
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, val)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q: %w", val, err)
+		}
+		*ms = EpochMillis(t.UnixMilli())
+	default:
+		return fmt.Errorf("unsupported timestamp type %T", v)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package willys
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEpochMillisJSON(t *testing.T) {
+	ms := EpochMillis(1792508400000)
+	data, err := json.Marshal(ms)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `"` + ms.Time().Format(time.RFC3339) + `"`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+
+	for _, input := range []string{string(data), "1792508400000"} {
+		var decoded EpochMillis
+		if err := json.Unmarshal([]byte(input), &decoded); err != nil || decoded != ms {
+			t.Errorf("Unmarshal %s: got %d, %v", input, decoded, err)
+		}
+	}
+
+	if data, _ := json.Marshal(EpochMillis(0)); string(data) != "null" {
+		t.Errorf("Expected zero to encode as null, got %s", data)
+	}
+}
+
+func TestTimeSlotJSON(t *testing.T) {
+	slot := TimeSlot{SlotID: "s1", Date: "2026-10-20", StartTime: "22:00", EndTime: "00:00"}
+	data, err := json.Marshal(slot)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wire map[string]any
+	json.Unmarshal(data, &wire)
+	start, err := time.Parse(time.RFC3339, wire["startTime"].(string))
+	if err != nil || start.Format("2006-01-02 15:04") != "2026-10-20 22:00" {
+		t.Errorf("Unexpected startTime %v (%v)", wire["startTime"], err)
+	}
+	end, err := time.Parse(time.RFC3339, wire["endTime"].(string))
+	if err != nil || end.Format("2006-01-02 15:04") != "2026-10-21 00:00" {
+		t.Errorf("Expected a midnight end on the next day, got %v (%v)", wire["endTime"], err)
+	}
+	if wire["window"] != "22:00-00:00" {
+		t.Errorf("Unexpected window %v", wire["window"])
+	}
+
+	// Bookings stored before the output format changed hold HH:MM and epoch millis
+	legacy := `{"slotId":"s1","date":"2026-10-20","startTime":"17:00","endTime":"19:00","earliestDateTime":1792508400000}`
+	var decoded TimeSlot
+	if err := json.NewDecoder(strings.NewReader(legacy)).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.StartTime != "17:00" || decoded.EndTime != "19:00" || decoded.EarliestDateTime != 1792508400000 {
+		t.Errorf("Unexpected legacy slot: %+v", decoded)
+	}
+}
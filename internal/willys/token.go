@@ -0,0 +1,132 @@
+package willys
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// OAuthClientID is the public client the Willys mobile app uses against the Axfood
+	// authorization server. Refresh tokens are bound to it.
+	OAuthClientID = "axfood_mobile"
+
+	// Refresh this long before the access token expires so a request never races expiry.
+	tokenRefreshMargin = time.Minute
+)
+
+type (
+	TokenResponse struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+
+	// TokenRefreshHandler is called with the new refresh token whenever the server
+	// rotates it, so it can be persisted for the next start.
+	TokenRefreshHandler func(refreshToken string)
+)
+
+// WithTokenRefreshHandler registers a callback for rotated refresh tokens.
+func WithTokenRefreshHandler(handler TokenRefreshHandler) ClientOption {
+	return func(c *Client) {
+		c.onTokenRefresh = handler
+	}
+}
+
+// LoginWithToken authenticates with an OAuth refresh token instead of a password, the way
+// the Willys mobile app does. It needs neither stored credentials nor a browser, which
+// suits long-running deployments. Access tokens are refreshed automatically.
+func (c *Client) LoginWithToken(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return NewValidationError("refresh_token", "refresh token cannot be empty")
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {OAuthClientID},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url(EndpointOAuthToken), strings.NewReader(form.Encode()))
+	if err != nil {
+		return NewAuthenticationError("failed to create token request", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		req.Header.Set(CorrelationIDHeader, id)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return NewAuthenticationError("token request failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return NewAuthenticationError("refresh token was rejected, log in again to obtain a new one", ErrCredentialsInvalid)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return responseError(ctx, resp, EndpointOAuthToken, "token refresh failed")
+	}
+
+	var token TokenResponse
+	if err := c.readJSON(resp, EndpointOAuthToken, &token); err != nil {
+		return NewAuthenticationError("failed to parse token response", err)
+	}
+	if token.AccessToken == "" {
+		return NewAuthenticationError("token response did not contain an access token", nil)
+	}
+
+	// The authorization server may or may not rotate the refresh token
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	c.loggedIn(func(st *sessionState) {
+		st.accessToken, st.refreshToken, st.tokenExpiry = token.AccessToken, token.RefreshToken, expiry
+	})
+	c.authAttempts.Store(0)
+
+	if token.RefreshToken != refreshToken && c.onTokenRefresh != nil {
+		c.onTokenRefresh(token.RefreshToken)
+	}
+
+	if _, err := c.FetchCSRFToken(); err != nil {
+		return NewAuthenticationError("failed to fetch CSRF token after token login", err)
+	}
+
+	return nil
+}
+
+// refreshTokenIfExpiring renews the access token ahead of expiry. Token logins only; a
+// zero expiry means the server didn't say, and a 401 will trigger the refresh instead.
+func (c *Client) refreshTokenIfExpiring(ctx context.Context) error {
+	expiring := func(st sessionState) bool {
+		return st.refreshToken != "" && !st.tokenExpiry.IsZero() && time.Until(st.tokenExpiry) <= tokenRefreshMargin
+	}
+	seen := c.session.snapshot()
+	if !expiring(seen) {
+		return nil
+	}
+	_, err := c.relogin(ctx, seen.generation, func(ctx context.Context, st sessionState) error {
+		if !expiring(st) {
+			return nil
+		}
+		return c.LoginWithToken(ctx, st.refreshToken)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoginWithToken(t *testing.T) {
+	var authHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case EndpointOAuthToken:
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "old-refresh" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"access-1","refresh_token":"new-refresh","token_type":"bearer","expires_in":3600}`))
+		case EndpointCSRFToken:
+			w.Write([]byte(`"token"`))
+		case EndpointCustomer:
+			authHeader = r.Header.Get("Authorization")
+			w.Write([]byte(`{"email":"a@example.com"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var rotated string
+	client, err := NewClient(srv.URL, "", "", WithTokenRefreshHandler(func(token string) {
+		rotated = token
+	}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	if err := client.LoginWithToken(context.Background(), "old-refresh"); err != nil {
+		t.Fatalf("LoginWithToken failed: %v", err)
+	}
+	if rotated != "new-refresh" {
+		t.Errorf("Expected rotated refresh token to be reported, got %q", rotated)
+	}
+
+	if _, err := client.GetCustomerInfo(context.Background()); err != nil {
+		t.Fatalf("GetCustomerInfo failed: %v", err)
+	}
+	if authHeader != "Bearer access-1" {
+		t.Errorf("Expected bearer access token, got %q", authHeader)
+	}
+	if status := client.AuthStatus(); status.AuthMethod != "refresh_token" {
+		t.Errorf("Expected refresh_token auth method, got %s", status.AuthMethod)
+	}
+
+	if err := client.LoginWithToken(context.Background(), "revoked"); err == nil {
+		t.Error("Expected error for rejected refresh token")
+	}
+}
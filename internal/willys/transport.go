@@ -0,0 +1,161 @@
+package willys
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 10
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultDialTimeout         = 30 * time.Second
+)
+
+type (
+	// TransportOptions tunes the connection pool to Willys. A single stdio session needs
+	// a handful of connections; a long-lived server with several clients and background
+	// pollers may want more per host, or to cap them. Zero values keep the defaults.
+	TransportOptions struct {
+		MaxIdleConns        int
+		MaxIdleConnsPerHost int
+		// MaxConnsPerHost caps connections in any state; 0 means no limit
+		MaxConnsPerHost int
+		IdleConnTimeout time.Duration
+		DialTimeout     time.Duration
+		// DisableHTTP2 forces HTTP/1.1, e.g. when a proxy mishandles HTTP/2
+		DisableHTTP2 bool
+	}
+
+	// PoolStats reports how the connection pool is used since the client was created.
+	PoolStats struct {
+		Requests        int64   `json:"requests"`
+		ConnsOpened     int64   `json:"connsOpened"`
+		ConnsOpen       int64   `json:"connsOpen"`
+		ConnsReused     int64   `json:"connsReused"`
+		ReuseRatio      float64 `json:"reuseRatio"`
+		DialErrors      int64   `json:"dialErrors"`
+		HTTP2           bool    `json:"http2"`
+		MaxConnsPerHost int     `json:"maxConnsPerHost,omitempty"`
+	}
+
+	poolMetrics struct {
+		requests    atomic.Int64
+		connsOpened atomic.Int64
+		connsOpen   atomic.Int64
+		connsReused atomic.Int64
+		dialErrors  atomic.Int64
+	}
+
+	// countedConn decrements the open connection count once when closed.
+	countedConn struct {
+		net.Conn
+		once    sync.Once
+		metrics *poolMetrics
+	}
+)
+
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConns:        defaultMaxIdleConns,
+		MaxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		IdleConnTimeout:     defaultIdleConnTimeout,
+		DialTimeout:         defaultDialTimeout,
+	}
+}
+
+// WithTransport replaces the connection pool settings. Apply it before options that wrap
+// the transport, such as WithHTTPDump.
+func WithTransport(opts TransportOptions) ClientOption {
+	return func(c *Client) {
+		c.transportOpts = opts.withDefaults()
+		c.httpClient.Transport = &decodingTransport{next: c.newHTTPTransport()}
+	}
+}
+
+func (o TransportOptions) withDefaults() TransportOptions {
+	d := DefaultTransportOptions()
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = d.MaxIdleConns
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = d.MaxIdleConnsPerHost
+	}
+	if o.IdleConnTimeout <= 0 {
+		o.IdleConnTimeout = d.IdleConnTimeout
+	}
+	if o.DialTimeout <= 0 {
+		o.DialTimeout = d.DialTimeout
+	}
+	return o
+}
+
+func (c *Client) newHTTPTransport() *http.Transport {
+	opts := c.transportOpts
+	dialer := &net.Dialer{Timeout: opts.DialTimeout, KeepAlive: 30 * time.Second}
+	metrics := c.pool
+
+	t := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				metrics.dialErrors.Add(1)
+				return nil, err
+			}
+			metrics.connsOpened.Add(1)
+			metrics.connsOpen.Add(1)
+			return &countedConn{Conn: conn, metrics: metrics}, nil
+		},
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     !opts.DisableHTTP2,
+	}
+	if opts.DisableHTTP2 {
+		// A non-nil, empty map turns off the transport's HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() { c.metrics.connsOpen.Add(-1) })
+	return c.Conn.Close()
+}
+
+// tracePool counts the request and whether it got a pooled connection.
+func (m *poolMetrics) tracePool(ctx context.Context) context.Context {
+	m.requests.Add(1)
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				m.connsReused.Add(1)
+			}
+		},
+	})
+}
+
+func (c *Client) PoolStats() PoolStats {
+	stats := PoolStats{
+		Requests:        c.pool.requests.Load(),
+		ConnsOpened:     c.pool.connsOpened.Load(),
+		ConnsOpen:       c.pool.connsOpen.Load(),
+		ConnsReused:     c.pool.connsReused.Load(),
+		DialErrors:      c.pool.dialErrors.Load(),
+		HTTP2:           !c.transportOpts.DisableHTTP2,
+		MaxConnsPerHost: c.transportOpts.MaxConnsPerHost,
+	}
+	if stats.Requests > 0 {
+		stats.ReuseRatio = float64(stats.ConnsReused) / float64(stats.Requests)
+	}
+	return stats
+}
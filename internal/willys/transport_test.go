@@ -0,0 +1,51 @@
+package willys
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPoolStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(srv.URL, "", "", WithTransport(TransportOptions{MaxConnsPerHost: 4, DisableHTTP2: true}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.DoRequest(context.Background(), "GET", EndpointCart, nil, false)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		discard(resp)
+	}
+
+	stats := client.PoolStats()
+	if stats.Requests != 3 || stats.ConnsOpened != 1 || stats.ConnsOpen != 1 || stats.ConnsReused != 2 {
+		t.Errorf("Expected one connection reused twice, got %+v", stats)
+	}
+	if stats.HTTP2 || stats.MaxConnsPerHost != 4 {
+		t.Errorf("Expected the options to be reported, got %+v", stats)
+	}
+
+	transport := client.httpClient.Transport.(*decodingTransport).next.(*http.Transport)
+	if transport.TLSNextProto == nil || transport.ForceAttemptHTTP2 {
+		t.Error("Expected HTTP/2 to be disabled")
+	}
+	if transport.MaxIdleConnsPerHost != defaultMaxIdleConnsPerHost || transport.IdleConnTimeout != 90*time.Second {
+		t.Errorf("Expected zero options to keep the defaults, got %d idle per host, %s idle timeout",
+			transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+
+	client.httpClient.CloseIdleConnections()
+	if n := client.PoolStats().ConnsOpen; n != 0 {
+		t.Errorf("Expected closed connections to be counted, got %d open", n)
+	}
+}
@@ -0,0 +1,102 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// VerificationCodeTimeout is how long browser login waits for a submitted code before
+// giving up. Willys' emailed/SMS codes expire after a few minutes anyway.
+const VerificationCodeTimeout = 5 * time.Minute
+
+var ErrNoVerificationPending = errors.New("no verification code has been requested")
+
+// verificationBroker hands a verification code from the MCP side (submit_verification_code)
+// to a browser login that is blocked on the code prompt.
+type verificationBroker struct {
+	mu        sync.Mutex
+	pending   bool
+	prompt    string
+	codes     chan string
+	requested chan struct{}
+}
+
+func newVerificationBroker() *verificationBroker {
+	return &verificationBroker{
+		codes:     make(chan string, 1),
+		requested: make(chan struct{}),
+	}
+}
+
+func (b *verificationBroker) await(ctx context.Context, prompt string) (string, error) {
+	b.mu.Lock()
+	select {
+	case <-b.codes: // drop a code that arrived after an earlier wait timed out
+	default:
+	}
+	b.pending = true
+	b.prompt = prompt
+	select {
+	case <-b.requested:
+	default:
+		close(b.requested)
+	}
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.pending = false
+		b.prompt = ""
+		b.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(VerificationCodeTimeout)
+	defer timer.Stop()
+
+	select {
+	case code := <-b.codes:
+		return code, nil
+	case <-timer.C:
+		return "", NewAuthenticationError("timed out waiting for verification code", nil)
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (b *verificationBroker) submit(code string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.pending {
+		return ErrNoVerificationPending
+	}
+	select {
+	case b.codes <- code:
+		return nil
+	default:
+		return NewValidationError("code", "a verification code has already been submitted")
+	}
+}
+
+func (b *verificationBroker) status() (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.prompt, b.pending
+}
+
+// SubmitVerificationCode completes a browser login that is waiting for an emailed or SMS
+// verification code.
+func (c *Client) SubmitVerificationCode(code string) error {
+	if code == "" {
+		return NewValidationError("code", "verification code cannot be empty")
+	}
+	return c.verification.submit(code)
+}
+
+// VerificationRequested is closed the first time a browser login stops at a verification
+// code prompt, so callers can start serving tools while the login waits for the code.
+func (c *Client) VerificationRequested() <-chan struct{} {
+	return c.verification.requested
+}
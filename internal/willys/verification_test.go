@@ -0,0 +1,51 @@
+package willys
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerificationBroker(t *testing.T) {
+	b := newVerificationBroker()
+
+	if err := b.submit("123456"); !errors.Is(err, ErrNoVerificationPending) {
+		t.Errorf("Expected ErrNoVerificationPending before a prompt, got %v", err)
+	}
+
+	result := make(chan string, 1)
+	go func() {
+		code, err := b.await(context.Background(), "Ange koden")
+		if err != nil {
+			t.Errorf("await failed: %v", err)
+		}
+		result <- code
+	}()
+
+	<-b.requested
+	if prompt, pending := b.status(); !pending || prompt != "Ange koden" {
+		t.Errorf("Expected pending prompt, got %q %v", prompt, pending)
+	}
+	if err := b.submit("123456"); err != nil {
+		t.Fatalf("submit failed: %v", err)
+	}
+
+	select {
+	case code := <-result:
+		if code != "123456" {
+			t.Errorf("Expected code 123456, got %q", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for code")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.await(ctx, "again"); err == nil {
+		t.Error("Expected error for cancelled context")
+	}
+	if _, pending := b.status(); pending {
+		t.Error("Expected no pending verification after await returns")
+	}
+}
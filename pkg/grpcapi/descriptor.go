@@ -0,0 +1,172 @@
+package grpcapi
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// ServiceName is the fully qualified gRPC service name.
+const ServiceName = "willys.v1.Willys"
+
+// The service is described in Go rather than generated from willys.proto, so the build
+// needs no protoc. Messages are dynamicpb values on the wire, which keeps them compatible
+// with stubs generated from willys.proto; TestProtoFileInSync keeps the two in step.
+var fileDescriptor = mustFile(&descriptorpb.FileDescriptorProto{
+	Name:    proto.String("willys/v1/willys.proto"),
+	Package: proto.String("willys.v1"),
+	Syntax:  proto.String("proto3"),
+	MessageType: []*descriptorpb.DescriptorProto{
+		message("Money",
+			scalar("ore", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+			scalar("currency", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("formatted", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		message("Product",
+			scalar("code", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			nested("price", 3, "Money"),
+			scalar("compare_price", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("compare_price_unit", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("display_volume", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("manufacturer", 7, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			repeated(scalar("labels", 8, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+			scalar("online", 9, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			scalar("stock_status", 10, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("image_url", 11, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		message("CartItem",
+			scalar("product_code", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("name", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("quantity", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			nested("price", 4, "Money"),
+			nested("total_price", 5, "Money"),
+			scalar("category", 6, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		message("Cart",
+			repeated(nested("items", 1, "CartItem")),
+			scalar("item_count", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			nested("total_price", 3, "Money"),
+			nested("delivery_fee", 4, "Money"),
+			nested("picking_fee", 5, "Money"),
+			nested("final_total", 6, "Money"),
+		),
+		message("TimeSlot",
+			scalar("slot_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("date", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("start_time", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("end_time", 4, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			nested("fee", 5, "Money"),
+			scalar("available", 6, descriptorpb.FieldDescriptorProto_TYPE_BOOL),
+			scalar("cutoff_unix", 7, descriptorpb.FieldDescriptorProto_TYPE_INT64),
+		),
+		message("SearchRequest",
+			scalar("query", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("page_size", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			scalar("max_results", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+		),
+		message("ProductRequest",
+			scalar("product_code", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		message("CartRequest"),
+		message("CartChange",
+			scalar("product_code", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+			scalar("quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+		),
+		message("TimeSlotsRequest",
+			scalar("postal_code", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+		),
+		message("TimeSlots",
+			repeated(nested("slots", 1, "TimeSlot")),
+		),
+	},
+	Service: []*descriptorpb.ServiceDescriptorProto{{
+		Name: proto.String("Willys"),
+		Method: []*descriptorpb.MethodDescriptorProto{
+			method("SearchProducts", "SearchRequest", "Product", true),
+			method("GetRelatedProducts", "ProductRequest", "Product", true),
+			method("GetCart", "CartRequest", "Cart", false),
+			method("AddToCart", "CartChange", "Cart", false),
+			method("RemoveFromCart", "CartChange", "Cart", false),
+			method("ClearCart", "CartRequest", "Cart", false),
+			method("GetTimeSlots", "TimeSlotsRequest", "TimeSlots", false),
+		},
+	}},
+})
+
+func init() {
+	// Registering the file lets the reflection service describe it to grpcurl and friends.
+	if err := protoregistry.GlobalFiles.RegisterFile(fileDescriptor); err != nil {
+		panic(err)
+	}
+}
+
+func mustFile(fd *descriptorpb.FileDescriptorProto) protoreflect.FileDescriptor {
+	file, err := protodesc.NewFile(fd, new(protoregistry.Files))
+	if err != nil {
+		panic(err)
+	}
+	return file
+}
+
+func messageDescriptor(name protoreflect.Name) protoreflect.MessageDescriptor {
+	return fileDescriptor.Messages().ByName(name)
+}
+
+func message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: proto.String(name), Field: fields}
+}
+
+func scalar(name string, number int32, kind descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:     proto.String(name),
+		JsonName: proto.String(jsonName(name)),
+		Number:   proto.Int32(number),
+		Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:     kind.Enum(),
+	}
+}
+
+func nested(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := scalar(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	field.TypeName = proto.String(".willys.v1." + typeName)
+	return field
+}
+
+func repeated(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldDescriptorProto {
+	field.Label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum()
+	return field
+}
+
+func method(name, input, output string, serverStreaming bool) *descriptorpb.MethodDescriptorProto {
+	m := &descriptorpb.MethodDescriptorProto{
+		Name:       proto.String(name),
+		InputType:  proto.String(".willys.v1." + input),
+		OutputType: proto.String(".willys.v1." + output),
+	}
+	if serverStreaming {
+		m.ServerStreaming = proto.Bool(true)
+	}
+	return m
+}
+
+// jsonName is protoc's default JSON name: snake_case to lowerCamelCase.
+func jsonName(name string) string {
+	out := make([]byte, 0, len(name))
+	upper := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper && 'a' <= c && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		upper = false
+		out = append(out, c)
+	}
+	return string(out)
+}
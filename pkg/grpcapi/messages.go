@@ -0,0 +1,108 @@
+package grpcapi
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func newMessage(name string) *dynamicpb.Message {
+	return dynamicpb.NewMessage(messageDescriptor(protoreflect.Name(name)))
+}
+
+func field(msg *dynamicpb.Message, name string) protoreflect.FieldDescriptor {
+	return msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+}
+
+func getString(msg *dynamicpb.Message, name string) string {
+	return msg.Get(field(msg, name)).String()
+}
+
+func getInt(msg *dynamicpb.Message, name string) int64 {
+	return msg.Get(field(msg, name)).Int()
+}
+
+func set(msg *dynamicpb.Message, name string, value any) {
+	switch v := value.(type) {
+	case int:
+		value = int32(v)
+	case *dynamicpb.Message:
+		value = v.ProtoReflect()
+	}
+	msg.Set(field(msg, name), protoreflect.ValueOf(value))
+}
+
+func mutableList(msg *dynamicpb.Message, name string) protoreflect.List {
+	return msg.Mutable(field(msg, name)).List()
+}
+
+func moneyMessage(m willys.Money) *dynamicpb.Message {
+	msg := newMessage("Money")
+	currency := m.Currency
+	if currency == "" {
+		currency = willys.DefaultCurrency
+	}
+	set(msg, "ore", m.Ore)
+	set(msg, "currency", currency)
+	set(msg, "formatted", m.String())
+	return msg
+}
+
+func productMessage(p willys.Product) *dynamicpb.Message {
+	msg := newMessage("Product")
+	set(msg, "code", p.Code)
+	set(msg, "name", p.Name)
+	set(msg, "price", moneyMessage(p.PriceValue))
+	// Products without a comparison price keep sending "" rather than "0,00 kr"
+	if !p.ComparePrice.IsZero() {
+		set(msg, "compare_price", p.ComparePrice.String())
+	}
+	set(msg, "compare_price_unit", p.ComparePriceUnit)
+	set(msg, "display_volume", p.DisplayVolume)
+	set(msg, "manufacturer", p.Manufacturer)
+	labels := mutableList(msg, "labels")
+	for _, label := range p.Labels {
+		labels.Append(protoreflect.ValueOfString(label))
+	}
+	set(msg, "online", p.Online)
+	set(msg, "stock_status", p.StockStatus)
+	set(msg, "image_url", p.Image.URL)
+	return msg
+}
+
+func cartMessage(cart *willys.CartSummary) *dynamicpb.Message {
+	msg := newMessage("Cart")
+	items := mutableList(msg, "items")
+	for _, item := range cart.Items {
+		line := newMessage("CartItem")
+		set(line, "product_code", item.ProductCode)
+		set(line, "name", item.Name)
+		set(line, "quantity", item.Quantity)
+		set(line, "price", moneyMessage(item.Price))
+		set(line, "total_price", moneyMessage(item.TotalPrice))
+		set(line, "category", item.Category)
+		items.Append(protoreflect.ValueOfMessage(line))
+	}
+	set(msg, "item_count", cart.ItemCount)
+	set(msg, "total_price", moneyMessage(cart.TotalPrice))
+	set(msg, "delivery_fee", moneyMessage(cart.DeliveryFee))
+	set(msg, "picking_fee", moneyMessage(cart.PickingFee))
+	set(msg, "final_total", moneyMessage(cart.FinalTotal))
+	return msg
+}
+
+// timeSlotMessage leaves cutoff_unix at 0 when the slot has no cut-off time.
+func timeSlotMessage(slot willys.TimeSlot) *dynamicpb.Message {
+	msg := newMessage("TimeSlot")
+	set(msg, "slot_id", slot.SlotID)
+	set(msg, "date", slot.Date)
+	set(msg, "start_time", slot.StartTime)
+	set(msg, "end_time", slot.EndTime)
+	set(msg, "fee", moneyMessage(slot.Fee))
+	set(msg, "available", slot.Available)
+	if !slot.CutoffTime.IsZero() {
+		set(msg, "cutoff_unix", slot.CutoffTime.Unix())
+	}
+	return msg
+}
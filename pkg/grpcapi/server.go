@@ -0,0 +1,293 @@
+// Package grpcapi serves the Willys client over gRPC for programs that want typed access
+// without speaking MCP or importing this module. The service is defined in willys.proto.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+const (
+	defaultSearchPageSize = 30
+	maxSearchPageSize     = 100
+
+	// SearchProducts streams up to max_results products, fetching pages as it goes.
+	defaultSearchResults = 100
+	maxSearchResults     = 500
+)
+
+type (
+	// Cart applies cart changes on behalf of the service. The MCP server passes one that
+	// runs them through its tool guards, so gRPC callers get the same quotas, middleware,
+	// and draft basket handling as agents.
+	Cart interface {
+		AddToCart(ctx context.Context, productCode string, quantity int) error
+		RemoveFromCart(ctx context.Context, productCode string, quantity int) error
+		ClearCart(ctx context.Context) error
+	}
+
+	service struct {
+		client willys.WillysAPI
+		cart   Cart
+		token  string
+	}
+)
+
+// ErrRejected wraps errors from a Cart that refused a change, e.g. over a quota; they are
+// returned as FailedPrecondition.
+var ErrRejected = errors.New("change rejected")
+
+// NewServer returns a gRPC server with the Willys service and server reflection
+// registered. Reads go to client and cart changes to cart. With a token, every call needs
+// "authorization: Bearer <token>" metadata.
+func NewServer(client willys.WillysAPI, cart Cart, token string) *grpc.Server {
+	s := &service{client: client, cart: cart, token: token}
+	srv := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := s.authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := s.authorize(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	)
+	srv.RegisterService(&serviceDesc, s)
+	reflection.Register(srv)
+	return srv
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		unary("GetCart", (*service).getCart),
+		unary("AddToCart", (*service).addToCart),
+		unary("RemoveFromCart", (*service).removeFromCart),
+		unary("ClearCart", (*service).clearCart),
+		unary("GetTimeSlots", (*service).getTimeSlots),
+	},
+	Streams: []grpc.StreamDesc{
+		serverStream("SearchProducts", (*service).searchProducts),
+		serverStream("GetRelatedProducts", (*service).relatedProducts),
+	},
+	Metadata: fileDescriptor.Path(),
+}
+
+func (s *service) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+func (s *service) getCart(ctx context.Context, _ *dynamicpb.Message) (*dynamicpb.Message, error) {
+	cart, err := s.client.GetCart(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return cartMessage(cart), nil
+}
+
+func (s *service) addToCart(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	code, quantity, err := cartChange(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if quantity == 0 {
+		quantity = 1
+	}
+	if err := s.cart.AddToCart(ctx, code, quantity); err != nil {
+		return nil, statusError(err)
+	}
+	return s.getCart(ctx, req)
+}
+
+// removeFromCart removes quantity pieces, or the whole line when quantity is 0.
+func (s *service) removeFromCart(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	code, quantity, err := cartChange(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if err := s.cart.RemoveFromCart(ctx, code, quantity); err != nil {
+		return nil, statusError(err)
+	}
+	return s.getCart(ctx, req)
+}
+
+func (s *service) clearCart(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	if err := s.cart.ClearCart(ctx); err != nil {
+		return nil, statusError(err)
+	}
+	return s.getCart(ctx, req)
+}
+
+// cartChange validates a CartChange. A quantity of 0 means the default, so only negative
+// quantities are rejected.
+func cartChange(req *dynamicpb.Message) (string, int, error) {
+	code := getString(req, "product_code")
+	if err := willys.ValidateProductCode(code); err != nil {
+		return "", 0, err
+	}
+	quantity := int(getInt(req, "quantity"))
+	if quantity < 0 {
+		return "", 0, willys.NewValidationError("quantity", "quantity must be positive")
+	}
+	return code, quantity, nil
+}
+
+func (s *service) getTimeSlots(ctx context.Context, req *dynamicpb.Message) (*dynamicpb.Message, error) {
+	slots, err := s.client.GetAvailableTimeSlots(ctx, getString(req, "postal_code"))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	out := newMessage("TimeSlots")
+	list := mutableList(out, "slots")
+	for _, slot := range slots {
+		list.Append(protoreflect.ValueOfMessage(timeSlotMessage(slot)))
+	}
+	return out, nil
+}
+
+func (s *service) searchProducts(ctx context.Context, req *dynamicpb.Message, send func(*dynamicpb.Message) error) error {
+	query := strings.TrimSpace(getString(req, "query"))
+	if query == "" {
+		return status.Error(codes.InvalidArgument, "query is required")
+	}
+	size := int(getInt(req, "page_size"))
+	if size == 0 {
+		size = defaultSearchPageSize
+	}
+	if size < 0 || size > maxSearchPageSize {
+		return status.Errorf(codes.InvalidArgument, "page_size must be between 1 and %d", maxSearchPageSize)
+	}
+	limit := int(getInt(req, "max_results"))
+	if limit == 0 {
+		limit = defaultSearchResults
+	}
+	if limit < 0 || limit > maxSearchResults {
+		return status.Errorf(codes.InvalidArgument, "max_results must be between 1 and %d", maxSearchResults)
+	}
+
+	sent := 0
+	for page := 0; sent < limit; page++ {
+		products, err := s.client.SearchProducts(ctx, query, page, size, nil)
+		if err != nil {
+			return statusError(err)
+		}
+		for _, product := range products {
+			if sent == limit {
+				return nil
+			}
+			if err := send(productMessage(product)); err != nil {
+				return err
+			}
+			sent++
+		}
+		if len(products) < size {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *service) relatedProducts(ctx context.Context, req *dynamicpb.Message, send func(*dynamicpb.Message) error) error {
+	products, err := s.client.GetRelatedProducts(ctx, getString(req, "product_code"))
+	if err != nil {
+		return statusError(err)
+	}
+	for _, product := range products {
+		if err := send(productMessage(product)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statusError maps client errors onto gRPC codes. Willys authentication failures are
+// FailedPrecondition: Unauthenticated is reserved for a bad bearer token.
+func statusError(err error) error {
+	var lockout *willys.LockoutError
+	code := codes.Unavailable
+	switch {
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case willys.IsValidationError(err):
+		code = codes.InvalidArgument
+	case willys.IsNotFoundError(err):
+		code = codes.NotFound
+	case willys.IsAuthenticationError(err):
+		code = codes.FailedPrecondition
+	case errors.As(err, &lockout):
+		code = codes.ResourceExhausted
+	case errors.Is(err, ErrRejected):
+		code = codes.FailedPrecondition
+	}
+	return status.Error(code, err.Error())
+}
+
+func unary(name string, call func(*service, context.Context, *dynamicpb.Message) (*dynamicpb.Message, error)) grpc.MethodDesc {
+	input := methodDescriptor(name).Input()
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			req := dynamicpb.NewMessage(input)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return call(srv.(*service), ctx, req.(*dynamicpb.Message))
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
+
+func serverStream(name string, call func(*service, context.Context, *dynamicpb.Message, func(*dynamicpb.Message) error) error) grpc.StreamDesc {
+	input := methodDescriptor(name).Input()
+	return grpc.StreamDesc{
+		StreamName:    name,
+		ServerStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			req := dynamicpb.NewMessage(input)
+			if err := stream.RecvMsg(req); err != nil {
+				return err
+			}
+			send := func(msg *dynamicpb.Message) error { return stream.SendMsg(msg) }
+			return call(srv.(*service), stream.Context(), req, send)
+		},
+	}
+}
+
+func methodDescriptor(name string) protoreflect.MethodDescriptor {
+	return fileDescriptor.Services().ByName("Willys").Methods().ByName(protoreflect.Name(name))
+}
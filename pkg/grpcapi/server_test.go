@@ -0,0 +1,247 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+type fakeClient struct {
+	willys.WillysAPI
+	cart  willys.CartSummary
+	pages int
+}
+
+func (c *fakeClient) SearchProducts(ctx context.Context, query string, page, size int, prefs *willys.SearchPreferences) ([]willys.Product, error) {
+	c.pages++
+	products := make([]willys.Product, size)
+	for i := range products {
+		products[i] = willys.Product{Code: fmt.Sprintf("%d_ST", page*size+i), Name: query, PriceValue: willys.SEK(1590)}
+	}
+	return products, nil
+}
+
+func (c *fakeClient) GetCart(ctx context.Context) (*willys.CartSummary, error) {
+	return &c.cart, nil
+}
+
+func (c *fakeClient) AddToCart(ctx context.Context, productCode string, quantity int) (*willys.CartSummary, error) {
+	if err := willys.ValidateProductCode(productCode); err != nil {
+		return nil, err
+	}
+	c.cart.Items = append(c.cart.Items, willys.CartItem{ProductCode: productCode, Quantity: quantity, Price: willys.SEK(1590)})
+	c.cart.ItemCount += quantity
+	return &c.cart, nil
+}
+
+// directCart applies changes straight to the fake client; rejectAll stands in for a
+// guard that refuses every change.
+type directCart struct {
+	client    *fakeClient
+	rejectAll bool
+}
+
+func (c directCart) AddToCart(ctx context.Context, productCode string, quantity int) error {
+	if c.rejectAll {
+		return fmt.Errorf("%w: cart_mutation quota exceeded", ErrRejected)
+	}
+	_, err := c.client.AddToCart(ctx, productCode, quantity)
+	return err
+}
+
+func (c directCart) RemoveFromCart(ctx context.Context, productCode string, quantity int) error {
+	return nil
+}
+
+func (c directCart) ClearCart(ctx context.Context) error {
+	c.client.cart = willys.CartSummary{}
+	return nil
+}
+
+func dial(t *testing.T, client *fakeClient, token string) *grpc.ClientConn {
+	return dialCart(t, client, directCart{client: client}, token)
+}
+
+func dialCart(t *testing.T, client *fakeClient, cart Cart, token string) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	srv := NewServer(client, cart, token)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUnaryCalls(t *testing.T) {
+	conn := dial(t, &fakeClient{}, "secret")
+	method := "/" + ServiceName + "/AddToCart"
+
+	req := newMessage("CartChange")
+	set(req, "product_code", "101_ST")
+	set(req, "quantity", 2)
+	reply := newMessage("Cart")
+	err := conn.Invoke(context.Background(), method, req, reply)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if err := conn.Invoke(ctx, method, req, reply); err != nil {
+		t.Fatalf("AddToCart failed: %v", err)
+	}
+	if getInt(reply, "item_count") != 2 {
+		t.Errorf("Expected 2 items, got %d", getInt(reply, "item_count"))
+	}
+	line := reply.Get(field(reply, "items")).List().Get(0).Message()
+	price := line.Get(line.Descriptor().Fields().ByName("price")).Message()
+	if price.Get(price.Descriptor().Fields().ByName("ore")).Int() != 1590 {
+		t.Errorf("Expected the price in öre, got %v", price)
+	}
+
+	set(req, "product_code", "")
+	if err := conn.Invoke(ctx, method, req, reply); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty product code, got %v", err)
+	}
+
+	set(req, "product_code", "101_ST")
+	set(req, "quantity", -1)
+	if err := conn.Invoke(ctx, method, req, reply); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a negative quantity, got %v", err)
+	}
+}
+
+func TestRejectedChange(t *testing.T) {
+	client := &fakeClient{}
+	conn := dialCart(t, client, directCart{client: client, rejectAll: true}, "")
+
+	req := newMessage("CartChange")
+	set(req, "product_code", "101_ST")
+	err := conn.Invoke(context.Background(), "/"+ServiceName+"/AddToCart", req, newMessage("Cart"))
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Expected FailedPrecondition for a rejected change, got %v", err)
+	}
+	if len(client.cart.Items) != 0 {
+		t.Errorf("Expected the cart to be untouched, got %+v", client.cart.Items)
+	}
+}
+
+func TestSearchStream(t *testing.T) {
+	client := &fakeClient{}
+	conn := dial(t, client, "")
+
+	desc := &grpc.StreamDesc{StreamName: "SearchProducts", ServerStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, "/"+ServiceName+"/SearchProducts")
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	req := newMessage("SearchRequest")
+	set(req, "query", "mjölk")
+	set(req, "page_size", 10)
+	set(req, "max_results", 25)
+	if err := stream.SendMsg(req); err != nil {
+		t.Fatalf("SendMsg failed: %v", err)
+	}
+	stream.CloseSend()
+
+	var got []string
+	for {
+		product := dynamicpb.NewMessage(messageDescriptor("Product"))
+		err := stream.RecvMsg(product)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("RecvMsg failed: %v", err)
+		}
+		got = append(got, getString(product, "code"))
+	}
+	if len(got) != 25 || got[24] != "24_ST" {
+		t.Errorf("Expected products 0-24, got %d ending in %v", len(got), got[len(got)-1:])
+	}
+	if client.pages != 3 {
+		t.Errorf("Expected 3 pages fetched, got %d", client.pages)
+	}
+}
+
+// TestProtoFileInSync renders the Go descriptor and compares it with willys.proto, which
+// is what consumers generate their stubs from.
+func TestProtoFileInSync(t *testing.T) {
+	data, err := os.ReadFile("willys.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			lines = append(lines, line)
+		}
+	}
+	if got, want := strings.Join(lines, "\n"), renderProto(fileDescriptor); got != want {
+		t.Errorf("willys.proto is out of date; expected:\n%s", want)
+	}
+}
+
+func renderProto(file protoreflect.FileDescriptor) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "syntax = %q;\n\npackage %s;\n", file.Syntax().String(), file.Package())
+	for i := 0; i < file.Messages().Len(); i++ {
+		msg := file.Messages().Get(i)
+		fmt.Fprintf(&b, "\nmessage %s {\n", msg.Name())
+		for j := 0; j < msg.Fields().Len(); j++ {
+			f := msg.Fields().Get(j)
+			typ := f.Kind().String()
+			if f.Kind() == protoreflect.MessageKind {
+				typ = string(f.Message().Name())
+			}
+			if f.IsList() {
+				typ = "repeated " + typ
+			}
+			fmt.Fprintf(&b, "  %s %s = %d;\n", typ, f.Name(), f.Number())
+		}
+		b.WriteString("}\n")
+	}
+	for i := 0; i < file.Services().Len(); i++ {
+		svc := file.Services().Get(i)
+		fmt.Fprintf(&b, "\nservice %s {\n", svc.Name())
+		for j := 0; j < svc.Methods().Len(); j++ {
+			m := svc.Methods().Get(j)
+			out := string(m.Output().Name())
+			if m.IsStreamingServer() {
+				out = "stream " + out
+			}
+			fmt.Fprintf(&b, "  rpc %s(%s) returns (%s);\n", m.Name(), m.Input().Name(), out)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+func TestProductMessageComparePrice(t *testing.T) {
+	if got := getString(productMessage(willys.Product{Code: "101"}), "compare_price"); got != "" {
+		t.Errorf("Expected no compare price, got %q", got)
+	}
+	if got := getString(productMessage(willys.Product{Code: "101", ComparePrice: willys.SEK(1590)}), "compare_price"); got == "" {
+		t.Error("Expected the compare price to be set")
+	}
+}
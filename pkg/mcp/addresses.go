@@ -0,0 +1,292 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var addressLabelPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// SavedAddress is an address book entry, stored under its label.
+type SavedAddress struct {
+	Label     string                 `json:"label"`
+	Address   willys.DeliveryAddress `json:"address"`
+	Default   bool                   `json:"default"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+func normalizeLabel(label string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if !addressLabelPattern.MatchString(label) {
+		return "", willys.NewValidationError("label", "use 1-32 letters, digits, '-' or '_' (e.g., 'home')")
+	}
+	return label, nil
+}
+
+// deliveryAddressProperties is the address object schema shared by the tools that take
+// a full address.
+func deliveryAddressProperties() map[string]any {
+	return map[string]any{
+		"first_name": map[string]any{
+			"type":        "string",
+			"description": "Recipient's first name",
+			"required":    true,
+		},
+		"last_name": map[string]any{
+			"type":        "string",
+			"description": "Recipient's last name",
+			"required":    true,
+		},
+		"address": map[string]any{
+			"type":        "string",
+			"description": "Street address (e.g., 'Drottninggatan 1')",
+			"required":    true,
+		},
+		"postal_code": map[string]any{
+			"type":        "string",
+			"description": "Postal code (e.g., '11151')",
+			"required":    true,
+		},
+		"city": map[string]any{
+			"type":        "string",
+			"description": "City name (e.g., 'Stockholm')",
+			"required":    true,
+		},
+		"door_code": map[string]any{
+			"type":        "string",
+			"description": "Optional door code for building access",
+		},
+		"message_to_driver": map[string]any{
+			"type":        "string",
+			"description": "Optional message to delivery driver (e.g., instructions or directions)",
+		},
+	}
+}
+
+func parseDeliveryAddress(addressData map[string]any) willys.DeliveryAddress {
+	return willys.DeliveryAddress{
+		FirstName:       getStringField(addressData, "first_name"),
+		LastName:        getStringField(addressData, "last_name"),
+		Address:         getStringField(addressData, "address"),
+		PostalCode:      getStringField(addressData, "postal_code"),
+		City:            getStringField(addressData, "city"),
+		DoorCode:        getStringField(addressData, "door_code"),
+		MessageToDriver: getStringField(addressData, "message_to_driver"),
+	}
+}
+
+// parseAddressText reads a one-line address. When the text names no recipient, the
+// account holder's name is used.
+func (h *ToolHandler) parseAddressText(ctx context.Context, text string) (willys.DeliveryAddress, error) {
+	address, err := willys.ParseAddressText(text)
+	if err != nil {
+		return address, err
+	}
+	if address.FirstName == "" || address.LastName == "" {
+		customer, err := h.client.GetCustomerInfo(ctx)
+		if err != nil {
+			return address, fmt.Errorf("address_text has no recipient name and the account name couldn't be read: %w", err)
+		}
+		address.FirstName, address.LastName = customer.FirstName, customer.LastName
+	}
+	return address, willys.ValidateDeliveryAddress(address)
+}
+
+func (h *ToolHandler) loadAddresses() ([]SavedAddress, error) {
+	entries, err := h.store.List(store.BucketAddresses)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]SavedAddress, 0, len(entries))
+	for label, data := range entries {
+		var saved SavedAddress
+		if err := json.Unmarshal(data, &saved); err != nil {
+			log.Printf("Skipping unreadable address %s: %v", label, err)
+			continue
+		}
+		addresses = append(addresses, saved)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addresses[i].Label < addresses[j].Label
+	})
+	return addresses, nil
+}
+
+// setDefaultAddress marks label as the default and clears the flag on every other entry.
+func (h *ToolHandler) setDefaultAddress(label string) error {
+	addresses, err := h.loadAddresses()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, saved := range addresses {
+		isDefault := saved.Label == label
+		found = found || isDefault
+		if saved.Default == isDefault {
+			continue
+		}
+		saved.Default = isDefault
+		if err := store.PutJSON(h.store, store.BucketAddresses, saved.Label, saved); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return willys.NewNotFoundError("address", label)
+	}
+	return nil
+}
+
+func (h *ToolHandler) getAddress(label string) (SavedAddress, error) {
+	var saved SavedAddress
+	if err := store.GetJSON(h.store, store.BucketAddresses, label, &saved); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return saved, fmt.Errorf("no saved address labelled %q, see list_addresses", label)
+		}
+		return saved, err
+	}
+	return saved, nil
+}
+
+// resolveAddress picks the delivery address for a tool call: an inline address object,
+// then address_text, then address_label, then the session's address, then the default
+// address book entry.
+func (h *ToolHandler) resolveAddress(ctx context.Context, request mcp.CallToolRequest) (willys.DeliveryAddress, error) {
+	if addressData := mcp.ParseStringMap(request, "address", nil); addressData != nil {
+		return parseDeliveryAddress(addressData), nil
+	}
+	if text := mcp.ParseString(request, "address_text", ""); text != "" {
+		return h.parseAddressText(ctx, text)
+	}
+
+	label := mcp.ParseString(request, "address_label", "")
+	if label == "" {
+		label = h.session(ctx).AddressLabel
+	}
+	if label != "" {
+		label, err := normalizeLabel(label)
+		if err != nil {
+			return willys.DeliveryAddress{}, err
+		}
+		saved, err := h.getAddress(label)
+		if err != nil {
+			return willys.DeliveryAddress{}, err
+		}
+		return saved.Address, nil
+	}
+
+	addresses, err := h.loadAddresses()
+	if err != nil {
+		return willys.DeliveryAddress{}, err
+	}
+	for _, saved := range addresses {
+		if saved.Default {
+			return saved.Address, nil
+		}
+	}
+	return willys.DeliveryAddress{}, errors.New("address or address_label parameter is required (no default address saved)")
+}
+
+func (h *ToolHandler) SaveAddress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	label, err := normalizeLabel(mcp.ParseString(request, "label", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid label: %v", err)), nil
+	}
+
+	addressData := mcp.ParseStringMap(request, "address", nil)
+	if addressData == nil {
+		return mcp.NewToolResultError("address parameter is required"), nil
+	}
+	address := parseDeliveryAddress(addressData)
+	if err := willys.ValidateDeliveryAddress(address); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid address: %v", err)), nil
+	}
+
+	existing, err := h.loadAddresses()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read address book: %v", err)), nil
+	}
+
+	saved := SavedAddress{Label: label, Address: address, UpdatedAt: time.Now()}
+	makeDefault := mcp.ParseBoolean(request, "default", false) || len(existing) == 0
+	for _, e := range existing {
+		if e.Label == label {
+			saved.Default = e.Default
+		}
+	}
+	if err := store.PutJSON(h.store, store.BucketAddresses, label, saved); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save address: %v", err)), nil
+	}
+	if makeDefault {
+		if err := h.setDefaultAddress(label); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("address saved but failed to make it the default: %v", err)), nil
+		}
+		saved.Default = true
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"saved": saved,
+	})
+}
+
+func (h *ToolHandler) ListAddresses(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	addresses, err := h.loadAddresses()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read address book: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"addresses": addresses,
+		"count":     len(addresses),
+	})
+}
+
+func (h *ToolHandler) DeleteAddress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	label, err := normalizeLabel(mcp.ParseString(request, "label", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid label: %v", err)), nil
+	}
+
+	if _, err := h.store.Get(store.BucketAddresses, label); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no saved address labelled %q", label)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read address: %v", err)), nil
+	}
+
+	if err := h.store.Delete(store.BucketAddresses, label); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete address: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"deleted": true,
+		"label":   label,
+	})
+}
+
+func (h *ToolHandler) SetDefaultAddress(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	label, err := normalizeLabel(mcp.ParseString(request, "label", ""))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid label: %v", err)), nil
+	}
+
+	if err := h.setDefaultAddress(label); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to set default address: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"default": label,
+	})
+}
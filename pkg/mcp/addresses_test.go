@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func toolRequest(args map[string]any) mcp.CallToolRequest {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+	return request
+}
+
+func TestAddressBook(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx := context.Background()
+
+	home := map[string]any{
+		"first_name": "Test", "last_name": "User", "address": "Drottninggatan 1",
+		"postal_code": "11151", "city": "Stockholm",
+	}
+	cabin := map[string]any{
+		"first_name": "Test", "last_name": "User", "address": "Stugvägen 3",
+		"postal_code": "76291", "city": "Rimbo",
+	}
+
+	for _, args := range []map[string]any{
+		{"label": "Home", "address": home},
+		{"label": "cabin", "address": cabin},
+	} {
+		result, err := h.SaveAddress(ctx, toolRequest(args))
+		if err != nil || result.IsError {
+			t.Fatalf("SaveAddress(%v) failed: %v %+v", args["label"], err, result)
+		}
+	}
+
+	if result, _ := h.SaveAddress(ctx, toolRequest(map[string]any{"label": "bad label!", "address": home})); !result.IsError {
+		t.Error("Expected invalid label to be rejected")
+	}
+
+	// The first saved address becomes the default
+	address, err := h.resolveAddress(ctx, toolRequest(nil))
+	if err != nil || address.City != "Stockholm" {
+		t.Errorf("Expected default address in Stockholm, got %+v (%v)", address, err)
+	}
+
+	address, err = h.resolveAddress(ctx, toolRequest(map[string]any{"address_label": "cabin"}))
+	if err != nil || address.City != "Rimbo" {
+		t.Errorf("Expected cabin address, got %+v (%v)", address, err)
+	}
+
+	if result, _ := h.SetDefaultAddress(ctx, toolRequest(map[string]any{"label": "cabin"})); result.IsError {
+		t.Fatalf("SetDefaultAddress failed: %+v", result)
+	}
+	addresses, _ := h.loadAddresses()
+	for _, a := range addresses {
+		if a.Default != (a.Label == "cabin") {
+			t.Errorf("Unexpected default flag on %s: %v", a.Label, a.Default)
+		}
+	}
+
+	if _, err := h.resolveAddress(ctx, toolRequest(map[string]any{"address_label": "parents"})); err == nil {
+		t.Error("Expected error for unknown label")
+	}
+}
+
+// customerClient returns a fixed account holder; other calls panic.
+type customerClient struct {
+	willys.WillysAPI
+}
+
+func (customerClient) GetCustomerInfo(ctx context.Context) (*willys.CustomerInfo, error) {
+	return &willys.CustomerInfo{FirstName: "Test", LastName: "User"}, nil
+}
+
+func TestResolveAddressText(t *testing.T) {
+	h := NewToolHandler(customerClient{})
+	ctx := context.Background()
+
+	address, err := h.resolveAddress(ctx, toolRequest(map[string]any{"address_text": "Drottninggatan 1, 111 51 Stockholm, portkod 1234"}))
+	expected := willys.DeliveryAddress{FirstName: "Test", LastName: "User", Address: "Drottninggatan 1", PostalCode: "11151", City: "Stockholm", DoorCode: "1234"}
+	if err != nil || address != expected {
+		t.Errorf("Expected %+v, got %+v (%v)", expected, address, err)
+	}
+
+	address, err = h.resolveAddress(ctx, toolRequest(map[string]any{"address_text": "Anna Berg, Storgatan 2, 753 21 Uppsala"}))
+	if err != nil || address.FirstName != "Anna" || address.LastName != "Berg" {
+		t.Errorf("Expected the recipient named in the text, got %+v (%v)", address, err)
+	}
+
+	if _, err := h.resolveAddress(ctx, toolRequest(map[string]any{"address_text": "Drottninggatan 1, Stockholm"})); !willys.IsValidationError(err) {
+		t.Errorf("Expected a validation error without postal code, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *ToolHandler) AdminAuthStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultJSON(h.client.AuthStatus())
+}
+
+func (h *ToolHandler) AdminCacheStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	buckets, err := h.store.Buckets()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list store buckets: %v", err)), nil
+	}
+
+	entries := make(map[string]int, len(buckets))
+	for _, bucket := range buckets {
+		values, err := h.store.List(bucket)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read bucket %s: %v", bucket, err)), nil
+		}
+		entries[bucket] = len(values)
+	}
+
+	schemaVersion, _ := store.SchemaVersion(h.store)
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"store_entries":     entries,
+		"schema_version":    schemaVersion,
+		"csrf_token_cached": h.client.AuthStatus().CSRFTokenCached,
+		"response_cache":    h.client.ResponseCacheStats(),
+	})
+}
+
+// cacheEndpoints are the names admin_invalidate_cache takes for the cached endpoints.
+var cacheEndpoints = map[string]string{
+	"deliverability":  willys.EndpointShippingDelivery,
+	"slots":           willys.EndpointSlotHomeDelivery,
+	"product_details": willys.EndpointProduct,
+}
+
+func (h *ToolHandler) AdminInvalidateCache(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	postalCode := mcp.ParseString(request, "postal_code", "")
+	if postalCode != "" {
+		if err := willys.ValidatePostalCode(postalCode); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+	endpoint := ""
+	if name := mcp.ParseString(request, "endpoint", ""); name != "" {
+		var ok bool
+		if endpoint, ok = cacheEndpoints[name]; !ok {
+			return mcp.NewToolResultError(fmt.Sprintf("unknown endpoint %q: use deliverability, slots, or product_details", name)), nil
+		}
+	}
+
+	dropped := h.client.InvalidateResponseCache(postalCode, endpoint)
+	return mcp.NewToolResultJSON(map[string]any{
+		"dropped":        dropped,
+		"response_cache": h.client.ResponseCacheStats(),
+	})
+}
+
+func (h *ToolHandler) AdminListBackgroundJobs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultJSON(map[string]any{
+		"jobs": h.jobs.Status(),
+	})
+}
+
+func (h *ToolHandler) PurgeLocalData(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if !mcp.ParseBoolean(request, "confirm", false) {
+		return mcp.NewToolResultError("confirm must be true to purge local data"), nil
+	}
+
+	purged, err := store.Purge(h.store)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to purge local data: %v", err)), nil
+	}
+	// Exported plans and calendars hold the delivery address
+	if err := os.RemoveAll(h.exportDir); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to remove exports: %v", err)), nil
+	}
+	purged = append(purged, "exports")
+
+	h.sessions.reset()
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"purged":  purged,
+		"message": "All locally stored data has been deleted",
+	})
+}
+
+// Only settings that are safe to swap on a running server are applied; credentials,
+// base URL, and data directory are reported as requiring a restart.
+func (h *ToolHandler) AdminReloadConfig(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if h.configLoader == nil {
+		return mcp.NewToolResultError("config reload is not available"), nil
+	}
+
+	cfg, err := h.configLoader()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to reload config: %v", err)), nil
+	}
+
+	h.mu.Lock()
+	h.pickPolicy = cfg.PickPolicy
+	h.outputDetail = cfg.OutputDetail
+	h.ownBrand = cfg.OwnBrand
+	h.diet = cfg.Diet
+	h.weeklyBudget = willys.MoneyFromFloat(cfg.WeeklyBudget)
+	h.priceAlertPercent = cfg.PriceAlertPercent
+	h.appLinkBase = cfg.AppLinkBase
+	h.mailFrom = cfg.Mail.From
+	h.summaryRecipients = cfg.SummaryRecipients
+	h.mu.Unlock()
+
+	if err := willys.SetPriceLocale(cfg.PriceLocale); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to apply price locale: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"applied":             []string{"pick_policy", "price_locale", "output_detail", "own_brand", "diet", "weekly_budget", "price_alert_percent", "app_link_base", "summary_recipients"},
+		"requires_restart":    []string{"base_url", "credentials", "data_dir", "admin_tools", "debug_http", "http_transport", "decode", "endpoints", "mobile_api", "mail_transport", "transport", "grpc", "mcp_log_level", "features", "response_cache", "b2b"},
+		"pick_policy":         cfg.PickPolicy,
+		"price_locale":        cfg.PriceLocale,
+		"output_detail":       cfg.OutputDetail,
+		"own_brand":           cfg.OwnBrand,
+		"diet":                cfg.Diet,
+		"weekly_budget":       cfg.WeeklyBudget,
+		"price_alert_percent": cfg.PriceAlertPercent,
+		"app_link_base":       cfg.AppLinkBase,
+	})
+}
+
+func (h *ToolHandler) APIHealthReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	reports := h.client.DriftReports()
+
+	drifting := 0
+	for _, r := range reports {
+		if len(r.MissingFields) > 0 || len(r.TypeMismatches) > 0 || r.DecodeErrors > 0 {
+			drifting++
+		}
+	}
+
+	status := "healthy"
+	if !h.client.StrictDecode() {
+		status = "unknown (strict decode disabled, set WILLYS_STRICT_DECODE=true)"
+	} else if drifting > 0 {
+		status = "degraded"
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"status":             status,
+		"strict_decode":      h.client.StrictDecode(),
+		"drifting_endpoints": drifting,
+		"endpoints":          reports,
+		"tools":              h.metrics.snapshot(),
+		"connection_pool":    h.client.PoolStats(),
+		"endpoint_overrides": h.client.EndpointOverrides(),
+	})
+}
@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolBehavior describes what a tool does to the world, published to clients as MCP
+// tool annotations so they can ask for confirmation before cart changes and the like.
+type toolBehavior struct {
+	readOnly    bool // changes nothing, locally or at Willys
+	destructive bool // may remove or overwrite existing state
+	idempotent  bool // repeating the call with the same arguments changes nothing more
+	openWorld   bool // talks to Willys rather than only local state
+}
+
+var (
+	readsWillys  = toolBehavior{readOnly: true, idempotent: true, openWorld: true}
+	readsLocal   = toolBehavior{readOnly: true, idempotent: true}
+	addsToCart   = toolBehavior{openWorld: true}
+	replacesCart = toolBehavior{destructive: true, openWorld: true}
+)
+
+// toolBehaviors is the single registry of tool annotations; every registered tool must
+// have an entry.
+var toolBehaviors = map[string]toolBehavior{
+	"search_groceries":                  readsWillys,
+	"search_many":                       readsWillys,
+	"whats_new":                         readsWillys,
+	"related_products":                  readsWillys,
+	"suggest_complements":               readsWillys,
+	"pre_checkout_review":               readsWillys,
+	"sync_cart":                         readsWillys,
+	"whats_expiring":                    readsWillys,
+	"plan_budget":                       readsWillys,
+	"view_cart":                         readsWillys,
+	"cart_climate_report":               readsWillys,
+	"get_available_time_slots":          readsWillys,
+	"check_deliverability":              readsWillys,
+	"send_order_summary":                {openWorld: true},
+	"delivery_fee_overview":             readsWillys,
+	"plan_fulfillment":                  readsWillys,
+	"compare_with_last_order":           readsWillys,
+	"price_changes_since_last_purchase": readsWillys,
+	"proceed_to_checkout":               {idempotent: true, openWorld: true}, // may set the payment type
+	"diagnose_checkout":                 readsWillys,
+	"list_payment_methods":              readsWillys,
+	"server_capabilities":               readsWillys,
+
+	"add_to_cart":              addsToCart,
+	"list_to_cart":             addsToCart,
+	"parse_ingredients":        addsToCart,
+	"commit_basket":            addsToCart,
+	"apply_template":           addsToCart,
+	"submit_verification_code": addsToCart,
+	"remove_from_cart":         replacesCart,
+	"optimize_cart_cost":       replacesCart,
+	"export_plan":              {openWorld: true}, // writes a PDF file when asked
+	"export_delivery_calendar": {idempotent: true, openWorld: true},
+	"select_delivery_time":     {destructive: true, idempotent: true, openWorld: true},
+	"release_delivery_slot":    {destructive: true, idempotent: true, openWorld: true},
+	"logout":                   {destructive: true, idempotent: true, openWorld: true},
+
+	"list_addresses":          readsLocal,
+	"list_members":            readsLocal,
+	"list_schedules":          readsLocal,
+	"view_pantry":             readsLocal,
+	"list_baskets":            readsLocal,
+	"list_templates":          readsLocal,
+	"view_session_context":    readsLocal,
+	"update_pantry":           {destructive: true, idempotent: true},
+	"create_basket":           {},
+	"switch_basket":           {idempotent: true},
+	"delete_basket":           {destructive: true, idempotent: true},
+	"save_template":           {destructive: true, idempotent: true},
+	"delete_template":         {destructive: true, idempotent: true},
+	"save_address":            {destructive: true, idempotent: true},
+	"delete_address":          {destructive: true, idempotent: true},
+	"set_default_address":     {idempotent: true},
+	"save_member":             {destructive: true, idempotent: true},
+	"delete_member":           {destructive: true, idempotent: true},
+	"assign_cart_item":        {destructive: true, idempotent: true},
+	"set_session_context":     {idempotent: true},
+	"create_schedule":         {},
+	"cancel_schedule":         {destructive: true, idempotent: true},
+	"configure_slot_autobook": {destructive: true, idempotent: true},
+
+	"admin_auth_status":          readsLocal,
+	"admin_cache_stats":          readsLocal,
+	"admin_invalidate_cache":     {idempotent: true},
+	"admin_list_background_jobs": readsLocal,
+	"api_health_report":          readsLocal,
+	"admin_purge_local_data":     {destructive: true, idempotent: true},
+	"admin_reload_config":        {idempotent: true},
+}
+
+// annotate sets the behavior hints of tool from toolBehaviors. Tools missing from the
+// registry keep mcp-go's conservative defaults (destructive, open world).
+func annotate(tool *mcp.Tool) {
+	behavior, ok := toolBehaviors[tool.Name]
+	if !ok {
+		log.Printf("No behavior annotations registered for tool %s", tool.Name)
+		return
+	}
+	tool.Annotations.ReadOnlyHint = mcp.ToBoolPtr(behavior.readOnly)
+	tool.Annotations.DestructiveHint = mcp.ToBoolPtr(behavior.destructive)
+	tool.Annotations.IdempotentHint = mcp.ToBoolPtr(behavior.idempotent)
+	tool.Annotations.OpenWorldHint = mcp.ToBoolPtr(behavior.openWorld)
+}
@@ -0,0 +1,31 @@
+package mcp
+
+import "testing"
+
+func TestEveryToolIsAnnotated(t *testing.T) {
+	s := NewServer(nil, WithAdminTools(true))
+
+	tools := s.mcpServer.ListTools()
+	if len(tools) == 0 {
+		t.Fatal("Expected registered tools")
+	}
+	for name := range tools {
+		if _, ok := toolBehaviors[name]; !ok {
+			t.Errorf("Tool %s has no entry in toolBehaviors", name)
+		}
+	}
+	for name := range toolBehaviors {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("toolBehaviors lists unknown tool %s", name)
+		}
+	}
+
+	viewCart := tools["view_cart"].Tool.Annotations
+	if !*viewCart.ReadOnlyHint || *viewCart.DestructiveHint {
+		t.Errorf("Expected view_cart to be read-only, got %+v", viewCart)
+	}
+	removeFromCart := tools["remove_from_cart"].Tool.Annotations
+	if *removeFromCart.ReadOnlyHint || !*removeFromCart.DestructiveHint {
+		t.Errorf("Expected remove_from_cart to be destructive, got %+v", removeFromCart)
+	}
+}
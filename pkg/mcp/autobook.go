@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/schedule"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	autobookKey          = "preferences"
+	autobookPollInterval = 30 * time.Second
+	autobookJitter       = 0.1
+
+	// Start polling a little before the release in case the server clock or Willys runs early
+	autobookLead = 2 * time.Minute
+
+	// minReleaseInterval is the shortest time allowed between two releases, and
+	// maxAutobookPollMinutes caps the polling after each, so the auto-booker can't be
+	// configured to poll the slot endpoint around the clock.
+	minReleaseInterval     = 12 * time.Hour
+	maxAutobookPollMinutes = 60
+
+	DefaultSlotReleaseCron     = "0 0 * * *"
+	DefaultAutobookPollMinutes = 15
+)
+
+type (
+	// SlotAutobook holds the opt-in preferences for reserving a slot as soon as Willys
+	// releases new delivery days.
+	SlotAutobook struct {
+		Enabled     bool         `json:"enabled"`
+		PostalCode  string       `json:"postalCode"`
+		ReleaseCron string       `json:"releaseCron"`
+		PollMinutes int          `json:"pollMinutes"`
+		Window      SlotWindow   `json:"window"`
+		MaxFee      willys.Money `json:"maxFee"` // zero means no limit
+		LastBooking *SlotBooking `json:"lastBooking,omitempty"`
+
+		// Horizon is the last delivery date that was bookable just before a release;
+		// only later slots are new
+		Horizon *SlotHorizon `json:"horizon,omitempty"`
+		// SkippedRelease is a release left alone because a slot was already reserved
+		SkippedRelease time.Time `json:"skippedRelease,omitempty"`
+	}
+
+	SlotHorizon struct {
+		Release  time.Time `json:"release"`
+		LastDate string    `json:"lastDate"` // YYYY-MM-DD
+	}
+
+	SlotBooking struct {
+		Release  time.Time       `json:"release"`
+		BookedAt time.Time       `json:"bookedAt"`
+		Slot     willys.TimeSlot `json:"slot"`
+
+		// ReleasedAt is set when release_delivery_slot gave the slot back. The booking is
+		// kept so the same release isn't booked again.
+		ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+	}
+)
+
+// activeRelease returns the release the auto-booker should currently be polling for: one
+// starting within autobookLead, or one that started less than pollFor ago.
+func activeRelease(cron *schedule.Cron, now time.Time, pollFor time.Duration) (time.Time, bool) {
+	release := cron.Next(now.Add(-pollFor - time.Minute))
+	if release.IsZero() {
+		return time.Time{}, false
+	}
+	if now.Before(release.Add(-autobookLead)) || now.After(release.Add(pollFor)) {
+		return time.Time{}, false
+	}
+	return release, true
+}
+
+func (h *ToolHandler) loadAutobook() (SlotAutobook, error) {
+	prefs := SlotAutobook{ReleaseCron: DefaultSlotReleaseCron, PollMinutes: DefaultAutobookPollMinutes}
+	err := store.GetJSON(h.store, store.BucketSlotAutobook, autobookKey, &prefs)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return prefs, err
+	}
+	return prefs, nil
+}
+
+// pollAutobook tries to reserve a preferred slot if a release window is open and nothing
+// has been booked for it yet. It returns the new booking, if any.
+func (h *ToolHandler) pollAutobook(ctx context.Context, now time.Time) (*SlotBooking, error) {
+	prefs, err := h.loadAutobook()
+	if err != nil || !prefs.Enabled {
+		return nil, err
+	}
+
+	cron, err := schedule.ParseCron(prefs.ReleaseCron)
+	if err != nil {
+		return nil, err
+	}
+	release, ok := activeRelease(cron, now, time.Duration(prefs.PollMinutes)*time.Minute)
+	if !ok || (prefs.LastBooking != nil && prefs.LastBooking.Release.Equal(release)) || prefs.SkippedRelease.Equal(release) {
+		return nil, nil
+	}
+
+	slots, err := h.client.GetAvailableTimeSlots(willys.WithFreshResponses(ctx), prefs.PostalCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time slots: %w", err)
+	}
+
+	// Before the release, only note how far the bookable days reach. Those slots were
+	// open all along and aren't what the auto-booker waits for.
+	if now.Before(release) {
+		prefs.Horizon = &SlotHorizon{Release: release, LastDate: lastSlotDate(slots)}
+		if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+			return nil, fmt.Errorf("failed to record slot horizon: %w", err)
+		}
+		return nil, nil
+	}
+
+	released := releasedSlots(slots, prefs.Horizon, release)
+	var candidates []willys.TimeSlot
+	for _, slot := range released {
+		if prefs.MaxFee.IsZero() || slot.Fee.Ore <= prefs.MaxFee.Ore {
+			candidates = append(candidates, slot)
+		}
+	}
+	slot := cheapestSlotInWindow(candidates, prefs.Window)
+	if slot == nil {
+		return nil, nil
+	}
+
+	// Never swap a reservation the user already has, whoever made it
+	current, err := h.client.GetSlotInCart(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the reserved slot: %w", err)
+	}
+	if current != nil {
+		log.Printf("Slot auto-booking skipped: slot %s %s-%s is already reserved", current.Date, current.StartTime, current.EndTime)
+		prefs.SkippedRelease = release
+		if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+			log.Printf("Failed to record skipped release: %v", err)
+		}
+		return nil, nil
+	}
+
+	// Reserve and record together, so a restart can't leave a booking the next run
+	// doesn't know about.
+	booking := &SlotBooking{Release: release, BookedAt: now, Slot: *slot}
+	err = h.nonInterruptible(ctx, "slot auto-booking", func(ctx context.Context) error {
+		if err := h.client.SelectTimeSlot(ctx, *slot); err != nil {
+			return fmt.Errorf("failed to reserve slot %s %s-%s: %w", slot.Date, slot.StartTime, slot.EndTime, err)
+		}
+		prefs.LastBooking = booking
+		if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+			log.Printf("Failed to record slot booking: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// releasedSlots keeps the slots after the horizon noted before release. Without one, e.g.
+// when the server started after the release, only the last listed day counts as new.
+func releasedSlots(slots []willys.TimeSlot, horizon *SlotHorizon, release time.Time) []willys.TimeSlot {
+	var released []willys.TimeSlot
+	if horizon != nil && horizon.Release.Equal(release) {
+		for _, slot := range slots {
+			if slot.Date > horizon.LastDate {
+				released = append(released, slot)
+			}
+		}
+		return released
+	}
+
+	last := lastSlotDate(slots)
+	for _, slot := range slots {
+		if slot.Date == last {
+			released = append(released, slot)
+		}
+	}
+	return released
+}
+
+func lastSlotDate(slots []willys.TimeSlot) string {
+	var last string
+	for _, slot := range slots {
+		last = max(last, slot.Date)
+	}
+	return last
+}
+
+// autobookJob polls for new slots around each release.
+func (s *Server) autobookJob() schedule.Job {
+	return schedule.Job{
+		Name:     "slot_autobook",
+		Interval: autobookPollInterval,
+		Jitter:   autobookJitter,
+		Run: func(ctx context.Context, now time.Time) error {
+			runCtx := willys.WithCorrelationID(ctx, willys.NewCorrelationID())
+			booking, err := s.toolHandler.pollAutobook(runCtx, now)
+			if err != nil {
+				s.broadcast(mcp.LoggingLevelError, fmt.Sprintf("Slot auto-booking failed: %v", err), map[string]any{
+					"event": "slot_autobook_failed",
+				})
+				return err
+			}
+			if booking != nil {
+				s.broadcast(mcp.LoggingLevelNotice, fmt.Sprintf("Reserved delivery slot %s %s-%s (%s)",
+					booking.Slot.Date, booking.Slot.StartTime, booking.Slot.EndTime, booking.Slot.Fee), map[string]any{
+					"event":   "slot_autobooked",
+					"booking": booking,
+				})
+			}
+			return nil
+		},
+	}
+}
+
+func (h *ToolHandler) ConfigureSlotAutobook(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	prefs, err := h.loadAutobook()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to load auto-book preferences: %v", err)), nil
+	}
+
+	args := request.GetArguments()
+	if _, ok := args["enabled"]; ok {
+		prefs.Enabled = mcp.ParseBoolean(request, "enabled", false)
+	}
+	if postalCode := mcp.ParseString(request, "postal_code", ""); postalCode != "" {
+		if err := willys.ValidatePostalCode(postalCode); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid postal code: %v", err)), nil
+		}
+		prefs.PostalCode = postalCode
+	}
+	if releaseCron := mcp.ParseString(request, "release_cron", ""); releaseCron != "" {
+		prefs.ReleaseCron = releaseCron
+	}
+	if pollMinutes := mcp.ParseInt(request, "poll_minutes", 0); pollMinutes > 0 {
+		if pollMinutes > maxAutobookPollMinutes {
+			return mcp.NewToolResultError(fmt.Sprintf("poll_minutes may be at most %d", maxAutobookPollMinutes)), nil
+		}
+		prefs.PollMinutes = pollMinutes
+	}
+	if _, ok := args["max_fee"]; ok {
+		prefs.MaxFee = willys.MoneyFromFloat(mcp.ParseFloat64(request, "max_fee", 0))
+	}
+	if windowData := mcp.ParseStringMap(request, "slot_window", nil); windowData != nil {
+		prefs.Window = parseSlotWindow(windowData)
+	}
+
+	cron, err := schedule.ParseCron(prefs.ReleaseCron)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid release_cron: %v", err)), nil
+	}
+	if err := checkCronInterval(cron, time.Now(), minReleaseInterval); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid release_cron: %v", err)), nil
+	}
+	if err := prefs.Window.validate(); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid slot_window: %v", err)), nil
+	}
+	if prefs.Enabled && prefs.PostalCode == "" {
+		return mcp.NewToolResultError("postal_code is required to enable auto-booking"), nil
+	}
+
+	if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save auto-book preferences: %v", err)), nil
+	}
+
+	result := map[string]any{
+		"preferences": prefs,
+	}
+	if prefs.Enabled {
+		result["next_release"] = cron.Next(time.Now())
+	}
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,134 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/schedule"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestActiveRelease(t *testing.T) {
+	cron, err := schedule.ParseCron("0 6 * * *")
+	if err != nil {
+		t.Fatalf("ParseCron failed: %v", err)
+	}
+	release := time.Date(2026, 10, 16, 6, 0, 0, 0, time.UTC)
+	pollFor := 15 * time.Minute
+
+	tests := []struct {
+		now    time.Time
+		active bool
+	}{
+		{release.Add(-10 * time.Minute), false},
+		{release.Add(-time.Minute), true},
+		{release, true},
+		{release.Add(14 * time.Minute), true},
+		{release.Add(16 * time.Minute), false},
+	}
+
+	for _, tt := range tests {
+		got, ok := activeRelease(cron, tt.now, pollFor)
+		if ok != tt.active {
+			t.Errorf("At %s: expected active=%v, got %v", tt.now.Format("15:04"), tt.active, ok)
+			continue
+		}
+		if ok && !got.Equal(release) {
+			t.Errorf("At %s: expected release %v, got %v", tt.now.Format("15:04"), release, got)
+		}
+	}
+}
+
+type autobookClient struct {
+	warningsClient
+	slots    []willys.TimeSlot
+	inCart   *willys.TimeSlot
+	selected []string
+}
+
+func (c *autobookClient) GetAvailableTimeSlots(ctx context.Context, postalCode string) ([]willys.TimeSlot, error) {
+	return c.slots, nil
+}
+
+func (c *autobookClient) GetSlotInCart(ctx context.Context) (*willys.TimeSlot, error) {
+	return c.inCart, nil
+}
+
+func (c *autobookClient) SelectTimeSlot(ctx context.Context, slot willys.TimeSlot) error {
+	c.selected = append(c.selected, slot.SlotID)
+	return nil
+}
+
+func TestPollAutobookBooksReleasedSlotsOnly(t *testing.T) {
+	release := time.Date(2026, 10, 16, 0, 0, 0, 0, time.Local)
+	open := []willys.TimeSlot{
+		{SlotID: "old-cheap", Date: "2026-10-22", StartTime: "08:00", EndTime: "10:00", Fee: willys.SEK(0), Available: true},
+	}
+	released := append(open, willys.TimeSlot{SlotID: "new", Date: "2026-10-23", StartTime: "18:00", EndTime: "20:00", Fee: willys.SEK(4900), Available: true})
+
+	setup := func(client *autobookClient) *ToolHandler {
+		h := NewToolHandler(client)
+		prefs := SlotAutobook{Enabled: true, PostalCode: "11151", ReleaseCron: "0 0 * * *", PollMinutes: 15}
+		if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	client := &autobookClient{slots: open}
+	h := setup(client)
+	if booking, err := h.pollAutobook(context.Background(), release.Add(-time.Minute)); err != nil || booking != nil {
+		t.Fatalf("Expected no booking before the release, got %+v (%v)", booking, err)
+	}
+
+	client.slots = released
+	booking, err := h.pollAutobook(context.Background(), release.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("pollAutobook failed: %v", err)
+	}
+	if booking == nil || booking.Slot.SlotID != "new" || len(client.selected) != 1 {
+		t.Errorf("Expected the released slot to be booked, got %+v (selected %v)", booking, client.selected)
+	}
+
+	// An existing reservation is left alone
+	client = &autobookClient{slots: released, inCart: &open[0]}
+	h = setup(client)
+	if booking, err := h.pollAutobook(context.Background(), release.Add(time.Minute)); err != nil || booking != nil {
+		t.Fatalf("Expected no booking over a reservation, got %+v (%v)", booking, err)
+	}
+	if len(client.selected) != 0 {
+		t.Errorf("Expected no slot to be selected, got %v", client.selected)
+	}
+	prefs, _ := h.loadAutobook()
+	if !prefs.SkippedRelease.Equal(release) {
+		t.Errorf("Expected the release to be marked skipped, got %v", prefs.SkippedRelease)
+	}
+}
+
+func TestConfigureSlotAutobookLimits(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		args  map[string]any
+		valid bool
+	}{
+		{map[string]any{"release_cron": "0 6 * * *", "poll_minutes": 30}, true},
+		{map[string]any{"release_cron": "0 0,12 * * *"}, true},
+		{map[string]any{"release_cron": "* * * * *"}, false},
+		{map[string]any{"release_cron": "0 * * * *"}, false},
+		{map[string]any{"poll_minutes": 60}, true},
+		{map[string]any{"poll_minutes": 600}, false},
+	}
+	for _, tt := range tests {
+		result, err := h.ConfigureSlotAutobook(ctx, toolRequest(tt.args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.IsError == tt.valid {
+			t.Errorf("%v: expected valid=%v, got %+v", tt.args, tt.valid, result.Content)
+		}
+	}
+}
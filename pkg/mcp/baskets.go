@@ -0,0 +1,290 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// liveBasket names the real Willys cart in switch_basket; drafts can't use it.
+const liveBasket = "live"
+
+type (
+	// DraftBasket is a locally stored cart, stored under its name, that is only sent to
+	// Willys by commit_basket.
+	DraftBasket struct {
+		Name      string       `json:"name"`
+		Items     []BasketItem `json:"items"`
+		CreatedAt time.Time    `json:"createdAt"`
+		UpdatedAt time.Time    `json:"updatedAt"`
+	}
+
+	// BasketItem keeps the origin and household member of the line so they can be
+	// recorded on commit.
+	BasketItem struct {
+		Code     string `json:"code"`
+		Name     string `json:"name,omitempty"`
+		Quantity int    `json:"quantity"`
+		Source   string `json:"source"`
+		Query    string `json:"query,omitempty"`
+		Member   string `json:"member,omitempty"`
+	}
+)
+
+func normalizeBasketName(name string) (string, error) {
+	name, err := normalizeLabel(name)
+	if err != nil {
+		return "", willys.NewValidationError("name", "use 1-32 letters, digits, '-' or '_' (e.g., 'next-week')")
+	}
+	if name == liveBasket {
+		return "", willys.NewValidationError("name", fmt.Sprintf("%q is reserved for the Willys cart", liveBasket))
+	}
+	return name, nil
+}
+
+// activeBasket returns the draft basket the session's cart tools currently write to, or
+// "" for the live cart.
+func (h *ToolHandler) activeBasket(ctx context.Context) string {
+	return h.session(ctx).Basket
+}
+
+func (h *ToolHandler) getBasket(name string) (*DraftBasket, error) {
+	var basket DraftBasket
+	if err := store.GetJSON(h.store, store.BucketBaskets, name, &basket); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, willys.NewNotFoundError("basket", name)
+		}
+		return nil, err
+	}
+	return &basket, nil
+}
+
+// ensureBasket creates the named draft if it doesn't exist yet.
+func (h *ToolHandler) ensureBasket(name string) error {
+	if _, err := h.getBasket(name); !willys.IsNotFoundError(err) {
+		return err
+	}
+	now := time.Now()
+	return store.PutJSON(h.store, store.BucketBaskets, name, DraftBasket{Name: name, Items: []BasketItem{}, CreatedAt: now, UpdatedAt: now})
+}
+
+func (h *ToolHandler) loadBaskets() ([]DraftBasket, error) {
+	entries, err := h.store.List(store.BucketBaskets)
+	if err != nil {
+		return nil, err
+	}
+
+	baskets := make([]DraftBasket, 0, len(entries))
+	for name, data := range entries {
+		var basket DraftBasket
+		if err := json.Unmarshal(data, &basket); err != nil {
+			log.Printf("Skipping unreadable basket %s: %v", name, err)
+			continue
+		}
+		baskets = append(baskets, basket)
+	}
+	sort.Slice(baskets, func(i, j int) bool {
+		return baskets[i].Name < baskets[j].Name
+	})
+	return baskets, nil
+}
+
+// addToBasket adds item to the named draft, merging it with a line for the same product.
+func (h *ToolHandler) addToBasket(name string, item BasketItem) (*DraftBasket, error) {
+	basket, err := h.getBasket(name)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := false
+	for i := range basket.Items {
+		if basket.Items[i].Code == item.Code {
+			basket.Items[i].Quantity += item.Quantity
+			if basket.Items[i].Member == "" {
+				basket.Items[i].Member = item.Member
+			}
+			merged = true
+			break
+		}
+	}
+	if !merged {
+		basket.Items = append(basket.Items, item)
+	}
+	basket.UpdatedAt = time.Now()
+
+	if err := store.PutJSON(h.store, store.BucketBaskets, name, basket); err != nil {
+		return nil, err
+	}
+	return basket, nil
+}
+
+// addToCartOrBasket adds a product to the active draft basket, or to the Willys cart when
+// no draft is active, and records where it came from.
+func (h *ToolHandler) addToCartOrBasket(ctx context.Context, basket string, item BasketItem) error {
+	if basket != "" {
+		_, err := h.addToBasket(basket, item)
+		return err
+	}
+	cart, err := h.client.AddToCart(ctx, item.Code, item.Quantity)
+	if err != nil {
+		return err
+	}
+	h.forgetRemovedItems(cart)
+	h.recordOrigin(ctx, item.Code, item.Source, item.Query, item.Quantity)
+	h.tagMember(item.Code, item.Member)
+	return nil
+}
+
+func (h *ToolHandler) CreateBasket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := normalizeBasketName(mcp.ParseString(request, "name", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if _, err := h.getBasket(name); err == nil {
+		return mcp.NewToolResultError(fmt.Sprintf("basket %q already exists", name)), nil
+	}
+
+	now := time.Now()
+	basket := DraftBasket{Name: name, Items: []BasketItem{}, CreatedAt: now, UpdatedAt: now}
+	if err := store.PutJSON(h.store, store.BucketBaskets, name, basket); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create basket: %v", err)), nil
+	}
+
+	if mcp.ParseBoolean(request, "switch", true) {
+		h.updateSession(ctx, func(sc *SessionContext) { sc.Basket = name })
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"basket": basket,
+		"active": h.activeBasket(ctx) == name,
+	})
+}
+
+func (h *ToolHandler) SwitchBasket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := mcp.ParseString(request, "name", liveBasket)
+
+	if name != liveBasket {
+		var err error
+		if name, err = normalizeBasketName(name); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if _, err := h.getBasket(name); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	h.updateSession(ctx, func(sc *SessionContext) {
+		sc.Basket = name
+		if name == liveBasket {
+			sc.Basket = ""
+		}
+	})
+
+	return mcp.NewToolResultJSON(map[string]any{"active": name})
+}
+
+func (h *ToolHandler) ListBaskets(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	baskets, err := h.loadBaskets()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list baskets: %v", err)), nil
+	}
+
+	active := h.activeBasket(ctx)
+	if active == "" {
+		active = liveBasket
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"active":  active,
+		"baskets": baskets,
+		"count":   len(baskets),
+	})
+}
+
+func (h *ToolHandler) CommitBasket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := normalizeBasketName(mcp.ParseString(request, "name", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	basket, err := h.getBasket(name)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(basket.Items) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("basket %q is empty", name)), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaCartMutation, len(basket.Items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Lines that fail stay in the draft so the commit can be retried.
+	total := len(basket.Items)
+	var failed []BasketItem
+	var errs []string
+	for _, item := range basket.Items {
+		if err := h.addToCartOrBasket(ctx, "", item); err != nil {
+			failed = append(failed, item)
+			errs = append(errs, fmt.Sprintf("%s: %v", item.Code, err))
+		}
+	}
+
+	if len(failed) == 0 && !mcp.ParseBoolean(request, "keep", false) {
+		if err := h.store.Delete(store.BucketBaskets, name); err != nil {
+			log.Printf("Failed to delete committed basket %s: %v", name, err)
+		}
+		h.forgetBasket(name)
+	} else if len(failed) > 0 {
+		basket.Items = failed
+		basket.UpdatedAt = time.Now()
+		if err := store.PutJSON(h.store, store.BucketBaskets, name, basket); err != nil {
+			log.Printf("Failed to save uncommitted items of basket %s: %v", name, err)
+		}
+	}
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("basket committed but failed to get cart: %v", err)), nil
+	}
+
+	result := map[string]any{
+		"committed": total - len(failed),
+		"cart":      cart,
+	}
+	if len(failed) > 0 {
+		result["failed"] = failed
+		result["errors"] = errs
+	}
+	return mcp.NewToolResultJSON(result)
+}
+
+func (h *ToolHandler) DeleteBasket(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := normalizeBasketName(mcp.ParseString(request, "name", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if _, err := h.getBasket(name); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := h.store.Delete(store.BucketBaskets, name); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete basket: %v", err)), nil
+	}
+
+	h.forgetBasket(name)
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"deleted": true,
+		"name":    name,
+	})
+}
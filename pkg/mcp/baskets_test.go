@@ -0,0 +1,103 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDraftBaskets(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx := context.Background()
+
+	if result, _ := h.CreateBasket(ctx, toolRequest(map[string]any{"name": "live"})); !result.IsError {
+		t.Error("Expected 'live' to be reserved")
+	}
+	if result, err := h.CreateBasket(ctx, toolRequest(map[string]any{"name": "Next-Week"})); err != nil || result.IsError {
+		t.Fatalf("CreateBasket failed: %v %+v", err, result)
+	}
+	if h.activeBasket(ctx) != "next-week" {
+		t.Fatalf("Expected new basket to be active, got %q", h.activeBasket(ctx))
+	}
+
+	// With a draft active, add_to_cart never reaches the (nil) Willys client
+	for _, qty := range []float64{2, 1} {
+		result, err := h.AddToCart(ctx, toolRequest(map[string]any{"product_code": "101233933_ST", "quantity": qty}))
+		if err != nil || result.IsError {
+			t.Fatalf("AddToCart failed: %v %+v", err, result)
+		}
+	}
+	basket, err := h.getBasket("next-week")
+	if err != nil {
+		t.Fatalf("getBasket failed: %v", err)
+	}
+	if len(basket.Items) != 1 || basket.Items[0].Quantity != 3 || basket.Items[0].Source != "add_to_cart" {
+		t.Errorf("Expected one merged line of 3, got %+v", basket.Items)
+	}
+
+	if result, _ := h.SwitchBasket(ctx, toolRequest(map[string]any{"name": "missing"})); !result.IsError {
+		t.Error("Expected switching to an unknown basket to fail")
+	}
+	if result, _ := h.SwitchBasket(ctx, toolRequest(nil)); result.IsError || h.activeBasket(ctx) != "" {
+		t.Errorf("Expected switch to the live cart, got %q", h.activeBasket(ctx))
+	}
+
+	if result, _ := h.SwitchBasket(ctx, toolRequest(map[string]any{"name": "next-week"})); result.IsError {
+		t.Fatalf("SwitchBasket failed: %+v", result)
+	}
+	if result, _ := h.DeleteBasket(ctx, toolRequest(map[string]any{"name": "next-week"})); result.IsError {
+		t.Fatalf("DeleteBasket failed: %+v", result)
+	}
+	if h.activeBasket(ctx) != "" {
+		t.Error("Expected deleting the active basket to switch back to the live cart")
+	}
+}
+
+type failingAddClient struct {
+	restClient
+	fail string
+}
+
+func (c *failingAddClient) AddToCart(ctx context.Context, productCode string, quantity int) (*willys.CartSummary, error) {
+	if productCode == c.fail {
+		return nil, errors.New("out of stock")
+	}
+	return c.restClient.AddToCart(ctx, productCode, quantity)
+}
+
+func TestCommitBasketReportsPartialFailure(t *testing.T) {
+	client := &failingAddClient{
+		restClient: restClient{warningsClient: warningsClient{cart: &willys.CartSummary{}}, added: map[string]int{}},
+		fail:       "102",
+	}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	if result, err := h.CreateBasket(ctx, toolRequest(map[string]any{"name": "weekly"})); err != nil || result.IsError {
+		t.Fatalf("CreateBasket failed: %v %+v", err, result)
+	}
+	for _, code := range []string{"101", "102", "103"} {
+		if result, err := h.AddToCart(ctx, toolRequest(map[string]any{"product_code": code, "quantity": float64(1)})); err != nil || result.IsError {
+			t.Fatalf("AddToCart failed: %v %+v", err, result)
+		}
+	}
+
+	result, err := h.CommitBasket(ctx, toolRequest(map[string]any{"name": "weekly"}))
+	if err != nil || result.IsError {
+		t.Fatalf("CommitBasket failed: %v %+v", err, result)
+	}
+	var body struct {
+		Committed int          `json:"committed"`
+		Failed    []BasketItem `json:"failed"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Committed != 2 || len(body.Failed) != 1 || body.Failed[0].Code != "102" {
+		t.Errorf("Expected 2 committed and 102 failed, got %+v", body)
+	}
+}
@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/effati/willys-mcp/internal/recipe"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// budgetItems resolves the plan given to plan_budget: a saved template, explicit list
+// items, or a meal plan as ingredient lines, in that order of precedence.
+func (h *ToolHandler) budgetItems(request mcp.CallToolRequest) ([]listItem, error) {
+	if name := normalizeQuery(mcp.ParseString(request, "template", "")); name != "" {
+		var t OrderTemplate
+		if err := store.GetJSON(h.store, store.BucketTemplates, name, &t); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				return nil, fmt.Errorf("no template named %q", name)
+			}
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		return t.resolve(mcp.ParseFloat64(request, "value", t.Default)), nil
+	}
+
+	if items := parseListItems(request.GetArguments()["items"]); len(items) > 0 {
+		return items, nil
+	}
+
+	if text := mcp.ParseString(request, "meal_plan", ""); text != "" {
+		pantry, err := h.loadPantry()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pantry: %w", err)
+		}
+		items, _ := ingredientListItems(recipe.Parse(text), pantry)
+		return items, nil
+	}
+
+	return nil, errors.New("one of template, items, or meal_plan is required")
+}
+
+// budgetLines prices picked products and falls back to the average price paid in past
+// orders for items search couldn't price. History is only fetched when needed.
+func (h *ToolHandler) budgetLines(ctx context.Context, results []map[string]any) []willys.BudgetLine {
+	var orders []willys.Order
+	historyLoaded := false
+
+	lines := make([]willys.BudgetLine, 0, len(results))
+	for _, result := range results {
+		line := willys.BudgetLine{
+			Query:    result["query"].(string),
+			Quantity: result["quantity"].(int),
+		}
+		line.Member, _ = result["member"].(string)
+
+		pick, _ := result["pick"].(willys.PickResult)
+		if pick.Product != nil && !pick.Product.PriceValue.IsZero() {
+			line.Code = pick.Product.Code
+			line.Name = pick.Product.Name
+			line.Cost = pick.Product.PriceValue.Mul(line.Quantity)
+		} else {
+			if !historyLoaded {
+				historyLoaded = true
+				var err error
+				if orders, err = h.client.GetOrderHistory(ctx); err != nil {
+					orders = nil
+				}
+			}
+			if price, ok := willys.HistoricalPrice(orders, line.Query); ok {
+				line.Cost = price.Mul(line.Quantity)
+				line.Estimated = true
+			} else {
+				line.Cost = willys.SEK(0)
+				line.Unpriced = true
+			}
+		}
+
+		name := line.Name
+		if name == "" {
+			name = line.Query
+		}
+		line.Category = willys.CategorizeCartItem(willys.CartItem{Name: name}).Name
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (h *ToolHandler) PlanBudget(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	budget := mcp.ParseFloat64(request, "budget", 0)
+	if budget <= 0 {
+		return mcp.NewToolResultError("budget must be a positive amount in kronor"), nil
+	}
+
+	items, err := h.budgetItems(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(items) == 0 {
+		return mcp.NewToolResultError("the plan has no items to price"), nil
+	}
+	if err := h.resolveItemMembers(items, mcp.ParseString(request, "member", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	policy, err := h.parsePickPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid policy: %v", err)), nil
+	}
+	if err := h.consumeQuota(ctx, quotaSearch, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := h.priceListItems(ctx, items, policy)
+	plan := willys.PlanBudget(h.budgetLines(ctx, results), willys.MoneyFromFloat(budget))
+
+	return mcp.NewToolResultJSON(plan)
+}
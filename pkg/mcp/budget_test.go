@@ -0,0 +1,26 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBudgetItems(t *testing.T) {
+	h := NewToolHandler(nil)
+
+	if _, err := h.budgetItems(toolRequest(nil)); err == nil {
+		t.Error("Expected an error without template, items, or meal_plan")
+	}
+	if _, err := h.budgetItems(toolRequest(map[string]any{"template": "missing"})); err == nil {
+		t.Error("Expected an error for an unknown template")
+	}
+
+	items, err := h.budgetItems(toolRequest(map[string]any{"meal_plan": "4 ägg\nSalt\n500 g kycklingfilé"}))
+	if err != nil {
+		t.Fatalf("budgetItems failed: %v", err)
+	}
+	expected := []listItem{{Query: "ägg", Quantity: 4}, {Query: "kycklingfilé", Quantity: 1}}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, items)
+	}
+}
@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/export"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const bookedDeliveryKey = "current"
+
+// BookedDelivery is the delivery last set up through select_delivery_time.
+type BookedDelivery struct {
+	Delivery willys.DeliveryInfo `json:"delivery"`
+	BookedAt time.Time           `json:"bookedAt"`
+}
+
+// recordDelivery remembers a booked delivery for export_delivery_calendar. Failures are
+// logged: the booking itself already succeeded.
+func (h *ToolHandler) recordDelivery(info *willys.DeliveryInfo) {
+	booked := BookedDelivery{Delivery: *info, BookedAt: time.Now()}
+	if err := store.PutJSON(h.store, store.BucketDeliveries, bookedDeliveryKey, booked); err != nil {
+		log.Printf("Failed to record booked delivery: %v", err)
+	}
+}
+
+// latestDelivery returns the most recent booking still held, from select_delivery_time or
+// the slot auto-booker; auto-booked slots carry no address.
+func (h *ToolHandler) latestDelivery() (*BookedDelivery, error) {
+	var latest *BookedDelivery
+
+	var booked BookedDelivery
+	err := store.GetJSON(h.store, store.BucketDeliveries, bookedDeliveryKey, &booked)
+	if err == nil {
+		latest = &booked
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	prefs, err := h.loadAutobook()
+	if err != nil {
+		return nil, err
+	}
+	if b := prefs.LastBooking; b != nil && b.ReleasedAt == nil && (latest == nil || b.BookedAt.After(latest.BookedAt)) {
+		latest = &BookedDelivery{Delivery: willys.DeliveryInfo{TimeSlot: b.Slot, DeliveryFee: b.Slot.Fee}, BookedAt: b.BookedAt}
+	}
+	return latest, nil
+}
+
+// forgetDelivery drops the recorded bookings after their slot was released.
+func (h *ToolHandler) forgetDelivery(now time.Time) error {
+	if err := h.store.Delete(store.BucketDeliveries, bookedDeliveryKey); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+
+	prefs, err := h.loadAutobook()
+	if err != nil {
+		return err
+	}
+	if b := prefs.LastBooking; b != nil && b.ReleasedAt == nil {
+		b.ReleasedAt = &now
+		return store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs)
+	}
+	return nil
+}
+
+func (h *ToolHandler) ReleaseDeliverySlot(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	booked, err := h.latestDelivery()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read booked delivery: %v", err)), nil
+	}
+
+	err = h.nonInterruptible(ctx, "release_delivery_slot", func(ctx context.Context) error {
+		return h.client.ReleaseTimeSlot(ctx)
+	})
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to release delivery slot: %v", err)), nil
+	}
+
+	if err := h.forgetDelivery(time.Now()); err != nil {
+		log.Printf("Failed to forget released delivery: %v", err)
+	}
+
+	result := map[string]any{"released": true}
+	if booked != nil {
+		result["slot"] = booked.Delivery.TimeSlot
+	}
+	return mcp.NewToolResultJSON(result)
+}
+
+// slotWindow returns when a slot starts and ends, from its timestamps or else its date
+// and local HH:MM times.
+func slotWindow(slot willys.TimeSlot) (time.Time, time.Time, error) {
+	if slot.EarliestDateTime > 0 && slot.LatestDateTime > slot.EarliestDateTime {
+		return slot.EarliestDateTime.Time(), slot.LatestDateTime.Time(), nil
+	}
+	start, err := time.ParseInLocation("2006-01-02 15:04", slot.Date+" "+slot.StartTime, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("slot has no usable start time: %w", err)
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04", slot.Date+" "+slot.EndTime, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("slot has no usable end time: %w", err)
+	}
+	return start, end, nil
+}
+
+// deliveryEvent describes a booked delivery as a calendar event. The door code is left
+// out: the file is mailed and synced to calendar providers.
+func deliveryEvent(booked *BookedDelivery, orderRef string) (export.Event, error) {
+	slot := booked.Delivery.TimeSlot
+	start, end, err := slotWindow(slot)
+	if err != nil {
+		return export.Event{}, err
+	}
+
+	event := export.Event{
+		UID:     export.EventUID("delivery", slot.Date, slot.StartTime),
+		Start:   start,
+		End:     end,
+		Summary: "Willys grocery delivery",
+	}
+
+	address := booked.Delivery.Address
+	if address.Address != "" {
+		event.Location = fmt.Sprintf("%s, %s %s", address.Address, address.PostalCode, address.City)
+	}
+
+	var details []string
+	if orderRef != "" {
+		details = append(details, "Order reference: "+orderRef)
+	}
+	if !booked.Delivery.DeliveryFee.IsZero() {
+		details = append(details, "Delivery fee: "+booked.Delivery.DeliveryFee.String())
+	}
+	if !slot.CutoffTime.IsZero() {
+		details = append(details, "Order can be changed until "+slot.CutoffTime.Format("2006-01-02 15:04"))
+	}
+	event.Description = strings.Join(details, "\n")
+	return event, nil
+}
+
+func (h *ToolHandler) ExportDeliveryCalendar(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	booked, err := h.latestDelivery()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read booked delivery: %v", err)), nil
+	}
+	if booked == nil {
+		return mcp.NewToolResultError("no delivery has been booked; use select_delivery_time first"), nil
+	}
+
+	// The cart GUID is the only order reference there is before the order is placed.
+	orderRef := ""
+	if cart, err := h.client.GetCart(ctx); err == nil {
+		orderRef = cart.GUID
+	} else {
+		log.Printf("Exporting delivery without order reference: %v", err)
+	}
+
+	event, err := deliveryEvent(booked, orderRef)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	ics := export.ICal([]export.Event{event}, time.Now())
+
+	result := map[string]any{
+		"ics":   ics,
+		"start": event.Start,
+		"end":   event.End,
+	}
+	if mcp.ParseBoolean(request, "save", false) {
+		if err := os.MkdirAll(h.exportDir, 0o700); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to create export directory: %v", err)), nil
+		}
+		path := filepath.Join(h.exportDir, "delivery-"+event.Start.Format("20060102-1504")+".ics")
+		if err := os.WriteFile(path, []byte(ics), 0o600); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save calendar: %v", err)), nil
+		}
+		result["path"] = path
+	}
+
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestLatestDelivery(t *testing.T) {
+	h := NewToolHandler(nil)
+
+	if booked, err := h.latestDelivery(); err != nil || booked != nil {
+		t.Fatalf("Expected no delivery, got %+v (%v)", booked, err)
+	}
+
+	now := time.Now()
+	h.recordDelivery(&willys.DeliveryInfo{
+		Address:  willys.DeliveryAddress{Address: "Drottninggatan 1", PostalCode: "11151", City: "Stockholm", DoorCode: "4711"},
+		TimeSlot: willys.TimeSlot{Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00"},
+	})
+
+	// An older auto-booking doesn't replace the manual one
+	prefs := SlotAutobook{LastBooking: &SlotBooking{BookedAt: now.Add(-time.Hour), Slot: willys.TimeSlot{Date: "2026-10-21"}}}
+	if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+		t.Fatal(err)
+	}
+
+	booked, err := h.latestDelivery()
+	if err != nil || booked == nil || booked.Delivery.TimeSlot.Date != "2026-10-20" {
+		t.Fatalf("Expected the manual booking, got %+v (%v)", booked, err)
+	}
+
+	event, err := deliveryEvent(booked, "guid-123")
+	if err != nil {
+		t.Fatalf("deliveryEvent failed: %v", err)
+	}
+	if event.Start.Hour() != 17 || event.End.Sub(event.Start) != 2*time.Hour {
+		t.Errorf("Unexpected window %v - %v", event.Start, event.End)
+	}
+	if event.Location != "Drottninggatan 1, 11151 Stockholm" || !strings.Contains(event.Description, "guid-123") {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if strings.Contains(event.Description, "4711") {
+		t.Errorf("Expected the door code to be left out, got %q", event.Description)
+	}
+}
+
+// releaseClient records slot releases; other calls panic.
+type releaseClient struct {
+	willys.WillysAPI
+	released int
+}
+
+func (c *releaseClient) ReleaseTimeSlot(ctx context.Context) error {
+	c.released++
+	return nil
+}
+
+func TestReleaseDeliverySlot(t *testing.T) {
+	client := &releaseClient{}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	h.recordDelivery(&willys.DeliveryInfo{TimeSlot: willys.TimeSlot{Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00"}})
+	prefs := SlotAutobook{LastBooking: &SlotBooking{BookedAt: time.Now().Add(-time.Hour), Slot: willys.TimeSlot{Date: "2026-10-21"}}}
+	if err := store.PutJSON(h.store, store.BucketSlotAutobook, autobookKey, prefs); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := h.ReleaseDeliverySlot(ctx, toolRequest(nil))
+	if err != nil || result.IsError {
+		t.Fatalf("ReleaseDeliverySlot failed: %v %+v", err, result)
+	}
+	if client.released != 1 {
+		t.Errorf("Expected one release call, got %d", client.released)
+	}
+
+	if booked, err := h.latestDelivery(); err != nil || booked != nil {
+		t.Errorf("Expected no booking after release, got %+v (%v)", booked, err)
+	}
+	prefs, _ = h.loadAutobook()
+	if prefs.LastBooking == nil || prefs.LastBooking.ReleasedAt == nil {
+		t.Errorf("Expected the auto-booking to be kept and marked released, got %+v", prefs.LastBooking)
+	}
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"sort"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type (
+	// Capabilities lets an agent adapt to this server instance, e.g. skip cart tools
+	// when they aren't offered or the session isn't logged in.
+	Capabilities struct {
+		Name          string           `json:"name"`
+		Version       string           `json:"version"`
+		Features      map[string]any   `json:"features"`
+		Authenticated bool             `json:"authenticated"`
+		AuthState     string           `json:"authState,omitempty"`
+		AuthMethod    string           `json:"authMethod,omitempty"`
+		ActiveStore   *willys.StoreRef `json:"activeStore,omitempty"`
+		Tools         []ToolInfo       `json:"tools"`
+	}
+
+	ToolInfo struct {
+		Name        string `json:"name"`
+		ReadOnly    bool   `json:"readOnly"`
+		Destructive bool   `json:"destructive"`
+	}
+)
+
+func (s *Server) capabilities() Capabilities {
+	h := s.toolHandler
+	h.mu.RLock()
+	features := map[string]any{
+		"admin_tools":       s.adminTools,
+		"scheduler":         s.scheduler,
+		"slot_autobook":     s.autobook,
+		"polite_mode":       s.politeMode,
+		"pick_strategy":     h.pickPolicy.Strategy,
+		"output_detail":     h.outputDetail,
+		"own_brand":         h.ownBrand,
+		"price_locale":      willys.PriceLocale(),
+		"order_mail":        h.mailer != nil,
+		"semantic_matching": h.matcher != nil,
+		"flags":             s.features,
+	}
+	h.mu.RUnlock()
+
+	caps := Capabilities{
+		Name:     ServerName,
+		Version:  ServerVersion,
+		Features: features,
+		Tools:    []ToolInfo{},
+	}
+	if s.client != nil {
+		status := s.client.AuthStatus()
+		caps.Authenticated = status.Authenticated
+		caps.AuthState = status.State
+		caps.AuthMethod = status.AuthMethod
+		caps.ActiveStore = status.ActiveStore
+		features["strict_decode"] = s.client.StrictDecode()
+	}
+
+	for name, tool := range s.mcpServer.ListTools() {
+		annotations := tool.Tool.Annotations
+		caps.Tools = append(caps.Tools, ToolInfo{
+			Name:        name,
+			ReadOnly:    annotations.ReadOnlyHint != nil && *annotations.ReadOnlyHint,
+			Destructive: annotations.DestructiveHint == nil || *annotations.DestructiveHint,
+		})
+	}
+	sort.Slice(caps.Tools, func(i, j int) bool { return caps.Tools[i].Name < caps.Tools[j].Name })
+
+	return caps
+}
+
+func (s *Server) ServerCapabilities(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultJSON(s.capabilities())
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/features"
+)
+
+func TestCapabilities(t *testing.T) {
+	s := NewServer(nil, WithAdminTools(false))
+
+	caps := s.capabilities()
+	if caps.Version != ServerVersion {
+		t.Errorf("Expected version %s, got %s", ServerVersion, caps.Version)
+	}
+	if caps.Features["admin_tools"] != false {
+		t.Errorf("Expected admin_tools disabled, got %v", caps.Features["admin_tools"])
+	}
+
+	tools := make(map[string]ToolInfo, len(caps.Tools))
+	for _, tool := range caps.Tools {
+		tools[tool.Name] = tool
+	}
+	if _, ok := tools["admin_auth_status"]; ok {
+		t.Error("Expected admin tools to be left out when disabled")
+	}
+	if tool, ok := tools["search_groceries"]; !ok || !tool.ReadOnly {
+		t.Errorf("Expected read-only search_groceries, got %+v", tool)
+	}
+	if tool := tools["add_to_cart"]; tool.ReadOnly {
+		t.Error("Expected add_to_cart not to be read-only")
+	}
+}
+
+func TestCapabilitiesFeatureFlags(t *testing.T) {
+	flags := features.Defaults()
+	flags[features.SlotAutobook] = false
+	s := NewServer(nil, WithFeatures(flags), WithSlotAutobook(true))
+
+	caps := s.capabilities()
+	if caps.Features["slot_autobook"] != false {
+		t.Error("Expected slot_autobook off when its flag is off")
+	}
+	if got := caps.Features["flags"].(features.Flags); got[features.SlotAutobook] || !got[features.ScheduledOrders] {
+		t.Errorf("Expected flags in capabilities, got %v", got)
+	}
+	tools := make(map[string]bool, len(caps.Tools))
+	for _, tool := range caps.Tools {
+		tools[tool.Name] = true
+	}
+	if tools["configure_slot_autobook"] {
+		t.Error("Expected configure_slot_autobook to be left out when its flag is off")
+	}
+	if !tools["create_schedule"] {
+		t.Error("Expected create_schedule while scheduled_orders is on")
+	}
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	CartSourceMirror = "mirror"
+	CartSourceAPI    = "api"
+
+	// cartMirrorMaxAge is how long view_cart and the cart warnings answer from the local
+	// copy of the cart before asking Willys again.
+	cartMirrorMaxAge = 10 * time.Minute
+
+	// cartMirrorStaleAfter is when a mirrored cart is flagged stale: changes made on
+	// willys.se or in the app since then aren't reflected.
+	cartMirrorStaleAfter = time.Minute
+)
+
+// CartState says where a cart came from and how current it is.
+type CartState struct {
+	Source   string    `json:"source"`
+	SyncedAt time.Time `json:"syncedAt"`
+	Stale    bool      `json:"stale"`
+}
+
+// currentCart returns the mirrored cart while it is recent enough, and otherwise, or
+// when refresh is set, fetches it.
+func (h *ToolHandler) currentCart(ctx context.Context, refresh bool) (*willys.CartSummary, CartState, error) {
+	if !refresh {
+		if cart, syncedAt, ok := h.client.CachedCart(); ok {
+			if age := time.Since(syncedAt); age <= cartMirrorMaxAge {
+				return cart, CartState{Source: CartSourceMirror, SyncedAt: syncedAt, Stale: age > cartMirrorStaleAfter}, nil
+			}
+		}
+	}
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return nil, CartState{}, err
+	}
+	return cart, CartState{Source: CartSourceAPI, SyncedAt: time.Now()}, nil
+}
+
+func (h *ToolHandler) SyncCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	mirrored, syncedAt, hadMirror := h.client.CachedCart()
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+
+	result := map[string]any{
+		"cart":      h.annotateCart(cart),
+		"synced_at": time.Now(),
+	}
+	if hadMirror {
+		changes := willys.DiffCarts(mirrored, cart)
+		result["changes"] = changes
+		result["in_sync"] = len(changes) == 0 && mirrored.TotalPrice == cart.TotalPrice
+		result["previous_sync"] = syncedAt
+	}
+	return mcp.NewToolResultJSON(result)
+}
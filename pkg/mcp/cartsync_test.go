@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mirrorClient serves a mirrored cart and a different cart from Willys; other calls panic.
+type mirrorClient struct {
+	willys.WillysAPI
+	mirrored *willys.CartSummary
+	syncedAt time.Time
+	remote   *willys.CartSummary
+	fetches  int
+}
+
+func (c *mirrorClient) CachedCart() (*willys.CartSummary, time.Time, bool) {
+	return c.mirrored, c.syncedAt, c.mirrored != nil
+}
+
+func (c *mirrorClient) GetCart(ctx context.Context) (*willys.CartSummary, error) {
+	c.fetches++
+	return c.remote, nil
+}
+
+func TestCurrentCart(t *testing.T) {
+	client := &mirrorClient{
+		mirrored: &willys.CartSummary{Items: []willys.CartItem{{ProductCode: "milk", Quantity: 1}}},
+		syncedAt: time.Now().Add(-2 * time.Minute),
+		remote:   &willys.CartSummary{Items: []willys.CartItem{{ProductCode: "milk", Quantity: 2}}},
+	}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	cart, state, err := h.currentCart(ctx, false)
+	if err != nil || cart != client.mirrored || state.Source != CartSourceMirror || !state.Stale || client.fetches != 0 {
+		t.Errorf("Expected the stale mirror without a fetch, got %+v (%v), %d fetches", state, err, client.fetches)
+	}
+
+	if cart, state, _ = h.currentCart(ctx, true); cart != client.remote || state.Source != CartSourceAPI {
+		t.Errorf("Expected refresh to fetch the cart, got %+v", state)
+	}
+
+	client.syncedAt = time.Now().Add(-cartMirrorMaxAge - time.Minute)
+	if _, state, _ = h.currentCart(ctx, false); state.Source != CartSourceAPI {
+		t.Errorf("Expected an expired mirror to be ignored, got %+v", state)
+	}
+}
+
+func TestSyncCart(t *testing.T) {
+	client := &mirrorClient{
+		mirrored: &willys.CartSummary{Items: []willys.CartItem{{ProductCode: "milk", Name: "Mjölk", Quantity: 1}}},
+		syncedAt: time.Now(),
+		remote: &willys.CartSummary{Items: []willys.CartItem{
+			{ProductCode: "milk", Name: "Mjölk", Quantity: 2},
+			{ProductCode: "bread", Name: "Bröd", Quantity: 1},
+		}},
+	}
+	h := NewToolHandler(client)
+
+	result, err := h.SyncCart(context.Background(), toolRequest(nil))
+	if err != nil || result.IsError {
+		t.Fatalf("SyncCart failed: %v %+v", err, result)
+	}
+
+	var response struct {
+		Changes []willys.CartChange `json:"changes"`
+		InSync  bool                `json:"in_sync"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.InSync || len(response.Changes) != 2 {
+		t.Fatalf("Expected two changes, got %+v", response)
+	}
+	if c := response.Changes[0]; c.Code != "bread" || c.Before != 0 || c.After != 1 {
+		t.Errorf("Expected bread to be new, got %+v", c)
+	}
+	if c := response.Changes[1]; c.Code != "milk" || c.Before != 1 || c.After != 2 {
+		t.Errorf("Expected more milk, got %+v", c)
+	}
+}
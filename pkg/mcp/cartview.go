@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+const (
+	CartGroupNone     = "none"
+	CartGroupCategory = "category"
+	CartGroupAisle    = "aisle"
+
+	CartSortDefault       = "default"
+	CartSortPrice         = "price"
+	CartSortPriceDesc     = "price_desc"
+	CartSortName          = "name"
+	CartSortRecentlyAdded = "recently_added"
+)
+
+type cartGroup struct {
+	Category willys.CartCategory `json:"category"`
+	Items    []annotatedCartItem `json:"items"`
+	Subtotal willys.Money        `json:"subtotal"`
+}
+
+func validateCartView(groupBy, sortBy string) error {
+	switch groupBy {
+	case CartGroupNone, CartGroupCategory, CartGroupAisle:
+	default:
+		return fmt.Errorf("unknown group_by %q (use 'none', 'category', or 'aisle')", groupBy)
+	}
+	switch sortBy {
+	case CartSortDefault, CartSortPrice, CartSortPriceDesc, CartSortName, CartSortRecentlyAdded:
+	default:
+		return fmt.Errorf("unknown sort_by %q (use 'default', 'price', 'price_desc', 'name', or 'recently_added')", sortBy)
+	}
+	return nil
+}
+
+// sortCartItems orders items in place. "default" keeps the order Willys returned.
+func sortCartItems(items []annotatedCartItem, sortBy string) {
+	lastAdded := func(item annotatedCartItem) int64 {
+		if len(item.AddedBy) == 0 {
+			return 0
+		}
+		return item.AddedBy[len(item.AddedBy)-1].At.UnixNano()
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		switch sortBy {
+		case CartSortPrice:
+			return a.TotalPrice.Ore < b.TotalPrice.Ore
+		case CartSortPriceDesc:
+			return a.TotalPrice.Ore > b.TotalPrice.Ore
+		case CartSortName:
+			return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		case CartSortRecentlyAdded:
+			return lastAdded(a) > lastAdded(b)
+		default:
+			return false
+		}
+	})
+}
+
+// groupCartItems splits sorted items into departments, ordered alphabetically for
+// "category" and by walking order through the store for "aisle".
+func groupCartItems(items []annotatedCartItem, groupBy string) []cartGroup {
+	byKey := make(map[string]*cartGroup)
+	var groups []*cartGroup
+	for _, item := range items {
+		category := willys.CategorizeCartItem(item.CartItem)
+		group, ok := byKey[category.Key]
+		if !ok {
+			group = &cartGroup{Category: category}
+			byKey[category.Key] = group
+			groups = append(groups, group)
+		}
+		group.Items = append(group.Items, item)
+		group.Subtotal = group.Subtotal.Add(item.TotalPrice)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groupBy == CartGroupAisle {
+			return groups[i].Category.Aisle < groups[j].Category.Aisle
+		}
+		return groups[i].Category.Name < groups[j].Category.Name
+	})
+
+	result := make([]cartGroup, len(groups))
+	for i, g := range groups {
+		result[i] = *g
+	}
+	return result
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestGroupAndSortCartItems(t *testing.T) {
+	now := time.Now()
+	items := []annotatedCartItem{
+		{CartItem: willys.CartItem{ProductCode: "1", Name: "Mellanmjölk", TotalPrice: willys.SEK(1590)},
+			AddedBy: []CartOrigin{{Source: "add_to_cart", At: now.Add(-time.Hour)}}},
+		{CartItem: willys.CartItem{ProductCode: "2", Name: "Bananer", TotalPrice: willys.SEK(2490)},
+			AddedBy: []CartOrigin{{Source: "add_to_cart", At: now}}},
+		{CartItem: willys.CartItem{ProductCode: "3", Name: "Lättmjölk", TotalPrice: willys.SEK(1390), Category: "mejeri-ost-och-agg|mjolk"}},
+		{CartItem: willys.CartItem{ProductCode: "4", Name: "Laxfilé", TotalPrice: willys.SEK(8990)}},
+	}
+
+	sortCartItems(items, CartSortRecentlyAdded)
+	if items[0].ProductCode != "2" || items[1].ProductCode != "1" {
+		t.Errorf("Expected most recently added first, got %s, %s", items[0].ProductCode, items[1].ProductCode)
+	}
+
+	sortCartItems(items, CartSortPrice)
+	groups := groupCartItems(items, CartGroupAisle)
+	var keys []string
+	for _, g := range groups {
+		keys = append(keys, g.Category.Key)
+	}
+	expected := []string{"frukt-och-gront", "fisk-och-skaldjur", "mejeri-ost-och-agg"}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected groups %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Fatalf("Expected groups %v, got %v", expected, keys)
+		}
+	}
+
+	dairy := groups[2]
+	if len(dairy.Items) != 2 || dairy.Items[0].ProductCode != "3" || dairy.Subtotal != willys.SEK(2980) {
+		t.Errorf("Unexpected dairy group: %+v", dairy)
+	}
+
+	if err := validateCartView("shelf", CartSortDefault); err == nil {
+		t.Error("Expected error for unknown group_by")
+	}
+}
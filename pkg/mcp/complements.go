@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// complementLookups caps how many cart items suggest_complements asks Willys for related
+// products, starting with the items the customer buys most of.
+const complementLookups = 5
+
+func (h *ToolHandler) SuggestComplements(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := mcp.ParseInt(request, "limit", 5)
+	useRelated := mcp.ParseBoolean(request, "use_related", true)
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+	if len(cart.Items) == 0 {
+		return mcp.NewToolResultError("cart is empty"), nil
+	}
+
+	result := map[string]any{}
+
+	orders, err := h.client.GetOrderHistory(ctx)
+	if err != nil {
+		// Suggestions from other customers still work without the customer's own history
+		result["history_error"] = err.Error()
+	}
+
+	related := make(map[string][]willys.Product)
+	if useRelated {
+		items := append([]willys.CartItem(nil), cart.Items...)
+		sort.SliceStable(items, func(i, j int) bool { return items[i].Quantity > items[j].Quantity })
+		items = items[:min(len(items), complementLookups)]
+
+		if err := h.consumeQuota(ctx, quotaSearch, len(items)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		for _, item := range items {
+			products, err := h.client.GetRelatedProducts(ctx, item.ProductCode)
+			if err != nil {
+				result["related_error"] = err.Error()
+				continue
+			}
+			related[item.ProductCode] = products
+		}
+	}
+
+	suggestions := willys.SuggestComplements(cart.Items, orders, related, limit)
+	result["suggestions"] = suggestions
+	result["count"] = len(suggestions)
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// withCorrelationID tags each tool call with an ID that is sent upstream, logged, and
+// appended to error results so an agent failure can be matched to the server log.
+func withCorrelationID(toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id := willys.NewCorrelationID()
+		ctx = willys.WithCorrelationID(ctx, id)
+		start := time.Now()
+
+		result, err := next(ctx, request)
+
+		elapsed := time.Since(start).Round(time.Millisecond)
+		switch {
+		case err != nil:
+			log.Printf("[%s] tool %s failed after %s: %v", id, toolName, elapsed, err)
+			return result, fmt.Errorf("%w (correlation_id: %s)", err, id)
+		case result != nil && result.IsError:
+			log.Printf("[%s] tool %s returned error after %s", id, toolName, elapsed)
+			annotateErrorResult(result, id)
+		default:
+			log.Printf("[%s] tool %s completed in %s", id, toolName, elapsed)
+		}
+
+		return result, nil
+	}
+}
+
+func annotateErrorResult(result *mcp.CallToolResult, id string) {
+	for i, content := range result.Content {
+		text, ok := mcp.AsTextContent(content)
+		if !ok {
+			continue
+		}
+		if !strings.Contains(text.Text, id) {
+			text.Text = fmt.Sprintf("%s (correlation_id: %s)", text.Text, id)
+			result.Content[i] = *text
+		}
+		return
+	}
+}
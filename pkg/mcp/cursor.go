@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// cursorTTL is how long the rest of a paged result waits for its next page.
+	cursorTTL = 15 * time.Minute
+	// maxCursors bounds the paged results kept at once; the oldest go first.
+	maxCursors = 100
+)
+
+var errCursorExpired = errors.New("cursor is unknown or has expired; call the tool again without a cursor")
+
+type (
+	// cursorStore keeps the remaining entries of paged tool results, so later pages come
+	// from the same snapshot instead of a new, possibly shifted, request to Willys. A
+	// cursor belongs to the tool and session that made it and is used up by reading it.
+	cursorStore struct {
+		mu      sync.Mutex
+		cursors map[string]*pagedResult
+		now     func() time.Time
+	}
+
+	pagedResult struct {
+		tool    string
+		session string
+		rest    any // []T of the entries not returned yet
+		size    int
+		total   int
+		expires time.Time
+	}
+
+	// page is one page of a paged result.
+	page[T any] struct {
+		Items      []T
+		Total      int
+		NextCursor string
+	}
+)
+
+func newCursorStore() *cursorStore {
+	return &cursorStore{
+		cursors: make(map[string]*pagedResult),
+		now:     time.Now,
+	}
+}
+
+func (s *cursorStore) put(result *pagedResult) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	cursor := hex.EncodeToString(b)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var oldest string
+	for c, r := range s.cursors {
+		if now.After(r.expires) {
+			delete(s.cursors, c)
+		} else if oldest == "" || r.expires.Before(s.cursors[oldest].expires) {
+			oldest = c
+		}
+	}
+	if len(s.cursors) >= maxCursors {
+		delete(s.cursors, oldest)
+	}
+	result.expires = now.Add(cursorTTL)
+	s.cursors[cursor] = result
+	return cursor
+}
+
+func (s *cursorStore) take(cursor, tool, session string) (*pagedResult, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.cursors[cursor]
+	if !ok || result.tool != tool || result.session != session || s.now().After(result.expires) {
+		return nil, false
+	}
+	delete(s.cursors, cursor)
+	return result, true
+}
+
+// pageOf returns the first size items and keeps the rest behind a cursor. A size of 0 or
+// less returns everything.
+func pageOf[T any](ctx context.Context, h *ToolHandler, tool string, items []T, size int) page[T] {
+	return nextPageOf(ctx, h, tool, items, size, len(items))
+}
+
+// resumePage returns the page after cursor.
+func resumePage[T any](ctx context.Context, h *ToolHandler, tool, cursor string) (page[T], error) {
+	result, ok := h.cursors.take(cursor, tool, sessionIDFromContext(ctx))
+	if !ok {
+		return page[T]{}, errCursorExpired
+	}
+	rest, ok := result.rest.([]T)
+	if !ok {
+		return page[T]{}, errCursorExpired
+	}
+	return nextPageOf(ctx, h, tool, rest, result.size, result.total), nil
+}
+
+func nextPageOf[T any](ctx context.Context, h *ToolHandler, tool string, items []T, size, total int) page[T] {
+	if size <= 0 || len(items) <= size {
+		return page[T]{Items: items, Total: total}
+	}
+	cursor := h.cursors.put(&pagedResult{
+		tool:    tool,
+		session: sessionIDFromContext(ctx),
+		rest:    items[size:],
+		size:    size,
+		total:   total,
+	})
+	return page[T]{Items: items[:size], Total: total, NextCursor: cursor}
+}
+
+// pageSizeParam reads page_size, defaulting to def and capped at max.
+func pageSizeParam(request mcp.CallToolRequest, def, max int) int {
+	size := mcp.ParseInt(request, "page_size", def)
+	if size <= 0 {
+		return def
+	}
+	return min(size, max)
+}
+
+// cursorProperties are the parameters of a paged tool.
+func cursorProperties(def, max int) []mcp.ToolOption {
+	return []mcp.ToolOption{
+		mcp.WithNumber("page_size",
+			mcp.Description(fmt.Sprintf("Entries per page (default %d, at most %d)", def, max)),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("next_cursor from the previous page; continues the same result without other arguments"),
+		),
+	}
+}
+
+// toResult adds the page's total and next cursor to result.
+func (p page[T]) toResult(result map[string]any) map[string]any {
+	result["count"] = len(p.Items)
+	result["total"] = p.Total
+	if p.NextCursor != "" {
+		result["next_cursor"] = p.NextCursor
+	}
+	return result
+}
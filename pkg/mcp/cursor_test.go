@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// slotsClient serves a fixed list of slots and counts the fetches; other calls panic.
+type slotsClient struct {
+	willys.WillysAPI
+	slots   []willys.TimeSlot
+	fetches int
+}
+
+func (c *slotsClient) GetAvailableTimeSlots(ctx context.Context, postalCode string) ([]willys.TimeSlot, error) {
+	c.fetches++
+	return c.slots, nil
+}
+
+func TestTimeSlotCursor(t *testing.T) {
+	client := &slotsClient{}
+	for i := range 120 {
+		client.slots = append(client.slots, willys.TimeSlot{SlotID: fmt.Sprintf("slot-%d", i), Date: "2026-10-20"})
+	}
+	h := NewToolHandler(client)
+	alice, bob := sessionContext("alice"), sessionContext("bob")
+
+	var pages []string
+	var seen int
+	args := map[string]any{"postal_code": "11151"}
+	for {
+		result, _ := h.GetAvailableTimeSlots(alice, toolRequest(args))
+		if result.IsError {
+			t.Fatalf("Unexpected error: %v", result.Content)
+		}
+		var body struct {
+			Slots      []willys.TimeSlot `json:"slots"`
+			Total      int               `json:"total"`
+			NextCursor string            `json:"next_cursor"`
+		}
+		if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Total != 120 || body.Slots[0].SlotID != fmt.Sprintf("slot-%d", seen) {
+			t.Fatalf("Unexpected page after %d slots: total %d, first %s", seen, body.Total, body.Slots[0].SlotID)
+		}
+		seen += len(body.Slots)
+		if body.NextCursor == "" {
+			break
+		}
+		pages = append(pages, body.NextCursor)
+		args = map[string]any{"cursor": body.NextCursor}
+	}
+	if seen != 120 || len(pages) != 2 || client.fetches != 1 {
+		t.Errorf("Expected 120 slots in 3 pages from one fetch, got %d slots, %d cursors, %d fetches", seen, len(pages), client.fetches)
+	}
+
+	// A cursor is used up by reading it, and belongs to its session
+	if result, _ := h.GetAvailableTimeSlots(alice, toolRequest(map[string]any{"cursor": pages[0]})); !result.IsError {
+		t.Error("Expected a used cursor to be rejected")
+	}
+	result, _ := h.GetAvailableTimeSlots(alice, toolRequest(map[string]any{"postal_code": "11151", "page_size": 100}))
+	var body map[string]any
+	json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body)
+	cursor := body["next_cursor"].(string)
+	if result, _ := h.GetAvailableTimeSlots(bob, toolRequest(map[string]any{"cursor": cursor})); !result.IsError {
+		t.Error("Expected another session's cursor to be rejected")
+	}
+	if result, _ := h.WhatsNew(alice, toolRequest(map[string]any{"cursor": cursor})); !result.IsError {
+		t.Error("Expected another tool's cursor to be rejected")
+	}
+}
+
+func TestCursorStoreExpiry(t *testing.T) {
+	s := newCursorStore()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	cursor := s.put(&pagedResult{tool: "t", rest: []int{1}})
+	now = now.Add(cursorTTL + time.Second)
+	if _, ok := s.take(cursor, "t", ""); ok {
+		t.Error("Expected the cursor to expire")
+	}
+
+	for range maxCursors + 10 {
+		s.put(&pagedResult{tool: "t", rest: []int{1}})
+		now = now.Add(time.Millisecond)
+	}
+	if len(s.cursors) > maxCursors {
+		t.Errorf("Expected at most %d cursors, got %d", maxCursors, len(s.cursors))
+	}
+}
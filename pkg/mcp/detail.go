@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// compactPick mirrors willys.PickResult with the product projected to its compact form.
+type compactPick struct {
+	Query      string                 `json:"query"`
+	Product    *willys.CompactProduct `json:"product,omitempty"`
+	Reason     string                 `json:"reason"`
+	Candidates int                    `json:"candidates"`
+	Confidence float64                `json:"confidence,omitempty"`
+}
+
+func outputDetailProperty() mcp.ToolOption {
+	return mcp.WithString("output_detail",
+		mcp.Description("'compact' returns only code, name, price, and unit price per product; 'full' returns everything. Defaults to the server setting"),
+		mcp.Enum(willys.OutputDetailFull, willys.OutputDetailCompact),
+	)
+}
+
+// parseOutputDetail returns the per-call output_detail, falling back to the server default.
+func (h *ToolHandler) parseOutputDetail(request mcp.CallToolRequest) (string, error) {
+	h.mu.RLock()
+	detail := h.outputDetail
+	h.mu.RUnlock()
+
+	detail = mcp.ParseString(request, "output_detail", detail)
+	return detail, willys.ValidateOutputDetail(detail)
+}
+
+func projectProducts(products []willys.Product, detail string) any {
+	if detail != willys.OutputDetailCompact {
+		return products
+	}
+	compact := make([]willys.CompactProduct, len(products))
+	for i, p := range products {
+		compact[i] = p.Compact()
+	}
+	return compact
+}
+
+func projectPick(pick willys.PickResult, detail string) any {
+	if detail != willys.OutputDetailCompact {
+		return pick
+	}
+	result := compactPick{Query: pick.Query, Reason: pick.Reason, Candidates: pick.Candidates, Confidence: pick.Confidence}
+	if pick.Product != nil {
+		p := pick.Product.Compact()
+		result.Product = &p
+	}
+	return result
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestProjectProducts(t *testing.T) {
+	products := []willys.Product{{
+		Code:             "101205823_ST",
+		Name:             "Ekologisk Mellanmjölk 1,5%",
+		PriceValue:       willys.SEK(1950),
+		ComparePrice:     willys.SEK(1300),
+		ComparePriceUnit: "l",
+		Manufacturer:     "Arla Ko",
+	}}
+
+	if _, ok := projectProducts(products, willys.OutputDetailFull).([]willys.Product); !ok {
+		t.Error("Expected full products for full detail")
+	}
+
+	compact, ok := projectProducts(products, willys.OutputDetailCompact).([]willys.CompactProduct)
+	if !ok || len(compact) != 1 {
+		t.Fatalf("Expected compact products, got %T", compact)
+	}
+	unitPrice := willys.SEK(1300)
+	expected := willys.CompactProduct{Code: "101205823_ST", Name: "Ekologisk Mellanmjölk 1,5%", Price: willys.SEK(1950), UnitPrice: &unitPrice, UnitPriceUnit: "l"}
+	if !reflect.DeepEqual(compact[0], expected) {
+		t.Errorf("Expected %+v, got %+v", expected, compact[0])
+	}
+
+	pick := projectPick(willys.PickResult{Query: "mjölk", Product: &products[0], Reason: "cheapest"}, willys.OutputDetailCompact).(compactPick)
+	if pick.Product == nil || pick.Product.Code != "101205823_ST" {
+		t.Errorf("Expected compact pick product, got %+v", pick)
+	}
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// expiringPantryItems returns the pantry items whose best-before date falls before
+// deadline, in the order they were given.
+func expiringPantryItems(pantry []PantryItem, deadline time.Time) []PantryItem {
+	var expiring []PantryItem
+	for _, item := range pantry {
+		if item.Expires == "" {
+			continue
+		}
+		expires, err := time.ParseInLocation(time.DateOnly, item.Expires, time.Local)
+		if err != nil || expires.After(deadline) {
+			continue
+		}
+		expiring = append(expiring, item)
+	}
+	return expiring
+}
+
+func (h *ToolHandler) WhatsExpiring(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	days := mcp.ParseInt(request, "days", 3)
+	if days < 0 {
+		return mcp.NewToolResultError("days must not be negative"), nil
+	}
+	includePantry := mcp.ParseBoolean(request, "include_pantry", true)
+
+	orders, err := h.client.GetOrderHistory(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get order history: %v", err)), nil
+	}
+
+	now := time.Now()
+	horizon := time.Duration(days) * 24 * time.Hour
+	result := map[string]any{
+		"days":     days,
+		"products": willys.EstimateExpiry(orders, now, horizon),
+	}
+
+	if includePantry {
+		pantry, err := h.loadPantry()
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read pantry: %v", err)), nil
+		}
+		result["pantry"] = expiringPantryItems(pantry, now.Add(horizon))
+	}
+
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/export"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	ExportFormatMarkdown = "markdown"
+	ExportFormatPDF      = "pdf"
+)
+
+// shoppingLines builds the shopping list section from explicit list items or, without
+// them, from a draft basket: the named one or else the active one.
+func (h *ToolHandler) shoppingLines(ctx context.Context, request mcp.CallToolRequest) ([]export.ShoppingLine, error) {
+	if items := parseListItems(request.GetArguments()["items"]); len(items) > 0 {
+		lines := make([]export.ShoppingLine, 0, len(items))
+		for _, item := range items {
+			lines = append(lines, export.ShoppingLine{Item: item.Query, Quantity: item.Quantity})
+		}
+		return lines, nil
+	}
+
+	name := mcp.ParseString(request, "basket", "")
+	if name == "" {
+		name = h.activeBasket(ctx)
+	} else {
+		var err error
+		if name, err = normalizeBasketName(name); err != nil {
+			return nil, err
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	basket, err := h.getBasket(name)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]export.ShoppingLine, 0, len(basket.Items))
+	for _, item := range basket.Items {
+		line := export.ShoppingLine{Item: item.Query, Quantity: item.Quantity, Note: item.Name}
+		if line.Item == "" {
+			line.Item, line.Note = item.Name, ""
+		}
+		if line.Item == "" {
+			line.Item = item.Code
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func mealLines(text string) []string {
+	var meals []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if line != "" {
+			meals = append(meals, line)
+		}
+	}
+	return meals
+}
+
+func (h *ToolHandler) ExportPlan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	format := mcp.ParseString(request, "format", ExportFormatMarkdown)
+	if format != ExportFormatMarkdown && format != ExportFormatPDF {
+		return mcp.NewToolResultError(fmt.Sprintf("format must be %q or %q", ExportFormatMarkdown, ExportFormatPDF)), nil
+	}
+
+	now := time.Now()
+	doc := export.Document{
+		Title:     mcp.ParseString(request, "title", "Plan for "+now.Format(time.DateOnly)),
+		Generated: now,
+		Meals:     mealLines(mcp.ParseString(request, "meal_plan", "")),
+	}
+
+	shopping, err := h.shoppingLines(ctx, request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to build shopping list: %v", err)), nil
+	}
+	doc.Shopping = shopping
+
+	if mcp.ParseBoolean(request, "include_cart", true) {
+		cart, err := h.client.GetCart(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+		}
+		doc.Cart = cart
+	}
+
+	markdown := export.Markdown(doc)
+	if format == ExportFormatMarkdown {
+		return mcp.NewToolResultJSON(map[string]any{"markdown": markdown})
+	}
+
+	page, err := export.HTML(doc)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to render plan: %v", err)), nil
+	}
+	pdf, err := export.PDF(ctx, page)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to render PDF: %v", err)), nil
+	}
+
+	if err := os.MkdirAll(h.exportDir, 0o700); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to create export directory: %v", err)), nil
+	}
+	path := filepath.Join(h.exportDir, "plan-"+now.Format("20060102-150405")+".pdf")
+	if err := os.WriteFile(path, pdf, 0o600); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save PDF: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"markdown": markdown,
+		"pdf":      path,
+	})
+}
@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// feeOverviewDays is how far ahead delivery_fee_overview looks by default; Willys rarely
+// offers slots further out.
+const feeOverviewDays = 14
+
+func (h *ToolHandler) DeliveryFeeOverview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	postalCode := mcp.ParseString(request, "postal_code", "")
+	if postalCode == "" {
+		address, err := h.resolveAddress(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("postal_code parameter is required: %v", err)), nil
+		}
+		postalCode = address.PostalCode
+	}
+	if err := willys.ValidatePostalCode(postalCode); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid postal code: %v", err)), nil
+	}
+
+	days := mcp.ParseInt(request, "days", feeOverviewDays)
+	if days < 1 || days > 2*feeOverviewDays {
+		return mcp.NewToolResultError(fmt.Sprintf("days must be between 1 and %d", 2*feeOverviewDays)), nil
+	}
+
+	slots, err := h.client.GetAvailableTimeSlots(ctx, postalCode)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get time slots: %v", err)), nil
+	}
+
+	calendar := willys.FeeCalendar(slots, time.Now(), days)
+	result := map[string]any{
+		"postal_code": postalCode,
+		"days":        calendar,
+	}
+	if cheapest := willys.CheapestDay(calendar); cheapest != nil {
+		result["cheapest_day"] = cheapest.Date
+		result["cheapest_fee"] = cheapest.MinFee
+	}
+
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func fieldsProperty(what string) mcp.ToolOption {
+	return mcp.WithArray("fields",
+		mcp.Description("Return only these fields of each "+what+"; nested fields use dots (e.g., ['code', 'name', 'price.display']). Omit for all fields"),
+		mcp.WithStringItems(),
+	)
+}
+
+// toJSONValue converts v to the generic form encoding/json produces, so field selection
+// works on the same names the client sees.
+func toJSONValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// selectFields keeps only the given dotted paths of obj. Paths that don't exist are
+// skipped rather than reported, so one field list works across product types.
+func selectFields(obj map[string]any, fields []string) map[string]any {
+	result := make(map[string]any, len(fields))
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		var value any = obj
+		found := true
+		for _, key := range path {
+			m, ok := value.(map[string]any)
+			if !ok {
+				found = false
+				break
+			}
+			if value, ok = m[key]; !ok {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		target := result
+		for _, key := range path[:len(path)-1] {
+			next, ok := target[key].(map[string]any)
+			if !ok {
+				next = make(map[string]any)
+				target[key] = next
+			}
+			target = next
+		}
+		target[path[len(path)-1]] = value
+	}
+	return result
+}
+
+// selectFieldsInList applies selectFields to every object in list, which must be the
+// generic form of a JSON array.
+func selectFieldsInList(list any, fields []string) any {
+	items, ok := list.([]any)
+	if !ok {
+		return list
+	}
+	for i, item := range items {
+		if obj, ok := item.(map[string]any); ok {
+			items[i] = selectFields(obj, fields)
+		}
+	}
+	return items
+}
+
+// projectList returns list with only the requested fields of each element, or list
+// unchanged when no fields were requested.
+func projectList(list any, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return list, nil
+	}
+	generic, err := toJSONValue(list)
+	if err != nil {
+		return nil, err
+	}
+	return selectFieldsInList(generic, fields), nil
+}
@@ -0,0 +1,30 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestProjectList(t *testing.T) {
+	items := []willys.CartItem{{ProductCode: "101_ST", Name: "Mjölk", Quantity: 2, Price: willys.SEK(1590)}}
+
+	projected, err := projectList(items, []string{"code", "price.display", "missing", "name.nested"})
+	if err != nil {
+		t.Fatalf("projectList failed: %v", err)
+	}
+
+	expected := []any{map[string]any{
+		"code":  "101_ST",
+		"price": map[string]any{"display": willys.SEK(1590).String()},
+	}}
+	if !reflect.DeepEqual(projected, expected) {
+		t.Errorf("Expected %v, got %v", expected, projected)
+	}
+
+	unchanged, _ := projectList(items, nil)
+	if _, ok := unchanged.([]willys.CartItem); !ok {
+		t.Error("Expected list to be returned unchanged without fields")
+	}
+}
@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// planDays is how far ahead plan_fulfillment compares by default: this week.
+	planDays      = 7
+	planMaxStores = 5
+)
+
+func (h *ToolHandler) PlanFulfillment(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	postalCode := mcp.ParseString(request, "postal_code", "")
+	if postalCode == "" {
+		address, err := h.resolveAddress(ctx, request)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("postal_code parameter is required: %v", err)), nil
+		}
+		postalCode = address.PostalCode
+	}
+	if err := willys.ValidatePostalCode(postalCode); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid postal code: %v", err)), nil
+	}
+
+	days := mcp.ParseInt(request, "days", planDays)
+	if days < 1 || days > 2*planDays {
+		return mcp.NewToolResultError(fmt.Sprintf("days must be between 1 and %d", 2*planDays)), nil
+	}
+	maxStores := mcp.ParseInt(request, "max_stores", 3)
+	if maxStores < 0 || maxStores > planMaxStores {
+		return mcp.NewToolResultError(fmt.Sprintf("max_stores must be between 0 and %d", planMaxStores)), nil
+	}
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+	until := time.Now().AddDate(0, 0, days-1).Format("2006-01-02")
+
+	// A mode that can't be checked is reported in its option rather than failing the plan
+	var options []willys.FulfillmentOption
+	slots, err := h.client.GetAvailableTimeSlots(ctx, postalCode)
+	home := willys.NewFulfillmentOption(willys.FulfillmentHomeDelivery, nil, slots, until, cart.TotalPrice)
+	if err != nil {
+		home.Error = err.Error()
+	}
+	options = append(options, home)
+
+	if maxStores > 0 {
+		stores, err := h.client.FindPickupStores(ctx, postalCode)
+		if err != nil {
+			options = append(options, willys.FulfillmentOption{Mode: willys.FulfillmentPickup, Error: err.Error()})
+		}
+		if len(stores) > maxStores {
+			stores = stores[:maxStores]
+		}
+		for i := range stores {
+			store := &stores[i]
+			slots, err := h.client.GetPickupTimeSlots(ctx, store.ID)
+			pickup := willys.NewFulfillmentOption(willys.FulfillmentPickup, store, slots, until, cart.TotalPrice)
+			if err != nil {
+				pickup.Error = err.Error()
+			}
+			options = append(options, pickup)
+		}
+	}
+
+	return mcp.NewToolResultJSON(willys.PlanFulfillment(cart.TotalPrice, until, options))
+}
@@ -0,0 +1,118 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/effati/willys-mcp/pkg/grpcapi"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// grpcSource is the added_by origin of items added through the gRPC API.
+const grpcSource = "grpc_api"
+
+// WithGRPC also serves the gRPC API on addr, next to the MCP transport. With a token,
+// calls need it as a bearer token in their metadata; without one, addr must be a
+// loopback address.
+func WithGRPC(addr, token string) ServerOption {
+	return func(s *Server) {
+		s.grpcAddr = addr
+		s.grpcToken = token
+	}
+}
+
+// serveGRPC starts the gRPC API in the background and returns a func that stops it,
+// letting calls in flight finish.
+func (s *Server) serveGRPC() (func(), error) {
+	if s.grpcToken == "" && !isLoopbackAddr(s.grpcAddr) {
+		return nil, fmt.Errorf("refusing to serve gRPC on %s without WILLYS_API_TOKEN: set a token or listen on a loopback address", s.grpcAddr)
+	}
+	listener, err := net.Listen("tcp", s.grpcAddr)
+	if err != nil {
+		return nil, err
+	}
+	srv := grpcapi.NewServer(s.toolHandler.client, grpcCart{s}, s.grpcToken)
+	if s.grpcToken == "" {
+		log.Printf("WILLYS_API_TOKEN is not set: the gRPC API on %s is open to local processes", s.grpcAddr)
+	}
+	log.Printf("Serving gRPC (%s) on %s", grpcapi.ServiceName, listener.Addr())
+	go func() {
+		if err := srv.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %v", err)
+		}
+	}()
+	return srv.GracefulStop, nil
+}
+
+// grpcCart applies gRPC cart changes through the cart tools, so their middleware, quotas,
+// and the active draft basket apply. Clearing has no tool of its own and runs through the
+// same middleware as clear_cart.
+type grpcCart struct {
+	s *Server
+}
+
+func (c grpcCart) AddToCart(ctx context.Context, productCode string, quantity int) error {
+	result, err := c.s.callTool(ctx, "add_to_cart", map[string]any{
+		"product_code": productCode,
+		"quantity":     float64(quantity),
+		"source":       grpcSource,
+	})
+	return toolError(result, err)
+}
+
+func (c grpcCart) RemoveFromCart(ctx context.Context, productCode string, quantity int) error {
+	result, err := c.s.callTool(ctx, "remove_from_cart", map[string]any{
+		"product_code": productCode,
+		"quantity":     float64(quantity),
+	})
+	return toolError(result, err)
+}
+
+func (c grpcCart) ClearCart(ctx context.Context) error {
+	tool := mcp.NewTool("clear_cart", mcp.WithDestructiveHintAnnotation(true))
+	handler := chain(tool, c.s.toolHandler.clearCart, c.s.toolMiddleware()...)
+	var request mcp.CallToolRequest
+	request.Params.Name = tool.Name
+	return toolError(handler(ctx, request))
+}
+
+// clearCart empties the active draft basket, or the Willys cart when none is active.
+func (h *ToolHandler) clearCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if name := h.activeBasket(ctx); name != "" {
+		basket, err := h.getBasket(name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		basket.Items, basket.UpdatedAt = []BasketItem{}, time.Now()
+		if err := store.PutJSON(h.store, store.BucketBaskets, name, basket); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to clear basket: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(basket)
+	}
+
+	if err := h.consumeQuota(ctx, quotaCartMutation, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := h.client.ClearCart(ctx); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to clear cart: %v", err)), nil
+	}
+	h.forgetRemovedItems(&willys.CartSummary{})
+	return mcp.NewToolResultJSON(map[string]any{"cleared": true})
+}
+
+// toolError turns a tool error result into an error the gRPC service reports as
+// FailedPrecondition.
+func toolError(result *mcp.CallToolResult, err error) error {
+	if err != nil {
+		return err
+	}
+	if result.IsError {
+		return fmt.Errorf("%w: %s", grpcapi.ErrRejected, resultText(result))
+	}
+	return nil
+}
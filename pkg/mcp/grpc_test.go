@@ -0,0 +1,40 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/effati/willys-mcp/pkg/grpcapi"
+)
+
+func TestGRPCCartUsesToolGuards(t *testing.T) {
+	client := &restClient{
+		warningsClient: warningsClient{cart: &willys.CartSummary{}},
+		added:          map[string]int{},
+	}
+	s := NewServer(client, WithQuotas(Quotas{CartMutations: QuotaLimit{Max: 1, Window: time.Hour}}), WithScheduler(false), WithSlotAutobook(false))
+	cart := grpcCart{s}
+
+	if err := cart.AddToCart(context.Background(), "101_ST", 2); err != nil {
+		t.Fatalf("AddToCart failed: %v", err)
+	}
+	if client.added["101_ST"] != 2 {
+		t.Errorf("Expected 2x 101_ST in the cart, got %v", client.added)
+	}
+	if err := cart.AddToCart(context.Background(), "101_ST", 1); !errors.Is(err, grpcapi.ErrRejected) {
+		t.Errorf("Expected the quota to reject the second change, got %v", err)
+	}
+	if err := cart.ClearCart(context.Background()); !errors.Is(err, grpcapi.ErrRejected) {
+		t.Errorf("Expected the quota to reject clearing, got %v", err)
+	}
+}
+
+func TestGRPCRequiresTokenOffLoopback(t *testing.T) {
+	s := NewServer(&warningsClient{}, WithGRPC("0.0.0.0:0", ""), WithScheduler(false), WithSlotAutobook(false))
+	if _, err := s.serveGRPC(); err == nil {
+		t.Error("Expected gRPC without a token on all interfaces to be refused")
+	}
+}
@@ -0,0 +1,300 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type (
+	shoppingListAddRequest struct {
+		Item     string         `json:"item,omitempty" jsonschema:"description=A single item to add, e.g. mjölk"`
+		Quantity int            `json:"quantity,omitempty" jsonschema:"description=Quantity of item (default 1)"`
+		Items    []listItem     `json:"items,omitempty" jsonschema:"description=Several items at once; quantity defaults to 1"`
+		Policy   map[string]any `json:"policy,omitempty" jsonschema:"description=Auto-pick policy override, as for list_to_cart"`
+	}
+
+	// restError is the body of every REST and webhook error response.
+	restError struct {
+		Error string `json:"error"`
+	}
+)
+
+const (
+	// restSource is the added_by origin of items added through the REST facade.
+	restSource = "rest_api"
+
+	// maxRESTBody caps request bodies; a shopping list entry is a few hundred bytes.
+	maxRESTBody = 64 << 10
+)
+
+// WithHTTP serves MCP over streamable HTTP at /mcp on addr instead of stdio. With an
+// apiToken, every request needs it as a bearer token and the REST facade under /api is
+// enabled; without one, addr must be a loopback address.
+func WithHTTP(addr, apiToken string) ServerOption {
+	return func(s *Server) {
+		s.httpAddr = addr
+		s.apiToken = apiToken
+	}
+}
+
+// WithAllowedHosts adds host names, besides localhost and IP addresses, that requests
+// may name in their Host and Origin headers, e.g. the name of a reverse proxy.
+func WithAllowedHosts(hosts ...string) ServerOption {
+	return func(s *Server) {
+		s.allowedHosts = hosts
+	}
+}
+
+// serveHTTP runs the HTTP transport until SIGINT or SIGTERM and returns context.Canceled
+// then, like ServeStdio.
+func (s *Server) serveHTTP(ctx context.Context) error {
+	if s.apiToken == "" && !isLoopbackAddr(s.httpAddr) {
+		return fmt.Errorf("refusing to serve HTTP on %s without WILLYS_API_TOKEN: set a token or listen on a loopback address", s.httpAddr)
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              s.httpAddr,
+		Handler:           s.httpHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if s.apiToken == "" {
+		log.Printf("WILLYS_API_TOKEN is not set: /mcp on %s is open to local processes and the REST API is off", s.httpAddr)
+	}
+	log.Printf("Serving MCP at http://%s/mcp", s.httpAddr)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownGrace)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP shutdown: %v", err)
+	}
+	return context.Canceled
+}
+
+func (s *Server) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", server.NewStreamableHTTPServer(s.mcpServer))
+	if s.apiToken != "" {
+		mux.HandleFunc("GET /api/cart", s.restCart)
+		mux.HandleFunc("POST /api/shopping-list/add", s.restShoppingListAdd)
+	}
+	protected := s.requireToken(mux)
+	if s.apiToken == "" && s.webhookSecret == "" {
+		return s.checkHost(protected)
+	}
+
+	// The webhook has its own secret; voice platforms can't send the bearer token
+	root := http.NewServeMux()
+	root.HandleFunc("GET "+openAPIPath, s.serveOpenAPI)
+	if s.webhookSecret != "" {
+		root.HandleFunc("POST /hooks/add-item", s.hookAddItem)
+	}
+	root.Handle("/", protected)
+	return s.checkHost(root)
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections from this
+// machine. An empty host, as in ":8080", listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// checkHost rejects requests whose Host or Origin names a host the server doesn't
+// answer to. A web page can otherwise reach a local server through DNS rebinding, or
+// post to it cross-origin, token or not.
+func (s *Server) checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host, true) {
+			writeRESTError(w, http.StatusForbidden, fmt.Sprintf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Host == "" || !s.allowedHost(u.Host, false) {
+				writeRESTError(w, http.StatusForbidden, fmt.Sprintf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost accepts localhost, the host the server listens on, and the hosts from
+// WithAllowedHosts. For the Host header anyIP also accepts IP addresses, which DNS
+// rebinding can't produce; an Origin may only be a loopback IP, since a page served
+// from any other address is exactly the cross-origin caller to keep out.
+func (s *Server) allowedHost(hostport string, anyIP bool) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "" {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && (anyIP || ip.IsLoopback()) {
+		return true
+	}
+	if listenHost, _, err := net.SplitHostPort(s.httpAddr); err == nil && strings.EqualFold(listenHost, host) {
+		return true
+	}
+	for _, allowed := range s.allowedHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) requireToken(next http.Handler) http.Handler {
+	if s.apiToken == "" {
+		return next
+	}
+	expected := []byte("Bearer " + s.apiToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="willys-mcp"`)
+			writeRESTError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// restCart returns the view_cart result; group_by and sort work as query parameters.
+func (s *Server) restCart(w http.ResponseWriter, r *http.Request) {
+	args := map[string]any{}
+	for param, arg := range map[string]string{"group_by": "group_by", "sort": "sort_by"} {
+		if value := r.URL.Query().Get(param); value != "" {
+			args[arg] = value
+		}
+	}
+	s.writeToolResult(w, r, "view_cart", args)
+}
+
+// restShoppingListAdd takes {"item": "mjölk", "quantity": 2} or {"items": [{"query":
+// "mjölk"}, ...]} and adds the items through list_to_cart, so the pick policy, the
+// active basket, and quotas apply as for an agent.
+func (s *Server) restShoppingListAdd(w http.ResponseWriter, r *http.Request) {
+	var body shoppingListAddRequest
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxRESTBody))
+	if err := decoder.Decode(&body); err != nil {
+		writeRESTError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+
+	var items []any
+	for _, item := range body.Items {
+		items = append(items, map[string]any{"query": item.Query, "quantity": float64(max(item.Quantity, 1))})
+	}
+	if body.Item != "" {
+		items = append(items, map[string]any{"query": body.Item, "quantity": float64(max(body.Quantity, 1))})
+	}
+	if len(items) == 0 {
+		writeRESTError(w, http.StatusBadRequest, "item or items is required")
+		return
+	}
+
+	args := map[string]any{"items": items, "source": restSource}
+	if body.Policy != nil {
+		args["policy"] = body.Policy
+	}
+	s.writeToolResult(w, r, "list_to_cart", args)
+}
+
+// writeToolResult calls a tool through its full middleware chain and writes its JSON.
+func (s *Server) writeToolResult(w http.ResponseWriter, r *http.Request, toolName string, args map[string]any) {
+	result, err := s.callTool(r.Context(), toolName, args)
+	switch {
+	case errors.Is(err, errToolUnavailable):
+		writeRESTError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		writeRESTError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	text := resultText(result)
+	if result.IsError {
+		writeRESTError(w, http.StatusUnprocessableEntity, text)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, text)
+}
+
+var errToolUnavailable = errors.New("tool is not available")
+
+// callTool runs a registered tool, middleware included, as an MCP call would.
+func (s *Server) callTool(ctx context.Context, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+	tool := s.mcpServer.GetTool(toolName)
+	if tool == nil {
+		return nil, fmt.Errorf("%w: %s", errToolUnavailable, toolName)
+	}
+
+	var request mcp.CallToolRequest
+	request.Params.Name = toolName
+	request.Params.Arguments = args
+	return tool.Handler(ctx, request)
+}
+
+// resultText returns the first text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if t, ok := mcp.AsTextContent(content); ok {
+			return t.Text
+		}
+	}
+	return ""
+}
+
+func writeRESTError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(restError{Error: strings.TrimSpace(message)})
+}
+
+// errIsShutdown reports whether err only means the transport was stopped on purpose.
+func errIsShutdown(err error) bool {
+	return err == nil || errors.Is(err, context.Canceled) || errors.Is(err, http.ErrServerClosed)
+}
@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+type restClient struct {
+	warningsClient
+	added map[string]int
+}
+
+func (c *restClient) SearchProducts(ctx context.Context, query string, page, size int, prefs *willys.SearchPreferences) ([]willys.Product, error) {
+	return []willys.Product{{Code: "101", Name: "Mellanmjölk 1,5%"}}, nil
+}
+
+func (c *restClient) AddToCart(ctx context.Context, productCode string, quantity int) (*willys.CartSummary, error) {
+	c.added[productCode] += quantity
+	return c.cart, nil
+}
+
+func TestRESTFacade(t *testing.T) {
+	client := &restClient{
+		warningsClient: warningsClient{cart: &willys.CartSummary{ItemCount: 1, FinalTotal: willys.SEK(1690)}},
+		added:          map[string]int{},
+	}
+	s := NewServer(client, WithHTTP("127.0.0.1:0", "secret"), WithScheduler(false), WithSlotAutobook(false))
+	srv := httptest.NewServer(s.httpHandler())
+	defer srv.Close()
+
+	do := func(method, path, token, body string) (*http.Response, map[string]any) {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		var decoded map[string]any
+		json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp, decoded
+	}
+
+	if resp, _ := do("GET", "/api/cart", "wrong", ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", resp.StatusCode)
+	}
+	if resp, _ := do("POST", "/mcp", "", "{}"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected the MCP endpoint to need the token too, got %d", resp.StatusCode)
+	}
+
+	resp, body := do("POST", "/api/shopping-list/add", "secret", `{"item": "mjölk", "quantity": 2}`)
+	if resp.StatusCode != http.StatusOK || body["added"] != float64(1) {
+		t.Fatalf("Expected the item to be added, got %d %v", resp.StatusCode, body)
+	}
+	if client.added["101"] != 2 {
+		t.Errorf("Expected 2 of product 101 in the cart, got %v", client.added)
+	}
+
+	if resp, body := do("POST", "/api/shopping-list/add", "secret", `{}`); resp.StatusCode != http.StatusBadRequest || body["error"] == nil {
+		t.Errorf("Expected 400 without items, got %d %v", resp.StatusCode, body)
+	}
+
+	resp, body = do("GET", "/api/cart", "secret", "")
+	if resp.StatusCode != http.StatusOK || body["itemCount"] != float64(1) {
+		t.Errorf("Expected the cart, got %d %v", resp.StatusCode, body)
+	}
+}
+
+func TestAddItemWebhook(t *testing.T) {
+	client := &restClient{
+		warningsClient: warningsClient{cart: &willys.CartSummary{}},
+		added:          map[string]int{},
+	}
+	s := NewServer(client, WithHTTP("127.0.0.1:0", "secret"), WithWebhook("hook-secret", "Voice"), WithScheduler(false), WithSlotAutobook(false))
+	srv := httptest.NewServer(s.httpHandler())
+	defer srv.Close()
+
+	post := func(path, header, body string) (int, map[string]any) {
+		req, _ := http.NewRequest("POST", srv.URL+path, strings.NewReader(body))
+		if header != "" {
+			req.Header.Set("X-Webhook-Secret", header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		defer resp.Body.Close()
+		var decoded map[string]any
+		json.NewDecoder(resp.Body).Decode(&decoded)
+		return resp.StatusCode, decoded
+	}
+
+	if status, _ := post("/hooks/add-item", "secret", `{"query": "mjölk"}`); status != http.StatusUnauthorized {
+		t.Errorf("Expected the API token not to work for the webhook, got %d", status)
+	}
+
+	status, body := post("/hooks/add-item?secret=hook-secret", "", `{"query": "mjölk", "qty": 2}`)
+	if status != http.StatusOK || body["added"] != true || body["basket"] != "voice" {
+		t.Fatalf("Expected the item in the voice basket, got %d %v", status, body)
+	}
+	if len(client.added) != 0 {
+		t.Errorf("Expected nothing in the live cart, got %v", client.added)
+	}
+	basket, err := s.toolHandler.getBasket("voice")
+	if err != nil || len(basket.Items) != 1 || basket.Items[0].Quantity != 2 || basket.Items[0].Source != webhookSource {
+		t.Errorf("Unexpected basket: %+v (%v)", basket, err)
+	}
+	if !strings.Contains(body["speech"].(string), "Mellanmjölk") {
+		t.Errorf("Expected the product in the speech text, got %v", body["speech"])
+	}
+
+	if status, _ := post("/hooks/add-item", "hook-secret", `{"qty": 1}`); status != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a query, got %d", status)
+	}
+}
+
+func TestHTTPRequiresTokenOffLoopback(t *testing.T) {
+	s := NewServer(&warningsClient{}, WithHTTP("0.0.0.0:0", ""), WithScheduler(false), WithSlotAutobook(false))
+	if err := s.serveHTTP(context.Background()); err == nil || !strings.Contains(err.Error(), "WILLYS_API_TOKEN") {
+		t.Errorf("Expected HTTP without a token on all interfaces to be refused, got %v", err)
+	}
+
+	for addr, loopback := range map[string]bool{
+		"127.0.0.1:8080": true, "[::1]:8080": true, "localhost:8080": true,
+		":8080": false, "0.0.0.0:8080": false, "192.168.1.5:8080": false,
+	} {
+		if isLoopbackAddr(addr) != loopback {
+			t.Errorf("isLoopbackAddr(%q): expected %v", addr, loopback)
+		}
+	}
+}
+
+func TestHTTPHostAndOriginChecks(t *testing.T) {
+	s := NewServer(&warningsClient{}, WithHTTP("127.0.0.1:0", ""), WithAllowedHosts("willys.home.lan"), WithScheduler(false), WithSlotAutobook(false))
+	handler := s.httpHandler()
+
+	tests := []struct {
+		host, origin string
+		allowed      bool
+	}{
+		{"127.0.0.1:8080", "", true},
+		{"localhost:8080", "http://localhost:3000", true},
+		{"willys.home.lan", "https://willys.home.lan", true},
+		{"attacker.example:8080", "", false},
+		{"127.0.0.1:8080", "http://attacker.example", false},
+		{"127.0.0.1:8080", "null", false},
+		{"127.0.0.1:8080", "http://127.0.0.1:3000", true},
+		{"127.0.0.1:8080", "http://[::1]:3000", true},
+		{"127.0.0.1:8080", "http://203.0.113.5", false},
+		{"203.0.113.5:8080", "", true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/openapi.json", nil)
+		req.Host = tt.host
+		if tt.origin != "" {
+			req.Header.Set("Origin", tt.origin)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if forbidden := rec.Code == http.StatusForbidden; forbidden == tt.allowed {
+			t.Errorf("Host %q Origin %q: expected allowed=%v, got %d", tt.host, tt.origin, tt.allowed, rec.Code)
+		}
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	s := NewServer(&restClient{}, WithHTTP("127.0.0.1:0", "secret"), WithScheduler(false), WithSlotAutobook(false))
+	srv := httptest.NewServer(s.httpHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + openAPIPath)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", openAPIPath, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the spec without a token, got %d", resp.StatusCode)
+	}
+	var spec struct {
+		Paths      map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	raw, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		t.Fatalf("Invalid spec: %v", err)
+	}
+	if spec.Paths["/api/cart"] == nil || spec.Paths["/api/shopping-list/add"] == nil {
+		t.Errorf("Expected the REST paths, got %v", spec.Paths)
+	}
+	if spec.Paths["/hooks/add-item"] != nil {
+		t.Error("Expected no webhook path while the webhook is off")
+	}
+
+	for _, match := range regexp.MustCompile(`"\$ref": "([^"]+)"`).FindAllStringSubmatch(string(raw), -1) {
+		name, ok := strings.CutPrefix(match[1], "#/components/schemas/")
+		if !ok || spec.Components.Schemas[name] == nil {
+			t.Errorf("Unresolved reference %s", match[1])
+		}
+	}
+
+	// The schema follows the JSON the tools produce: totals are Money objects
+	cart := spec.Components.Schemas["CartSummary"].(map[string]any)["properties"].(map[string]any)
+	if ref := cart["finalTotal"].(map[string]any)["$ref"]; ref != "#/components/schemas/Money" {
+		t.Errorf("Expected finalTotal to be Money, got %v", ref)
+	}
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const lastDeliveryKey = "last"
+
+// DeliverySettings are the choices of the last successful select_delivery_time, which
+// use_last repeats. Unlike the booked delivery they survive releasing the slot.
+type DeliverySettings struct {
+	Address   willys.DeliveryAddress `json:"address"`
+	Window    string                 `json:"window"`  // e.g. "17:00-19:00"
+	Weekday   string                 `json:"weekday"` // e.g. "Monday"
+	UpdatedAt time.Time              `json:"updatedAt"`
+}
+
+// rememberDeliverySettings stores the address and slot preferences of a booking.
+// Failures are logged: the booking itself already succeeded.
+func (h *ToolHandler) rememberDeliverySettings(info *willys.DeliveryInfo) {
+	settings := DeliverySettings{
+		Address:   info.Address,
+		Window:    info.TimeSlot.StartTime + "-" + info.TimeSlot.EndTime,
+		UpdatedAt: time.Now(),
+	}
+	if date, err := time.Parse("2006-01-02", info.TimeSlot.Date); err == nil {
+		settings.Weekday = date.Weekday().String()
+	}
+	if err := store.PutJSON(h.store, store.BucketDeliveries, lastDeliveryKey, settings); err != nil {
+		log.Printf("Failed to remember delivery settings: %v", err)
+	}
+}
+
+func (h *ToolHandler) lastDeliverySettings() (*DeliverySettings, error) {
+	var settings DeliverySettings
+	if err := store.GetJSON(h.store, store.BucketDeliveries, lastDeliveryKey, &settings); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errors.New("use_last: no earlier delivery to reuse; give the address and slot this time")
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// preferredSlot picks the earliest available slot in the remembered window, on the
+// remembered weekday if one is open, else on any day.
+func (s *DeliverySettings) preferredSlot(slots []willys.TimeSlot) *willys.TimeSlot {
+	var matches []*willys.TimeSlot
+	for i := range slots {
+		slot := &slots[i]
+		if slot.Available && slot.StartTime+"-"+slot.EndTime == s.Window {
+			matches = append(matches, slot)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Date < matches[j].Date })
+
+	for _, slot := range matches {
+		if date, err := time.Parse("2006-01-02", slot.Date); err == nil && date.Weekday().String() == s.Weekday {
+			return slot
+		}
+	}
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	return nil
+}
+
+// hasAddressArgument reports whether the call names an address itself.
+func hasAddressArgument(request mcp.CallToolRequest) bool {
+	args := request.GetArguments()
+	for _, key := range []string{"address", "address_text", "address_label"} {
+		if _, ok := args[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// windowStart is the HH:MM the remembered window starts at.
+func (s *DeliverySettings) windowStart() string {
+	start, _, _ := strings.Cut(s.Window, "-")
+	return start
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// deliveryClient offers fixed slots and books whatever it is asked to; other calls panic.
+type deliveryClient struct {
+	willys.WillysAPI
+	slots []willys.TimeSlot
+}
+
+func (c *deliveryClient) GetAvailableTimeSlots(ctx context.Context, postalCode string) ([]willys.TimeSlot, error) {
+	return c.slots, nil
+}
+
+func (c *deliveryClient) SetupDelivery(ctx context.Context, address willys.DeliveryAddress, slot willys.TimeSlot) (*willys.DeliveryInfo, error) {
+	return &willys.DeliveryInfo{Address: address, TimeSlot: slot, DeliveryFee: slot.Fee}, nil
+}
+
+func TestSelectDeliveryTimeUseLast(t *testing.T) {
+	client := &deliveryClient{slots: []willys.TimeSlot{
+		{SlotID: "mon-evening", Date: "2026-10-19", StartTime: "17:00", EndTime: "19:00", Available: true},
+		{SlotID: "tue-evening", Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00", Available: true},
+		{SlotID: "tue-morning", Date: "2026-10-20", StartTime: "08:00", EndTime: "10:00", Available: true},
+		{SlotID: "mon-next", Date: "2026-10-26", StartTime: "17:00", EndTime: "19:00", Available: true},
+	}}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	if result, _ := h.SelectDeliveryTime(ctx, toolRequest(map[string]any{"use_last": true})); !result.IsError {
+		t.Error("Expected use_last to fail before any delivery was booked")
+	}
+
+	home := map[string]any{"first_name": "Test", "last_name": "User", "address": "Drottninggatan 1", "postal_code": "11151", "city": "Stockholm"}
+	result, _ := h.SelectDeliveryTime(ctx, toolRequest(map[string]any{"address": home, "delivery_date": "2026-10-20", "time_slot": "17:00-19:00"}))
+	if result.IsError {
+		t.Fatalf("Unexpected error: %v", result.Content)
+	}
+
+	// Tuesday evening was booked, so it wins over the earlier Monday evening
+	booked := selectAndDecode(t, h, map[string]any{"use_last": true})
+	if booked.Address.City != "Stockholm" || booked.TimeSlot.SlotID != "tue-evening" {
+		t.Errorf("Expected the last address, window, and weekday, got %+v", booked)
+	}
+
+	// Without a Tuesday evening, the earliest evening in the same window is used
+	client.slots[1].Available = false
+	if booked := selectAndDecode(t, h, map[string]any{"use_last": true}); booked.TimeSlot.SlotID != "mon-evening" {
+		t.Errorf("Expected the earliest slot in the window, got %s", booked.TimeSlot.SlotID)
+	}
+	client.slots[1].Available = true
+
+	// A date alone reuses the last window on that date
+	if booked := selectAndDecode(t, h, map[string]any{"use_last": true, "delivery_date": "2026-10-26"}); booked.TimeSlot.SlotID != "mon-next" {
+		t.Errorf("Expected the last window on the given date, got %s", booked.TimeSlot.SlotID)
+	}
+
+	// Explicit arguments take precedence
+	if booked := selectAndDecode(t, h, map[string]any{"use_last": true, "delivery_date": "2026-10-20", "time_slot": "08:00-10:00"}); booked.TimeSlot.SlotID != "tue-morning" {
+		t.Errorf("Expected the explicit slot, got %s", booked.TimeSlot.SlotID)
+	}
+}
+
+func selectAndDecode(t *testing.T, h *ToolHandler, args map[string]any) willys.DeliveryInfo {
+	t.Helper()
+	result, _ := h.SelectDeliveryTime(context.Background(), toolRequest(args))
+	if result.IsError {
+		t.Fatalf("Unexpected error: %v", result.Content)
+	}
+	var info willys.DeliveryInfo
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &info); err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestSelectDeliveryTimeSlotSpecWithoutDates(t *testing.T) {
+	client := &deliveryClient{slots: []willys.TimeSlot{
+		{SlotID: "evening", Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00", Available: true},
+	}}
+	h := NewToolHandler(client)
+	collector := &warningCollector{}
+	ctx := context.WithValue(context.Background(), warningsKey{}, collector)
+
+	home := map[string]any{"first_name": "Test", "last_name": "User", "address": "Drottninggatan 1", "postal_code": "11151", "city": "Stockholm"}
+	result, _ := h.SelectDeliveryTime(ctx, toolRequest(map[string]any{"address": home, "slot_spec": "earliest evening"}))
+	if result.IsError {
+		t.Fatalf("Unexpected error: %v", result.Content)
+	}
+	for _, w := range collector.warnings {
+		if w.Code == WarningHolidaySlots {
+			t.Errorf("Expected no holiday warning without dates, got %q", w.Message)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const listSearchSize = 20
+
+type pickHistory struct {
+	store store.Store
+}
+
+func newPickHistory(s store.Store) *pickHistory {
+	return &pickHistory{store: s}
+}
+
+func (h *pickHistory) LastChoice(query string) (string, bool) {
+	code, err := h.store.Get(store.BucketPickHistory, normalizeQuery(query))
+	if err != nil {
+		return "", false
+	}
+	return string(code), true
+}
+
+func (h *pickHistory) Record(query, productCode string) {
+	if err := h.store.Put(store.BucketPickHistory, normalizeQuery(query), []byte(productCode)); err != nil {
+		log.Printf("Failed to record pick history for %q: %v", query, err)
+	}
+}
+
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.TrimSpace(query))
+}
+
+func pickPolicyProperty() mcp.ToolOption {
+	return mcp.WithObject("policy",
+		mcp.Description("Auto-pick policy overriding the server default"),
+		mcp.Properties(map[string]any{
+			"strategy": map[string]any{
+				"type":        "string",
+				"description": "Pick strategy: 'cheapest_unit', 'preferred_brand', or 'historical'",
+			},
+			"preferred_brands": map[string]any{
+				"type":        "array",
+				"description": "Brands to prefer, in priority order (e.g., ['Garant', 'Arla'])",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+		}),
+	)
+}
+
+func (h *ToolHandler) parsePickPolicy(request mcp.CallToolRequest) (willys.PickPolicy, error) {
+	h.mu.RLock()
+	policy := h.pickPolicy
+	h.mu.RUnlock()
+
+	if policyData := mcp.ParseStringMap(request, "policy", nil); policyData != nil {
+		if strategy, ok := policyData["strategy"].(string); ok && strategy != "" {
+			policy.Strategy = strategy
+		}
+		if brands, ok := policyData["preferred_brands"].([]any); ok {
+			policy.PreferredBrands = nil
+			for _, brand := range brands {
+				if b, ok := brand.(string); ok {
+					policy.PreferredBrands = append(policy.PreferredBrands, b)
+				}
+			}
+		}
+	}
+
+	return policy, willys.ValidatePickPolicy(policy)
+}
+
+func (h *ToolHandler) SearchMany(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	queries := getStringSlice(request.GetArguments(), "queries")
+	if len(queries) == 0 {
+		return mcp.NewToolResultError("queries parameter is required"), nil
+	}
+
+	size := mcp.ParseInt(request, "size", 5)
+
+	policy, err := h.parsePickPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid policy: %v", err)), nil
+	}
+
+	detail, err := h.parseOutputDetail(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaSearch, len(queries)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	results := make([]map[string]any, 0, len(queries))
+	for _, query := range queries {
+		products, err := h.client.SearchProducts(ctx, query, 0, max(size, listSearchSize), nil)
+		if err != nil {
+			results = append(results, map[string]any{
+				"query": query,
+				"error": err.Error(),
+			})
+			continue
+		}
+
+		pick := h.pickProduct(ctx, query, products, policy)
+		if len(products) > size {
+			products = products[:size]
+		}
+
+		results = append(results, map[string]any{
+			"query":    query,
+			"pick":     projectPick(pick, detail),
+			"products": projectProducts(products, detail),
+		})
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"policy":  policy,
+		"results": results,
+	})
+}
+
+func (h *ToolHandler) ListToCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	items := parseListItems(request.GetArguments()["items"])
+	if len(items) == 0 {
+		return mcp.NewToolResultError("items parameter is required"), nil
+	}
+
+	policy, err := h.parsePickPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid policy: %v", err)), nil
+	}
+	if err := h.resolveItemMembers(items, mcp.ParseString(request, "member", "")); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	detail, err := h.parseOutputDetail(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaSearch, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := h.consumeQuota(ctx, quotaCartMutation, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	basket := h.activeBasket(ctx)
+	results, added := h.addListItems(ctx, items, policy, mcp.ParseString(request, "source", "list_to_cart"), basket)
+	for _, result := range results {
+		if pick, ok := result["pick"].(willys.PickResult); ok {
+			result["pick"] = projectPick(pick, detail)
+		}
+	}
+
+	response := listToCartResponse{Policy: policy, Results: results, Added: added}
+	if basket != "" {
+		draft, err := h.getBasket(basket)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("items processed but failed to read basket: %v", err)), nil
+		}
+		response.Basket = draft
+		return mcp.NewToolResultJSON(response)
+	}
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("items processed but failed to get cart: %v", err)), nil
+	}
+	response.Cart = cart
+
+	return mcp.NewToolResultJSON(response)
+}
+
+// listToCartResponse is the list_to_cart result. It lands in the draft basket when one
+// is active, so exactly one of Basket and Cart is set.
+type listToCartResponse struct {
+	Policy  willys.PickPolicy   `json:"policy"`
+	Results []map[string]any    `json:"results" jsonschema:"description=Per item: query, quantity, the pick, and added or error"`
+	Added   int                 `json:"added"`
+	Basket  *DraftBasket        `json:"basket,omitempty"`
+	Cart    *willys.CartSummary `json:"cart,omitempty"`
+}
+
+// listItem is one free-text line of a shopping list, e.g. {"query": "mjölk", "quantity": 2}.
+type listItem struct {
+	Query    string `json:"query"`
+	Quantity int    `json:"quantity,omitempty"`
+	Member   string `json:"member,omitempty"`
+}
+
+func parseListItems(raw any) []listItem {
+	rawItems, ok := raw.([]any)
+	if !ok {
+		return nil
+	}
+
+	items := make([]listItem, 0, len(rawItems))
+	for _, r := range rawItems {
+		item, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		query := getStringField(item, "query")
+		if query == "" {
+			continue
+		}
+		quantity := 1
+		if q, ok := item["quantity"].(float64); ok && q > 0 {
+			quantity = int(q)
+		}
+		items = append(items, listItem{Query: query, Quantity: quantity, Member: getStringField(item, "member")})
+	}
+	return items
+}
+
+// addListItems auto-picks a product for each item and adds it under the given origin
+// source to the named draft basket, or to the cart when basket is "". It returns a
+// per-item result and the number of items added.
+func (h *ToolHandler) addListItems(ctx context.Context, items []listItem, policy willys.PickPolicy, source, basket string) ([]map[string]any, int) {
+	results := make([]map[string]any, 0, len(items))
+	added := 0
+
+	for _, item := range items {
+		result := map[string]any{
+			"query":    item.Query,
+			"quantity": item.Quantity,
+		}
+		if item.Member != "" {
+			result["member"] = item.Member
+		}
+
+		products, err := h.client.SearchProducts(ctx, item.Query, 0, listSearchSize, nil)
+		if err != nil {
+			result["error"] = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		pick := h.pickProduct(ctx, item.Query, products, policy)
+		result["pick"] = pick
+		if pick.Product == nil {
+			results = append(results, result)
+			continue
+		}
+
+		line := BasketItem{
+			Code:     pick.Product.Code,
+			Name:     pick.Product.Name,
+			Quantity: item.Quantity,
+			Source:   source,
+			Query:    item.Query,
+			Member:   item.Member,
+		}
+		if err := h.addToCartOrBasket(ctx, basket, line); err != nil {
+			result["error"] = err.Error()
+			results = append(results, result)
+			continue
+		}
+
+		h.pickHistory.Record(item.Query, pick.Product.Code)
+		result["added"] = true
+		added++
+		results = append(results, result)
+	}
+
+	return results, added
+}
+
+func getStringSlice(m map[string]any, key string) []string {
+	raw, ok := m[key].([]any)
+	if !ok {
+		return nil
+	}
+	values := make([]string, 0, len(raw))
+	for _, v := range raw {
+		if s, ok := v.(string); ok && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// LogLevelOff turns off forwarding of the server log to MCP clients.
+const LogLevelOff = "off"
+
+// localLog writes to the process log only. Code that already sends a structured
+// notification logs through it, so clients don't get the same message twice.
+var localLog = log.New(os.Stderr, "", log.LstdFlags)
+
+// logForwarder copies the server log to MCP clients as notifications/message, for
+// clients such as Claude Desktop that don't show the server's stderr. Each session
+// starts at defaultLevel and can change its level with logging/setLevel.
+type logForwarder struct {
+	mcpServer    *server.MCPServer
+	defaultLevel mcp.LoggingLevel
+
+	mu       sync.RWMutex
+	sessions map[string]server.SessionWithLogging
+}
+
+func newLogForwarder(defaultLevel mcp.LoggingLevel) *logForwarder {
+	return &logForwarder{
+		defaultLevel: defaultLevel,
+		sessions:     make(map[string]server.SessionWithLogging),
+	}
+}
+
+// hooks tracks sessions as they connect and disconnect.
+func (f *logForwarder) hooks() *server.Hooks {
+	hooks := &server.Hooks{}
+	hooks.AddOnRegisterSession(func(_ context.Context, session server.ClientSession) {
+		s, ok := session.(server.SessionWithLogging)
+		if !ok {
+			return
+		}
+		s.SetLogLevel(f.defaultLevel)
+		f.mu.Lock()
+		f.sessions[s.SessionID()] = s
+		f.mu.Unlock()
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		f.mu.Lock()
+		delete(f.sessions, session.SessionID())
+		f.mu.Unlock()
+	})
+	return hooks
+}
+
+// send delivers a log notification to every session whose level admits it.
+// Failures are dropped rather than logged: logging them would feed back into send.
+func (f *logForwarder) send(level mcp.LoggingLevel, data any) {
+	if f.mcpServer == nil {
+		return
+	}
+	f.mu.RLock()
+	ids := make([]string, 0, len(f.sessions))
+	for id, s := range f.sessions {
+		if s.Initialized() {
+			ids = append(ids, id)
+		}
+	}
+	f.mu.RUnlock()
+
+	notification := mcp.NewLoggingMessageNotification(level, notificationLogger, data)
+	for _, id := range ids {
+		_ = f.mcpServer.SendLogMessageToSpecificClient(id, notification)
+	}
+}
+
+// Write forwards one line written by the log package. It never fails, so the local
+// log keeps working when no client is listening.
+func (f *logForwarder) Write(p []byte) (int, error) {
+	line := stripLogTimestamp(strings.TrimRight(string(p), "\n"))
+	if line != "" {
+		f.send(logLineLevel(line), map[string]any{"message": line})
+	}
+	return len(p), nil
+}
+
+// install adds f to the standard logger's output and returns a func that undoes it.
+func (f *logForwarder) install() func() {
+	prev := log.Writer()
+	localLog.SetOutput(prev)
+	log.SetOutput(io.MultiWriter(prev, f))
+	return func() { log.SetOutput(prev) }
+}
+
+// stripLogTimestamp drops the date and time the standard logger puts first; the
+// notification is timestamped by the client.
+func stripLogTimestamp(line string) string {
+	const layout = "2006/01/02 15:04:05"
+	if len(line) > len(layout) && line[len(layout)] == ' ' {
+		if _, err := time.Parse(layout, line[:len(layout)]); err == nil {
+			return line[len(layout)+1:]
+		}
+	}
+	return line
+}
+
+// logLineLevel guesses the level of a plain log line from its wording.
+func logLineLevel(line string) mcp.LoggingLevel {
+	lower := strings.ToLower(line)
+	switch {
+	case strings.Contains(lower, "panic"):
+		return mcp.LoggingLevelCritical
+	case strings.Contains(lower, "failed"), strings.Contains(lower, "error"):
+		return mcp.LoggingLevelError
+	case strings.Contains(lower, "warning"), strings.Contains(lower, "disabled"),
+		strings.Contains(lower, "ignoring"), strings.Contains(lower, "gave up"),
+		strings.Contains(lower, "retry"):
+		return mcp.LoggingLevelWarning
+	default:
+		return mcp.LoggingLevelInfo
+	}
+}
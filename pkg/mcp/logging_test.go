@@ -0,0 +1,88 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type loggingSession struct {
+	id    string
+	ch    chan mcp.JSONRPCNotification
+	level mcp.LoggingLevel
+}
+
+func (s *loggingSession) Initialize()                                         {}
+func (s *loggingSession) Initialized() bool                                   { return true }
+func (s *loggingSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.ch }
+func (s *loggingSession) SessionID() string                                   { return s.id }
+func (s *loggingSession) SetLogLevel(level mcp.LoggingLevel)                  { s.level = level }
+func (s *loggingSession) GetLogLevel() mcp.LoggingLevel                       { return s.level }
+
+func TestLogForwarding(t *testing.T) {
+	s := NewServer(nil, WithLogForwarding("warning"))
+	quiet := &loggingSession{id: "quiet", ch: make(chan mcp.JSONRPCNotification, 10)}
+	chatty := &loggingSession{id: "chatty", ch: make(chan mcp.JSONRPCNotification, 10)}
+	for _, session := range []*loggingSession{quiet, chatty} {
+		if err := s.mcpServer.RegisterSession(context.Background(), session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if quiet.level != mcp.LoggingLevelWarning {
+		t.Fatalf("new session level = %q, want the configured warning", quiet.level)
+	}
+	chatty.SetLogLevel(mcp.LoggingLevelDebug)
+
+	defer s.logs.install()()
+	log.Println("Logged in to Willys")
+	log.Printf("Failed to refresh token: %v", context.DeadlineExceeded)
+
+	if got := len(quiet.ch); got != 1 {
+		t.Fatalf("quiet session got %d notifications, want only the error", got)
+	}
+	if got := len(chatty.ch); got != 2 {
+		t.Fatalf("chatty session got %d notifications, want 2", got)
+	}
+	n := <-quiet.ch
+	params := n.Params.AdditionalFields
+	if n.Method != "notifications/message" || params["level"] != mcp.LoggingLevelError || params["logger"] != notificationLogger {
+		t.Fatalf("notification = %+v", n)
+	}
+	if msg := params["data"].(map[string]any)["message"]; msg != "Failed to refresh token: context deadline exceeded" {
+		t.Errorf("message = %q, want it without the log timestamp", msg)
+	}
+
+	s.mcpServer.UnregisterSession(context.Background(), "chatty")
+	log.Println("Failed again")
+	if got := len(chatty.ch); got != 2 {
+		t.Errorf("unregistered session got %d notifications, want 2", got)
+	}
+
+	// broadcast goes through the forwarder once, not again via the log
+	s.broadcast(mcp.LoggingLevelError, "Scheduled order failed", map[string]any{"event": "schedule_run"})
+	if got := len(quiet.ch); got != 2 {
+		t.Errorf("quiet session got %d notifications after broadcast, want 2", got)
+	}
+}
+
+func TestLogLineLevel(t *testing.T) {
+	cases := map[string]mcp.LoggingLevel{
+		"Starting Willys MCP server...":           mcp.LoggingLevelInfo,
+		"Semantic matching disabled: no key":      mcp.LoggingLevelWarning,
+		"Failed to send re-auth notification: x":  mcp.LoggingLevelError,
+		"background job scheduler panicked: boom": mcp.LoggingLevelCritical,
+	}
+	for line, want := range cases {
+		if got := logLineLevel(line); got != want {
+			t.Errorf("logLineLevel(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestWithLogForwardingOff(t *testing.T) {
+	if s := NewServer(nil, WithLogForwarding(LogLevelOff)); s.logs != nil {
+		t.Error("log forwarding should be off")
+	}
+}
@@ -0,0 +1,298 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	MemberRoleAdult = "adult"
+	MemberRoleChild = "child"
+
+	maxMemberNameLength = 32
+)
+
+type (
+	// HouseholdMember is someone items can be assigned to ("Erik's lunchbox"), stored
+	// under the lowercased name.
+	HouseholdMember struct {
+		Name      string    `json:"name"`
+		Role      string    `json:"role"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+
+	// memberSummary is one member's share of the cart. An item assigned to several
+	// members is split evenly between them; Unassigned collects the rest.
+	memberSummary struct {
+		Member     string       `json:"member,omitempty"`
+		Unassigned bool         `json:"unassigned,omitempty"`
+		Items      int          `json:"items"`
+		Subtotal   willys.Money `json:"subtotal"`
+	}
+)
+
+func memberKey(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len([]rune(name)) > maxMemberNameLength {
+		return "", willys.NewValidationError("member", fmt.Sprintf("use a name of 1-%d characters", maxMemberNameLength))
+	}
+	return strings.ToLower(name), nil
+}
+
+func memberProperty() mcp.ToolOption {
+	return mcp.WithString("member",
+		mcp.Description("Household member the items are for (see save_member), shown per member in view_cart"),
+	)
+}
+
+func (h *ToolHandler) loadMembers() ([]HouseholdMember, error) {
+	entries, err := h.store.List(store.BucketMembers)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]HouseholdMember, 0, len(entries))
+	for key := range entries {
+		var member HouseholdMember
+		if err := store.GetJSON(h.store, store.BucketMembers, key, &member); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Name < members[j].Name })
+	return members, nil
+}
+
+// resolveMember returns the saved spelling of name, or "" for "". Unknown names are
+// refused so a typo doesn't start a new member.
+func (h *ToolHandler) resolveMember(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil
+	}
+	key, err := memberKey(name)
+	if err != nil {
+		return "", err
+	}
+	var member HouseholdMember
+	if err := store.GetJSON(h.store, store.BucketMembers, key, &member); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return "", fmt.Errorf("no household member named %q; add them with save_member", strings.TrimSpace(name))
+		}
+		return "", fmt.Errorf("failed to read household member: %w", err)
+	}
+	return member.Name, nil
+}
+
+// resolveItemMembers resolves the member of every list item, defaulting to fallback.
+func (h *ToolHandler) resolveItemMembers(items []listItem, fallback string) error {
+	for i := range items {
+		if items[i].Member == "" {
+			items[i].Member = fallback
+		}
+		member, err := h.resolveMember(items[i].Member)
+		if err != nil {
+			return err
+		}
+		items[i].Member = member
+	}
+	return nil
+}
+
+// tagMember assigns productCode to member in the cart. Like recordOrigin, failures are
+// only logged.
+func (h *ToolHandler) tagMember(productCode, member string) {
+	if member == "" {
+		return
+	}
+	h.membersMu.Lock()
+	defer h.membersMu.Unlock()
+	members, err := h.itemMembers(productCode)
+	if err != nil {
+		log.Printf("Failed to read members of %s: %v", productCode, err)
+	}
+	if slices.Contains(members, member) {
+		return
+	}
+	if err := store.PutJSON(h.store, store.BucketMemberTags, productCode, append(members, member)); err != nil {
+		log.Printf("Failed to assign %s to %s: %v", productCode, member, err)
+	}
+}
+
+func (h *ToolHandler) itemMembers(productCode string) ([]string, error) {
+	var members []string
+	if err := store.GetJSON(h.store, store.BucketMemberTags, productCode, &members); err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	return members, nil
+}
+
+// memberSummaries splits the items' totals between their members, in name order with
+// the unassigned share last. It returns nil when nothing is assigned.
+func memberSummaries(items []annotatedCartItem) []memberSummary {
+	byMember := make(map[string]*memberSummary)
+	unassigned := memberSummary{Unassigned: true}
+	for _, item := range items {
+		if len(item.Members) == 0 {
+			unassigned.Items++
+			unassigned.Subtotal = unassigned.Subtotal.Add(item.TotalPrice)
+			continue
+		}
+		share := item.TotalPrice.Ore / int64(len(item.Members))
+		remainder := item.TotalPrice.Ore - share*int64(len(item.Members))
+		for i, name := range item.Members {
+			summary, ok := byMember[name]
+			if !ok {
+				summary = &memberSummary{Member: name}
+				byMember[name] = summary
+			}
+			summary.Items++
+			amount := share
+			if i == 0 {
+				amount += remainder // the first member takes the odd öre
+			}
+			summary.Subtotal = summary.Subtotal.Add(willys.SEK(amount))
+		}
+	}
+	if len(byMember) == 0 {
+		return nil
+	}
+
+	summaries := make([]memberSummary, 0, len(byMember)+1)
+	for _, summary := range byMember {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Member < summaries[j].Member })
+	if unassigned.Items > 0 {
+		summaries = append(summaries, unassigned)
+	}
+	return summaries
+}
+
+func (h *ToolHandler) SaveMember(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := strings.Join(strings.Fields(mcp.ParseString(request, "name", "")), " ")
+	key, err := memberKey(name)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid name: %v", err)), nil
+	}
+
+	role := mcp.ParseString(request, "role", MemberRoleAdult)
+	if role != MemberRoleAdult && role != MemberRoleChild {
+		return mcp.NewToolResultError(fmt.Sprintf("unknown role %q (use %s or %s)", role, MemberRoleAdult, MemberRoleChild)), nil
+	}
+
+	// Keep the saved spelling of an existing member: assignments refer to it by name
+	var existing HouseholdMember
+	if err := store.GetJSON(h.store, store.BucketMembers, key, &existing); err == nil {
+		name = existing.Name
+	} else if !errors.Is(err, store.ErrNotFound) {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read household member: %v", err)), nil
+	}
+
+	member := HouseholdMember{Name: name, Role: role, UpdatedAt: time.Now()}
+	if err := store.PutJSON(h.store, store.BucketMembers, key, member); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save household member: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(member)
+}
+
+func (h *ToolHandler) ListMembers(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	members, err := h.loadMembers()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read household members: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"members": members,
+		"count":   len(members),
+	})
+}
+
+// DeleteMember removes a member and their assignments; their items become unassigned.
+func (h *ToolHandler) DeleteMember(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name, err := h.resolveMember(mcp.ParseString(request, "name", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+	key, _ := memberKey(name)
+	if err := h.store.Delete(store.BucketMembers, key); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete household member: %v", err)), nil
+	}
+
+	h.membersMu.Lock()
+	defer h.membersMu.Unlock()
+	tags, err := h.store.List(store.BucketMemberTags)
+	if err != nil {
+		log.Printf("Failed to read member assignments: %v", err)
+	}
+	for code := range tags {
+		members, err := h.itemMembers(code)
+		if err != nil || !slices.Contains(members, name) {
+			continue
+		}
+		if err := store.PutJSON(h.store, store.BucketMemberTags, code, slices.DeleteFunc(members, func(m string) bool { return m == name })); err != nil {
+			log.Printf("Failed to unassign %s from %s: %v", code, name, err)
+		}
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"deleted": true,
+		"name":    name,
+	})
+}
+
+// AssignCartItem sets who a cart item is for, replacing earlier assignments; an empty
+// member list makes it unassigned.
+func (h *ToolHandler) AssignCartItem(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	productCode := mcp.ParseString(request, "product_code", "")
+	if productCode == "" {
+		return mcp.NewToolResultError("product_code parameter is required"), nil
+	}
+
+	var members []string
+	for _, name := range getStringSlice(request.GetArguments(), "members") {
+		member, err := h.resolveMember(name)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if !slices.Contains(members, member) {
+			members = append(members, member)
+		}
+	}
+
+	cart, _, err := h.currentCart(ctx, false)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+	if !slices.ContainsFunc(cart.Items, func(item willys.CartItem) bool { return item.ProductCode == productCode }) {
+		return mcp.NewToolResultError(fmt.Sprintf("product %s is not in the cart", productCode)), nil
+	}
+
+	h.membersMu.Lock()
+	defer h.membersMu.Unlock()
+	if len(members) == 0 {
+		err = h.store.Delete(store.BucketMemberTags, productCode)
+	} else {
+		err = store.PutJSON(h.store, store.BucketMemberTags, productCode, members)
+	}
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to assign cart item: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"product_code": productCode,
+		"members":      members,
+	})
+}
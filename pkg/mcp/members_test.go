@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestHouseholdMembers(t *testing.T) {
+	client := &warningsClient{cart: &willys.CartSummary{Items: []willys.CartItem{
+		{ProductCode: "101_ST", Name: "Yoghurt", TotalPrice: willys.SEK(3001)},
+		{ProductCode: "202_ST", Name: "Knäckebröd", TotalPrice: willys.SEK(2500)},
+		{ProductCode: "303_ST", Name: "Kaffe", TotalPrice: willys.SEK(8900)},
+	}}}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	for _, name := range []string{"Erik", "Åsa"} {
+		if result, _ := h.SaveMember(ctx, toolRequest(map[string]any{"name": name})); result.IsError {
+			t.Fatalf("Failed to save %s: %v", name, result.Content)
+		}
+	}
+	if result, _ := h.SaveMember(ctx, toolRequest(map[string]any{"name": "Erik", "role": "pet"})); !result.IsError {
+		t.Error("Expected an unknown role to be rejected")
+	}
+
+	// Names resolve case-insensitively to the saved spelling; unknown ones are refused
+	if member, err := h.resolveMember("erik"); err != nil || member != "Erik" {
+		t.Errorf("Expected Erik, got %q (%v)", member, err)
+	}
+	if _, err := h.resolveMember("Erk"); err == nil {
+		t.Error("Expected an unknown member to be refused")
+	}
+
+	h.tagMember("101_ST", "Erik")
+	if result, _ := h.AssignCartItem(ctx, toolRequest(map[string]any{"product_code": "202_ST", "members": []any{"erik", "åsa"}})); result.IsError {
+		t.Fatalf("Failed to assign: %v", result.Content)
+	}
+	if result, _ := h.AssignCartItem(ctx, toolRequest(map[string]any{"product_code": "999_ST", "members": []any{"Erik"}})); !result.IsError {
+		t.Error("Expected an item outside the cart to be refused")
+	}
+
+	view := h.annotateCart(client.cart)
+	if len(view.Items[1].Members) != 2 {
+		t.Errorf("Expected two members on the shared item, got %v", view.Items[1].Members)
+	}
+	want := []memberSummary{
+		{Member: "Erik", Items: 2, Subtotal: willys.SEK(4251)},
+		{Member: "Åsa", Items: 1, Subtotal: willys.SEK(1250)},
+		{Unassigned: true, Items: 1, Subtotal: willys.SEK(8900)},
+	}
+	if len(view.PerMember) != len(want) {
+		t.Fatalf("Expected %d summaries, got %+v", len(want), view.PerMember)
+	}
+	for i := range want {
+		if view.PerMember[i] != want[i] {
+			t.Errorf("Summary %d: expected %+v, got %+v", i, want[i], view.PerMember[i])
+		}
+	}
+
+	// Deleting a member unassigns their items
+	if result, _ := h.DeleteMember(ctx, toolRequest(map[string]any{"name": "Åsa"})); result.IsError {
+		t.Fatalf("Failed to delete: %v", result.Content)
+	}
+	if members, _ := h.itemMembers("202_ST"); len(members) != 1 || members[0] != "Erik" {
+		t.Errorf("Expected only Erik left on the shared item, got %v", members)
+	}
+}
+
+func TestSaveMemberKeepsSpelling(t *testing.T) {
+	h := NewToolHandler(&warningsClient{})
+	ctx := context.Background()
+
+	h.SaveMember(ctx, toolRequest(map[string]any{"name": "Erik"}))
+	h.tagMember("101_ST", "Erik")
+	if result, _ := h.SaveMember(ctx, toolRequest(map[string]any{"name": "erik", "role": "child"})); result.IsError {
+		t.Fatalf("Failed to update: %v", result.Content)
+	}
+
+	members, _ := h.loadMembers()
+	if len(members) != 1 || members[0].Name != "Erik" || members[0].Role != MemberRoleChild {
+		t.Errorf("Expected Erik updated to a child, got %+v", members)
+	}
+	if tags, _ := h.itemMembers("101_ST"); len(tags) != 1 || tags[0] != members[0].Name {
+		t.Errorf("Expected the assignment to still match the member, got %v", tags)
+	}
+}
+
+func TestTagMemberConcurrently(t *testing.T) {
+	h := NewToolHandler(&warningsClient{})
+	names := []string{"Anna", "Erik", "Maja", "Olle", "Sara", "Åsa"}
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.tagMember("101_ST", name)
+		}()
+	}
+	wg.Wait()
+
+	if members, _ := h.itemMembers("101_ST"); len(members) != len(names) {
+		t.Errorf("Expected all %d members assigned, got %v", len(names), members)
+	}
+}
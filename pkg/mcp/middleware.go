@@ -0,0 +1,143 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type (
+	// Middleware wraps the handler of one tool. It gets the tool definition so it can
+	// look at the name, schema, or annotations when the tool is registered.
+	Middleware func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc
+
+	// ToolStats are the call counts and latency of one tool since the server started.
+	ToolStats struct {
+		Calls        int     `json:"calls"`
+		Errors       int     `json:"errors"`
+		AvgLatencyMs float64 `json:"avgLatencyMs"`
+		MaxLatencyMs int64   `json:"maxLatencyMs"`
+	}
+
+	toolMetrics struct {
+		mu    sync.Mutex
+		stats map[string]*toolTotals
+	}
+
+	toolTotals struct {
+		calls, errors int
+		total, max    time.Duration
+	}
+)
+
+// byName adapts a wrapper that only needs the tool name.
+func byName(wrap func(toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc) Middleware {
+	return func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return wrap(tool.Name, next)
+	}
+}
+
+// chain wraps handler in middleware, the first one outermost.
+func chain(tool mcp.Tool, handler server.ToolHandlerFunc, middleware ...Middleware) server.ToolHandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](tool, handler)
+	}
+	return handler
+}
+
+// WithMiddleware adds middleware around every tool, inside the built-in correlation,
+// tracing, metrics, and re-login handling and outside validation and warnings.
+func WithMiddleware(middleware ...Middleware) ServerOption {
+	return func(s *Server) {
+		s.middleware = append(s.middleware, middleware...)
+	}
+}
+
+// toolMiddleware is the full chain applied by addTool.
+func (s *Server) toolMiddleware() []Middleware {
+	mws := []Middleware{
+		byName(withCorrelationID),
+		byName(withTracing),
+		s.toolHandler.metrics.middleware,
+		byName(withReauthNotifications),
+	}
+	mws = append(mws, s.middleware...)
+	return append(mws, validateRequired, byName(s.toolHandler.withWarnings))
+}
+
+// validateRequired rejects calls that leave out a parameter the tool schema requires, so
+// handlers don't each repeat the check.
+func validateRequired(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	required := tool.InputSchema.Required
+	if len(required) == 0 {
+		return next
+	}
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args := request.GetArguments()
+		for _, key := range required {
+			if value, ok := args[key]; !ok || value == nil || value == "" {
+				return mcp.NewToolResultError(fmt.Sprintf("%s parameter is required", key)), nil
+			}
+		}
+		return next(ctx, request)
+	}
+}
+
+func newToolMetrics() *toolMetrics {
+	return &toolMetrics{stats: make(map[string]*toolTotals)}
+}
+
+func (m *toolMetrics) middleware(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		m.record(tool.Name, time.Since(start), err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+func (m *toolMetrics) record(toolName string, elapsed time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.stats[toolName]
+	if !ok {
+		t = &toolTotals{}
+		m.stats[toolName] = t
+	}
+	t.calls++
+	if failed {
+		t.errors++
+	}
+	t.total += elapsed
+	t.max = max(t.max, elapsed)
+}
+
+// snapshot returns the stats of every tool called so far, keyed by tool name.
+func (m *toolMetrics) snapshot() map[string]ToolStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.stats))
+	for name := range m.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	stats := make(map[string]ToolStats, len(names))
+	for _, name := range names {
+		t := m.stats[name]
+		stats[name] = ToolStats{
+			Calls:        t.calls,
+			Errors:       t.errors,
+			AvgLatencyMs: float64(t.total.Milliseconds()) / float64(t.calls),
+			MaxLatencyMs: t.max.Milliseconds(),
+		}
+	}
+	return stats
+}
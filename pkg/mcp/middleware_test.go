@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(tool mcp.Tool, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+			return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			}
+		}
+	}
+	handler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls = append(calls, "handler")
+		return mcp.NewToolResultText("ok"), nil
+	}
+
+	wrapped := chain(mcp.NewTool("test"), handler, trace("outer"), trace("inner"))
+	if _, err := wrapped(context.Background(), toolRequest(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "outer,inner,handler" {
+		t.Errorf("Expected outer,inner,handler, got %s", got)
+	}
+}
+
+func TestValidateRequired(t *testing.T) {
+	tool := mcp.NewTool("test", mcp.WithString("query", mcp.Required()), mcp.WithNumber("size"))
+	called := false
+	handler := validateRequired(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	result, _ := handler(context.Background(), toolRequest(map[string]any{"query": "", "size": 3}))
+	if !result.IsError || called {
+		t.Errorf("Expected an empty required parameter to be rejected, got %+v", result)
+	}
+	if result, _ := handler(context.Background(), toolRequest(map[string]any{"query": "mjölk"})); result.IsError || !called {
+		t.Errorf("Expected the call to reach the handler, got %+v", result)
+	}
+}
+
+func TestToolMetrics(t *testing.T) {
+	m := newToolMetrics()
+	tool := mcp.NewTool("search_groceries")
+	failing := m.middleware(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("boom"), nil
+	})
+	succeeding := m.middleware(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	failing(context.Background(), toolRequest(nil))
+	succeeding(context.Background(), toolRequest(nil))
+
+	stats := m.snapshot()["search_groceries"]
+	if stats.Calls != 2 || stats.Errors != 1 {
+		t.Errorf("Expected 2 calls and 1 error, got %+v", stats)
+	}
+}
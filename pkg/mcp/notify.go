@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const notificationLogger = "willys"
+
+// withReauthNotifications tells the MCP client when a tool call had to log in again, so
+// a slow call or a failed re-login isn't a mystery on the client side.
+func withReauthNotifications(toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx = willys.WithReauthNotifier(ctx, func(event willys.ReauthEvent) {
+			sendReauthNotification(ctx, toolName, event)
+		})
+		return next(ctx, request)
+	}
+}
+
+func sendReauthNotification(ctx context.Context, toolName string, event willys.ReauthEvent) {
+	level := mcp.LoggingLevelNotice
+	kind := "reauthenticated"
+	message := fmt.Sprintf("Willys session expired during %s; logged in again in %s", toolName, event.Duration.Round(time.Millisecond))
+	var lockout *willys.LockoutError
+	switch {
+	case errors.As(event.Err, &lockout):
+		level = mcp.LoggingLevelCritical
+		kind = "locked_out"
+		message = fmt.Sprintf("Willys blocked the re-login during %s (%s); automatic re-login is paused until %s",
+			toolName, lockout.Reason, lockout.Until.Format("15:04"))
+	case errors.Is(event.Err, willys.ErrCredentialsInvalid):
+		level = mcp.LoggingLevelCritical
+		kind = "credentials_invalid"
+		message = fmt.Sprintf("Willys rejected the stored credentials during %s; automatic re-login is off until they are updated", toolName)
+	case event.Err != nil:
+		level = mcp.LoggingLevelError
+		kind = "reauthentication_failed"
+		message = fmt.Sprintf("Willys session expired during %s and re-login failed: %v", toolName, event.Err)
+	}
+
+	data := map[string]any{
+		"event":          kind,
+		"message":        message,
+		"tool":           toolName,
+		"endpoint":       event.Endpoint,
+		"attempt":        event.Attempt,
+		"duration_ms":    event.Duration.Milliseconds(),
+		"correlation_id": willys.CorrelationIDFromContext(ctx),
+	}
+	localLog.Printf("[%s] %s", willys.CorrelationIDFromContext(ctx), message)
+
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return
+	}
+	notification := mcp.NewLoggingMessageNotification(level, notificationLogger, data)
+	if err := mcpServer.SendLogMessageToClient(ctx, notification); err != nil {
+		localLog.Printf("Failed to send re-auth notification: %v", err)
+	}
+}
+
+// notifyScheduleRun broadcasts the outcome of a scheduled run.
+func (s *Server) notifyScheduleRun(sched Schedule) {
+	run := sched.LastRun
+	level := mcp.LoggingLevelNotice
+	message := fmt.Sprintf("Scheduled order %q is ready for approval: %d items in basket %q", sched.Name, run.Added, run.Basket)
+	if run.Status != RunStatusAwaitingApproval {
+		level = mcp.LoggingLevelError
+		message = fmt.Sprintf("Scheduled order %q failed: %s", sched.Name, run.Error)
+	}
+
+	s.broadcast(level, message, map[string]any{
+		"event":       "schedule_run",
+		"schedule_id": sched.ID,
+		"run":         run,
+	})
+}
+
+// broadcast sends a log notification for background work. It happens outside any tool
+// call, so it goes to every connected client rather than a single session. With log
+// forwarding on, each client's log level applies.
+func (s *Server) broadcast(level mcp.LoggingLevel, message string, data map[string]any) {
+	localLog.Println(message)
+
+	if s.mcpServer == nil {
+		return
+	}
+	data["message"] = message
+	if s.logs != nil {
+		s.logs.send(level, data)
+		return
+	}
+	s.mcpServer.SendNotificationToAllClients("notifications/message", map[string]any{
+		"level":  level,
+		"logger": notificationLogger,
+		"data":   data,
+	})
+}
@@ -0,0 +1,149 @@
+package mcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+// openAPIPath serves the spec. It needs no token, so clients can be generated before
+// one is handed out; the spec itself holds nothing secret.
+const openAPIPath = "/api/openapi.json"
+
+// OpenAPISpec describes the REST facade (when rest is set) and the add-item webhook
+// (when webhook is set) as an OpenAPI 3.1 document. The schemas are reflected from the
+// types the handlers decode and encode, so the spec can't drift from them.
+func OpenAPISpec(rest, webhook bool) ([]byte, error) {
+	schemas := map[string]*jsonschema.Schema{}
+	reflector := &jsonschema.Reflector{
+		Anonymous: true,
+		Namer: func(t reflect.Type) string {
+			if t.Name() == "" {
+				return ""
+			}
+			return strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		},
+		Mapper: func(t reflect.Type) *jsonschema.Schema {
+			if t == reflect.TypeOf(willys.Money{}) {
+				return &jsonschema.Schema{Ref: "#/$defs/Money"}
+			}
+			return nil
+		},
+	}
+	ref := func(v any) *jsonschema.Schema {
+		schema := reflector.Reflect(v)
+		for name, def := range schema.Definitions {
+			schemas[name] = def
+		}
+		schema.Definitions = nil
+		return schema
+	}
+	money := reflector.Reflect(willys.MoneyJSON{})
+	schemas["Money"] = money.Definitions["MoneyJSON"]
+
+	jsonBody := func(schema *jsonschema.Schema) map[string]any {
+		return map[string]any{"content": map[string]any{"application/json": map[string]any{"schema": schema}}}
+	}
+	errorResponse := func(description string) map[string]any {
+		response := jsonBody(ref(restError{}))
+		response["description"] = description
+		return response
+	}
+	response := func(description string, v any) map[string]any {
+		response := jsonBody(ref(v))
+		response["description"] = description
+		return response
+	}
+
+	paths := map[string]any{}
+	securitySchemes := map[string]any{}
+	if rest {
+		securitySchemes["bearerToken"] = map[string]any{
+			"type": "http", "scheme": "bearer",
+			"description": "WILLYS_API_TOKEN",
+		}
+		bearer := []map[string][]string{{"bearerToken": {}}}
+		paths["/api/cart"] = map[string]any{"get": map[string]any{
+			"operationId": "getCart",
+			"summary":     "The cart, as returned by view_cart",
+			"security":    bearer,
+			"parameters": []map[string]any{
+				{"name": "group_by", "in": "query", "schema": map[string]any{"type": "string", "enum": []string{CartGroupNone, CartGroupCategory, CartGroupAisle}}},
+				{"name": "sort", "in": "query", "schema": map[string]any{"type": "string", "enum": []string{CartSortDefault, CartSortPrice, CartSortPriceDesc, CartSortName, CartSortRecentlyAdded}}},
+			},
+			"responses": map[string]any{
+				"200": response("The cart", annotatedCart{}),
+				"401": errorResponse("Missing or invalid bearer token"),
+				"422": errorResponse("The cart could not be read"),
+			},
+		}}
+		paths["/api/shopping-list/add"] = map[string]any{"post": map[string]any{
+			"operationId": "addShoppingListItems",
+			"summary":     "Pick products for free-text items and add them, as list_to_cart does",
+			"security":    bearer,
+			"requestBody": jsonBody(ref(shoppingListAddRequest{})),
+			"responses": map[string]any{
+				"200": response("What was picked and added", listToCartResponse{}),
+				"400": errorResponse("Invalid body"),
+				"401": errorResponse("Missing or invalid bearer token"),
+				"422": errorResponse("The items could not be added, e.g. a quota is used up"),
+			},
+		}}
+	}
+	if webhook {
+		securitySchemes["webhookSecret"] = map[string]any{
+			"type": "apiKey", "in": "header", "name": "X-Webhook-Secret",
+			"description": "WILLYS_WEBHOOK_SECRET",
+		}
+		securitySchemes["webhookSecretQuery"] = map[string]any{
+			"type": "apiKey", "in": "query", "name": "secret",
+			"description": "WILLYS_WEBHOOK_SECRET, for callers that can't set headers",
+		}
+		paths["/hooks/add-item"] = map[string]any{"post": map[string]any{
+			"operationId": "hookAddItem",
+			"summary":     "Add one item for a voice assistant; it goes to WILLYS_WEBHOOK_BASKET when set",
+			"security":    []map[string][]string{{"webhookSecret": {}}, {"webhookSecretQuery": {}}},
+			"requestBody": jsonBody(ref(webhookRequest{})),
+			"responses": map[string]any{
+				"200": response("The outcome, with a sentence to read back", WebhookResult{}),
+				"400": errorResponse("Invalid body"),
+				"401": errorResponse("Missing or invalid webhook secret"),
+				"429": errorResponse("A quota is used up"),
+				"502": errorResponse("Willys rejected the change"),
+			},
+		}}
+	}
+
+	spec := map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "Willys MCP REST API",
+			"version": ServerVersion,
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":         schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.ReplaceAll(string(data), `"#/$defs/`, `"#/components/schemas/`)), nil
+}
+
+func (s *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	spec, err := OpenAPISpec(s.apiToken != "", s.webhookSecret != "")
+	if err != nil {
+		writeRESTError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(spec)
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// findCostSwaps searches for each cart item by name and proposes the cheapest equivalent.
+// Items whose current product isn't among the search results can't be compared and are
+// reported as unchanged.
+func (h *ToolHandler) findCostSwaps(ctx context.Context, cart *willys.CartSummary, ownBrand string) *willys.CartCostReport {
+	report := &willys.CartCostReport{Swaps: []willys.CostSwap{}}
+
+	for _, item := range cart.Items {
+		products, err := h.client.SearchProducts(ctx, item.Name, 0, listSearchSize, nil)
+		if err != nil {
+			report.Unchanged = append(report.Unchanged, item.Name)
+			continue
+		}
+
+		idx := slices.IndexFunc(products, func(p willys.Product) bool { return p.Code == item.ProductCode })
+		if idx < 0 {
+			report.Unchanged = append(report.Unchanged, item.Name)
+			continue
+		}
+
+		swap := willys.FindCheaperEquivalent(item, products[idx], products, ownBrand)
+		if swap == nil {
+			report.Unchanged = append(report.Unchanged, item.Name)
+			continue
+		}
+		report.Swaps = append(report.Swaps, *swap)
+		report.TotalSavings = report.TotalSavings.Add(swap.Savings)
+	}
+
+	return report
+}
+
+func (h *ToolHandler) applyCostSwap(ctx context.Context, swap *willys.CostSwap) {
+	if _, err := h.client.RemoveFromCart(ctx, swap.ProductCode, swap.Quantity); err != nil {
+		swap.Error = fmt.Sprintf("failed to remove %s: %v", swap.ProductCode, err)
+		return
+	}
+	cart, err := h.client.AddToCart(ctx, swap.Replacement.Code, swap.ReplacementQuantity)
+	if err != nil {
+		swap.Error = fmt.Sprintf("removed %s but failed to add %s: %v", swap.ProductCode, swap.Replacement.Code, err)
+		return
+	}
+	h.forgetRemovedItems(cart)
+	h.recordOrigin(ctx, swap.Replacement.Code, "optimize_cart_cost", swap.Name, swap.ReplacementQuantity)
+	swap.Applied = true
+}
+
+func (h *ToolHandler) OptimizeCartCost(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	approved := getStringSlice(request.GetArguments(), "apply")
+	applyAll := mcp.ParseBoolean(request, "apply_all", false)
+
+	h.mu.RLock()
+	ownBrand := mcp.ParseString(request, "own_brand", h.ownBrand)
+	h.mu.RUnlock()
+	if err := willys.ValidateOwnBrand(ownBrand); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+	if len(cart.Items) == 0 {
+		return mcp.NewToolResultError("cart is empty"), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaSearch, len(cart.Items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	report := h.findCostSwaps(ctx, cart, ownBrand)
+
+	applied := 0
+	for i := range report.Swaps {
+		swap := &report.Swaps[i]
+		if !applyAll && !slices.Contains(approved, swap.ProductCode) {
+			continue
+		}
+		if err := h.consumeQuota(ctx, quotaCartMutation, 2); err != nil {
+			swap.Error = err.Error()
+			continue
+		}
+		h.applyCostSwap(ctx, swap)
+		if swap.Applied {
+			applied++
+		}
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"swaps":        report.Swaps,
+		"unchanged":    report.Unchanged,
+		"totalSavings": report.TotalSavings,
+		"applied":      applied,
+	})
+}
@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *ToolHandler) CompareWithLastOrder(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cart, _, err := h.currentCart(ctx, mcp.ParseBoolean(request, "refresh", false))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+
+	orders, err := h.client.GetOrderHistory(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get order history: %v", err)), nil
+	}
+
+	var order *willys.Order
+	if code := mcp.ParseString(request, "order_code", ""); code != "" {
+		for i := range orders {
+			if orders[i].Code == code {
+				order = &orders[i]
+				break
+			}
+		}
+		if order == nil {
+			return mcp.NewToolResultError(fmt.Sprintf("no order %s in the order history", code)), nil
+		}
+	} else if order = willys.LastComparableOrder(orders); order == nil {
+		return mcp.NewToolResultError("there is no earlier order to compare with"), nil
+	}
+
+	return mcp.NewToolResultJSON(willys.DiffOrder(cart.Items, *order, orders))
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxOriginsPerItem keeps the audit trail per product short; older entries are dropped.
+const maxOriginsPerItem = 5
+
+type (
+	// CartOrigin records why a product was put in the cart: the tool or label that added
+	// it ("add_to_cart", "list_to_cart", "schedule:Weekly basics", ...) and the query used.
+	CartOrigin struct {
+		Source        string    `json:"source"`
+		Query         string    `json:"query,omitempty"`
+		Quantity      int       `json:"quantity"`
+		CorrelationID string    `json:"correlationId,omitempty"`
+		At            time.Time `json:"at"`
+	}
+
+	annotatedCartItem struct {
+		willys.CartItem
+		AddedBy []CartOrigin `json:"added_by,omitempty"`
+		Members []string     `json:"members,omitempty"`
+	}
+
+	// annotatedCart is a CartSummary whose items carry their origins. When the view is
+	// grouped, the items are listed under Groups instead and Items is empty.
+	annotatedCart struct {
+		*willys.CartSummary
+		Items         []annotatedCartItem   `json:"items"`
+		Groups        []cartGroup           `json:"groups,omitempty"`
+		StockWarnings []willys.StockWarning `json:"stockWarnings,omitempty"`
+		CartState     *CartState            `json:"cartState,omitempty"`
+		PerMember     []memberSummary       `json:"per_member,omitempty"`
+	}
+)
+
+func sourceProperty() mcp.ToolOption {
+	return mcp.WithString("source",
+		mcp.Description("Why the items are added, shown as added_by in view_cart (e.g., 'list:Weekly', 'recipe:Lasagne'); defaults to the tool name"),
+	)
+}
+
+// recordOrigin appends an audit entry for productCode. Failures are logged, never
+// surfaced: the cart change itself already succeeded.
+func (h *ToolHandler) recordOrigin(ctx context.Context, productCode, source, query string, quantity int) {
+	var origins []CartOrigin
+	if err := store.GetJSON(h.store, store.BucketCartOrigins, productCode, &origins); err != nil && !errors.Is(err, store.ErrNotFound) {
+		log.Printf("Failed to read cart origins for %s: %v", productCode, err)
+	}
+
+	origins = append(origins, CartOrigin{
+		Source:        source,
+		Query:         query,
+		Quantity:      quantity,
+		CorrelationID: willys.CorrelationIDFromContext(ctx),
+		At:            time.Now(),
+	})
+	if len(origins) > maxOriginsPerItem {
+		origins = origins[len(origins)-maxOriginsPerItem:]
+	}
+
+	if err := store.PutJSON(h.store, store.BucketCartOrigins, productCode, origins); err != nil {
+		log.Printf("Failed to record cart origin for %s: %v", productCode, err)
+	}
+}
+
+// annotateCart attaches recorded origins and household members to the cart items. It
+// only reads: the records of products that left the cart are dropped by the tools that
+// change the cart, through forgetRemovedItems.
+func (h *ToolHandler) annotateCart(cart *willys.CartSummary) *annotatedCart {
+	entries, err := h.store.List(store.BucketCartOrigins)
+	if err != nil {
+		log.Printf("Failed to read cart origins: %v", err)
+	}
+	tags, err := h.store.List(store.BucketMemberTags)
+	if err != nil {
+		log.Printf("Failed to read member assignments: %v", err)
+	}
+
+	result := &annotatedCart{CartSummary: cart, Items: make([]annotatedCartItem, 0, len(cart.Items))}
+	for _, item := range cart.Items {
+		annotated := annotatedCartItem{CartItem: item}
+		if _, ok := entries[item.ProductCode]; ok {
+			if err := store.GetJSON(h.store, store.BucketCartOrigins, item.ProductCode, &annotated.AddedBy); err != nil {
+				log.Printf("Failed to decode cart origins for %s: %v", item.ProductCode, err)
+			}
+		}
+		if _, ok := tags[item.ProductCode]; ok {
+			if annotated.Members, err = h.itemMembers(item.ProductCode); err != nil {
+				log.Printf("Failed to decode members of %s: %v", item.ProductCode, err)
+			}
+		}
+		result.Items = append(result.Items, annotated)
+	}
+	result.PerMember = memberSummaries(result.Items)
+
+	return result
+}
+
+// forgetRemovedItems drops the origins and members of products that are no longer in
+// cart, the cart as a change left it. A nil cart, when the change didn't return one,
+// leaves them for the next change. Failures are only logged.
+func (h *ToolHandler) forgetRemovedItems(cart *willys.CartSummary) {
+	if cart == nil {
+		return
+	}
+	inCart := make(map[string]bool, len(cart.Items))
+	for _, item := range cart.Items {
+		inCart[item.ProductCode] = true
+	}
+
+	entries, err := h.store.List(store.BucketCartOrigins)
+	if err != nil {
+		log.Printf("Failed to read cart origins: %v", err)
+	}
+	for code := range entries {
+		if !inCart[code] {
+			if err := h.store.Delete(store.BucketCartOrigins, code); err != nil {
+				log.Printf("Failed to forget cart origins for %s: %v", code, err)
+			}
+		}
+	}
+
+	h.membersMu.Lock()
+	defer h.membersMu.Unlock()
+	tags, err := h.store.List(store.BucketMemberTags)
+	if err != nil {
+		log.Printf("Failed to read member assignments: %v", err)
+	}
+	for code := range tags {
+		if !inCart[code] {
+			if err := h.store.Delete(store.BucketMemberTags, code); err != nil {
+				log.Printf("Failed to forget members of %s: %v", code, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestAnnotateCart(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx := context.Background()
+
+	h.recordOrigin(ctx, "101_ST", "list:Weekly", "mjölk", 2)
+	h.recordOrigin(ctx, "101_ST", "add_to_cart", "", 1)
+	h.recordOrigin(ctx, "202_ST", "recipe:Lasagne", "krossade tomater", 1)
+
+	cart := &willys.CartSummary{Items: []willys.CartItem{{ProductCode: "101_ST", Name: "Mjölk", Quantity: 3}}}
+	annotated := h.annotateCart(cart)
+
+	if len(annotated.Items) != 1 || len(annotated.Items[0].AddedBy) != 2 {
+		t.Fatalf("Expected two origins on the cart item, got %+v", annotated.Items)
+	}
+	if annotated.Items[0].AddedBy[0].Source != "list:Weekly" || annotated.Items[0].AddedBy[0].Query != "mjölk" {
+		t.Errorf("Unexpected first origin: %+v", annotated.Items[0].AddedBy[0])
+	}
+
+	// Viewing changes nothing; origins of products no longer in the cart are forgotten
+	// once the cart changes
+	if _, err := h.store.Get(store.BucketCartOrigins, "202_ST"); err != nil {
+		t.Errorf("Expected annotating the cart to keep the records, got %v", err)
+	}
+	h.tagMember("202_ST", "Erik")
+	h.forgetRemovedItems(cart)
+	if _, err := h.store.Get(store.BucketCartOrigins, "202_ST"); err == nil {
+		t.Error("Expected origins of removed product to be pruned")
+	}
+	if _, err := h.store.Get(store.BucketMemberTags, "202_ST"); err == nil {
+		t.Error("Expected members of removed product to be pruned")
+	}
+	if _, err := h.store.Get(store.BucketCartOrigins, "101_ST"); err != nil {
+		t.Errorf("Expected origins of the product still in the cart to be kept, got %v", err)
+	}
+
+	data, err := json.Marshal(annotated)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"added_by"`) || !strings.Contains(string(data), `"code":"101_ST"`) {
+		t.Errorf("Expected item fields and added_by in JSON, got %s", data)
+	}
+}
+
+func TestAnnotateEmptyCartListsItems(t *testing.T) {
+	h := NewToolHandler(nil)
+	data, err := json.Marshal(h.annotateCart(&willys.CartSummary{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"items":[]`) {
+		t.Errorf("Expected an empty items array, got %s", data)
+	}
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+var (
+	// Keys whose last word names an amount must hold a Money object
+	amountKey = regexp.MustCompile(`(^|[a-z])(Price|Fee|Total|Cost|Amount|Savings|price|fee|cost|amount|savings)$`)
+	// Keys whose last word names an instant must hold an RFC 3339 string with offset
+	instantKey = regexp.MustCompile(`(At|Time|DateTime|Until|Since)$`)
+)
+
+// outputViolations walks the generic JSON form of a tool result and reports every value
+// that breaks the output policy: amounts are {amount_ore, currency, display} and instants
+// are RFC 3339 with a time zone. Calendar days such as a slot's "date" stay YYYY-MM-DD.
+func outputViolations(v any, path string) []string {
+	var violations []string
+	switch val := v.(type) {
+	case map[string]any:
+		if _, ok := val["amount_ore"]; ok {
+			violations = append(violations, moneyViolations(val, path)...)
+			return violations
+		}
+		for key, child := range val {
+			childPath := path + "." + key
+			switch {
+			case amountKey.MatchString(key) && child != nil:
+				if _, ok := child.(map[string]any); !ok {
+					violations = append(violations, fmt.Sprintf("%s: amount is %T, not Money", childPath, child))
+					continue
+				}
+			case instantKey.MatchString(key) && child != nil:
+				s, ok := child.(string)
+				if !ok {
+					violations = append(violations, fmt.Sprintf("%s: instant is %T, not RFC 3339", childPath, child))
+					continue
+				}
+				if _, err := time.Parse(time.RFC3339, s); err != nil {
+					violations = append(violations, fmt.Sprintf("%s: %q is not RFC 3339", childPath, s))
+				}
+				continue
+			}
+			violations = append(violations, outputViolations(child, childPath)...)
+		}
+	case []any:
+		for i, child := range val {
+			violations = append(violations, outputViolations(child, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return violations
+}
+
+func moneyViolations(m map[string]any, path string) []string {
+	var violations []string
+	if ore, ok := m["amount_ore"].(float64); !ok || ore != float64(int64(ore)) {
+		violations = append(violations, path+".amount_ore: not an integer")
+	}
+	if _, ok := m["currency"].(string); !ok {
+		violations = append(violations, path+".currency: missing")
+	}
+	if _, ok := m["display"].(string); !ok {
+		violations = append(violations, path+".display: missing")
+	}
+	if len(m) != 3 {
+		violations = append(violations, fmt.Sprintf("%s: Money has %d fields, want 3", path, len(m)))
+	}
+	return violations
+}
+
+func assertOutputPolicy(t *testing.T, name string, v any) {
+	t.Helper()
+	generic, err := toJSONValue(v)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	for _, violation := range outputViolations(generic, name) {
+		t.Error(violation)
+	}
+}
+
+func TestOutputPolicy(t *testing.T) {
+	savings := willys.SEK(250)
+	slot := willys.TimeSlot{SlotID: "s1", Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00", Fee: willys.SEK(4900),
+		Available: true, EarliestDateTime: 1792508400000, LatestDateTime: 1792515600000, CutoffTime: time.Now()}
+	product := willys.Product{Code: "1_ST", Name: "Mjölk", PriceValue: willys.SEK(1590), Price: willys.SEK(1590),
+		ComparePrice: willys.SEK(1590), ComparePriceUnit: "l", SavingsAmount: &savings}
+
+	outputs := map[string]any{
+		"product":  product,
+		"compact":  product.Compact(),
+		"slot":     slot,
+		"delivery": willys.DeliveryInfo{TimeSlot: slot, TotalFee: willys.SEK(4900), ModifiableUntil: time.Now()},
+		"cart": willys.CartSummary{Items: []willys.CartItem{{ProductCode: "1_ST", Quantity: 2, Price: willys.SEK(1590), TotalPrice: willys.SEK(3180)}},
+			TotalPrice: willys.SEK(3180), FinalTotal: willys.SEK(3180)},
+		"order":  willys.Order{Code: "o1", PlacedAt: time.Now(), Total: willys.SEK(3180), Entries: []willys.OrderEntry{{Code: "1_ST", Price: willys.SEK(1590)}}},
+		"booked": BookedDelivery{Delivery: willys.DeliveryInfo{TimeSlot: slot}, BookedAt: time.Now()},
+	}
+	for name, v := range outputs {
+		assertOutputPolicy(t, name, v)
+	}
+
+	// A tool result as the client receives it, paging wrapper included
+	h := NewToolHandler(&slotsClient{slots: []willys.TimeSlot{slot}})
+	result, _ := h.GetAvailableTimeSlots(sessionContext("alice"), toolRequest(map[string]any{"postal_code": "11151"}))
+	if result.IsError {
+		t.Fatalf("Unexpected error: %v", result.Content)
+	}
+	var body any
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body); err != nil {
+		t.Fatal(err)
+	}
+	for _, violation := range outputViolations(body, "get_available_time_slots") {
+		t.Error(violation)
+	}
+}
+
+func TestOutputViolations(t *testing.T) {
+	body := map[string]any{
+		"totalPrice": 31.8,
+		"fee":        "49,00 kr",
+		"placedAt":   float64(1792508400000),
+		"cutoffTime": "17:00",
+		"total":      3, // a count, not an amount
+		"items":      []any{map[string]any{"price": map[string]any{"amount": 15.9, "amount_ore": float64(1590)}}},
+	}
+	if violations := outputViolations(body, "out"); len(violations) != 7 {
+		t.Errorf("Expected 7 violations, got %d: %v", len(violations), violations)
+	}
+}
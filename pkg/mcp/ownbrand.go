@@ -0,0 +1,32 @@
+package mcp
+
+import "github.com/effati/willys-mcp/internal/willys"
+
+const ownBrandDescription = "Axfood own brands (Garant, Eldorado, ...): 'prefer' ranks them first, 'only' excludes other brands, 'off' treats all brands alike. Defaults to the server setting"
+
+func ownBrandSchema() map[string]any {
+	return map[string]any{
+		"type":        "string",
+		"description": ownBrandDescription,
+		"enum":        []string{willys.OwnBrandOff, willys.OwnBrandPrefer, willys.OwnBrandOnly},
+	}
+}
+
+// applyOwnBrand fills in the server's own-brand mode when the call didn't set one,
+// creating preferences if needed so the mode also applies to plain searches.
+func (h *ToolHandler) applyOwnBrand(prefs *willys.SearchPreferences) (*willys.SearchPreferences, error) {
+	h.mu.RLock()
+	mode := h.ownBrand
+	h.mu.RUnlock()
+
+	if prefs == nil {
+		if mode == "" || mode == willys.OwnBrandOff {
+			return nil, nil
+		}
+		prefs = &willys.SearchPreferences{}
+	}
+	if prefs.OwnBrand == "" {
+		prefs.OwnBrand = mode
+	}
+	return prefs, willys.ValidateOwnBrand(prefs.OwnBrand)
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/recipe"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type (
+	// PantryItem is a staple the household already has, stored under its lower-cased name.
+	// Quantity is in Unit; an empty unit counts pieces or packages.
+	PantryItem struct {
+		Name      string    `json:"name"`
+		Quantity  float64   `json:"quantity"`
+		Unit      string    `json:"unit,omitempty"`
+		Expires   string    `json:"expires,omitempty"` // YYYY-MM-DD
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+
+	// pantryCoverage records an ingredient that was left out of the cart because the
+	// pantry has enough of it.
+	pantryCoverage struct {
+		Ingredient string     `json:"ingredient"`
+		Pantry     PantryItem `json:"pantry"`
+	}
+)
+
+func (h *ToolHandler) loadPantry() ([]PantryItem, error) {
+	entries, err := h.store.List(store.BucketPantry)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]PantryItem, 0, len(entries))
+	for name, data := range entries {
+		var item PantryItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			log.Printf("Skipping unreadable pantry item %s: %v", name, err)
+			continue
+		}
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+	return items, nil
+}
+
+// pantryCovers returns the pantry item that fully covers ing, if any. Names match when
+// the ingredient contains the pantry item's name ("finriven parmesan" is covered by
+// "parmesan"). An ingredient without an amount is covered by any stock.
+func pantryCovers(pantry []PantryItem, ing recipe.Ingredient) (PantryItem, bool) {
+	item := strings.ToLower(ing.Item)
+	for _, p := range pantry {
+		name := strings.ToLower(p.Name)
+		if p.Quantity <= 0 || !strings.Contains(item, name) {
+			continue
+		}
+		if ing.Amount == 0 {
+			return p, true
+		}
+		needed, ok := recipe.Convert(ing.Amount, ing.Unit, p.Unit)
+		if ok && p.Quantity >= needed {
+			return p, true
+		}
+	}
+	return PantryItem{}, false
+}
+
+// ingredientListItems turns parsed ingredients into list items for addListItems,
+// leaving out pantry staples and anything the pantry already covers.
+func ingredientListItems(ingredients []recipe.Ingredient, pantry []PantryItem) ([]listItem, []pantryCoverage) {
+	items := make([]listItem, 0, len(ingredients))
+	var covered []pantryCoverage
+	for _, ing := range ingredients {
+		if ing.Pantry {
+			continue
+		}
+		if p, ok := pantryCovers(pantry, ing); ok {
+			covered = append(covered, pantryCoverage{Ingredient: ing.Line, Pantry: p})
+			continue
+		}
+		items = append(items, listItem{Query: ing.Item, Quantity: ing.CartQuantity()})
+	}
+	return items, covered
+}
+
+func (h *ToolHandler) UpdatePantry(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rawItems, ok := request.GetArguments()["items"].([]any)
+	if !ok || len(rawItems) == 0 {
+		return mcp.NewToolResultError("items parameter is required"), nil
+	}
+
+	var updated, removed []string
+	for _, raw := range rawItems {
+		data, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := strings.TrimSpace(getStringField(data, "name"))
+		if name == "" {
+			return mcp.NewToolResultError("every pantry item needs a name"), nil
+		}
+		key := normalizeQuery(name)
+
+		quantity, hasQuantity := data["quantity"].(float64)
+		if hasQuantity && quantity <= 0 {
+			if err := h.store.Delete(store.BucketPantry, key); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("failed to remove %s: %v", name, err)), nil
+			}
+			removed = append(removed, name)
+			continue
+		}
+		if !hasQuantity {
+			quantity = 1
+		}
+
+		item := PantryItem{
+			Name:      name,
+			Quantity:  quantity,
+			Unit:      recipe.NormalizeUnit(getStringField(data, "unit")),
+			Expires:   getStringField(data, "expires"),
+			UpdatedAt: time.Now(),
+		}
+		if item.Expires != "" {
+			if _, err := time.Parse(time.DateOnly, item.Expires); err != nil {
+				return mcp.NewToolResultError(fmt.Sprintf("expires for %s must be in YYYY-MM-DD format", name)), nil
+			}
+		}
+		if err := store.PutJSON(h.store, store.BucketPantry, key, item); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to save %s: %v", name, err)), nil
+		}
+		updated = append(updated, name)
+	}
+
+	pantry, err := h.loadPantry()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("pantry updated but failed to list it: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"updated": updated,
+		"removed": removed,
+		"pantry":  pantry,
+	})
+}
+
+func (h *ToolHandler) ViewPantry(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	pantry, err := h.loadPantry()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list pantry: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"items": pantry,
+		"count": len(pantry),
+	})
+}
@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *ToolHandler) PriceChangesSinceLastPurchase(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	threshold := mcp.ParseFloat64(request, "threshold_percent", h.alertPercent())
+	if threshold < 0 {
+		return mcp.NewToolResultError("threshold_percent must not be negative"), nil
+	}
+
+	cart, _, err := h.currentCart(ctx, mcp.ParseBoolean(request, "refresh", false))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+	history, err := h.client.PurchaseHistory(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get purchase history: %v", err)), nil
+	}
+
+	changes := history.PriceChangesSinceLastPurchase(cart.Items, threshold)
+	alerts := 0
+	for _, change := range changes {
+		if change.Alert {
+			alerts++
+		}
+	}
+	if mcp.ParseBoolean(request, "alerts_only", false) {
+		filtered := make([]willys.PurchasePriceChange, 0, alerts)
+		for _, change := range changes {
+			if change.Alert {
+				filtered = append(filtered, change)
+			}
+		}
+		changes = filtered
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"threshold_percent": threshold,
+		"changes":           changes,
+		"alerts":            alerts,
+	})
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	quotaSearch       = "search"
+	quotaCartMutation = "cart_mutation"
+
+	defaultSessionID = "default"
+)
+
+type (
+	QuotaLimit struct {
+		Max    int
+		Window time.Duration
+	}
+
+	// Quotas caps how often a single MCP session may hit the upstream API, so a runaway
+	// agent loop can't hammer the account. A zero Max disables that quota.
+	Quotas struct {
+		Searches      QuotaLimit
+		CartMutations QuotaLimit
+	}
+
+	quotaTracker struct {
+		mu     sync.Mutex
+		limits map[string]QuotaLimit
+		calls  map[string][]time.Time
+		now    func() time.Time
+	}
+)
+
+func DefaultQuotas() Quotas {
+	return Quotas{
+		Searches:      QuotaLimit{Max: 30, Window: time.Minute},
+		CartMutations: QuotaLimit{Max: 200, Window: time.Hour},
+	}
+}
+
+func newQuotaTracker(q Quotas) *quotaTracker {
+	return &quotaTracker{
+		limits: map[string]QuotaLimit{
+			quotaSearch:       q.Searches,
+			quotaCartMutation: q.CartMutations,
+		},
+		calls: make(map[string][]time.Time),
+		now:   time.Now,
+	}
+}
+
+// consume records n calls of category for the session, or rejects all of them if that
+// would exceed the quota.
+func (q *quotaTracker) consume(sessionID, category string, n int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	limit := q.limits[category]
+	if limit.Max <= 0 || limit.Window <= 0 {
+		return nil
+	}
+
+	now := q.now()
+	key := sessionID + "|" + category
+	cutoff := now.Add(-limit.Window)
+
+	calls := q.calls[key]
+	kept := calls[:0]
+	for _, t := range calls {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept)+n > limit.Max {
+		q.calls[key] = kept
+		retryIn := limit.Window
+		if len(kept) > 0 {
+			retryIn = kept[0].Add(limit.Window).Sub(now).Round(time.Second)
+		}
+		return fmt.Errorf("%s quota exceeded: max %d per %s for this session (%d used), retry in %s",
+			category, limit.Max, limit.Window, len(kept), retryIn)
+	}
+
+	for i := 0; i < n; i++ {
+		kept = append(kept, now)
+	}
+	q.calls[key] = kept
+	return nil
+}
+
+func sessionIDFromContext(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil && session.SessionID() != "" {
+		return session.SessionID()
+	}
+	return defaultSessionID
+}
+
+func (h *ToolHandler) consumeQuota(ctx context.Context, category string, n int) error {
+	return h.quotas.consume(sessionIDFromContext(ctx), category, n)
+}
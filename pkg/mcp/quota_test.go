@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	q := newQuotaTracker(Quotas{
+		Searches: QuotaLimit{Max: 3, Window: time.Minute},
+	})
+	q.now = func() time.Time { return now }
+
+	if err := q.consume("a", quotaSearch, 2); err != nil {
+		t.Fatalf("Expected first calls to pass: %v", err)
+	}
+	if err := q.consume("a", quotaSearch, 2); err == nil {
+		t.Error("Expected quota to be exceeded")
+	}
+	if err := q.consume("b", quotaSearch, 3); err != nil {
+		t.Errorf("Quotas should be tracked per session: %v", err)
+	}
+	if err := q.consume("a", quotaCartMutation, 100); err != nil {
+		t.Errorf("Disabled quota should not limit: %v", err)
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := q.consume("a", quotaSearch, 3); err != nil {
+		t.Errorf("Expected quota to reset after window: %v", err)
+	}
+}
+
+// searchCountingClient finds nothing and counts the searches.
+type searchCountingClient struct {
+	willys.WillysAPI
+	searches int
+}
+
+func (c *searchCountingClient) SearchProducts(ctx context.Context, query string, page, size int, prefs *willys.SearchPreferences) ([]willys.Product, error) {
+	c.searches++
+	return nil, nil
+}
+
+func TestSemanticCandidatesChargeSearchQuota(t *testing.T) {
+	client := &searchCountingClient{}
+	h := NewToolHandler(client)
+	h.quotas = newQuotaTracker(Quotas{Searches: QuotaLimit{Max: 1, Window: time.Minute}})
+
+	if candidates := h.semanticCandidates(context.Background(), "2 dl färsk grädde"); candidates != nil {
+		t.Errorf("Expected no candidates, got %+v", candidates)
+	}
+	if client.searches != 1 {
+		t.Errorf("Expected the quota to stop after 1 search, got %d", client.searches)
+	}
+}
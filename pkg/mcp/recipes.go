@@ -0,0 +1,59 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/effati/willys-mcp/internal/recipe"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *ToolHandler) ParseIngredients(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	text := mcp.ParseString(request, "text", "")
+	if text == "" {
+		return mcp.NewToolResultError("text parameter is required"), nil
+	}
+
+	var err error
+	ingredients := recipe.Parse(text)
+	if len(ingredients) == 0 {
+		return mcp.NewToolResultError("no ingredients found in text"), nil
+	}
+	var pantry []PantryItem
+	if mcp.ParseBoolean(request, "use_pantry", true) {
+		if pantry, err = h.loadPantry(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to read pantry: %v", err)), nil
+		}
+	}
+	items, covered := ingredientListItems(ingredients, pantry)
+
+	result := map[string]any{
+		"ingredients":       ingredients,
+		"list_items":        items,
+		"covered_by_pantry": covered,
+	}
+	if !mcp.ParseBoolean(request, "add_to_cart", false) {
+		return mcp.NewToolResultJSON(result)
+	}
+
+	policy, err := h.parsePickPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid policy: %v", err)), nil
+	}
+	if err := h.consumeQuota(ctx, quotaSearch, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if err := h.consumeQuota(ctx, quotaCartMutation, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	source := "recipe"
+	if title := mcp.ParseString(request, "title", ""); title != "" {
+		source = "recipe:" + title
+	}
+	results, added := h.addListItems(ctx, items, policy, source, h.activeBasket(ctx))
+	result["results"] = results
+	result["added"] = added
+
+	return mcp.NewToolResultJSON(result)
+}
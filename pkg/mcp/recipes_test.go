@@ -0,0 +1,25 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/recipe"
+)
+
+func TestIngredientListItems(t *testing.T) {
+	pantry := []PantryItem{
+		{Name: "Mjölk", Quantity: 1, Unit: "l"},
+		{Name: "parmesan", Quantity: 1},
+		{Name: "grädde", Quantity: 1, Unit: "dl"},
+	}
+	items, covered := ingredientListItems(recipe.Parse("3 ägg\n2 dl grädde\n3 dl mjölk\nFinriven parmesan\nSalt\n1 l vatten"), pantry)
+
+	expected := []listItem{{Query: "ägg", Quantity: 3}, {Query: "grädde", Quantity: 1}}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, items)
+	}
+	if len(covered) != 2 || covered[0].Pantry.Name != "Mjölk" || covered[1].Pantry.Name != "parmesan" {
+		t.Errorf("Expected milk and parmesan covered by the pantry, got %+v", covered)
+	}
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func (h *ToolHandler) RelatedProducts(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	productCode := mcp.ParseString(request, "product_code", "")
+	if productCode == "" {
+		return mcp.NewToolResultError("product_code parameter is required"), nil
+	}
+	limit := mcp.ParseInt(request, "limit", 10)
+
+	detail, err := h.parseOutputDetail(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaSearch, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	products, err := h.client.GetRelatedProducts(ctx, productCode)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get related products: %v", err)), nil
+	}
+	if limit > 0 && len(products) > limit {
+		products = products[:limit]
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"product_code": productCode,
+		"related":      projectProducts(products, detail),
+		"count":        len(products),
+	})
+}
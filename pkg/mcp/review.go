@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	BudgetNotSet   = "not_set"
+	BudgetOK       = "ok"
+	BudgetNearing  = "nearing"
+	BudgetExceeded = "exceeded"
+
+	SlotNone         = "none"
+	SlotBooked       = "booked"
+	SlotCutoffPassed = "cutoff_passed"
+)
+
+type (
+	// CheckoutReview is everything worth a last look before the customer pays. Issues
+	// lists what should be fixed first; Ready is true when there are none.
+	CheckoutReview struct {
+		Ready            bool                     `json:"ready"`
+		Issues           []string                 `json:"issues"`
+		ItemCount        int                      `json:"itemCount"`
+		StockWarnings    []willys.StockWarning    `json:"stockWarnings"`
+		ForgottenStaples []willys.Staple          `json:"forgottenStaples"`
+		HistoryError     string                   `json:"historyError,omitempty"`
+		DietaryConflicts []willys.DietaryConflict `json:"dietaryConflicts"`
+		IngredientsError string                   `json:"ingredientsError,omitempty"`
+		Budget           BudgetReview             `json:"budget"`
+		Slot             SlotReview               `json:"slot"`
+		Fees             FeeBreakdown             `json:"fees"`
+		CheckoutURL      string                   `json:"checkoutUrl"`
+	}
+
+	BudgetReview struct {
+		Status       string        `json:"status"`
+		WeeklyBudget *willys.Money `json:"weeklyBudget,omitempty"`
+		Remaining    *willys.Money `json:"remaining,omitempty"`
+	}
+
+	SlotReview struct {
+		Status          string           `json:"status"`
+		Slot            *willys.TimeSlot `json:"slot,omitempty"`
+		MinutesToCutoff int              `json:"minutesToCutoff,omitempty"`
+	}
+
+	FeeBreakdown struct {
+		Items       willys.Money `json:"items"`
+		DeliveryFee willys.Money `json:"deliveryFee"`
+		PickingFee  willys.Money `json:"pickingFee"`
+		Total       willys.Money `json:"total"`
+	}
+)
+
+func (h *ToolHandler) PreCheckoutReview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+	}
+
+	// With a diet set, read the ingredient lists too; names alone miss a lot
+	var details map[string]*willys.ProductDetails
+	var detailsErr error
+	if len(h.dietFor(ctx)) > 0 && len(cart.Items) > 0 {
+		codes := make([]string, len(cart.Items))
+		for i, item := range cart.Items {
+			codes[i] = item.ProductCode
+		}
+		details, detailsErr = h.client.GetProductsDetails(ctx, codes)
+	}
+
+	orders, err := h.client.GetOrderHistory(ctx)
+	review := h.reviewCheckout(ctx, cart, orders, details, time.Now())
+	if err != nil {
+		review.HistoryError = err.Error()
+	}
+	if detailsErr != nil {
+		review.IngredientsError = detailsErr.Error()
+	}
+	review.CheckoutURL = h.client.GetCheckoutURL()
+
+	return mcp.NewToolResultJSON(review)
+}
+
+func (h *ToolHandler) reviewCheckout(ctx context.Context, cart *willys.CartSummary, orders []willys.Order, details map[string]*willys.ProductDetails, now time.Time) *CheckoutReview {
+	review := &CheckoutReview{
+		Issues:           []string{},
+		ItemCount:        cart.ItemCount,
+		ForgottenStaples: willys.ForgottenStaples(cart.Items, orders),
+		DietaryConflicts: willys.DietaryConflictsWithDetails(cart.Items, h.dietFor(ctx), details),
+		Budget:           reviewBudget(cart.FinalTotal, h.budgetFor(ctx)),
+		Slot:             SlotReview{Status: SlotNone},
+		Fees: FeeBreakdown{
+			Items:       cart.TotalPrice,
+			DeliveryFee: cart.DeliveryFee,
+			PickingFee:  cart.PickingFee,
+			Total:       cart.FinalTotal,
+		},
+	}
+	if len(cart.Items) == 0 {
+		review.Issues = append(review.Issues, "The cart is empty")
+	}
+
+	var deliveryDate time.Time
+	if booked, err := h.latestDelivery(); err == nil && booked != nil {
+		slot := booked.Delivery.TimeSlot
+		review.Slot = SlotReview{Status: SlotBooked, Slot: &slot}
+		if cutoff := slot.CutoffTime; !cutoff.IsZero() {
+			if now.After(cutoff) {
+				review.Slot.Status = SlotCutoffPassed
+				review.Issues = append(review.Issues, fmt.Sprintf("The order deadline for the %s %s-%s slot has passed; book a new slot", slot.Date, slot.StartTime, slot.EndTime))
+			} else {
+				review.Slot.MinutesToCutoff = int(cutoff.Sub(now).Minutes())
+			}
+		}
+		deliveryDate, _ = time.ParseInLocation(time.DateOnly, slot.Date, time.Local)
+	}
+	if review.Slot.Status == SlotNone {
+		review.Issues = append(review.Issues, "No delivery slot is booked; use get_available_time_slots and select_delivery_time")
+	}
+
+	review.StockWarnings = willys.StockWarnings(cart.Items, deliveryDate, now)
+	for _, w := range review.StockWarnings {
+		if w.StockStatus == willys.StockOutOfStock {
+			review.Issues = append(review.Issues, fmt.Sprintf("%s is out of stock", w.Name))
+		}
+	}
+	if review.StockWarnings == nil {
+		review.StockWarnings = []willys.StockWarning{}
+	}
+	if review.DietaryConflicts == nil {
+		review.DietaryConflicts = []willys.DietaryConflict{}
+	}
+	if review.Budget.Status == BudgetExceeded {
+		review.Issues = append(review.Issues, fmt.Sprintf("The cart total %s is over the weekly budget of %s", cart.FinalTotal, review.Budget.WeeklyBudget))
+	}
+
+	review.Ready = len(review.Issues) == 0
+	return review
+}
+
+func reviewBudget(total, budget willys.Money) BudgetReview {
+	if budget.Ore <= 0 {
+		return BudgetReview{Status: BudgetNotSet}
+	}
+	remaining := budget.Sub(total)
+	review := BudgetReview{Status: BudgetOK, WeeklyBudget: &budget, Remaining: &remaining}
+	switch {
+	case total.Ore > budget.Ore:
+		review.Status = BudgetExceeded
+	case float64(total.Ore) >= budgetWarningShare*float64(budget.Ore):
+		review.Status = BudgetNearing
+	}
+	return review
+}
@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestReviewCheckout(t *testing.T) {
+	h := NewToolHandler(nil)
+	now := time.Now()
+	ctx := context.Background()
+
+	cart := &willys.CartSummary{
+		Items: []willys.CartItem{
+			{ProductCode: "101_ST", Name: "Tacoskal", StockStatus: willys.StockOutOfStock},
+			{ProductCode: "102_ST", Name: "Bacon"},
+		},
+		ItemCount:   2,
+		TotalPrice:  willys.SEK(9000),
+		DeliveryFee: willys.SEK(4900),
+		FinalTotal:  willys.SEK(13900),
+	}
+	var orders []willys.Order
+	for i := 0; i < 3; i++ {
+		orders = append(orders, willys.Order{
+			PlacedAt: now.AddDate(0, 0, -7*(i+1)),
+			Entries:  []willys.OrderEntry{{Code: "200_ST", Name: "Mjölk", Quantity: 1}},
+		})
+	}
+
+	review := h.reviewCheckout(ctx, cart, orders, nil, now)
+	if review.Ready || len(review.Issues) != 2 {
+		t.Fatalf("Expected the missing slot and out-of-stock item as issues, got %v", review.Issues)
+	}
+	if review.Slot.Status != SlotNone || review.Budget.Status != BudgetNotSet {
+		t.Errorf("Unexpected slot or budget: %+v %+v", review.Slot, review.Budget)
+	}
+	if len(review.ForgottenStaples) != 1 || review.ForgottenStaples[0].Name != "Mjölk" {
+		t.Errorf("Expected milk as a forgotten staple, got %+v", review.ForgottenStaples)
+	}
+	if review.Fees.DeliveryFee != willys.SEK(4900) || review.Fees.Total != willys.SEK(13900) {
+		t.Errorf("Unexpected fees: %+v", review.Fees)
+	}
+
+	budget := willys.SEK(10000)
+	h.updateSession(ctx, func(s *SessionContext) { s.WeeklyBudget = &budget; s.Diet = []string{"vegetarian"} })
+	h.recordDelivery(&willys.DeliveryInfo{TimeSlot: willys.TimeSlot{
+		Date: now.Format(time.DateOnly), StartTime: "17:00", EndTime: "19:00", CutoffTime: now.Add(-time.Minute),
+	}})
+
+	review = h.reviewCheckout(ctx, cart, orders, nil, now)
+	if review.Slot.Status != SlotCutoffPassed || review.Budget.Status != BudgetExceeded {
+		t.Errorf("Expected a passed cut-off and an exceeded budget, got %+v %+v", review.Slot, review.Budget)
+	}
+	if len(review.DietaryConflicts) != 1 || review.DietaryConflicts[0].Code != "102_ST" {
+		t.Errorf("Expected bacon to conflict with a vegetarian diet, got %+v", review.DietaryConflicts)
+	}
+	if !strings.Contains(strings.Join(review.Issues, "\n"), "weekly budget") {
+		t.Errorf("Expected the budget issue, got %v", review.Issues)
+	}
+}
@@ -0,0 +1,442 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/schedule"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	schedulerInterval = time.Minute
+
+	// minScheduleInterval is the shortest time allowed between two runs of a schedule,
+	// so a cron like "* * * * *" can't turn it into a search and booking loop.
+	minScheduleInterval = time.Hour
+
+	RunStatusAwaitingApproval = "awaiting_approval"
+	RunStatusFailed           = "failed"
+)
+
+type (
+	// Schedule is a standing order: at each cron tick its draft basket is rebuilt from Items
+	// and the cheapest slot in Window is reserved. The Willys cart is left alone until the
+	// user commits the basket, and the order itself is never placed.
+	Schedule struct {
+		ID         string       `json:"id"`
+		Name       string       `json:"name"`
+		Cron       string       `json:"cron"`
+		Items      []listItem   `json:"items"`
+		PostalCode string       `json:"postalCode,omitempty"`
+		Window     SlotWindow   `json:"window"`
+		CreatedAt  time.Time    `json:"createdAt"`
+		NextRun    time.Time    `json:"nextRun"`
+		LastRun    *ScheduleRun `json:"lastRun,omitempty"`
+	}
+
+	// SlotWindow restricts which delivery slots a scheduled run may pick. Empty fields
+	// mean no restriction.
+	SlotWindow struct {
+		Weekdays     []string `json:"weekdays,omitempty"` // "mon", "tue", ...
+		From         string   `json:"from,omitempty"`     // HH:MM, earliest slot start
+		To           string   `json:"to,omitempty"`       // HH:MM, latest slot end
+		SkipHolidays bool     `json:"skipHolidays,omitempty"`
+	}
+
+	ScheduleRun struct {
+		At      time.Time        `json:"at"`
+		Status  string           `json:"status"`
+		Added   int              `json:"added"`
+		Missing []string         `json:"missing,omitempty"`
+		Slot    *willys.TimeSlot `json:"slot,omitempty"`
+		Basket  string           `json:"basket,omitempty"`
+		Error   string           `json:"error,omitempty"`
+	}
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func parseSlotWindow(data map[string]any) SlotWindow {
+	skip, _ := data["skip_holidays"].(bool)
+	return SlotWindow{
+		Weekdays:     getStringSlice(data, "weekdays"),
+		From:         getStringField(data, "from"),
+		To:           getStringField(data, "to"),
+		SkipHolidays: skip,
+	}
+}
+
+// slotWindowProperties is the slot_window object schema shared by the tools that pick a
+// slot on their own.
+func slotWindowProperties() map[string]any {
+	return map[string]any{
+		"weekdays": map[string]any{
+			"type":        "array",
+			"description": "Allowed delivery days (e.g., ['mon', 'tue'])",
+			"items": map[string]any{
+				"type": "string",
+			},
+		},
+		"from": map[string]any{
+			"type":        "string",
+			"description": "Earliest slot start (HH:MM)",
+		},
+		"to": map[string]any{
+			"type":        "string",
+			"description": "Latest slot end (HH:MM)",
+		},
+		"skip_holidays": map[string]any{
+			"type":        "boolean",
+			"description": "Never pick a slot on a Swedish public holiday or holiday eve (e.g. Julafton, Midsommarafton)",
+		},
+	}
+}
+
+func (w SlotWindow) validate() error {
+	for _, d := range w.Weekdays {
+		if _, ok := weekdayNames[normalizeWeekday(d)]; !ok {
+			return fmt.Errorf("unknown weekday %q", d)
+		}
+	}
+	for _, t := range []string{w.From, w.To} {
+		if t == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("invalid time %q, expected HH:MM", t)
+		}
+	}
+	return nil
+}
+
+func (w SlotWindow) contains(slot willys.TimeSlot) bool {
+	if w.From != "" && slot.StartTime < w.From {
+		return false
+	}
+	if w.To != "" && slot.EndTime > w.To {
+		return false
+	}
+	if len(w.Weekdays) == 0 && !w.SkipHolidays {
+		return true
+	}
+	date, err := time.Parse("2006-01-02", slot.Date)
+	if err != nil {
+		return false
+	}
+	if _, ok := willys.HolidayOn(date); ok && w.SkipHolidays {
+		return false
+	}
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if weekdayNames[normalizeWeekday(d)] == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeWeekday(day string) string {
+	day = strings.ToLower(strings.TrimSpace(day))
+	if len(day) > 3 {
+		day = day[:3]
+	}
+	return day
+}
+
+// cheapestSlotInWindow picks the lowest-fee available slot inside w, preferring the
+// earlier slot on a tie.
+func cheapestSlotInWindow(slots []willys.TimeSlot, w SlotWindow) *willys.TimeSlot {
+	var best *willys.TimeSlot
+	for i := range slots {
+		slot := &slots[i]
+		if !slot.Available || !w.contains(*slot) {
+			continue
+		}
+		if best == nil || slot.Fee.Ore < best.Fee.Ore ||
+			(slot.Fee.Ore == best.Fee.Ore && slot.EarliestDateTime < best.EarliestDateTime) {
+			best = slot
+		}
+	}
+	return best
+}
+
+func (h *ToolHandler) loadSchedules() ([]Schedule, error) {
+	entries, err := h.store.List(store.BucketSchedules)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]Schedule, 0, len(entries))
+	for id, data := range entries {
+		var sched Schedule
+		if err := json.Unmarshal(data, &sched); err != nil {
+			log.Printf("Skipping unreadable schedule %s: %v", id, err)
+			continue
+		}
+		schedules = append(schedules, sched)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].CreatedAt.Before(schedules[j].CreatedAt)
+	})
+	return schedules, nil
+}
+
+// runDueSchedules runs every schedule whose next run is at or before now and stores the
+// outcome. It returns the schedules that ran; one skipped for running too often, or
+// cancelled while it ran, is not among them.
+func (h *ToolHandler) runDueSchedules(ctx context.Context, now time.Time) []Schedule {
+	schedules, err := h.loadSchedules()
+	if err != nil {
+		log.Printf("Failed to load schedules: %v", err)
+		return nil
+	}
+
+	var ran []Schedule
+	for _, sched := range schedules {
+		if sched.NextRun.IsZero() || sched.NextRun.After(now) {
+			continue
+		}
+
+		// Schedules stored before the interval was enforced may still tick too often
+		didRun := sched.LastRun == nil || now.Sub(sched.LastRun.At) >= minScheduleInterval
+		if didRun {
+			runCtx := willys.WithCorrelationID(ctx, willys.NewCorrelationID())
+			run := h.runSchedule(runCtx, sched)
+			sched.LastRun = &run
+		}
+
+		cron, err := schedule.ParseCron(sched.Cron)
+		if err != nil {
+			log.Printf("Schedule %s has an invalid cron expression: %v", sched.ID, err)
+			sched.NextRun = time.Time{}
+		} else {
+			sched.NextRun = cron.Next(now)
+		}
+
+		saved, err := h.saveRunSchedule(sched)
+		if err != nil {
+			log.Printf("Failed to save schedule %s: %v", sched.ID, err)
+		}
+		if saved && didRun {
+			ran = append(ran, sched)
+		}
+	}
+	return ran
+}
+
+// saveRunSchedule stores sched after a run unless cancel_schedule deleted it meanwhile.
+// It reports whether the schedule still exists.
+func (h *ToolHandler) saveRunSchedule(sched Schedule) (bool, error) {
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+
+	if _, err := h.store.Get(store.BucketSchedules, sched.ID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil
+		}
+		return true, err
+	}
+	return true, store.PutJSON(h.store, store.BucketSchedules, sched.ID, sched)
+}
+
+func (h *ToolHandler) runSchedule(ctx context.Context, sched Schedule) ScheduleRun {
+	run := ScheduleRun{At: time.Now(), Status: RunStatusFailed}
+
+	h.mu.RLock()
+	policy := h.pickPolicy
+	h.mu.RUnlock()
+
+	if err := h.consumeQuota(ctx, quotaCartMutation, len(sched.Items)); err != nil {
+		run.Error = err.Error()
+		return run
+	}
+
+	// Each run replaces the draft basket named after the schedule, never the live cart,
+	// so items the user added in the meantime are kept.
+	basket := sched.ID
+	now := time.Now()
+	if err := store.PutJSON(h.store, store.BucketBaskets, basket, DraftBasket{
+		Name: basket, Items: []BasketItem{}, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		run.Error = fmt.Sprintf("failed to reset basket: %v", err)
+		return run
+	}
+	run.Basket = basket
+
+	results, added := h.addListItems(ctx, sched.Items, policy, "schedule:"+sched.Name, basket)
+	run.Added = added
+	for _, r := range results {
+		if r["added"] != true {
+			run.Missing = append(run.Missing, r["query"].(string))
+		}
+	}
+
+	if sched.PostalCode != "" {
+		slots, err := h.client.GetAvailableTimeSlots(willys.WithFreshResponses(ctx), sched.PostalCode)
+		if err != nil {
+			run.Error = fmt.Sprintf("failed to get time slots: %v", err)
+			return run
+		}
+		slot := cheapestSlotInWindow(slots, sched.Window)
+		if slot == nil {
+			run.Error = "no available delivery slot in the preferred window"
+			return run
+		}
+		if err := h.nonInterruptible(ctx, "schedule "+sched.ID+" slot reservation", func(ctx context.Context) error {
+			return h.client.SelectTimeSlot(ctx, *slot)
+		}); err != nil {
+			run.Error = fmt.Sprintf("failed to reserve time slot: %v", err)
+			return run
+		}
+		run.Slot = slot
+	}
+
+	run.Status = RunStatusAwaitingApproval
+	return run
+}
+
+// checkCronInterval rejects a cron that fires twice within minInterval anywhere in the
+// coming week.
+func checkCronInterval(cron *schedule.Cron, now time.Time, minInterval time.Duration) error {
+	end := now.Add(7 * 24 * time.Hour)
+	prev := cron.Next(now)
+	for !prev.IsZero() && prev.Before(end) {
+		next := cron.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if next.Sub(prev) < minInterval {
+			return fmt.Errorf("runs at %s and %s; it may run at most once every %s",
+				prev.Format("Mon 15:04"), next.Format("Mon 15:04"), minInterval)
+		}
+		prev = next
+	}
+	return nil
+}
+
+// schedulerJob checks for due schedules once a minute and notifies connected clients
+// about each run. It has no jitter: schedules are due on the minute.
+func (s *Server) schedulerJob() schedule.Job {
+	return schedule.Job{
+		Name:     "scheduler",
+		Interval: schedulerInterval,
+		Run: func(ctx context.Context, now time.Time) error {
+			for _, sched := range s.toolHandler.runDueSchedules(ctx, now) {
+				s.notifyScheduleRun(sched)
+			}
+			return nil
+		},
+	}
+}
+
+func (h *ToolHandler) CreateSchedule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := mcp.ParseString(request, "name", "")
+	if name == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	cronExpr := mcp.ParseString(request, "cron", "")
+	cron, err := schedule.ParseCron(cronExpr)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid cron: %v", err)), nil
+	}
+
+	items := parseListItems(request.GetArguments()["items"])
+	if len(items) == 0 {
+		return mcp.NewToolResultError("items parameter is required"), nil
+	}
+
+	var window SlotWindow
+	if windowData := mcp.ParseStringMap(request, "slot_window", nil); windowData != nil {
+		window = parseSlotWindow(windowData)
+		if err := window.validate(); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid slot_window: %v", err)), nil
+		}
+	}
+
+	postalCode := mcp.ParseString(request, "postal_code", "")
+	if postalCode != "" {
+		if err := willys.ValidatePostalCode(postalCode); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid postal code: %v", err)), nil
+		}
+	}
+
+	now := time.Now()
+	sched := Schedule{
+		ID:         "sched-" + willys.NewCorrelationID()[:8],
+		Name:       name,
+		Cron:       cron.String(),
+		Items:      items,
+		PostalCode: postalCode,
+		Window:     window,
+		CreatedAt:  now,
+		NextRun:    cron.Next(now),
+	}
+	if sched.NextRun.IsZero() {
+		return mcp.NewToolResultError(fmt.Sprintf("cron %q never matches", cronExpr)), nil
+	}
+	if err := checkCronInterval(cron, now, minScheduleInterval); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid cron: %v", err)), nil
+	}
+
+	if err := store.PutJSON(h.store, store.BucketSchedules, sched.ID, sched); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save schedule: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"schedule": sched,
+		"message":  fmt.Sprintf("Schedule created. Each run rebuilds draft basket %q and reserves a slot, then asks for approval; commit_basket moves the items to the cart. No order is placed automatically.", sched.ID),
+	})
+}
+
+func (h *ToolHandler) ListSchedules(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	schedules, err := h.loadSchedules()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list schedules: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"schedules": schedules,
+		"count":     len(schedules),
+	})
+}
+
+func (h *ToolHandler) CancelSchedule(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	id := mcp.ParseString(request, "id", "")
+	if id == "" {
+		return mcp.NewToolResultError("id parameter is required"), nil
+	}
+
+	h.schedulesMu.Lock()
+	defer h.schedulesMu.Unlock()
+
+	if _, err := h.store.Get(store.BucketSchedules, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("schedule %s not found", id)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read schedule: %v", err)), nil
+	}
+
+	if err := h.store.Delete(store.BucketSchedules, id); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to cancel schedule: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"cancelled": true,
+		"id":        id,
+	})
+}
@@ -0,0 +1,157 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/schedule"
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+func TestCheapestSlotInWindow(t *testing.T) {
+	// 2026-10-19 is a Monday
+	slots := []willys.TimeSlot{
+		{SlotID: "mon-early", Date: "2026-10-19", StartTime: "08:00", EndTime: "10:00", Fee: willys.SEK(2900), Available: true, EarliestDateTime: 1},
+		{SlotID: "mon-evening", Date: "2026-10-19", StartTime: "18:00", EndTime: "20:00", Fee: willys.SEK(4900), Available: true, EarliestDateTime: 2},
+		{SlotID: "tue-evening", Date: "2026-10-20", StartTime: "18:00", EndTime: "20:00", Fee: willys.SEK(4900), Available: true, EarliestDateTime: 3},
+		{SlotID: "mon-evening-full", Date: "2026-10-19", StartTime: "17:00", EndTime: "19:00", Fee: willys.SEK(0), Available: false, EarliestDateTime: 4},
+	}
+
+	tests := []struct {
+		name     string
+		window   SlotWindow
+		expected string
+	}{
+		{"no restriction", SlotWindow{}, "mon-early"},
+		{"evenings", SlotWindow{From: "17:00"}, "mon-evening"},
+		{"tuesday evenings", SlotWindow{Weekdays: []string{"Tuesday"}, From: "17:00"}, "tue-evening"},
+		{"nothing fits", SlotWindow{Weekdays: []string{"sat"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cheapestSlotInWindow(slots, tt.window)
+			if tt.expected == "" {
+				if got != nil {
+					t.Errorf("Expected no slot, got %s", got.SlotID)
+				}
+				return
+			}
+			if got == nil || got.SlotID != tt.expected {
+				t.Errorf("Expected %s, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	// 2026-10-31 is Alla helgons dag
+	holiday := append(slots, willys.TimeSlot{SlotID: "all-saints", Date: "2026-10-31", StartTime: "10:00", EndTime: "12:00", Fee: willys.SEK(0), Available: true, EarliestDateTime: 5})
+	if got := cheapestSlotInWindow(holiday, SlotWindow{}); got == nil || got.SlotID != "all-saints" {
+		t.Errorf("Expected the free holiday slot without skip_holidays, got %+v", got)
+	}
+	if got := cheapestSlotInWindow(holiday, SlotWindow{SkipHolidays: true}); got == nil || got.SlotID != "mon-early" {
+		t.Errorf("Expected the holiday to be skipped, got %+v", got)
+	}
+}
+
+func TestSlotWindowValidate(t *testing.T) {
+	if err := (SlotWindow{Weekdays: []string{"mon", "Friday"}, From: "08:00", To: "21:00"}).validate(); err != nil {
+		t.Errorf("Expected valid window, got %v", err)
+	}
+	if err := (SlotWindow{Weekdays: []string{"someday"}}).validate(); err == nil {
+		t.Error("Expected error for unknown weekday")
+	}
+	if err := (SlotWindow{From: "8"}).validate(); err == nil {
+		t.Error("Expected error for malformed time")
+	}
+}
+
+func TestCheckCronInterval(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		cron  string
+		valid bool
+	}{
+		{"0 18 * * 0", true},
+		{"@daily", true},
+		{"0 * * * *", true},
+		{"* * * * *", false},
+		{"*/30 9 * * 1", false},
+	}
+	for _, tt := range tests {
+		cron, err := schedule.ParseCron(tt.cron)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.cron, err)
+		}
+		if err := checkCronInterval(cron, now, minScheduleInterval); (err == nil) != tt.valid {
+			t.Errorf("%q: expected valid=%v, got %v", tt.cron, tt.valid, err)
+		}
+	}
+}
+
+func TestScheduleRunFillsDraftBasket(t *testing.T) {
+	client := &restClient{
+		warningsClient: warningsClient{cart: &willys.CartSummary{}},
+		added:          map[string]int{},
+	}
+	h := NewToolHandler(client)
+	sched := Schedule{
+		ID:      "sched-1",
+		Name:    "Weekly",
+		Cron:    "0 18 * * 0",
+		Items:   []listItem{{Query: "mjölk", Quantity: 2}},
+		NextRun: time.Now().Add(-time.Minute),
+	}
+	if err := store.PutJSON(h.store, store.BucketBaskets, sched.ID, DraftBasket{Name: sched.ID, Items: []BasketItem{{Code: "old", Quantity: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutJSON(h.store, store.BucketSchedules, sched.ID, sched); err != nil {
+		t.Fatal(err)
+	}
+
+	ran := h.runDueSchedules(context.Background(), time.Now())
+	if len(ran) != 1 || ran[0].LastRun.Status != RunStatusAwaitingApproval || ran[0].LastRun.Basket != sched.ID {
+		t.Fatalf("Expected a run into basket %s, got %+v", sched.ID, ran)
+	}
+	if len(client.added) != 0 {
+		t.Errorf("Expected the live cart to be left alone, got %v", client.added)
+	}
+	basket, err := h.getBasket(sched.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(basket.Items) != 1 || basket.Items[0].Code != "101" || basket.Items[0].Quantity != 2 {
+		t.Errorf("Expected the basket to be rebuilt with 2x 101, got %+v", basket.Items)
+	}
+
+	// A schedule stored with a too frequent cron doesn't run again within the interval
+	sched = ran[0]
+	firstRun := sched.LastRun.At
+	sched.NextRun = time.Now().Add(-time.Minute)
+	if err := store.PutJSON(h.store, store.BucketSchedules, sched.ID, sched); err != nil {
+		t.Fatal(err)
+	}
+	if ran = h.runDueSchedules(context.Background(), time.Now().Add(time.Minute)); len(ran) != 0 {
+		t.Errorf("Expected the run to be skipped, got %+v", ran)
+	}
+	var stored Schedule
+	if err := store.GetJSON(h.store, store.BucketSchedules, sched.ID, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if !stored.LastRun.At.Equal(firstRun) || !stored.NextRun.After(time.Now()) {
+		t.Errorf("Expected only the next run to move, got %+v", stored)
+	}
+
+	// A schedule cancelled during its run stays cancelled
+	if err := h.store.Delete(store.BucketSchedules, sched.ID); err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := h.saveRunSchedule(sched); saved || err != nil {
+		t.Errorf("Expected a cancelled schedule not to be saved, got %v (%v)", saved, err)
+	}
+	if _, err := h.store.Get(store.BucketSchedules, sched.ID); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Expected the schedule to stay deleted, got %v", err)
+	}
+}
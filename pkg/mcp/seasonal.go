@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// seasonalSearchSize is the number of products shown per seasonal query.
+const seasonalSearchSize = 5
+
+func (h *ToolHandler) WhatsNew(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	limit := mcp.ParseInt(request, "limit", 20)
+	includeSeasonal := mcp.ParseBoolean(request, "include_seasonal", true)
+
+	detail, err := h.parseOutputDetail(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if cursor := mcp.ParseString(request, "cursor", ""); cursor != "" {
+		p, err := resumePage[willys.Product](ctx, h, "whats_new", cursor)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultJSON(p.toResult(map[string]any{"new_products": projectProducts(p.Items, detail)}))
+	}
+
+	seasons := willys.ActiveSeasons(time.Now())
+	searches := 1
+	if includeSeasonal {
+		for _, s := range seasons {
+			searches += len(s.Queries)
+		}
+	}
+	if err := h.consumeQuota(ctx, quotaSearch, searches); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	products, err := h.client.GetNewProducts(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get new products: %v", err)), nil
+	}
+	p := pageOf(ctx, h, "whats_new", products, limit)
+	result := p.toResult(map[string]any{
+		"new_products": projectProducts(p.Items, detail),
+	})
+	if !includeSeasonal {
+		return mcp.NewToolResultJSON(result)
+	}
+
+	seasonal := make([]map[string]any, 0, len(seasons))
+	for _, season := range seasons {
+		queries := make([]map[string]any, 0, len(season.Queries))
+		for _, query := range season.Queries {
+			entry := map[string]any{"query": query}
+			found, err := h.client.SearchProducts(ctx, query, 0, seasonalSearchSize, nil)
+			if err != nil {
+				entry["error"] = err.Error()
+			} else {
+				entry["products"] = projectProducts(found, detail)
+			}
+			queries = append(queries, entry)
+		}
+		seasonal = append(seasonal, map[string]any{
+			"season":  season.Name,
+			"queries": queries,
+		})
+	}
+	result["seasonal"] = seasonal
+
+	return mcp.NewToolResultJSON(result)
+}
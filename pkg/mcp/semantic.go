@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/effati/willys-mcp/internal/semantic"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+// pickProduct auto-picks among products and, when that finds nothing and semantic
+// matching is enabled, falls back to broader searches ranked by embedding similarity.
+func (h *ToolHandler) pickProduct(ctx context.Context, query string, products []willys.Product, policy willys.PickPolicy) willys.PickResult {
+	pick := willys.AutoPick(query, products, policy, h.pickHistory)
+	if pick.Product != nil || h.matcher == nil {
+		return pick
+	}
+
+	candidates := h.semanticCandidates(ctx, query)
+	match, err := h.matcher.Best(ctx, query, candidates)
+	if err != nil {
+		log.Printf("Semantic matching failed for %q: %v", query, err)
+		return pick
+	}
+	if match == nil {
+		return pick
+	}
+
+	return willys.PickResult{
+		Query:      query,
+		Product:    &match.Product,
+		Reason:     fmt.Sprintf("no exact match, closest product by meaning (confidence %.2f)", match.Confidence),
+		Candidates: len(candidates),
+		Confidence: match.Confidence,
+	}
+}
+
+// semanticCandidates collects products from progressively broader searches, stopping
+// at the first query that returns anything or when the search quota runs out. Each search
+// counts against the quota like any other.
+func (h *ToolHandler) semanticCandidates(ctx context.Context, query string) []willys.Product {
+	for _, q := range semantic.CandidateQueries(query) {
+		if q == query {
+			continue
+		}
+		if err := h.consumeQuota(ctx, quotaSearch, 1); err != nil {
+			log.Printf("Semantic candidate search for %q skipped: %v", q, err)
+			return nil
+		}
+		products, err := h.client.SearchProducts(ctx, q, 0, listSearchSize, nil)
+		if err != nil {
+			log.Printf("Semantic candidate search for %q failed: %v", q, err)
+			continue
+		}
+		if len(products) > 0 {
+			return products
+		}
+	}
+	return nil
+}
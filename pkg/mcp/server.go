@@ -1,41 +1,217 @@
 package mcp
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/effati/willys-mcp/internal/config"
+	"github.com/effati/willys-mcp/internal/features"
+	"github.com/effati/willys-mcp/internal/mail"
+	"github.com/effati/willys-mcp/internal/semantic"
+	"github.com/effati/willys-mcp/internal/store"
 	"github.com/effati/willys-mcp/internal/willys"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+const (
+	ServerName    = "Willys Grocery Store"
+	ServerVersion = "1.0.0"
+)
+
 type Server struct {
 	mcpServer   *server.MCPServer
 	toolHandler *ToolHandler
 	client      willys.WillysAPI
+	adminTools  bool
+	scheduler   bool
+	autobook    bool
+	politeMode  bool
+	middleware  []Middleware // extra middleware from WithMiddleware
+	// features gates experimental subsystems; a flag that is off wins over the
+	// subsystem's own setting
+	features features.Flags
+
+	// httpAddr selects the HTTP transport; empty serves stdio
+	httpAddr      string
+	apiToken      string
+	webhookSecret string
+	webhookBasket string
+	// allowedHosts are extra names the Host and Origin headers may use in HTTP mode
+	allowedHosts []string
+	// grpcAddr additionally serves the gRPC API; empty leaves it off
+	grpcAddr  string
+	grpcToken string
+	// logs forwards the server log to MCP clients; nil leaves it on stderr only
+	logs *logForwarder
 }
 
-func NewServer(client willys.WillysAPI) *Server {
+type ServerOption func(*Server)
+
+func WithPickPolicy(policy willys.PickPolicy) ServerOption {
+	return func(s *Server) {
+		s.toolHandler.pickPolicy = policy
+	}
+}
+
+// WithConfig applies cfg and lets admin_reload_config re-read settings through loader.
+func WithConfig(cfg *config.Config, loader func() (*config.Config, error)) ServerOption {
+	return func(s *Server) {
+		s.adminTools = cfg.AdminTools
+		s.scheduler = cfg.Scheduler
+		s.autobook = cfg.SlotAutobook
+		s.politeMode = cfg.PoliteMode
+		s.features = cfg.Features
+		s.toolHandler.pickPolicy = cfg.PickPolicy
+		s.toolHandler.outputDetail = cfg.OutputDetail
+		s.toolHandler.ownBrand = cfg.OwnBrand
+		s.toolHandler.diet = cfg.Diet
+		s.toolHandler.weeklyBudget = willys.MoneyFromFloat(cfg.WeeklyBudget)
+		s.toolHandler.priceAlertPercent = cfg.PriceAlertPercent
+		s.toolHandler.appLinkBase = cfg.AppLinkBase
+		if mailer, err := mail.NewSender(cfg.Mail); err != nil {
+			log.Printf("Order summary mail disabled: %v", err)
+		} else {
+			s.toolHandler.mailer = mailer
+		}
+		s.toolHandler.mailFrom = cfg.Mail.From
+		s.toolHandler.summaryRecipients = cfg.SummaryRecipients
+		embedder, err := semantic.NewEmbedder(cfg.SemanticMatcher, cfg.EmbeddingsURL, cfg.EmbeddingsKey, cfg.EmbeddingsModel)
+		if err != nil {
+			log.Printf("Semantic matching disabled: %v", err)
+		} else if embedder != nil {
+			s.toolHandler.matcher = semantic.NewMatcher(embedder, semantic.DefaultMinConfidence)
+		}
+		if err := willys.SetPriceLocale(cfg.PriceLocale); err != nil {
+			log.Printf("Ignoring price locale: %v", err)
+		}
+		s.toolHandler.quotas = newQuotaTracker(Quotas{
+			Searches:      QuotaLimit{Max: cfg.SearchesPerMinute, Window: time.Minute},
+			CartMutations: QuotaLimit{Max: cfg.CartMutationsPerHour, Window: time.Hour},
+		})
+		if cfg.DataDir != "" {
+			s.toolHandler.exportDir = filepath.Join(cfg.DataDir, "exports")
+		}
+		s.toolHandler.configLoader = loader
+		if cfg.Transport == config.TransportHTTP {
+			s.httpAddr, s.apiToken = cfg.HTTPAddr, cfg.APIToken
+			s.webhookSecret, s.webhookBasket = cfg.WebhookSecret, cfg.WebhookBasket
+			s.allowedHosts = cfg.HTTPAllowedHosts
+		}
+		s.grpcAddr, s.grpcToken = cfg.GRPCAddr, cfg.APIToken
+		s.logs = nil
+		if cfg.MCPLogLevel != LogLevelOff {
+			s.logs = newLogForwarder(mcp.LoggingLevel(cfg.MCPLogLevel))
+		}
+	}
+}
+
+// WithLogForwarding sends server log lines at level and above to MCP clients, which can
+// change their level with logging/setLevel. LogLevelOff keeps the log on stderr only.
+func WithLogForwarding(level string) ServerOption {
+	return func(s *Server) {
+		s.logs = nil
+		if level != LogLevelOff {
+			s.logs = newLogForwarder(mcp.LoggingLevel(level))
+		}
+	}
+}
+
+// WithFeatures sets the feature flags; flags missing from flags are off.
+func WithFeatures(flags features.Flags) ServerOption {
+	return func(s *Server) {
+		s.features = flags
+	}
+}
+
+// WithQuotas overrides the default per-session tool quotas.
+func WithQuotas(q Quotas) ServerOption {
+	return func(s *Server) {
+		s.toolHandler.quotas = newQuotaTracker(q)
+	}
+}
+
+// WithAdminTools enables or disables the admin_* tool group.
+func WithAdminTools(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.adminTools = enabled
+	}
+}
+
+// WithScheduler enables or disables running recurring order schedules in the background.
+func WithScheduler(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.scheduler = enabled
+	}
+}
+
+// WithSlotAutobook enables or disables the background slot poller behind
+// configure_slot_autobook.
+func WithSlotAutobook(enabled bool) ServerOption {
+	return func(s *Server) {
+		s.autobook = enabled
+	}
+}
+
+// WithStore persists tool state (pick history, lists, ...) in s instead of memory.
+func WithStore(st store.Store) ServerOption {
+	return func(s *Server) {
+		s.toolHandler.setStore(st)
+	}
+}
+
+func NewServer(client willys.WillysAPI, opts ...ServerOption) *Server {
 	toolHandler := NewToolHandler(client)
 
 	s := &Server{
 		toolHandler: toolHandler,
 		client:      client,
+		adminTools:  true,
+		scheduler:   true,
+		autobook:    true,
+		features:    features.Defaults(),
 	}
 
-	mcpServer := server.NewMCPServer(
-		"Willys Grocery Store",
-		"1.0.0",
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.scheduler = s.scheduler && s.features.Enabled(features.ScheduledOrders)
+	s.autobook = s.autobook && s.features.Enabled(features.SlotAutobook)
+	if !s.features.Enabled(features.SemanticMatching) {
+		toolHandler.matcher = nil
+	}
+
+	serverOpts := []server.ServerOption{
 		server.WithToolCapabilities(true),
-	)
+		server.WithLogging(),
+	}
+	if s.logs != nil {
+		serverOpts = append(serverOpts, server.WithHooks(s.logs.hooks()))
+	}
+	mcpServer := server.NewMCPServer(ServerName, ServerVersion, serverOpts...)
 
 	s.registerTools(mcpServer)
+	if s.adminTools {
+		s.registerAdminTools(mcpServer)
+	}
 
 	s.mcpServer = mcpServer
+	if s.logs != nil {
+		s.logs.mcpServer = mcpServer
+	}
 
 	return s
 }
 
+func (s *Server) addTool(mcpServer *server.MCPServer, tool mcp.Tool, handler server.ToolHandlerFunc) {
+	annotate(&tool)
+	mcpServer.AddTool(tool, chain(tool, handler, s.toolMiddleware()...))
+}
+
 func (s *Server) registerTools(mcpServer *server.MCPServer) {
 	searchGroceriesTool := mcp.NewTool("search_groceries",
 		mcp.WithDescription("Search for products on Willys.se with optional filters and sorting"),
@@ -76,12 +252,22 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 				},
 				"sort_by": map[string]any{
 					"type":        "string",
-					"description": "Sort method: 'cheapest', 'best_value', or 'highest_quality'",
+					"description": "Sort method: 'cheapest', 'best_value', 'highest_quality', 'most_sustainable', or 'history_weighted' (products and brands bought before first)",
 				},
+				"min_sustainability": map[string]any{
+					"type":        "number",
+					"description": "Minimum sustainability score derived from eco labels (KRAV=3, EU-ekologisk=2, ...)",
+				},
+				"own_brand": ownBrandSchema(),
 			}),
 		),
+		mcp.WithBoolean("include_facets",
+			mcp.Description("Also return the brands, categories, and labels Willys offers for this query, with product counts and the query that applies each refinement"),
+		),
+		outputDetailProperty(),
+		fieldsProperty("product"),
 	)
-	mcpServer.AddTool(searchGroceriesTool, s.toolHandler.SearchGroceries)
+	s.addTool(mcpServer, searchGroceriesTool, s.toolHandler.SearchGroceries)
 
 	addToCartTool := mcp.NewTool("add_to_cart",
 		mcp.WithDescription("Add items to cart"),
@@ -93,13 +279,81 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 			mcp.Required(),
 			mcp.Description("Quantity to add"),
 		),
+		sourceProperty(),
+		memberProperty(),
 	)
-	mcpServer.AddTool(addToCartTool, s.toolHandler.AddToCart)
+	s.addTool(mcpServer, addToCartTool, s.toolHandler.AddToCart)
+
+	searchManyTool := mcp.NewTool("search_many",
+		mcp.WithDescription("Search for several products at once and auto-pick one product per query using the pick policy"),
+		mcp.WithArray("queries",
+			mcp.Required(),
+			mcp.Description("Search queries (e.g., ['mjölk', 'bröd', 'smör'])"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithNumber("size",
+			mcp.Description("Number of candidates returned per query (default: 5)"),
+		),
+		pickPolicyProperty(),
+		outputDetailProperty(),
+	)
+	s.addTool(mcpServer, searchManyTool, s.toolHandler.SearchMany)
+
+	listToCartTool := mcp.NewTool("list_to_cart",
+		mcp.WithDescription("Resolve a shopping list to products using the pick policy and add them to the cart"),
+		mcp.WithArray("items",
+			mcp.Required(),
+			mcp.Description("Shopping list entries"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "What to buy (e.g., 'mjölk')",
+					},
+					"quantity": map[string]any{
+						"type":        "number",
+						"description": "Quantity to add (default: 1)",
+					},
+					"member": map[string]any{
+						"type":        "string",
+						"description": "Household member the item is for; overrides member",
+					},
+				},
+				"required": []string{"query"},
+			}),
+		),
+		pickPolicyProperty(),
+		sourceProperty(),
+		memberProperty(),
+		outputDetailProperty(),
+	)
+	s.addTool(mcpServer, listToCartTool, s.toolHandler.ListToCart)
 
 	viewCartTool := mcp.NewTool("view_cart",
-		mcp.WithDescription("View current cart contents"),
+		mcp.WithDescription("View current cart contents; each item lists what added it under added_by and who it is for under members, with per_member subtotals"),
+		mcp.WithString("group_by",
+			mcp.Description("Group items by 'category' (alphabetical departments) or 'aisle' (store walking order); default 'none'"),
+			mcp.Enum(CartGroupNone, CartGroupCategory, CartGroupAisle),
+		),
+		mcp.WithString("sort_by",
+			mcp.Description("Sort items (within groups) by 'price', 'price_desc', 'name', or 'recently_added'; default keeps the cart order"),
+			mcp.Enum(CartSortDefault, CartSortPrice, CartSortPriceDesc, CartSortName, CartSortRecentlyAdded),
+		),
+		mcp.WithString("delivery_date",
+			mcp.Description("Planned delivery date (YYYY-MM-DD); low-stock warnings are skipped for same-day delivery"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Fetch the cart from Willys instead of answering from the local copy (default: false)"),
+		),
+		fieldsProperty("cart item"),
 	)
-	mcpServer.AddTool(viewCartTool, s.toolHandler.ViewCart)
+	s.addTool(mcpServer, viewCartTool, s.toolHandler.ViewCart)
+
+	syncCartTool := mcp.NewTool("sync_cart",
+		mcp.WithDescription("Fetch the cart from Willys, update the local copy view_cart answers from, and list what changed since it was last synced (for example edits made on willys.se)"),
+	)
+	s.addTool(mcpServer, syncCartTool, s.toolHandler.SyncCart)
 
 	removeFromCartTool := mcp.NewTool("remove_from_cart",
 		mcp.WithDescription("Remove items from cart"),
@@ -111,81 +365,773 @@ func (s *Server) registerTools(mcpServer *server.MCPServer) {
 			mcp.Description("Quantity to remove (default: removes all)"),
 		),
 	)
-	mcpServer.AddTool(removeFromCartTool, s.toolHandler.RemoveFromCart)
+	s.addTool(mcpServer, removeFromCartTool, s.toolHandler.RemoveFromCart)
 
-	selectDeliveryTimeTool := mcp.NewTool("select_delivery_time",
-		mcp.WithDescription("Select delivery address and time slot"),
-		mcp.WithObject("address",
+	cartClimateReportTool := mcp.NewTool("cart_climate_report",
+		mcp.WithDescription("Summarize the eco and climate labels of the products in the cart"),
+	)
+	s.addTool(mcpServer, cartClimateReportTool, s.toolHandler.CartClimateReport)
+
+	parseIngredientsTool := mcp.NewTool("parse_ingredients",
+		mcp.WithDescription("Parse a pasted recipe ingredient list (Swedish or English) into items, amounts, and units, skipping pantry staples like salt and water; optionally add the items to the cart"),
+		mcp.WithString("text",
 			mcp.Required(),
-			mcp.Description("Delivery address information"),
-			mcp.Properties(map[string]any{
-				"first_name": map[string]any{
-					"type":        "string",
-					"description": "Recipient's first name",
-					"required":    true,
-				},
-				"last_name": map[string]any{
-					"type":        "string",
-					"description": "Recipient's last name",
-					"required":    true,
-				},
-				"address": map[string]any{
-					"type":        "string",
-					"description": "Street address (e.g., 'Drottninggatan 1')",
-					"required":    true,
-				},
-				"postal_code": map[string]any{
-					"type":        "string",
-					"description": "Postal code (e.g., '11151')",
-					"required":    true,
+			mcp.Description("Ingredient lines, one per line (e.g., '2 dl grädde\n500 g nötfärs\nSalt')"),
+		),
+		mcp.WithBoolean("add_to_cart",
+			mcp.Description("Search for each non-pantry item and add the auto-picked product to the cart (default: false)"),
+		),
+		mcp.WithString("title",
+			mcp.Description("Recipe name, recorded as 'recipe:<title>' in added_by"),
+		),
+		mcp.WithBoolean("use_pantry",
+			mcp.Description("Leave out ingredients the pantry already has enough of (default: true)"),
+		),
+		pickPolicyProperty(),
+	)
+	s.addTool(mcpServer, parseIngredientsTool, s.toolHandler.ParseIngredients)
+
+	saveTemplateTool := mcp.NewTool("save_template",
+		mcp.WithDescription("Save a reusable order template such as 'weekly basics' or 'party'. Item quantities can scale with a parameter, e.g. one bag of chips per two people"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Template name (e.g., 'party'); saving under an existing name replaces it"),
+		),
+		mcp.WithString("description",
+			mcp.Description("What the template is for"),
+		),
+		mcp.WithString("parameter",
+			mcp.Description("Name of the scaling parameter (e.g., 'people'); required when any item has per_unit"),
+		),
+		mcp.WithNumber("default",
+			mcp.Description("Parameter value used when apply_template is called without one"),
+		),
+		mcp.WithArray("items",
+			mcp.Required(),
+			mcp.Description("Items; each resolves to quantity + per_unit × parameter, rounded up (e.g., [{'query': 'chips', 'per_unit': 0.5}, {'query': 'dipp', 'quantity': 1}])"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query":    map[string]any{"type": "string", "description": "Search query for the item"},
+					"quantity": map[string]any{"type": "number", "description": "Fixed quantity (default: 1 when per_unit is not set)"},
+					"per_unit": map[string]any{"type": "number", "description": "Additional quantity per unit of the parameter"},
 				},
-				"city": map[string]any{
-					"type":        "string",
-					"description": "City name (e.g., 'Stockholm')",
-					"required":    true,
+				"required": []string{"query"},
+			}),
+		),
+	)
+	s.addTool(mcpServer, saveTemplateTool, s.toolHandler.SaveTemplate)
+
+	listTemplatesTool := mcp.NewTool("list_templates",
+		mcp.WithDescription("List saved order templates"),
+	)
+	s.addTool(mcpServer, listTemplatesTool, s.toolHandler.ListTemplates)
+
+	deleteTemplateTool := mcp.NewTool("delete_template",
+		mcp.WithDescription("Delete a saved order template"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Template name"),
+		),
+	)
+	s.addTool(mcpServer, deleteTemplateTool, s.toolHandler.DeleteTemplate)
+
+	applyTemplateTool := mcp.NewTool("apply_template",
+		mcp.WithDescription("Resolve an order template for a parameter value, pick products at current prices, and add them to the cart (or the active draft basket)"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Template name"),
+		),
+		mcp.WithNumber("value",
+			mcp.Description("Parameter value, e.g. the number of people (default: the template's default)"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only resolve and price the items without adding them (default: false)"),
+		),
+		pickPolicyProperty(),
+	)
+	s.addTool(mcpServer, applyTemplateTool, s.toolHandler.ApplyTemplate)
+
+	exportPlanTool := mcp.NewTool("export_plan",
+		mcp.WithDescription("Render the meal plan, shopping list, and cart as shareable Markdown, or save it as a PDF, so it can be reviewed or printed before the order is finalized"),
+		mcp.WithString("title",
+			mcp.Description("Document title (default: 'Plan for <today>')"),
+		),
+		mcp.WithString("meal_plan",
+			mcp.Description("Meals, one per line (e.g., 'Måndag: Lasagne')"),
+		),
+		mcp.WithArray("items",
+			mcp.Description("Shopping list entries; without them the draft basket is used"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "What to buy (e.g., 'mjölk')",
+					},
+					"quantity": map[string]any{
+						"type":        "number",
+						"description": "Quantity (default: 1)",
+					},
 				},
-				"door_code": map[string]any{
-					"type":        "string",
-					"description": "Optional door code for building access",
+				"required": []string{"query"},
+			}),
+		),
+		mcp.WithString("basket",
+			mcp.Description("Draft basket to list as the shopping list (default: the active draft, if any)"),
+		),
+		mcp.WithBoolean("include_cart",
+			mcp.Description("Include the Willys cart with prices and total (default: true)"),
+		),
+		mcp.WithString("format",
+			mcp.Description("'markdown' (default) returns the document; 'pdf' also saves a PDF in the data directory and returns its path"),
+			mcp.Enum(ExportFormatMarkdown, ExportFormatPDF),
+		),
+	)
+	s.addTool(mcpServer, exportPlanTool, s.toolHandler.ExportPlan)
+
+	exportDeliveryCalendarTool := mcp.NewTool("export_delivery_calendar",
+		mcp.WithDescription("Export the booked delivery window as an iCal event with address and order reference, for adding to a calendar"),
+		mcp.WithBoolean("save",
+			mcp.Description("Also save the .ics file in the data directory and return its path (default: false)"),
+		),
+	)
+	s.addTool(mcpServer, exportDeliveryCalendarTool, s.toolHandler.ExportDeliveryCalendar)
+
+	planBudgetTool := mcp.NewTool("plan_budget",
+		mcp.WithDescription("Price a planned order against a weekly budget: spend per department and the items that push it over. Items search can't price use the average price from past orders"),
+		mcp.WithNumber("budget",
+			mcp.Required(),
+			mcp.Description("Weekly budget in kronor (e.g., 1200)"),
+		),
+		mcp.WithString("template",
+			mcp.Description("Saved order template to plan from"),
+		),
+		mcp.WithNumber("value",
+			mcp.Description("Template parameter value (default: the template's default)"),
+		),
+		mcp.WithArray("items",
+			mcp.Description("Planned items in priority order, used when no template is given (e.g., [{'query': 'mjölk', 'quantity': 2}])"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"query": map[string]any{
+						"type":        "string",
+						"description": "What to buy (e.g., 'mjölk')",
+					},
+					"quantity": map[string]any{
+						"type":        "number",
+						"description": "Quantity (default: 1)",
+					},
+					"member": map[string]any{
+						"type":        "string",
+						"description": "Household member the item is for; overrides member",
+					},
 				},
-				"message_to_driver": map[string]any{
-					"type":        "string",
-					"description": "Optional message to delivery driver (e.g., instructions or directions)",
+				"required": []string{"query"},
+			}),
+		),
+		memberProperty(),
+		mcp.WithString("meal_plan",
+			mcp.Description("Ingredient lines for the week's meals, one per line, used when neither template nor items is given; pantry stock is subtracted"),
+		),
+		pickPolicyProperty(),
+	)
+	s.addTool(mcpServer, planBudgetTool, s.toolHandler.PlanBudget)
+
+	createBasketTool := mcp.NewTool("create_basket",
+		mcp.WithDescription("Create a local draft basket, e.g. to plan next week's order while this week's is still open. While a draft is active, add_to_cart, list_to_cart, and parse_ingredients add to it instead of the Willys cart"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Basket name: 1-32 letters, digits, '-' or '_' (e.g., 'next-week')"),
+		),
+		mcp.WithBoolean("switch",
+			mcp.Description("Make the new basket active (default: true)"),
+		),
+	)
+	s.addTool(mcpServer, createBasketTool, s.toolHandler.CreateBasket)
+
+	switchBasketTool := mcp.NewTool("switch_basket",
+		mcp.WithDescription("Choose where cart tools add products: a draft basket, or 'live' for the Willys cart"),
+		mcp.WithString("name",
+			mcp.Description("Draft basket name, or 'live' (default) for the Willys cart"),
+		),
+	)
+	s.addTool(mcpServer, switchBasketTool, s.toolHandler.SwitchBasket)
+
+	listBasketsTool := mcp.NewTool("list_baskets",
+		mcp.WithDescription("List draft baskets with their items, and which basket is active"),
+	)
+	s.addTool(mcpServer, listBasketsTool, s.toolHandler.ListBaskets)
+
+	commitBasketTool := mcp.NewTool("commit_basket",
+		mcp.WithDescription("Add every item of a draft basket to the Willys cart, then delete the draft. Items that fail stay in the draft"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Draft basket to commit"),
+		),
+		mcp.WithBoolean("keep",
+			mcp.Description("Keep the draft after committing, e.g. to reuse it (default: false)"),
+		),
+	)
+	s.addTool(mcpServer, commitBasketTool, s.toolHandler.CommitBasket)
+
+	deleteBasketTool := mcp.NewTool("delete_basket",
+		mcp.WithDescription("Delete a draft basket without touching the Willys cart"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Draft basket to delete"),
+		),
+	)
+	s.addTool(mcpServer, deleteBasketTool, s.toolHandler.DeleteBasket)
+
+	setSessionContextTool := mcp.NewTool("set_session_context",
+		mcp.WithDescription("Set this conversation's delivery address, weekly budget, and dietary profile. They apply only to this MCP session and override the server-wide defaults"),
+		mcp.WithString("address_label",
+			mcp.Description("Saved address to use when a tool gets no address or address_label"),
+		),
+		mcp.WithNumber("weekly_budget",
+			mcp.Description("Weekly budget in kronor for cart warnings; 0 falls back to the configured budget"),
+		),
+		mcp.WithArray("diet",
+			mcp.Description("Dietary profile for cart warnings: vegetarian, vegan, lactose_free, gluten_free. An empty list falls back to the configured diet"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean("clear",
+			mcp.Description("Reset the session's context before applying the other parameters (default: false)"),
+		),
+	)
+	s.addTool(mcpServer, setSessionContextTool, s.toolHandler.SetSessionContext)
+
+	viewSessionContextTool := mcp.NewTool("view_session_context",
+		mcp.WithDescription("Show this conversation's address, budget, diet, and active basket, and the values tools will actually use"),
+	)
+	s.addTool(mcpServer, viewSessionContextTool, s.toolHandler.ViewSessionContext)
+
+	updatePantryTool := mcp.NewTool("update_pantry",
+		mcp.WithDescription("Record staples you already have at home, with quantity, unit, and expiry; a quantity of 0 removes an item"),
+		mcp.WithArray("items",
+			mcp.Required(),
+			mcp.Description("Pantry items to set (e.g., [{'name': 'mjölk', 'quantity': 1.5, 'unit': 'l', 'expires': '2026-10-20'}])"),
+			mcp.Items(map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name":     map[string]any{"type": "string", "description": "Item name (e.g., 'mjölk')"},
+					"quantity": map[string]any{"type": "number", "description": "Amount on hand (default: 1); 0 removes the item"},
+					"unit":     map[string]any{"type": "string", "description": "Unit such as 'l', 'dl', 'g', 'kg', or 'st'; omit for packages"},
+					"expires":  map[string]any{"type": "string", "description": "Best-before date (YYYY-MM-DD)"},
 				},
+				"required": []string{"name"},
 			}),
 		),
-		mcp.WithString("delivery_date",
+	)
+	s.addTool(mcpServer, updatePantryTool, s.toolHandler.UpdatePantry)
+
+	viewPantryTool := mcp.NewTool("view_pantry",
+		mcp.WithDescription("List the staples recorded in the pantry"),
+	)
+	s.addTool(mcpServer, viewPantryTool, s.toolHandler.ViewPantry)
+
+	whatsExpiringTool := mcp.NewTool("whats_expiring",
+		mcp.WithDescription("Estimate which previously ordered products are expiring or running out, from order history, typical shelf life per department, and how often each product is bought"),
+		mcp.WithNumber("days",
+			mcp.Description("Look this many days ahead (default: 3)"),
+		),
+		mcp.WithBoolean("include_pantry",
+			mcp.Description("Also list pantry items whose best-before date falls within the window (default: true)"),
+		),
+	)
+	s.addTool(mcpServer, whatsExpiringTool, s.toolHandler.WhatsExpiring)
+
+	whatsNewTool := mcp.NewTool("whats_new",
+		mcp.WithDescription("List new products and, when in season, seasonal items such as kräftor or semlor"),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of new products per page (default: 20); pass next_cursor as cursor for more"),
+		),
+		mcp.WithString("cursor",
+			mcp.Description("next_cursor from the previous page; returns the next new products without seasonal items"),
+		),
+		mcp.WithBoolean("include_seasonal",
+			mcp.Description("Also search for products of the current Swedish food seasons (default: true)"),
+		),
+		outputDetailProperty(),
+	)
+	s.addTool(mcpServer, whatsNewTool, s.toolHandler.WhatsNew)
+
+	relatedProductsTool := mcp.NewTool("related_products",
+		mcp.WithDescription("List products other customers bought together with a product, for suggesting complements such as salsa with taco shells"),
+		mcp.WithString("product_code",
 			mcp.Required(),
-			mcp.Description("Delivery date in ISO 8601 format (YYYY-MM-DD)"),
+			mcp.Description("Product code in format {id}_{ST|KG} (e.g., '101233933_ST')"),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of products (default: 10)"),
+		),
+		outputDetailProperty(),
+	)
+	s.addTool(mcpServer, relatedProductsTool, s.toolHandler.RelatedProducts)
+
+	suggestComplementsTool := mcp.NewTool("suggest_complements",
+		mcp.WithDescription("Before checkout, suggest likely-forgotten items: products the customer usually buys together with what's in the cart, and products other customers buy with it"),
+		mcp.WithNumber("limit",
+			mcp.Description("Maximum number of suggestions (default: 5)"),
+		),
+		mcp.WithBoolean("use_related",
+			mcp.Description("Also ask Willys what other customers bought with the cart items (default: true)"),
+		),
+	)
+	s.addTool(mcpServer, suggestComplementsTool, s.toolHandler.SuggestComplements)
+
+	preCheckoutReviewTool := mcp.NewTool("pre_checkout_review",
+		mcp.WithDescription("Run right before presenting the checkout URL: checks the cart, stock, forgotten staples from past orders, dietary conflicts, budget, the booked delivery slot, and fees in one report"),
+	)
+	s.addTool(mcpServer, preCheckoutReviewTool, s.toolHandler.PreCheckoutReview)
+
+	compareWithLastOrderTool := mcp.NewTool("compare_with_last_order",
+		mcp.WithDescription("Answer \"what's different from last week?\": compare the cart with the most recent comparable order. Lists usual items that are missing, new items, changed quantities, price changes per item, and the total delta"),
+		mcp.WithString("order_code",
+			mcp.Description("Order to compare with; defaults to the newest order that wasn't cancelled and isn't a small top-up"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Fetch the cart from Willys instead of using the local copy (default: false)"),
+		),
+	)
+	s.addTool(mcpServer, compareWithLastOrderTool, s.toolHandler.CompareWithLastOrder)
+
+	priceChangesTool := mcp.NewTool("price_changes_since_last_purchase",
+		mcp.WithDescription("List cart items whose price changed since the customer last bought them, biggest rise first. Rises above the alert threshold are flagged with alert and also reported as price_increase warnings by the cart tools"),
+		mcp.WithNumber("threshold_percent",
+			mcp.Description("Rise in percent above which an item is flagged (default: WILLYS_PRICE_ALERT_PERCENT, 5)"),
+		),
+		mcp.WithBoolean("alerts_only",
+			mcp.Description("Only list the flagged rises (default: false)"),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Fetch the cart from Willys instead of using the local copy (default: false)"),
+		),
+	)
+	s.addTool(mcpServer, priceChangesTool, s.toolHandler.PriceChangesSinceLastPurchase)
+
+	serverCapabilitiesTool := mcp.NewTool("server_capabilities",
+		mcp.WithDescription("Report the server version, enabled features, login state, active store, and available tools"),
+	)
+	s.addTool(mcpServer, serverCapabilitiesTool, s.ServerCapabilities)
+
+	optimizeCartCostTool := mcp.NewTool("optimize_cart_cost",
+		mcp.WithDescription("Find cheaper products with the same unit and eco labels for each cart item and propose swaps with savings"),
+		mcp.WithArray("apply",
+			mcp.Description("Product codes of cart items whose proposed swap should be applied"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean("apply_all",
+			mcp.Description("Apply every proposed swap (default: false, only propose)"),
+		),
+		mcp.WithString("own_brand",
+			mcp.Description(ownBrandDescription),
+			mcp.Enum(willys.OwnBrandOff, willys.OwnBrandPrefer, willys.OwnBrandOnly),
+		),
+	)
+	s.addTool(mcpServer, optimizeCartCostTool, s.toolHandler.OptimizeCartCost)
+
+	selectDeliveryTimeTool := mcp.NewTool("select_delivery_time",
+		mcp.WithDescription("Select delivery address and time slot"),
+		mcp.WithObject("address",
+			mcp.Description("Delivery address information; omit to use address_text, address_label, or the default saved address"),
+			mcp.Properties(deliveryAddressProperties()),
+		),
+		mcp.WithString("address_text",
+			mcp.Description("The address as one line instead of the address object, e.g. 'Drottninggatan 1, 111 51 Stockholm, portkod 1234'. Start with the recipient's name to deliver to someone other than the account holder; a door code and other notes for the driver may follow"),
+		),
+		mcp.WithString("address_label",
+			mcp.Description("Label of a saved address (e.g., 'home'), see list_addresses"),
+		),
+		mcp.WithString("delivery_date",
+			mcp.Description("Delivery date in ISO 8601 format (YYYY-MM-DD); required unless slot_spec is given"),
 		),
 		mcp.WithString("time_slot",
+			mcp.Description("Time slot in format 'HH:MM-HH:MM' (e.g., '15:00-17:00'), as in a slot's window field; required unless slot_spec is given"),
+		),
+		mcp.WithString("slot_spec",
+			mcp.Description("Fuzzy slot choice resolved against the real slot list instead of delivery_date/time_slot, e.g. 'earliest', 'tomorrow evening', 'cheapest this weekend'. Equally cheap slots go to the earlier start, equally early ones to the lower fee. A weekday that falls on a holiday falls back to the day before if the holiday has no slot"),
+		),
+		mcp.WithBoolean("use_last",
+			mcp.Description("Repeat the last successful delivery: its address, and the earliest open slot in the same time window, preferably on the same weekday. Any address or slot argument given as well takes precedence; a delivery_date alone books the same window on that date"),
+		),
+	)
+	s.addTool(mcpServer, selectDeliveryTimeTool, s.toolHandler.SelectDeliveryTime)
+
+	releaseDeliverySlotTool := mcp.NewTool("release_delivery_slot",
+		mcp.WithDescription("Give back the delivery slot reserved in the cart, e.g. to pick another one. An abandoned reservation can block selecting the slot again until it expires"),
+	)
+	s.addTool(mcpServer, releaseDeliverySlotTool, s.toolHandler.ReleaseDeliverySlot)
+
+	saveAddressTool := mcp.NewTool("save_address",
+		mcp.WithDescription("Save a delivery address under a label (e.g., 'home', 'parents', 'cabin'), replacing any address with the same label"),
+		mcp.WithString("label",
 			mcp.Required(),
-			mcp.Description("Time slot in format 'HH:MM-HH:MM' (e.g., '15:00-17:00')"),
+			mcp.Description("Short label for the address (letters, digits, '-' or '_')"),
+		),
+		mcp.WithObject("address",
+			mcp.Required(),
+			mcp.Description("Delivery address information"),
+			mcp.Properties(deliveryAddressProperties()),
+		),
+		mcp.WithBoolean("default",
+			mcp.Description("Use this address when select_delivery_time gets no address (the first saved address becomes the default)"),
 		),
 	)
-	mcpServer.AddTool(selectDeliveryTimeTool, s.toolHandler.SelectDeliveryTime)
+	s.addTool(mcpServer, saveAddressTool, s.toolHandler.SaveAddress)
+
+	listAddressesTool := mcp.NewTool("list_addresses",
+		mcp.WithDescription("List saved delivery addresses and which one is the default"),
+	)
+	s.addTool(mcpServer, listAddressesTool, s.toolHandler.ListAddresses)
+
+	deleteAddressTool := mcp.NewTool("delete_address",
+		mcp.WithDescription("Delete a saved delivery address"),
+		mcp.WithString("label",
+			mcp.Required(),
+			mcp.Description("Label of the address to delete"),
+		),
+	)
+	s.addTool(mcpServer, deleteAddressTool, s.toolHandler.DeleteAddress)
+
+	setDefaultAddressTool := mcp.NewTool("set_default_address",
+		mcp.WithDescription("Make a saved address the default for select_delivery_time"),
+		mcp.WithString("label",
+			mcp.Required(),
+			mcp.Description("Label of the address to use by default"),
+		),
+	)
+	s.addTool(mcpServer, setDefaultAddressTool, s.toolHandler.SetDefaultAddress)
+
+	saveMemberTool := mcp.NewTool("save_member",
+		mcp.WithDescription("Add or update a household member, so cart items and list items can be assigned to them (e.g. Erik's lunchbox items) and the cart and budget split per member"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Member's name (e.g., 'Erik'); matched case-insensitively"),
+		),
+		mcp.WithString("role",
+			mcp.Description("'adult' (default) or 'child'"),
+			mcp.Enum(MemberRoleAdult, MemberRoleChild),
+		),
+	)
+	s.addTool(mcpServer, saveMemberTool, s.toolHandler.SaveMember)
+
+	listMembersTool := mcp.NewTool("list_members",
+		mcp.WithDescription("List the household members items can be assigned to"),
+	)
+	s.addTool(mcpServer, listMembersTool, s.toolHandler.ListMembers)
+
+	deleteMemberTool := mcp.NewTool("delete_member",
+		mcp.WithDescription("Delete a household member; the items assigned to them become unassigned"),
+		mcp.WithString("name",
+			mcp.Required(),
+			mcp.Description("Name of the member to delete"),
+		),
+	)
+	s.addTool(mcpServer, deleteMemberTool, s.toolHandler.DeleteMember)
+
+	assignCartItemTool := mcp.NewTool("assign_cart_item",
+		mcp.WithDescription("Set which household members a cart item is for, replacing earlier assignments. view_cart then splits its price between them under per_member"),
+		mcp.WithString("product_code",
+			mcp.Required(),
+			mcp.Description("Product code of the cart item"),
+		),
+		mcp.WithArray("members",
+			mcp.Required(),
+			mcp.Description("Members the item is for (e.g., ['Erik']); an empty list unassigns it"),
+			mcp.WithStringItems(),
+		),
+	)
+	s.addTool(mcpServer, assignCartItemTool, s.toolHandler.AssignCartItem)
 
 	getAvailableTimeSlotsTool := mcp.NewTool("get_available_time_slots",
-		mcp.WithDescription("Get available delivery time slots for a postal code"),
+		append([]mcp.ToolOption{
+			mcp.WithDescription("Get available delivery time slots for a postal code, a page at a time; pass next_cursor as cursor for the next page"),
+			mcp.WithString("postal_code",
+				mcp.Description("Postal code to check availability for (e.g., '11151'); required unless cursor is given"),
+			),
+		}, cursorProperties(slotsPageSize, maxSlotsPageSize)...)...,
+	)
+	s.addTool(mcpServer, getAvailableTimeSlotsTool, s.toolHandler.GetAvailableTimeSlots)
+
+	checkDeliverabilityTool := mcp.NewTool("check_deliverability",
+		mcp.WithDescription("Check whether Willys delivers to a postal code, and report the delivering store, earliest available date, and delivery fee range"),
 		mcp.WithString("postal_code",
 			mcp.Required(),
-			mcp.Description("Postal code to check availability for (e.g., '11151')"),
+			mcp.Description("Postal code to check (e.g., '11151')"),
 		),
 	)
-	mcpServer.AddTool(getAvailableTimeSlotsTool, s.toolHandler.GetAvailableTimeSlots)
+	s.addTool(mcpServer, checkDeliverabilityTool, s.toolHandler.CheckDeliverability)
+
+	deliveryFeeOverviewTool := mcp.NewTool("delivery_fee_overview",
+		mcp.WithDescription("Summarize delivery slots per day: the lowest and highest fee, how many slots are still available, and holidays. Use it to compare days (e.g. 'Tuesday is 39 kr cheaper') without listing every slot"),
+		mcp.WithString("postal_code",
+			mcp.Description("Postal code to check; defaults to the address_label or default saved address"),
+		),
+		mcp.WithString("address_label",
+			mcp.Description("Label of a saved address to take the postal code from"),
+		),
+		mcp.WithNumber("days",
+			mcp.Description("Number of days from today to cover (default 14)"),
+		),
+	)
+	s.addTool(mcpServer, deliveryFeeOverviewTool, s.toolHandler.DeliveryFeeOverview)
+
+	planFulfillmentTool := mcp.NewTool("plan_fulfillment",
+		mcp.WithDescription("Compare home delivery with click-and-collect at nearby stores for the current cart: fees, cheapest and earliest slot per option, and a recommendation of which is cheaper or faster this week"),
+		mcp.WithString("postal_code",
+			mcp.Description("Postal code to deliver to and search stores near; defaults to the address_label or default saved address"),
+		),
+		mcp.WithString("address_label",
+			mcp.Description("Label of a saved address to take the postal code from"),
+		),
+		mcp.WithNumber("days",
+			mcp.Description("Number of days from today to compare (default 7)"),
+		),
+		mcp.WithNumber("max_stores",
+			mcp.Description("Number of nearby pickup stores to compare, nearest first (default 3, max 5, 0 for home delivery only)"),
+		),
+	)
+	s.addTool(mcpServer, planFulfillmentTool, s.toolHandler.PlanFulfillment)
 
 	proceedToCheckoutTool := mcp.NewTool("proceed_to_checkout",
-		mcp.WithDescription("Get checkout URL to complete payment"),
+		mcp.WithDescription("Get checkout links to complete payment: a web link and an app link for phones, plus the cart and slot the user should find there"),
+		mcp.WithBoolean("qr_code",
+			mcp.Description("Also return a QR code image of a link, so the user can scan it and pay on their phone"),
+		),
+		mcp.WithString("qr_link",
+			mcp.Description("Link in the QR code: 'app' (default) or 'web'"),
+			mcp.Enum("app", "web"),
+		),
+		mcp.WithString("payment_type",
+			mcp.Description("Payment type to select on the cart before checkout; defaults to 'invoice' for business accounts and the account's default otherwise"),
+			mcp.Enum("card", "invoice", "swish"),
+		),
+	)
+	s.addTool(mcpServer, proceedToCheckoutTool, s.toolHandler.ProceedToCheckout)
+
+	sendOrderSummaryTool := mcp.NewTool("send_order_summary",
+		mcp.WithDescription("Email a summary of the latest order (or the cart) to the household addresses configured on the server, with the booked delivery as a calendar attachment"),
+		mcp.WithString("source",
+			mcp.Description("What to summarize: 'order' (default, the latest or order_code) or 'cart'"),
+			mcp.Enum(SummarySourceOrder, SummarySourceCart),
+		),
+		mcp.WithString("order_code",
+			mcp.Description("Order to summarize instead of the latest"),
+		),
+		mcp.WithArray("recipients",
+			mcp.Description("Send to only these of the configured addresses"),
+			mcp.WithStringItems(),
+		),
+		mcp.WithBoolean("include_calendar",
+			mcp.Description("Attach the booked delivery as an .ics event (default true)"),
+		),
+	)
+	s.addTool(mcpServer, sendOrderSummaryTool, s.toolHandler.SendOrderSummary)
+
+	diagnoseCheckoutTool := mcp.NewTool("diagnose_checkout",
+		mcp.WithDescription("Load the checkout page headlessly and report what blocks it (empty cart, missing phone number, no delivery slot, ...) with a suggested fix for each"),
+	)
+	s.addTool(mcpServer, diagnoseCheckoutTool, s.toolHandler.DiagnoseCheckout)
+
+	listPaymentMethodsTool := mcp.NewTool("list_payment_methods",
+		mcp.WithDescription("List saved cards, invoice eligibility, and the default payment method that will be charged at checkout"),
 	)
-	mcpServer.AddTool(proceedToCheckoutTool, s.toolHandler.ProceedToCheckout)
+	s.addTool(mcpServer, listPaymentMethodsTool, s.toolHandler.ListPaymentMethods)
+
+	if s.features.Enabled(features.ScheduledOrders) {
+		createScheduleTool := mcp.NewTool("create_schedule",
+			mcp.WithDescription("Create a standing order: on a cron schedule, rebuild a draft basket from a list, reserve the cheapest slot in a preferred window, and notify for approval. The cart changes only when the basket is committed, and orders are never placed automatically. Runs at most once an hour"),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("Name of the schedule (e.g., 'Weekly basics')"),
+			),
+			mcp.WithString("cron",
+				mcp.Required(),
+				mcp.Description("Five-field cron expression in server local time (e.g., '0 18 * * 0' for Sundays at 18:00) or @daily/@weekly"),
+			),
+			mcp.WithArray("items",
+				mcp.Required(),
+				mcp.Description("Items the schedule's draft basket is rebuilt from on each run"),
+				mcp.Items(map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"query": map[string]any{
+							"type":        "string",
+							"description": "What to buy (e.g., 'mjölk')",
+						},
+						"quantity": map[string]any{
+							"type":        "number",
+							"description": "Quantity to add (default: 1)",
+						},
+					},
+					"required": []string{"query"},
+				}),
+			),
+			mcp.WithString("postal_code",
+				mcp.Description("Postal code for slot reservation; without it no slot is reserved"),
+			),
+			mcp.WithObject("slot_window",
+				mcp.Description("Preferred delivery window; the cheapest available slot inside it is reserved"),
+				mcp.Properties(slotWindowProperties()),
+			),
+		)
+		s.addTool(mcpServer, createScheduleTool, s.toolHandler.CreateSchedule)
+
+		listSchedulesTool := mcp.NewTool("list_schedules",
+			mcp.WithDescription("List standing order schedules with their next run and the outcome of the last run"),
+		)
+		s.addTool(mcpServer, listSchedulesTool, s.toolHandler.ListSchedules)
+
+		cancelScheduleTool := mcp.NewTool("cancel_schedule",
+			mcp.WithDescription("Delete a standing order schedule"),
+			mcp.WithString("id",
+				mcp.Required(),
+				mcp.Description("Schedule ID from list_schedules"),
+			),
+		)
+		s.addTool(mcpServer, cancelScheduleTool, s.toolHandler.CancelSchedule)
+	}
+
+	if s.features.Enabled(features.SlotAutobook) {
+		configureSlotAutobookTool := mcp.NewTool("configure_slot_autobook",
+			mcp.WithDescription("Configure opt-in auto-booking: around each release of new delivery days, poll for slots and reserve the cheapest one in the preferred window. Call without arguments to see the current preferences and last booking"),
+			mcp.WithBoolean("enabled",
+				mcp.Description("Turn auto-booking on or off"),
+			),
+			mcp.WithString("postal_code",
+				mcp.Description("Postal code to book delivery for"),
+			),
+			mcp.WithString("release_cron",
+				mcp.Description("When Willys releases new delivery days, as a cron expression in server local time, at most every 12 hours (default: '0 0 * * *')"),
+			),
+			mcp.WithNumber("poll_minutes",
+				mcp.Description("How long to keep polling after each release, at most 60 (default: 15)"),
+			),
+			mcp.WithNumber("max_fee",
+				mcp.Description("Highest acceptable delivery fee in kr (0 for no limit)"),
+			),
+			mcp.WithObject("slot_window",
+				mcp.Description("Preferred delivery window"),
+				mcp.Properties(slotWindowProperties()),
+			),
+		)
+		s.addTool(mcpServer, configureSlotAutobookTool, s.toolHandler.ConfigureSlotAutobook)
+	}
+
+	logoutTool := mcp.NewTool("logout",
+		mcp.WithDescription("Log out of Willys and clear the session cookies and stored credentials"),
+	)
+	s.addTool(mcpServer, logoutTool, s.toolHandler.Logout)
+
+	submitVerificationCodeTool := mcp.NewTool("submit_verification_code",
+		mcp.WithDescription("Submit the emailed or SMS verification code Willys asked for during login"),
+		mcp.WithString("code",
+			mcp.Required(),
+			mcp.Description("Verification code from the email or SMS (e.g., '123456')"),
+		),
+	)
+	s.addTool(mcpServer, submitVerificationCodeTool, s.toolHandler.SubmitVerificationCode)
+}
+
+// Admin tools are kept out of the shopping tool list so they can be disabled as a group.
+func (s *Server) registerAdminTools(mcpServer *server.MCPServer) {
+	authStatusTool := mcp.NewTool("admin_auth_status",
+		mcp.WithDescription("Show the authentication state of the Willys session"),
+	)
+	s.addTool(mcpServer, authStatusTool, s.toolHandler.AdminAuthStatus)
+
+	cacheStatsTool := mcp.NewTool("admin_cache_stats",
+		mcp.WithDescription("Show statistics for cached and locally stored data"),
+	)
+	s.addTool(mcpServer, cacheStatsTool, s.toolHandler.AdminCacheStats)
+
+	invalidateCacheTool := mcp.NewTool("admin_invalidate_cache",
+		mcp.WithDescription("Drop cached deliverability, slot, and product detail lookups so the next call asks Willys again. Without arguments the whole cache is cleared"),
+		mcp.WithString("postal_code",
+			mcp.Description("Only drop entries for this postal code"),
+		),
+		mcp.WithString("endpoint",
+			mcp.Description("Only drop entries from this lookup"),
+			mcp.Enum("deliverability", "slots", "product_details"),
+		),
+	)
+	s.addTool(mcpServer, invalidateCacheTool, s.toolHandler.AdminInvalidateCache)
+
+	purgeLocalDataTool := mcp.NewTool("admin_purge_local_data",
+		mcp.WithDescription("Permanently delete all locally stored data (pick history, lists, watches, snapshots)"),
+		mcp.WithBoolean("confirm",
+			mcp.Required(),
+			mcp.Description("Must be true to confirm the purge"),
+		),
+	)
+	s.addTool(mcpServer, purgeLocalDataTool, s.toolHandler.PurgeLocalData)
+
+	apiHealthReportTool := mcp.NewTool("api_health_report",
+		mcp.WithDescription("Report schema drift seen in Willys API responses (unknown, missing, or mistyped fields)"),
+	)
+	s.addTool(mcpServer, apiHealthReportTool, s.toolHandler.APIHealthReport)
+
+	reloadConfigTool := mcp.NewTool("admin_reload_config",
+		mcp.WithDescription("Re-read configuration from the environment and .env file"),
+	)
+	s.addTool(mcpServer, reloadConfigTool, s.toolHandler.AdminReloadConfig)
+
+	listJobsTool := mcp.NewTool("admin_list_background_jobs",
+		mcp.WithDescription("List the background jobs (scheduler, slot auto-booking) with their state, run counts, last error, and next run"),
+	)
+	s.addTool(mcpServer, listJobsTool, s.toolHandler.AdminListBackgroundJobs)
 }
 
 func (s *Server) Start() error {
+	if s.logs != nil {
+		defer s.logs.install()()
+	}
 	log.Println("Starting Willys MCP server...")
 
-	if err := server.ServeStdio(s.mcpServer); err != nil {
-		return fmt.Errorf("failed to start MCP server: %w", err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := s.toolHandler.jobs
+	if s.scheduler {
+		if err := jobs.Start(ctx, s.schedulerJob()); err != nil {
+			return err
+		}
+	}
+	if s.autobook {
+		if err := jobs.Start(ctx, s.autobookJob()); err != nil {
+			return err
+		}
+	}
+
+	if s.grpcAddr != "" {
+		stopGRPC, err := s.serveGRPC()
+		if err != nil {
+			return fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+		defer stopGRPC()
+	}
+
+	var err error
+	if s.httpAddr != "" {
+		err = s.serveHTTP(ctx)
+	} else {
+		err = server.ServeStdio(s.mcpServer)
 	}
 
+	// SIGINT and SIGTERM stop the transport by cancelling its context; let checkout
+	// operations that are already running finish before the process exits.
+	cancel()
+	if running := s.toolHandler.critical.drain(shutdownGrace); len(running) > 0 {
+		log.Printf("Shutdown: gave up waiting for %s; check the cart's delivery slot and release it with release_delivery_slot if needed", strings.Join(running, ", "))
+	}
+	if running := jobs.StopAll(shutdownGrace); len(running) > 0 {
+		log.Printf("Shutdown: background jobs still running: %s", strings.Join(running, ", "))
+	}
+
+	if !errIsShutdown(err) {
+		return fmt.Errorf("failed to start MCP server: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,213 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// sessionIdleTimeout is how long a conversation's context is kept after its last tool
+// call.
+const sessionIdleTimeout = 24 * time.Hour
+
+type (
+	// SessionContext is state scoped to one MCP session, so clients sharing a server
+	// don't see each other's address, budget, diet, or active basket. Empty fields fall
+	// back to the server-wide configuration.
+	SessionContext struct {
+		AddressLabel string        `json:"address_label,omitempty"`
+		WeeklyBudget *willys.Money `json:"weekly_budget,omitempty"`
+		Diet         []string      `json:"diet,omitempty"`
+		Basket       string        `json:"basket,omitempty"`
+		LastUsed     time.Time     `json:"last_used"`
+	}
+
+	sessionStore struct {
+		mu       sync.Mutex
+		sessions map[string]*SessionContext
+		now      func() time.Time
+	}
+)
+
+func newSessionStore() *sessionStore {
+	return &sessionStore{
+		sessions: make(map[string]*SessionContext),
+		now:      time.Now,
+	}
+}
+
+// update runs fn on the session's context, creating it if needed, and drops sessions
+// that have been idle for longer than sessionIdleTimeout.
+func (s *sessionStore) update(sessionID string, fn func(*SessionContext)) SessionContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for id, sc := range s.sessions {
+		if id != sessionID && now.Sub(sc.LastUsed) > sessionIdleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+
+	sc, ok := s.sessions[sessionID]
+	if !ok {
+		sc = &SessionContext{}
+		s.sessions[sessionID] = sc
+	}
+	sc.LastUsed = now
+	if fn != nil {
+		fn(sc)
+	}
+
+	snapshot := *sc
+	snapshot.Diet = append([]string(nil), sc.Diet...)
+	return snapshot
+}
+
+// each runs fn on every live session, e.g. to forget a deleted basket.
+func (s *sessionStore) each(fn func(*SessionContext)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sc := range s.sessions {
+		fn(sc)
+	}
+}
+
+func (s *sessionStore) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]*SessionContext)
+}
+
+// session returns a copy of the calling session's context.
+func (h *ToolHandler) session(ctx context.Context) SessionContext {
+	return h.sessions.update(sessionIDFromContext(ctx), nil)
+}
+
+func (h *ToolHandler) updateSession(ctx context.Context, fn func(*SessionContext)) SessionContext {
+	return h.sessions.update(sessionIDFromContext(ctx), fn)
+}
+
+// forgetBasket switches every session that had name active back to the live cart.
+func (h *ToolHandler) forgetBasket(name string) {
+	h.sessions.each(func(sc *SessionContext) {
+		if sc.Basket == name {
+			sc.Basket = ""
+		}
+	})
+}
+
+// dietFor returns the session's dietary profile, or the configured one.
+func (h *ToolHandler) dietFor(ctx context.Context) []string {
+	if diet := h.session(ctx).Diet; len(diet) > 0 {
+		return diet
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.diet
+}
+
+// budgetFor returns the session's weekly budget, or the configured one.
+func (h *ToolHandler) budgetFor(ctx context.Context) willys.Money {
+	if budget := h.session(ctx).WeeklyBudget; budget != nil {
+		return *budget
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.weeklyBudget
+}
+
+func (h *ToolHandler) SetSessionContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	args := request.GetArguments()
+
+	var label string
+	if raw, ok := args["address_label"]; ok && raw != "" {
+		var err error
+		if label, err = normalizeLabel(fmt.Sprint(raw)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		if _, err := h.getAddress(label); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	var budget *willys.Money
+	if _, ok := args["weekly_budget"]; ok {
+		kronor := mcp.ParseFloat64(request, "weekly_budget", 0)
+		if kronor < 0 {
+			return mcp.NewToolResultError(willys.NewValidationError("weekly_budget", "must not be negative").Error()), nil
+		}
+		m := willys.MoneyFromFloat(kronor)
+		budget = &m
+	}
+
+	_, hasDiet := args["diet"]
+	diet := getStringSlice(args, "diet")
+	for i := range diet {
+		diet[i] = normalizeQuery(diet[i])
+	}
+	if err := willys.ValidateDiet(diet); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	clear := mcp.ParseBoolean(request, "clear", false)
+	sc := h.updateSession(ctx, func(sc *SessionContext) {
+		if clear {
+			*sc = SessionContext{LastUsed: sc.LastUsed}
+		}
+		if label != "" {
+			sc.AddressLabel = label
+		}
+		if budget != nil {
+			sc.WeeklyBudget = budget
+			if budget.Ore == 0 {
+				sc.WeeklyBudget = nil
+			}
+		}
+		if hasDiet {
+			sc.Diet = diet
+		}
+	})
+
+	return mcp.NewToolResultJSON(h.sessionView(ctx, sc))
+}
+
+func (h *ToolHandler) ViewSessionContext(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultJSON(h.sessionView(ctx, h.session(ctx)))
+}
+
+// sessionView shows the session's own settings next to the values tools will use.
+func (h *ToolHandler) sessionView(ctx context.Context, sc SessionContext) map[string]any {
+	effective := map[string]any{
+		"diet":          h.dietFor(ctx),
+		"weekly_budget": h.budgetFor(ctx),
+		"basket":        liveBasket,
+	}
+	if sc.Basket != "" {
+		effective["basket"] = sc.Basket
+	}
+	if label := sc.AddressLabel; label != "" {
+		effective["address_label"] = label
+	} else if addresses, err := h.loadAddresses(); err == nil {
+		for _, saved := range addresses {
+			if saved.Default {
+				effective["address_label"] = saved.Label
+			}
+		}
+	}
+
+	diet := append([]string(nil), sc.Diet...)
+	sort.Strings(diet)
+	sc.Diet = diet
+
+	return map[string]any{
+		"session_id": sessionIDFromContext(ctx),
+		"session":    sc,
+		"effective":  effective,
+	}
+}
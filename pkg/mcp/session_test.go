@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type testSession string
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) SessionID() string                                   { return string(s) }
+
+func sessionContext(id string) context.Context {
+	return server.NewMCPServer("test", "0").WithContext(context.Background(), testSession(id))
+}
+
+func TestSessionContextIsolation(t *testing.T) {
+	h := NewToolHandler(nil)
+	h.diet = []string{willys.DietVegetarian}
+	alice, bob := sessionContext("alice"), sessionContext("bob")
+
+	home := map[string]any{
+		"first_name": "Test", "last_name": "User", "address": "Drottninggatan 1",
+		"postal_code": "11151", "city": "Stockholm",
+	}
+	cabin := map[string]any{
+		"first_name": "Test", "last_name": "User", "address": "Stugvägen 3",
+		"postal_code": "76291", "city": "Rimbo",
+	}
+	h.SaveAddress(alice, toolRequest(map[string]any{"label": "home", "address": home}))
+	h.SaveAddress(alice, toolRequest(map[string]any{"label": "cabin", "address": cabin}))
+
+	if result, _ := h.SetSessionContext(alice, toolRequest(map[string]any{"diet": []any{"keto"}})); !result.IsError {
+		t.Error("Expected unknown diet to be rejected")
+	}
+	if result, _ := h.SetSessionContext(alice, toolRequest(map[string]any{"address_label": "parents"})); !result.IsError {
+		t.Error("Expected unknown address label to be rejected")
+	}
+	result, err := h.SetSessionContext(alice, toolRequest(map[string]any{
+		"address_label": "cabin",
+		"weekly_budget": 900.0,
+		"diet":          []any{"vegan"},
+	}))
+	if err != nil || result.IsError {
+		t.Fatalf("SetSessionContext failed: %v %+v", err, result)
+	}
+	h.CreateBasket(alice, toolRequest(map[string]any{"name": "midsommar"}))
+
+	if address, _ := h.resolveAddress(alice, toolRequest(nil)); address.City != "Rimbo" {
+		t.Errorf("Expected alice's session address, got %+v", address)
+	}
+	if address, _ := h.resolveAddress(bob, toolRequest(nil)); address.City != "Stockholm" {
+		t.Errorf("Expected bob to get the default address, got %+v", address)
+	}
+
+	if diet := h.dietFor(alice); len(diet) != 1 || diet[0] != willys.DietVegan {
+		t.Errorf("Expected alice's diet, got %v", diet)
+	}
+	if diet := h.dietFor(bob); len(diet) != 1 || diet[0] != willys.DietVegetarian {
+		t.Errorf("Expected bob to get the configured diet, got %v", diet)
+	}
+	if budget := h.budgetFor(alice); budget.Ore != 90000 {
+		t.Errorf("Expected alice's budget of 900 kr, got %v", budget)
+	}
+	if budget := h.budgetFor(bob); !budget.IsZero() {
+		t.Errorf("Expected no budget for bob, got %v", budget)
+	}
+	if h.activeBasket(alice) != "midsommar" || h.activeBasket(bob) != "" {
+		t.Errorf("Expected only alice to have the draft active, got %q and %q", h.activeBasket(alice), h.activeBasket(bob))
+	}
+
+	h.DeleteBasket(bob, toolRequest(map[string]any{"name": "midsommar"}))
+	if h.activeBasket(alice) != "" {
+		t.Error("Expected deleting a basket to deactivate it in every session")
+	}
+
+	h.SetSessionContext(alice, toolRequest(map[string]any{"clear": true}))
+	if sc := h.session(alice); sc.AddressLabel != "" || sc.WeeklyBudget != nil || len(sc.Diet) != 0 {
+		t.Errorf("Expected clear to reset the session, got %+v", sc)
+	}
+}
+
+func TestSessionIdleExpiry(t *testing.T) {
+	s := newSessionStore()
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	s.update("old", func(sc *SessionContext) { sc.Basket = "draft" })
+	now = now.Add(sessionIdleTimeout + time.Minute)
+	s.update("new", nil)
+
+	if _, ok := s.sessions["old"]; ok {
+		t.Error("Expected idle session to be dropped")
+	}
+	if sc := s.update("old", nil); sc.Basket != "" {
+		t.Errorf("Expected a fresh session, got %+v", sc)
+	}
+}
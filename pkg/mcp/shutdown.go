@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// criticalSectionTimeout bounds a non-interruptible operation now that it no longer
+	// stops when the tool call is cancelled.
+	criticalSectionTimeout = 2 * time.Minute
+
+	// shutdownGrace is how long Start waits for non-interruptible operations to finish
+	// once the MCP transport has stopped.
+	shutdownGrace = 30 * time.Second
+)
+
+var errShuttingDown = errors.New("server is shutting down, try again after it restarts")
+
+// criticalSections tracks operations that leave the Willys account half-configured if
+// cut off midway, such as setting the delivery address and then the slot. They run to
+// completion even when the server is asked to stop, and no new ones start after that.
+type criticalSections struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	active  map[uint64]string
+	next    uint64
+	closing bool
+}
+
+func newCriticalSections() *criticalSections {
+	return &criticalSections{active: make(map[uint64]string)}
+}
+
+func (c *criticalSections) enter(name string) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closing {
+		return 0, errShuttingDown
+	}
+	c.next++
+	c.active[c.next] = name
+	c.wg.Add(1)
+	return c.next, nil
+}
+
+// leave ends a section and, during shutdown, logs how it ended.
+func (c *criticalSections) leave(id uint64, err error) {
+	c.mu.Lock()
+	name := c.active[id]
+	delete(c.active, id)
+	closing := c.closing
+	c.mu.Unlock()
+	c.wg.Done()
+
+	if closing {
+		if err != nil {
+			log.Printf("Shutdown: %s failed: %v", name, err)
+		} else {
+			log.Printf("Shutdown: %s finished", name)
+		}
+	}
+}
+
+// drain stops new sections and waits up to timeout for running ones. It returns the
+// sections still running when the timeout expires.
+func (c *criticalSections) drain(timeout time.Duration) []string {
+	c.mu.Lock()
+	c.closing = true
+	if n := len(c.active); n > 0 {
+		log.Printf("Shutdown: waiting for %d in-flight operation(s)", n)
+	}
+	c.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var running []string
+	for _, name := range c.active {
+		running = append(running, name)
+	}
+	sort.Strings(running)
+	return running
+}
+
+// nonInterruptible runs fn as a critical section, on a context that isn't cancelled with
+// the tool call or the transport, so a restart can't stop it between two upstream
+// requests.
+func (h *ToolHandler) nonInterruptible(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	id, err := h.critical.enter(name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), criticalSectionTimeout)
+	defer cancel()
+
+	err = fn(ctx)
+	h.critical.leave(id, err)
+	return err
+}
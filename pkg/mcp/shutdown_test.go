@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNonInterruptibleSurvivesShutdown(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started, release := make(chan struct{}), make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- h.nonInterruptible(ctx, "select_delivery_time", func(ctx context.Context) error {
+			close(started)
+			<-release
+			return ctx.Err()
+		})
+	}()
+	<-started
+
+	// The transport cancels the tool call, then Start drains.
+	cancel()
+	drained := make(chan []string, 1)
+	go func() { drained <- h.critical.drain(time.Second) }()
+
+	time.Sleep(10 * time.Millisecond)
+	if err := h.nonInterruptible(context.Background(), "late", func(context.Context) error { return nil }); !errors.Is(err, errShuttingDown) {
+		t.Errorf("Expected new sections to be refused while draining, got %v", err)
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("Expected the section to finish despite the cancelled call, got %v", err)
+	}
+	if running := <-drained; len(running) != 0 {
+		t.Errorf("Expected nothing left running, got %v", running)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	c := newCriticalSections()
+	id, _ := c.enter("slot auto-booking")
+	defer c.leave(id, nil)
+
+	if running := c.drain(10 * time.Millisecond); len(running) != 1 || running[0] != "slot auto-booking" {
+		t.Errorf("Expected the stuck section to be reported, got %v", running)
+	}
+}
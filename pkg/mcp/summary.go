@@ -0,0 +1,144 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/export"
+	"github.com/effati/willys-mcp/internal/mail"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	SummarySourceOrder = "order"
+	SummarySourceCart  = "cart"
+)
+
+// orderAsCart lets an order be rendered like a cart.
+func orderAsCart(order willys.Order) *willys.CartSummary {
+	cart := &willys.CartSummary{FinalTotal: order.Total}
+	for _, entry := range order.Entries {
+		cart.Items = append(cart.Items, willys.CartItem{
+			ProductCode: entry.Code,
+			Name:        entry.Name,
+			Quantity:    entry.Quantity,
+			Price:       entry.Price,
+			TotalPrice:  entry.Price.Mul(entry.Quantity),
+		})
+		cart.ItemCount += entry.Quantity
+	}
+	return cart
+}
+
+// summaryRecipients narrows the configured household addresses to requested ones; the
+// tool never mails anyone else.
+func summaryRecipients(configured, requested []string) ([]string, error) {
+	if len(configured) == 0 {
+		return nil, errors.New("no recipients configured; set WILLYS_SUMMARY_RECIPIENTS")
+	}
+	if len(requested) == 0 {
+		return configured, nil
+	}
+	for _, r := range requested {
+		if !slices.Contains(configured, r) {
+			return nil, fmt.Errorf("%s is not in WILLYS_SUMMARY_RECIPIENTS", r)
+		}
+	}
+	return requested, nil
+}
+
+func (h *ToolHandler) SendOrderSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	h.mu.RLock()
+	mailer, from, configured := h.mailer, h.mailFrom, h.summaryRecipients
+	h.mu.RUnlock()
+
+	if mailer == nil {
+		return mcp.NewToolResultError("email is not configured; set WILLYS_SMTP_ADDR or WILLYS_SENDMAIL_PATH"), nil
+	}
+	if from == "" {
+		return mcp.NewToolResultError("no sender address configured; set WILLYS_MAIL_FROM"), nil
+	}
+	recipients, err := summaryRecipients(configured, getStringSlice(request.GetArguments(), "recipients"))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	now := time.Now()
+	doc := export.Document{Generated: now}
+	orderRef := ""
+	switch source := mcp.ParseString(request, "source", SummarySourceOrder); source {
+	case SummarySourceOrder:
+		orders, err := h.client.GetOrderHistory(ctx)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get order history: %v", err)), nil
+		}
+		code := mcp.ParseString(request, "order_code", "")
+		idx := slices.IndexFunc(orders, func(o willys.Order) bool { return code == "" || o.Code == code })
+		if idx < 0 {
+			if code == "" {
+				return mcp.NewToolResultError("no orders yet; use source 'cart' to send the cart instead"), nil
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("order %s not found", code)), nil
+		}
+		order := orders[idx]
+		doc.Title = fmt.Sprintf("Willys order %s (%s)", order.Code, order.PlacedAt.Local().Format("2006-01-02"))
+		doc.Cart = orderAsCart(order)
+		orderRef = order.Code
+	case SummarySourceCart:
+		cart, _, err := h.currentCart(ctx, false)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
+		}
+		doc.Title = "Willys cart " + now.Format("2006-01-02")
+		doc.Cart = cart
+		orderRef = cart.GUID
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("unknown source %q (use order or cart)", source)), nil
+	}
+
+	msg := mail.Message{
+		From:    from,
+		To:      recipients,
+		Subject: doc.Title,
+		Text:    export.Markdown(doc),
+	}
+
+	booked, err := h.latestDelivery()
+	if err != nil {
+		log.Printf("Sending summary without delivery: %v", err)
+	}
+	if booked != nil {
+		slot := booked.Delivery.TimeSlot
+		msg.Text += fmt.Sprintf("\nDelivery: %s %s-%s\n", slot.Date, slot.StartTime, slot.EndTime)
+		if mcp.ParseBoolean(request, "include_calendar", true) {
+			if event, err := deliveryEvent(booked, orderRef); err == nil {
+				msg.Attachments = append(msg.Attachments, mail.Attachment{
+					Name:        "delivery.ics",
+					ContentType: "text/calendar; charset=utf-8; method=PUBLISH",
+					Data:        []byte(export.ICal([]export.Event{event}, now)),
+				})
+			}
+		}
+	}
+
+	if err := mailer.Send(msg); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to send summary: %v", err)), nil
+	}
+
+	attachments := []string{}
+	for _, a := range msg.Attachments {
+		attachments = append(attachments, a.Name)
+	}
+	return mcp.NewToolResultJSON(map[string]any{
+		"sent_to":     recipients,
+		"subject":     msg.Subject,
+		"items":       len(doc.Cart.Items),
+		"total":       doc.Cart.FinalTotal,
+		"attachments": attachments,
+	})
+}
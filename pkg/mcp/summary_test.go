@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/mail"
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+type recordingSender struct {
+	sent []mail.Message
+}
+
+func (s *recordingSender) Send(msg mail.Message) error {
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+type ordersClient struct {
+	willys.WillysAPI
+	orders []willys.Order
+}
+
+func (c *ordersClient) GetOrderHistory(ctx context.Context) ([]willys.Order, error) {
+	return c.orders, nil
+}
+
+func TestSendOrderSummary(t *testing.T) {
+	client := &ordersClient{orders: []willys.Order{
+		{Code: "1002", PlacedAt: time.Date(2026, 10, 15, 18, 0, 0, 0, time.UTC), Total: willys.SEK(10980),
+			Entries: []willys.OrderEntry{{Code: "101", Name: "Smör", Quantity: 2, Price: willys.SEK(5490)}}},
+		{Code: "1001", PlacedAt: time.Date(2026, 10, 8, 18, 0, 0, 0, time.UTC), Total: willys.SEK(2500)},
+	}}
+	h := NewToolHandler(client)
+	ctx := context.Background()
+
+	result, _ := h.SendOrderSummary(ctx, toolRequest(nil))
+	if !result.IsError {
+		t.Error("Expected an error without mail configured")
+	}
+
+	sender := &recordingSender{}
+	h.mailer, h.mailFrom = sender, "willys@example.com"
+	h.summaryRecipients = []string{"anna@example.com", "erik@example.com"}
+
+	result, _ = h.SendOrderSummary(ctx, toolRequest(nil))
+	if result.IsError || len(sender.sent) != 1 {
+		t.Fatalf("Expected the summary to be sent, got %+v", result)
+	}
+	msg := sender.sent[0]
+	if len(msg.To) != 2 || !strings.Contains(msg.Subject, "1002") {
+		t.Errorf("Expected the latest order to everyone, got %q to %v", msg.Subject, msg.To)
+	}
+	if !strings.Contains(msg.Text, "| Smör | 2 | ") || len(msg.Attachments) != 0 {
+		t.Errorf("Unexpected summary without a booked delivery:\n%s", msg.Text)
+	}
+
+	result, _ = h.SendOrderSummary(ctx, toolRequest(map[string]any{"order_code": "1001", "recipients": []any{"erik@example.com"}}))
+	if result.IsError || sender.sent[1].To[0] != "erik@example.com" || !strings.Contains(sender.sent[1].Subject, "1001") {
+		t.Errorf("Expected order 1001 to erik only, got %+v", sender.sent[1])
+	}
+
+	result, _ = h.SendOrderSummary(ctx, toolRequest(map[string]any{"recipients": []any{"stranger@example.com"}}))
+	if !result.IsError {
+		t.Error("Expected an unconfigured recipient to be rejected")
+	}
+}
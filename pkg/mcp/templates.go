@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type (
+	// OrderTemplate is a reusable order, stored under its lower-cased name. Item quantities
+	// may scale with a single parameter such as the number of guests.
+	OrderTemplate struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Parameter   string         `json:"parameter,omitempty"` // e.g. "people"
+		Default     float64        `json:"default,omitempty"`   // parameter value when none is given
+		Items       []TemplateItem `json:"items"`
+		UpdatedAt   time.Time      `json:"updatedAt"`
+	}
+
+	// TemplateItem resolves to Quantity + PerUnit*value, rounded up; "chips" with
+	// per_unit 0.5 buys one bag per two guests.
+	TemplateItem struct {
+		Query    string  `json:"query"`
+		Quantity float64 `json:"quantity,omitempty"`
+		PerUnit  float64 `json:"perUnit,omitempty"`
+	}
+)
+
+func (t OrderTemplate) resolve(value float64) []listItem {
+	items := make([]listItem, 0, len(t.Items))
+	for _, item := range t.Items {
+		quantity := int(math.Ceil(item.Quantity + item.PerUnit*value - 1e-9))
+		if quantity <= 0 {
+			continue
+		}
+		items = append(items, listItem{Query: item.Query, Quantity: quantity})
+	}
+	return items
+}
+
+func parseTemplateItems(raw any) ([]TemplateItem, error) {
+	rawItems, ok := raw.([]any)
+	if !ok {
+		return nil, nil
+	}
+
+	items := make([]TemplateItem, 0, len(rawItems))
+	for _, r := range rawItems {
+		data, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		item := TemplateItem{Query: getStringField(data, "query")}
+		if item.Query == "" {
+			return nil, willys.NewValidationError("items", "every item needs a query")
+		}
+		item.Quantity, _ = data["quantity"].(float64)
+		item.PerUnit, _ = data["per_unit"].(float64)
+		if item.Quantity < 0 || item.PerUnit < 0 {
+			return nil, willys.NewValidationError("items", fmt.Sprintf("quantities for %q must not be negative", item.Query))
+		}
+		if item.Quantity == 0 && item.PerUnit == 0 {
+			item.Quantity = 1
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (h *ToolHandler) loadTemplates() ([]OrderTemplate, error) {
+	entries, err := h.store.List(store.BucketTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]OrderTemplate, 0, len(entries))
+	for key, data := range entries {
+		var t OrderTemplate
+		if err := json.Unmarshal(data, &t); err != nil {
+			log.Printf("Skipping unreadable template %s: %v", key, err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// priceListItems picks a product for each item without touching the cart, for previews.
+func (h *ToolHandler) priceListItems(ctx context.Context, items []listItem, policy willys.PickPolicy) []map[string]any {
+	results := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		result := map[string]any{
+			"query":    item.Query,
+			"quantity": item.Quantity,
+		}
+		if item.Member != "" {
+			result["member"] = item.Member
+		}
+		products, err := h.client.SearchProducts(ctx, item.Query, 0, listSearchSize, nil)
+		if err != nil {
+			result["error"] = err.Error()
+		} else {
+			result["pick"] = h.pickProduct(ctx, item.Query, products, policy)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// estimatedTotal sums current prices of the picked products in list results.
+func estimatedTotal(results []map[string]any) willys.Money {
+	total := willys.SEK(0)
+	for _, result := range results {
+		pick, ok := result["pick"].(willys.PickResult)
+		if !ok || pick.Product == nil {
+			continue
+		}
+		total = total.Add(pick.Product.PriceValue.Mul(result["quantity"].(int)))
+	}
+	return total
+}
+
+func (h *ToolHandler) SaveTemplate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	name := mcp.ParseString(request, "name", "")
+	if normalizeQuery(name) == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	items, err := parseTemplateItems(request.GetArguments()["items"])
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if len(items) == 0 {
+		return mcp.NewToolResultError("items parameter is required"), nil
+	}
+
+	t := OrderTemplate{
+		Name:        name,
+		Description: mcp.ParseString(request, "description", ""),
+		Parameter:   mcp.ParseString(request, "parameter", ""),
+		Default:     mcp.ParseFloat64(request, "default", 0),
+		Items:       items,
+		UpdatedAt:   time.Now(),
+	}
+	if t.Default < 0 {
+		return mcp.NewToolResultError("default must not be negative"), nil
+	}
+	for _, item := range items {
+		if item.PerUnit > 0 && t.Parameter == "" {
+			return mcp.NewToolResultError(fmt.Sprintf("%q scales per unit, so the template needs a parameter (e.g., 'people')", item.Query)), nil
+		}
+	}
+
+	if err := store.PutJSON(h.store, store.BucketTemplates, normalizeQuery(name), t); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to save template: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{"template": t})
+}
+
+func (h *ToolHandler) ListTemplates(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	templates, err := h.loadTemplates()
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to list templates: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+func (h *ToolHandler) DeleteTemplate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	key := normalizeQuery(mcp.ParseString(request, "name", ""))
+	if key == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	if _, err := h.store.Get(store.BucketTemplates, key); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no template named %q", key)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read template: %v", err)), nil
+	}
+
+	if err := h.store.Delete(store.BucketTemplates, key); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to delete template: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"deleted": true,
+		"name":    key,
+	})
+}
+
+func (h *ToolHandler) ApplyTemplate(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	key := normalizeQuery(mcp.ParseString(request, "name", ""))
+	if key == "" {
+		return mcp.NewToolResultError("name parameter is required"), nil
+	}
+
+	var t OrderTemplate
+	if err := store.GetJSON(h.store, store.BucketTemplates, key, &t); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return mcp.NewToolResultError(fmt.Sprintf("no template named %q", key)), nil
+		}
+		return mcp.NewToolResultError(fmt.Sprintf("failed to read template: %v", err)), nil
+	}
+
+	value := mcp.ParseFloat64(request, "value", t.Default)
+	if value < 0 {
+		return mcp.NewToolResultError("value must not be negative"), nil
+	}
+	dryRun := mcp.ParseBoolean(request, "dry_run", false)
+
+	policy, err := h.parsePickPolicy(request)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid policy: %v", err)), nil
+	}
+
+	items := t.resolve(value)
+	if len(items) == 0 {
+		return mcp.NewToolResultError(fmt.Sprintf("template %q resolves to no items for %s=%g", t.Name, t.Parameter, value)), nil
+	}
+
+	if err := h.consumeQuota(ctx, quotaSearch, len(items)); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	result := map[string]any{
+		"template": t.Name,
+		"dry_run":  dryRun,
+	}
+	if t.Parameter != "" {
+		result["parameter"] = map[string]any{"name": t.Parameter, "value": value}
+	}
+
+	var results []map[string]any
+	if dryRun {
+		results = h.priceListItems(ctx, items, policy)
+	} else {
+		if err := h.consumeQuota(ctx, quotaCartMutation, len(items)); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		var added int
+		results, added = h.addListItems(ctx, items, policy, "template:"+t.Name, h.activeBasket(ctx))
+		result["added"] = added
+	}
+	result["results"] = results
+	result["estimated_total"] = estimatedTotal(results)
+
+	return mcp.NewToolResultJSON(result)
+}
@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestOrderTemplateResolve(t *testing.T) {
+	party := OrderTemplate{
+		Parameter: "people",
+		Items: []TemplateItem{
+			{Query: "chips", PerUnit: 0.5},
+			{Query: "dipp", Quantity: 1},
+			{Query: "läsk", Quantity: 2, PerUnit: 0.3},
+		},
+	}
+
+	expected := []listItem{{Query: "chips", Quantity: 5}, {Query: "dipp", Quantity: 1}, {Query: "läsk", Quantity: 5}}
+	if items := party.resolve(10); !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, items)
+	}
+
+	expected = []listItem{{Query: "dipp", Quantity: 1}, {Query: "läsk", Quantity: 2}}
+	if items := party.resolve(0); !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected per-unit items to drop out at 0, got %+v", items)
+	}
+}
+
+func TestSaveTemplate(t *testing.T) {
+	h := NewToolHandler(nil)
+	ctx := context.Background()
+
+	perUnit := []any{map[string]any{"query": "chips", "per_unit": 0.5}}
+	if result, _ := h.SaveTemplate(ctx, toolRequest(map[string]any{"name": "Party", "items": perUnit})); !result.IsError {
+		t.Error("Expected per-unit items without a parameter to be rejected")
+	}
+
+	result, err := h.SaveTemplate(ctx, toolRequest(map[string]any{"name": "Party", "parameter": "people", "default": 4.0, "items": perUnit}))
+	if err != nil || result.IsError {
+		t.Fatalf("SaveTemplate failed: %v %+v", err, result)
+	}
+
+	templates, _ := h.loadTemplates()
+	if len(templates) != 1 || templates[0].Name != "Party" || templates[0].Default != 4 {
+		t.Errorf("Expected the party template, got %+v", templates)
+	}
+
+	if result, _ := h.DeleteTemplate(ctx, toolRequest(map[string]any{"name": "party"})); result.IsError {
+		t.Errorf("Expected delete by lower-cased name to succeed: %+v", result)
+	}
+}
@@ -2,19 +2,81 @@ package mcp
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
+	"path/filepath"
 	"strings"
-
+	"sync"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/config"
+	"github.com/effati/willys-mcp/internal/export"
+	"github.com/effati/willys-mcp/internal/mail"
+	"github.com/effati/willys-mcp/internal/schedule"
+	"github.com/effati/willys-mcp/internal/semantic"
+	"github.com/effati/willys-mcp/internal/store"
 	"github.com/effati/willys-mcp/internal/willys"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type ToolHandler struct {
-	client willys.WillysAPI
+	client       willys.WillysAPI
+	store        store.Store
+	configLoader func() (*config.Config, error)
+
+	mu                sync.RWMutex
+	pickPolicy        willys.PickPolicy
+	outputDetail      string
+	ownBrand          string
+	diet              []string     // configured default; a session can override it
+	weeklyBudget      willys.Money // configured default; a session can override it
+	priceAlertPercent float64      // price rise since the last purchase that warns
+	appLinkBase       string       // where proceed_to_checkout points the app link
+	mailer            mail.Sender  // nil unless mail is configured
+	mailFrom          string
+	summaryRecipients []string
+	sessions          *sessionStore
+	critical          *criticalSections
+	jobs              *schedule.Manager
+	pickHistory       *pickHistory
+	quotas            *quotaTracker
+	metrics           *toolMetrics
+	cursors           *cursorStore // the rest of paged results
+	exportDir         string       // where export_plan saves PDFs
+
+	// schedulesMu keeps cancel_schedule from interleaving with saving a schedule after a
+	// run, which would bring the cancelled schedule back
+	schedulesMu sync.Mutex
+	// membersMu serializes read-modify-writes of the member assignments, so concurrent
+	// adds don't drop each other's tags
+	membersMu sync.Mutex
+
+	// matcher finds products for list items that plain search misses; nil when disabled
+	matcher *semantic.Matcher
 }
 
 func NewToolHandler(client willys.WillysAPI) *ToolHandler {
-	return &ToolHandler{client: client}
+	h := &ToolHandler{
+		client:            client,
+		pickPolicy:        willys.DefaultPickPolicy(),
+		outputDetail:      willys.OutputDetailFull,
+		ownBrand:          willys.OwnBrandOff,
+		priceAlertPercent: willys.DefaultPriceAlertPercent,
+		quotas:            newQuotaTracker(DefaultQuotas()),
+		metrics:           newToolMetrics(),
+		cursors:           newCursorStore(),
+		sessions:          newSessionStore(),
+		critical:          newCriticalSections(),
+		jobs:              schedule.NewManager(),
+		exportDir:         filepath.Join(store.DefaultDataDir(), "exports"),
+	}
+	h.setStore(store.NewMemory())
+	return h
+}
+
+func (h *ToolHandler) setStore(s store.Store) {
+	h.store = s
+	h.pickHistory = newPickHistory(s)
 }
 
 func (h *ToolHandler) SearchGroceries(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -23,6 +85,15 @@ func (h *ToolHandler) SearchGroceries(ctx context.Context, request mcp.CallToolR
 		return mcp.NewToolResultError("query parameter is required"), nil
 	}
 
+	if err := h.consumeQuota(ctx, quotaSearch, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	detail, err := h.parseOutputDetail(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	page := mcp.ParseInt(request, "page", 0)
 	size := mcp.ParseInt(request, "size", 30)
 
@@ -52,17 +123,42 @@ func (h *ToolHandler) SearchGroceries(ctx context.Context, request mcp.CallToolR
 		if sb, ok := prefsData["sort_by"].(string); ok {
 			prefs.SortBy = sb
 		}
+		if ms, ok := prefsData["min_sustainability"].(float64); ok {
+			prefs.MinSustainability = int(ms)
+		}
+		if ob, ok := prefsData["own_brand"].(string); ok {
+			prefs.OwnBrand = ob
+		}
+	}
+	if prefs, err = h.applyOwnBrand(prefs); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
-	products, err := h.client.SearchProducts(ctx, query, page, size, prefs)
+	var result *willys.SearchResult
+	if mcp.ParseBoolean(request, "include_facets", false) {
+		result, err = h.client.SearchWithFacets(ctx, query, page, size, prefs)
+	} else {
+		result = &willys.SearchResult{}
+		result.Products, err = h.client.SearchProducts(ctx, query, page, size, prefs)
+	}
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
 	}
+	products := result.Products
 
-	return mcp.NewToolResultJSON(map[string]any{
-		"products": products,
+	projected, err := projectList(projectProducts(products, detail), getStringSlice(request.GetArguments(), "fields"))
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to select fields: %v", err)), nil
+	}
+
+	response := map[string]any{
+		"products": projected,
 		"count":    len(products),
-	})
+	}
+	if result.Facets != nil {
+		response["facets"] = result.Facets
+	}
+	return mcp.NewToolResultJSON(response)
 }
 
 func (h *ToolHandler) AddToCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -72,22 +168,88 @@ func (h *ToolHandler) AddToCart(ctx context.Context, request mcp.CallToolRequest
 	}
 
 	quantity := mcp.ParseInt(request, "quantity", 1)
+	source := mcp.ParseString(request, "source", "add_to_cart")
+	member, err := h.resolveMember(mcp.ParseString(request, "member", ""))
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	if name := h.activeBasket(ctx); name != "" {
+		basket, err := h.addToBasket(name, BasketItem{Code: productCode, Quantity: quantity, Source: source, Member: member})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to add to basket: %v", err)), nil
+		}
+		return mcp.NewToolResultJSON(basket)
+	}
+
+	if err := h.consumeQuota(ctx, quotaCartMutation, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	cart, err := h.client.AddToCart(ctx, productCode, quantity)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to add to cart: %v", err)), nil
 	}
 
+	h.forgetRemovedItems(cart)
+	h.recordOrigin(ctx, productCode, source, "", quantity)
+	h.tagMember(productCode, member)
+
 	return mcp.NewToolResultJSON(cart)
 }
 
 func (h *ToolHandler) ViewCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	cart, err := h.client.GetCart(ctx)
+	groupBy := mcp.ParseString(request, "group_by", CartGroupNone)
+	sortBy := mcp.ParseString(request, "sort_by", CartSortDefault)
+	if err := validateCartView(groupBy, sortBy); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	var deliveryDate time.Time
+	if date := mcp.ParseString(request, "delivery_date", ""); date != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			return mcp.NewToolResultError("delivery_date must be in YYYY-MM-DD format"), nil
+		}
+		deliveryDate = parsed
+	}
+
+	cart, state, err := h.currentCart(ctx, mcp.ParseBoolean(request, "refresh", false))
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
 	}
 
-	return mcp.NewToolResultJSON(cart)
+	view := h.annotateCart(cart)
+	view.CartState = &state
+	view.StockWarnings = willys.StockWarnings(cart.Items, deliveryDate, time.Now())
+	sortCartItems(view.Items, sortBy)
+	if groupBy != CartGroupNone {
+		view.Groups = groupCartItems(view.Items, groupBy)
+		view.Items = []annotatedCartItem{}
+	}
+
+	fields := getStringSlice(request.GetArguments(), "fields")
+	if len(fields) == 0 {
+		return mcp.NewToolResultJSON(view)
+	}
+
+	generic, err := toJSONValue(view)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to select fields: %v", err)), nil
+	}
+	result := generic.(map[string]any)
+	if items, ok := result["items"]; ok {
+		result["items"] = selectFieldsInList(items, fields)
+	}
+	if groups, ok := result["groups"].([]any); ok {
+		for _, g := range groups {
+			if group, ok := g.(map[string]any); ok {
+				group["items"] = selectFieldsInList(group["items"], fields)
+			}
+		}
+	}
+
+	return mcp.NewToolResultJSON(result)
 }
 
 func (h *ToolHandler) RemoveFromCart(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -98,47 +260,82 @@ func (h *ToolHandler) RemoveFromCart(ctx context.Context, request mcp.CallToolRe
 
 	quantity := mcp.ParseInt(request, "quantity", 0)
 
+	if err := h.consumeQuota(ctx, quotaCartMutation, 1); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	cart, err := h.client.RemoveFromCart(ctx, productCode, quantity)
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to remove from cart: %v", err)), nil
 	}
+	h.forgetRemovedItems(cart)
 
 	return mcp.NewToolResultJSON(cart)
 }
 
-func (h *ToolHandler) SelectDeliveryTime(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	addressData := mcp.ParseStringMap(request, "address", nil)
-	if addressData == nil {
-		return mcp.NewToolResultError("address parameter is required"), nil
+func (h *ToolHandler) CartClimateReport(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	cart, err := h.client.GetCart(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get cart: %v", err)), nil
 	}
 
-	address := willys.DeliveryAddress{
-		FirstName:       getStringField(addressData, "first_name"),
-		LastName:        getStringField(addressData, "last_name"),
-		Address:         getStringField(addressData, "address"),
-		PostalCode:      getStringField(addressData, "postal_code"),
-		City:            getStringField(addressData, "city"),
-		DoorCode:        getStringField(addressData, "door_code"),
-		MessageToDriver: getStringField(addressData, "message_to_driver"),
+	return mcp.NewToolResultJSON(willys.BuildClimateReport(cart))
+}
+
+// checkoutQRScale is the pixels per QR module; large enough to scan off a laptop screen.
+const checkoutQRScale = 8
+
+// maxSlotAlternatives caps nearest_alternatives when the requested slot isn't available.
+const maxSlotAlternatives = 5
+
+func (h *ToolHandler) SelectDeliveryTime(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	var last *DeliverySettings
+	if mcp.ParseBoolean(request, "use_last", false) {
+		var err error
+		if last, err = h.lastDeliverySettings(); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
 	}
 
-	deliveryDate := mcp.ParseString(request, "delivery_date", "")
-	if deliveryDate == "" {
-		return mcp.NewToolResultError("delivery_date parameter is required"), nil
+	// With use_last, arguments that are given still win over the remembered settings
+	var address willys.DeliveryAddress
+	var err error
+	if last != nil && !hasAddressArgument(request) {
+		address = last.Address
+	} else if address, err = h.resolveAddress(ctx, request); err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
 	}
 
+	slotSpec := mcp.ParseString(request, "slot_spec", "")
+	deliveryDate := mcp.ParseString(request, "delivery_date", "")
 	timeSlot := mcp.ParseString(request, "time_slot", "")
-	if timeSlot == "" {
-		return mcp.NewToolResultError("time_slot parameter is required"), nil
+	if last != nil && slotSpec == "" && deliveryDate != "" && timeSlot == "" {
+		timeSlot = last.Window // the last window on the given date
 	}
+	useLastSlot := last != nil && slotSpec == "" && deliveryDate == "" && timeSlot == ""
 
-	startTime, endTime, err := willys.ValidateTimeSlot(timeSlot)
-	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid time slot: %v", err)), nil
+	if slotSpec == "" && !useLastSlot {
+		if deliveryDate == "" {
+			return mcp.NewToolResultError("delivery_date parameter is required (or use slot_spec)"), nil
+		}
+		if timeSlot == "" {
+			return mcp.NewToolResultError("time_slot parameter is required (or use slot_spec)"), nil
+		}
 	}
 
-	if err := willys.ValidateDeliveryDate(deliveryDate); err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("invalid delivery date: %v", err)), nil
+	var spec *willys.SlotSpec
+	var startTime, endTime string
+	if slotSpec != "" {
+		if spec, err = willys.ParseSlotSpec(slotSpec, time.Now()); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid slot_spec: %v", err)), nil
+		}
+	} else if !useLastSlot {
+		if startTime, endTime, err = willys.ValidateTimeSlot(timeSlot); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid time slot: %v", err)), nil
+		}
+		if err := willys.ValidateDeliveryDate(deliveryDate); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid delivery date: %v", err)), nil
+		}
 	}
 
 	availableSlots, err := h.client.GetAvailableTimeSlots(ctx, address.PostalCode)
@@ -151,45 +348,81 @@ func (h *ToolHandler) SelectDeliveryTime(ctx context.Context, request mcp.CallTo
 	}
 
 	var matchedSlot *willys.TimeSlot
-	for i := range availableSlots {
-		slot := &availableSlots[i]
-		if slot.Date == deliveryDate && slot.StartTime == startTime && slot.EndTime == endTime && slot.Available {
-			matchedSlot = slot
-			break
+	if spec != nil {
+		matchedSlot, _ = spec.Resolve(availableSlots)
+		for _, holiday := range spec.Holidays {
+			warnHolidaySlots(ctx, holiday)
 		}
-	}
-
-	if matchedSlot == nil {
-		var availableTimes []string
-		slotsByDate := make(map[string][]string)
-		for _, slot := range availableSlots {
-			if slot.Available {
-				timeRange := fmt.Sprintf("%s-%s", slot.StartTime, slot.EndTime)
-				slotsByDate[slot.Date] = append(slotsByDate[slot.Date], timeRange)
+		if matchedSlot != nil && spec.Fallback[matchedSlot.Date] && !spec.Dates[matchedSlot.Date] {
+			addWarning(ctx, Warning{
+				Code:    WarningHolidaySlots,
+				Message: fmt.Sprintf("No matching slot on the holiday, so the slot on %s, the day before, was chosen", matchedSlot.Date),
+			})
+		}
+	} else if useLastSlot {
+		matchedSlot = last.preferredSlot(availableSlots)
+	} else {
+		for i := range availableSlots {
+			slot := &availableSlots[i]
+			if slot.Date == deliveryDate && slot.StartTime == startTime && slot.EndTime == endTime && slot.Available {
+				matchedSlot = slot
+				break
 			}
 		}
+	}
 
-		for date, times := range slotsByDate {
-			availableTimes = append(availableTimes, fmt.Sprintf("%s: %s", date, strings.Join(times, ", ")))
+	if matchedSlot == nil {
+		requested := fmt.Sprintf("%s %s-%s", deliveryDate, startTime, endTime)
+		refDate, refTime := deliveryDate, startTime
+		switch {
+		case spec != nil:
+			requested = fmt.Sprintf("%q", slotSpec)
+			now := time.Now()
+			refDate, refTime = now.Format("2006-01-02"), now.Format("15:04")
+		case useLastSlot:
+			requested = fmt.Sprintf("the last window %s", last.Window)
+			refDate, refTime = time.Now().Format("2006-01-02"), last.windowStart()
 		}
 
-		return mcp.NewToolResultError(fmt.Sprintf(
-			"No matching time slot found for %s %s-%s. Available slots:\n%s\nPlease use get_available_time_slots tool to see all options.",
-			deliveryDate, startTime, endTime, strings.Join(availableTimes, "\n"),
-		)), nil
+		return mcp.NewToolResultJSON(map[string]any{
+			"selected":             false,
+			"message":              fmt.Sprintf("No available time slot matches %s; offer one of the nearest alternatives", requested),
+			"nearest_alternatives": willys.NearestSlots(availableSlots, refDate, refTime, maxSlotAlternatives),
+		})
 	}
 
 	slot := *matchedSlot
 
-	deliveryInfo, err := h.client.SetupDelivery(ctx, address, slot)
+	var deliveryInfo *willys.DeliveryInfo
+	err = h.nonInterruptible(ctx, "select_delivery_time", func(ctx context.Context) error {
+		var err error
+		deliveryInfo, err = h.client.SetupDelivery(ctx, address, slot)
+		return err
+	})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("failed to setup delivery: %v", err)), nil
 	}
+	h.recordDelivery(deliveryInfo)
+	h.rememberDeliverySettings(deliveryInfo)
 
 	return mcp.NewToolResultJSON(deliveryInfo)
 }
 
+// Page sizes of get_available_time_slots; two weeks of slots can run to hundreds.
+const (
+	slotsPageSize    = 50
+	maxSlotsPageSize = 200
+)
+
 func (h *ToolHandler) GetAvailableTimeSlots(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	if cursor := mcp.ParseString(request, "cursor", ""); cursor != "" {
+		p, err := resumePage[willys.TimeSlot](ctx, h, "get_available_time_slots", cursor)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultJSON(p.toResult(map[string]any{"slots": p.Items}))
+	}
+
 	postalCode := mcp.ParseString(request, "postal_code", "")
 	if postalCode == "" {
 		return mcp.NewToolResultError("postal_code parameter is required"), nil
@@ -200,18 +433,177 @@ func (h *ToolHandler) GetAvailableTimeSlots(ctx context.Context, request mcp.Cal
 		return mcp.NewToolResultError(fmt.Sprintf("failed to get time slots: %v", err)), nil
 	}
 
-	return mcp.NewToolResultJSON(map[string]any{
-		"slots": slots,
-		"count": len(slots),
+	p := pageOf(ctx, h, "get_available_time_slots", slots, pageSizeParam(request, slotsPageSize, maxSlotsPageSize))
+	result := p.toResult(map[string]any{"slots": p.Items})
+	if len(slots) > 0 {
+		// Slots come in date order
+		holidays := willys.HolidaysBetween(slots[0].Date, slots[len(slots)-1].Date)
+		for _, holiday := range holidays {
+			warnHolidaySlots(ctx, holiday)
+		}
+		if len(holidays) > 0 {
+			result["holidays"] = holidays
+		}
+	}
+
+	return mcp.NewToolResultJSON(result)
+}
+
+// warnHolidaySlots tells the agent that slots on and around holiday sell out early.
+func warnHolidaySlots(ctx context.Context, holiday willys.Holiday) {
+	addWarning(ctx, Warning{
+		Code:    WarningHolidaySlots,
+		Message: fmt.Sprintf("%s is %s; delivery slots around holidays are scarce and sell out early, so book soon or pick another day", holiday.Date, holiday.Name),
 	})
 }
 
+func (h *ToolHandler) CheckDeliverability(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	postalCode := mcp.ParseString(request, "postal_code", "")
+	if postalCode == "" {
+		return mcp.NewToolResultError("postal_code parameter is required"), nil
+	}
+
+	report, err := h.client.GetDeliverability(ctx, postalCode)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to check deliverability: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(report)
+}
+
 func (h *ToolHandler) ProceedToCheckout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	checkoutURL := h.client.GetCheckoutURL()
 
-	return mcp.NewToolResultJSON(map[string]any{
+	h.mu.RLock()
+	links := willys.NewCheckoutLinks(checkoutURL, h.appLinkBase)
+	h.mu.RUnlock()
+
+	// A business account defaults to invoice
+	paymentType := ""
+	if h.client.B2B() {
+		paymentType = willys.PaymentTypeInvoice
+	}
+
+	// Without a payment type the account's default at checkout stands
+	paymentType = mcp.ParseString(request, "payment_type", paymentType)
+	if paymentType != "" {
+		if err := h.client.SetPaymentType(ctx, paymentType); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("failed to set payment type: %v", err)), nil
+		}
+	}
+
+	result := map[string]any{
 		"checkout_url": checkoutURL,
-		"message":      "Visit this URL to complete payment",
+		"links":        links,
+		"message":      "Open the web link on a computer or the app link on a phone to complete payment; either has to be logged in to the same Willys account",
+	}
+	if paymentType != "" {
+		result["payment_type"] = paymentType
+	}
+
+	// What the user should find on arrival, so a mismatch (another account, an expired
+	// slot) is noticed. Both are best effort: the links work without them.
+	checkoutContext := map[string]any{}
+	if cart, _, err := h.currentCart(ctx, false); err == nil {
+		checkoutContext["item_count"] = cart.ItemCount
+		checkoutContext["total"] = cart.FinalTotal
+		if cart.GUID != "" {
+			checkoutContext["cart_id"] = cart.GUID
+		}
+	}
+	if booked, err := h.latestDelivery(); err == nil && booked != nil {
+		checkoutContext["slot"] = booked.Delivery.TimeSlot
+	}
+	if len(checkoutContext) > 0 {
+		result["context"] = checkoutContext
+	}
+
+	if !mcp.ParseBoolean(request, "qr_code", false) {
+		return mcp.NewToolResultJSON(result)
+	}
+
+	target := links.App
+	switch link := mcp.ParseString(request, "qr_link", "app"); link {
+	case "app":
+	case "web":
+		target = links.Web
+	default:
+		return mcp.NewToolResultError(fmt.Sprintf("unknown qr_link %q (use app or web)", link)), nil
+	}
+	qr, err := export.EncodeQR(target)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to encode QR code: %v", err)), nil
+	}
+	image, err := qr.PNG(checkoutQRScale)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to render QR code: %v", err)), nil
+	}
+	result["qr_code_url"] = target
+
+	toolResult, err := mcp.NewToolResultJSON(result)
+	if err != nil {
+		return nil, err
+	}
+	toolResult.Content = append(toolResult.Content, mcp.NewImageContent(base64.StdEncoding.EncodeToString(image), "image/png"))
+	return toolResult, nil
+}
+
+func (h *ToolHandler) DiagnoseCheckout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	diagnosis, err := h.client.DiagnoseCheckout(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to diagnose checkout: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(diagnosis)
+}
+
+func (h *ToolHandler) ListPaymentMethods(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	methods, err := h.client.GetPaymentMethods(ctx)
+	if err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to get payment methods: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"saved_cards":      methods.SavedCards,
+		"invoice_eligible": methods.InvoiceEligible,
+		"available_types":  methods.AvailableTypes,
+		"default_type":     methods.DefaultType,
+		"default_card":     methods.DefaultCard(),
+	})
+}
+
+func (h *ToolHandler) Logout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	logoutErr := h.client.Logout(ctx)
+
+	// The saved refresh token would log the next start straight back in. It is deleted
+	// after the client logout so a token rotated meanwhile isn't saved again.
+	if err := h.store.Delete(store.BucketAuth, store.KeyRefreshToken); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("session ended but failed to delete the saved refresh token: %v", err)), nil
+	}
+
+	if err := logoutErr; err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("local session cleared but logout request failed: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"logged_out": true,
+		"message":    "Session ended and credentials cleared. Restart the server to log in again.",
+	})
+}
+
+func (h *ToolHandler) SubmitVerificationCode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	code := strings.TrimSpace(mcp.ParseString(request, "code", ""))
+	if code == "" {
+		return mcp.NewToolResultError("code parameter is required"), nil
+	}
+
+	if err := h.client.SubmitVerificationCode(code); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("failed to submit verification code: %v", err)), nil
+	}
+
+	return mcp.NewToolResultJSON(map[string]any{
+		"submitted": true,
+		"message":   "Code submitted, login will finish in a few seconds. Check admin_auth_status if tools still fail.",
 	})
 }
 
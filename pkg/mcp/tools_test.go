@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/effati/willys-mcp/internal/store"
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type checkoutClient struct {
+	warningsClient
+	b2b bool
+}
+
+func (c *checkoutClient) GetCheckoutURL() string {
+	return "https://web.example/kassa"
+}
+
+func (c *checkoutClient) B2B() bool {
+	return c.b2b
+}
+
+func TestProceedToCheckoutQRCode(t *testing.T) {
+	h := NewToolHandler(&checkoutClient{warningsClient: warningsClient{cart: &willys.CartSummary{ItemCount: 3, FinalTotal: willys.SEK(25000)}}})
+	h.appLinkBase = "https://www.willys.se"
+
+	result, err := h.ProceedToCheckout(context.Background(), toolRequest(map[string]any{"qr_code": true}))
+	if err != nil || result.IsError {
+		t.Fatalf("ProceedToCheckout failed: %v %+v", err, result)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected JSON and an image, got %d contents", len(result.Content))
+	}
+
+	var body struct {
+		Links     willys.CheckoutLinks `json:"links"`
+		QRCodeURL string               `json:"qr_code_url"`
+		Context   map[string]any       `json:"context"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &body); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if body.Links.App != "https://www.willys.se/kassa" || body.QRCodeURL != body.Links.App {
+		t.Errorf("Expected the app link in the QR code, got %+v", body)
+	}
+	if body.Context["item_count"] != float64(3) {
+		t.Errorf("Expected the cart in the context, got %v", body.Context)
+	}
+
+	image := result.Content[1].(mcp.ImageContent)
+	data, err := base64.StdEncoding.DecodeString(image.Data)
+	if err != nil || image.MIMEType != "image/png" {
+		t.Fatalf("Expected a base64 PNG, got %s (%v)", image.MIMEType, err)
+	}
+	if _, err := png.Decode(strings.NewReader(string(data))); err != nil {
+		t.Errorf("Invalid PNG: %v", err)
+	}
+
+	result, _ = h.ProceedToCheckout(context.Background(), toolRequest(map[string]any{"qr_code": true, "qr_link": "sms"}))
+	if !result.IsError {
+		t.Error("Expected an unknown qr_link to be rejected")
+	}
+}
+
+type paymentTypeClient struct {
+	checkoutClient
+	paymentType string
+}
+
+func (c *paymentTypeClient) SetPaymentType(ctx context.Context, paymentType string) error {
+	c.paymentType = paymentType
+	return nil
+}
+
+func TestProceedToCheckoutPaymentType(t *testing.T) {
+	client := &paymentTypeClient{checkoutClient: checkoutClient{warningsClient: warningsClient{cart: &willys.CartSummary{}}}}
+	h := NewToolHandler(client)
+
+	if result, _ := h.ProceedToCheckout(context.Background(), toolRequest(nil)); result.IsError || client.paymentType != "" {
+		t.Errorf("Expected the account default for a consumer account, got %q", client.paymentType)
+	}
+
+	client.b2b = true
+	if result, _ := h.ProceedToCheckout(context.Background(), toolRequest(nil)); result.IsError || client.paymentType != willys.PaymentTypeInvoice {
+		t.Errorf("Expected invoice for a business account, got %q", client.paymentType)
+	}
+	if result, _ := h.ProceedToCheckout(context.Background(), toolRequest(map[string]any{"payment_type": "card"})); result.IsError || client.paymentType != willys.PaymentTypeCard {
+		t.Errorf("Expected an explicit payment type to win, got %q", client.paymentType)
+	}
+}
+
+type logoutClient struct {
+	warningsClient
+	loggedOut bool
+}
+
+func (c *logoutClient) Logout(ctx context.Context) error {
+	c.loggedOut = true
+	return nil
+}
+
+func TestLogoutDeletesSavedRefreshToken(t *testing.T) {
+	client := &logoutClient{}
+	h := NewToolHandler(client)
+	if err := h.store.Put(store.BucketAuth, store.KeyRefreshToken, []byte("refresh")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := h.Logout(context.Background(), toolRequest(nil))
+	if err != nil || result.IsError {
+		t.Fatalf("Logout failed: %v %+v", err, result)
+	}
+	if !client.loggedOut {
+		t.Error("Expected the client session to be ended")
+	}
+	if _, err := h.store.Get(store.BucketAuth, store.KeyRefreshToken); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Expected the refresh token to be deleted, got %v", err)
+	}
+}
+
+func TestPurgeLocalDataRemovesExports(t *testing.T) {
+	h := NewToolHandler(nil)
+	h.exportDir = filepath.Join(t.TempDir(), "exports")
+	if err := os.MkdirAll(h.exportDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(h.exportDir, "delivery.ics"), []byte("BEGIN:VCALENDAR"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := h.PurgeLocalData(context.Background(), toolRequest(map[string]any{"confirm": true}))
+	if err != nil || result.IsError {
+		t.Fatalf("PurgeLocalData failed: %v %+v", err, result)
+	}
+	if _, err := os.Stat(h.exportDir); !os.IsNotExist(err) {
+		t.Errorf("Expected the exports to be removed, got %v", err)
+	}
+}
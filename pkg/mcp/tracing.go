@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/effati/willys-mcp/pkg/mcp")
+
+// withTracing wraps a tool call in a span; upstream requests made by the handler become
+// child spans through the context.
+func withTracing(toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ctx, span := tracer.Start(ctx, "tool "+toolName)
+		defer span.End()
+
+		span.SetAttributes(
+			attribute.String("mcp.tool.name", toolName),
+			attribute.String("mcp.session.id", sessionIDFromContext(ctx)),
+			attribute.String("willys.correlation_id", willys.CorrelationIDFromContext(ctx)),
+		)
+
+		result, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else if result != nil && result.IsError {
+			span.SetStatus(codes.Error, "tool returned an error result")
+		}
+
+		return result, err
+	}
+}
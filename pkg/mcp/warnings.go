@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	WarningDietaryConflict = "dietary_conflict"
+	WarningBudgetNearing   = "budget_nearing"
+	WarningBudgetExceeded  = "budget_exceeded"
+	WarningSlotExpiring    = "slot_expiring"
+	WarningPriceIncrease   = "price_increase"
+	WarningHolidaySlots    = "holiday_slots"
+
+	// budgetWarningShare of the weekly budget is where the cart starts to warn.
+	budgetWarningShare = 0.9
+
+	// slotWarningLead is how long before a booked slot's cut-off every tool warns.
+	slotWarningLead = 2 * time.Hour
+)
+
+// cartWarningTools are the tools after which the cart is checked against the diet,
+// budget, and previous prices.
+var cartWarningTools = map[string]bool{
+	"add_to_cart":        true,
+	"list_to_cart":       true,
+	"parse_ingredients":  true,
+	"apply_template":     true,
+	"commit_basket":      true,
+	"remove_from_cart":   true,
+	"optimize_cart_cost": true,
+	"view_cart":          true,
+}
+
+type (
+	// Warning is a caveat the agent should pass on, returned in the warnings array of
+	// every successful tool result.
+	Warning struct {
+		Code        string `json:"code"`
+		Message     string `json:"message"`
+		ProductCode string `json:"productCode,omitempty"`
+	}
+
+	warningCollector struct {
+		mu       sync.Mutex
+		warnings []Warning
+	}
+
+	warningsKey struct{}
+)
+
+// addWarning lets a handler report a warning of its own alongside the cross-cutting ones.
+func addWarning(ctx context.Context, w Warning) {
+	if c, ok := ctx.Value(warningsKey{}).(*warningCollector); ok {
+		c.mu.Lock()
+		c.warnings = append(c.warnings, w)
+		c.mu.Unlock()
+	}
+}
+
+// withWarnings runs the cross-cutting checks after a tool and adds their warnings, and
+// any the handler reported, to its result.
+func (h *ToolHandler) withWarnings(toolName string, next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		collector := &warningCollector{}
+		ctx = context.WithValue(ctx, warningsKey{}, collector)
+
+		result, err := next(ctx, request)
+		if err != nil || result == nil || result.IsError {
+			return result, err
+		}
+
+		warnings := append(collector.warnings, h.collectWarnings(ctx, toolName, time.Now())...)
+		attachWarnings(result, warnings)
+		return result, nil
+	}
+}
+
+func (h *ToolHandler) collectWarnings(ctx context.Context, toolName string, now time.Time) []Warning {
+	var warnings []Warning
+	if w := h.slotWarning(now); w != nil {
+		warnings = append(warnings, *w)
+	}
+	if !cartWarningTools[toolName] || h.client == nil {
+		return warnings
+	}
+
+	cart, _, err := h.currentCart(ctx, false)
+	if err != nil {
+		log.Printf("Skipping cart warnings: %v", err)
+		return warnings
+	}
+	return append(warnings, h.cartWarnings(ctx, cart)...)
+}
+
+func (h *ToolHandler) slotWarning(now time.Time) *Warning {
+	booked, err := h.latestDelivery()
+	if err != nil || booked == nil {
+		return nil
+	}
+	cutoff := booked.Delivery.TimeSlot.CutoffTime
+	if cutoff.IsZero() || now.After(cutoff) || cutoff.Sub(now) > slotWarningLead {
+		return nil
+	}
+	slot := booked.Delivery.TimeSlot
+	return &Warning{
+		Code: WarningSlotExpiring,
+		Message: fmt.Sprintf("The order for the %s %s-%s delivery can only be changed for %d more minutes",
+			slot.Date, slot.StartTime, slot.EndTime, int(cutoff.Sub(now).Minutes())),
+	}
+}
+
+func (h *ToolHandler) cartWarnings(ctx context.Context, cart *willys.CartSummary) []Warning {
+	diet, budget := h.dietFor(ctx), h.budgetFor(ctx)
+
+	var warnings []Warning
+	for _, c := range willys.DietaryConflicts(cart.Items, diet) {
+		warnings = append(warnings, Warning{
+			Code:        WarningDietaryConflict,
+			Message:     fmt.Sprintf("%s may not be %s", c.Name, c.Diet),
+			ProductCode: c.Code,
+		})
+	}
+
+	switch total := cart.FinalTotal; reviewBudget(total, budget).Status {
+	case BudgetExceeded:
+		warnings = append(warnings, Warning{
+			Code:    WarningBudgetExceeded,
+			Message: fmt.Sprintf("The cart total %s is over the weekly budget of %s", total, budget),
+		})
+	case BudgetNearing:
+		warnings = append(warnings, Warning{
+			Code:    WarningBudgetNearing,
+			Message: fmt.Sprintf("The cart total %s is close to the weekly budget of %s (%s left)", total, budget, budget.Sub(total)),
+		})
+	}
+
+	history, err := h.client.PurchaseHistory(ctx)
+	if err != nil {
+		return warnings
+	}
+	for _, change := range history.PriceChangesSinceLastPurchase(cart.Items, h.alertPercent()) {
+		if !change.Alert {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Code:        WarningPriceIncrease,
+			Message:     fmt.Sprintf("%s costs %s, up %.0f%% from %s last time", change.Name, change.Now, change.ChangePercent, change.Was),
+			ProductCode: change.Code,
+		})
+	}
+	return warnings
+}
+
+func (h *ToolHandler) alertPercent() float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.priceAlertPercent
+}
+
+// attachWarnings adds a warnings array to a JSON object result, in both the text and
+// the structured content. Other results get the warnings as an extra text block, and
+// only when there are any.
+func attachWarnings(result *mcp.CallToolResult, warnings []Warning) {
+	if warnings == nil {
+		warnings = []Warning{}
+	}
+
+	for i, content := range result.Content {
+		text, ok := mcp.AsTextContent(content)
+		if !ok {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader([]byte(text.Text)))
+		decoder.UseNumber()
+		var object map[string]any
+		if decoder.Decode(&object) != nil || object == nil {
+			break
+		}
+		object["warnings"] = warnings
+		data, err := json.Marshal(object)
+		if err != nil {
+			break
+		}
+		text.Text = string(data)
+		result.Content[i] = *text
+		result.StructuredContent = object
+		return
+	}
+
+	if len(warnings) > 0 {
+		data, _ := json.Marshal(map[string]any{"warnings": warnings})
+		result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	}
+}
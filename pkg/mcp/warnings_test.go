@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/effati/willys-mcp/internal/willys"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// warningsClient serves a fixed cart and purchase history; other calls panic.
+type warningsClient struct {
+	willys.WillysAPI
+	cart    *willys.CartSummary
+	history *willys.PurchaseHistory
+}
+
+func (c *warningsClient) GetCart(ctx context.Context) (*willys.CartSummary, error) {
+	return c.cart, nil
+}
+
+func (c *warningsClient) CachedCart() (*willys.CartSummary, time.Time, bool) {
+	return nil, time.Time{}, false
+}
+
+func (c *warningsClient) PurchaseHistory(ctx context.Context) (*willys.PurchaseHistory, error) {
+	return c.history, nil
+}
+
+func warningCodes(warnings []Warning) map[string]int {
+	codes := make(map[string]int)
+	for _, w := range warnings {
+		codes[w.Code]++
+	}
+	return codes
+}
+
+func TestCollectWarnings(t *testing.T) {
+	client := &warningsClient{
+		cart: &willys.CartSummary{
+			Items: []willys.CartItem{
+				{ProductCode: "mince", Name: "Nötfärs 12%", Price: willys.SEK(5990)},
+				{ProductCode: "milk", Name: "Mellanmjölk 1,5%", Price: willys.SEK(1790)},
+			},
+			FinalTotal: willys.SEK(95000),
+		},
+		history: &willys.PurchaseHistory{LastPrices: map[string]willys.Money{"mince": willys.SEK(5490), "milk": willys.SEK(1790)}},
+	}
+	h := NewToolHandler(client)
+	h.diet = []string{willys.DietVegetarian}
+	h.weeklyBudget = willys.SEK(100000)
+
+	now := time.Now()
+	h.recordDelivery(&willys.DeliveryInfo{TimeSlot: willys.TimeSlot{Date: "2026-10-20", StartTime: "17:00", EndTime: "19:00", CutoffTime: now.Add(30 * time.Minute)}})
+
+	codes := warningCodes(h.collectWarnings(context.Background(), "add_to_cart", now))
+	expected := map[string]int{WarningSlotExpiring: 1, WarningDietaryConflict: 1, WarningBudgetNearing: 1, WarningPriceIncrease: 1}
+	if len(codes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, codes)
+	}
+	for code, n := range expected {
+		if codes[code] != n {
+			t.Errorf("Expected %d %s warning(s), got %v", n, code, codes)
+		}
+	}
+
+	// The 9% rise on the mince is under a 10% threshold
+	h.priceAlertPercent = 10
+	if codes := warningCodes(h.collectWarnings(context.Background(), "add_to_cart", now)); codes[WarningPriceIncrease] != 0 {
+		t.Errorf("Expected no price warning under the threshold, got %v", codes)
+	}
+
+	// Tools that don't touch the cart only get the slot check
+	codes = warningCodes(h.collectWarnings(context.Background(), "search_groceries", now))
+	if len(codes) != 1 || codes[WarningSlotExpiring] != 1 {
+		t.Errorf("Expected only the slot warning, got %v", codes)
+	}
+}
+
+func TestAttachWarnings(t *testing.T) {
+	result, _ := mcp.NewToolResultJSON(map[string]any{"count": 2})
+	attachWarnings(result, nil)
+
+	var object map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &object); err != nil {
+		t.Fatalf("Expected JSON text, got error: %v", err)
+	}
+	if warnings, ok := object["warnings"].([]any); !ok || len(warnings) != 0 || object["count"] != float64(2) {
+		t.Errorf("Expected an empty warnings array next to count, got %v", object)
+	}
+
+	result, _ = mcp.NewToolResultJSON([]string{"a"})
+	attachWarnings(result, []Warning{{Code: WarningBudgetNearing, Message: "close"}})
+	if len(result.Content) != 2 {
+		t.Errorf("Expected warnings as a separate block for non-object results, got %+v", result.Content)
+	}
+}
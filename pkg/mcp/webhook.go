@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/effati/willys-mcp/internal/willys"
+)
+
+// webhookSource is the added_by origin of items from the add-item webhook.
+const webhookSource = "webhook"
+
+// WithWebhook enables POST /hooks/add-item in HTTP mode, authenticated with secret.
+// Items go into the draft basket named basket, to be reviewed and committed later, or
+// straight into the cart when basket is empty.
+func WithWebhook(secret, basket string) ServerOption {
+	return func(s *Server) {
+		s.webhookSecret = secret
+		s.webhookBasket = basket
+	}
+}
+
+// hookAddItem takes {"query": "mjölk", "qty": 1}. The secret comes in the
+// X-Webhook-Secret header or, for voice platforms that can't set headers, the secret
+// query parameter.
+func (s *Server) hookAddItem(w http.ResponseWriter, r *http.Request) {
+	secret := r.Header.Get("X-Webhook-Secret")
+	if secret == "" {
+		secret = r.URL.Query().Get("secret")
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(s.webhookSecret)) != 1 {
+		writeRESTError(w, http.StatusUnauthorized, "missing or invalid webhook secret")
+		return
+	}
+
+	var body webhookRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRESTBody)).Decode(&body); err != nil {
+		writeRESTError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON body: %v", err))
+		return
+	}
+	body.Query = strings.TrimSpace(body.Query)
+	if body.Query == "" {
+		writeRESTError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	h := s.toolHandler
+	for _, category := range []string{quotaSearch, quotaCartMutation} {
+		if err := h.consumeQuota(r.Context(), category, 1); err != nil {
+			writeRESTError(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+	}
+
+	result, err := h.addWebhookItem(r.Context(), listItem{Query: body.Query, Quantity: max(body.Qty, 1)}, s.webhookBasket)
+	if err != nil {
+		writeRESTError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+type webhookRequest struct {
+	Query string `json:"query" jsonschema:"description=What to add, in the user's words"`
+	Qty   int    `json:"qty,omitempty" jsonschema:"description=Quantity (default 1)"`
+}
+
+// WebhookResult is what the webhook answers; Speech is a short sentence a voice
+// assistant can read back.
+type WebhookResult struct {
+	Added    bool   `json:"added"`
+	Query    string `json:"query"`
+	Quantity int    `json:"quantity"`
+	Product  string `json:"product,omitempty"`
+	Code     string `json:"code,omitempty"`
+	Basket   string `json:"basket,omitempty"`
+	Speech   string `json:"speech"`
+}
+
+func (h *ToolHandler) addWebhookItem(ctx context.Context, item listItem, basket string) (*WebhookResult, error) {
+	if basket != "" {
+		name, err := normalizeBasketName(basket)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WILLYS_WEBHOOK_BASKET: %w", err)
+		}
+		if err := h.ensureBasket(name); err != nil {
+			return nil, err
+		}
+		basket = name
+	}
+
+	h.mu.RLock()
+	policy := h.pickPolicy
+	h.mu.RUnlock()
+
+	results, added := h.addListItems(ctx, []listItem{item}, policy, webhookSource, basket)
+	result := &WebhookResult{Added: added > 0, Query: item.Query, Quantity: item.Quantity, Basket: basket}
+	if pick, ok := results[0]["pick"].(willys.PickResult); ok && pick.Product != nil {
+		result.Product, result.Code = pick.Product.Name, pick.Product.Code
+	}
+
+	switch {
+	case result.Added && basket != "":
+		result.Speech = fmt.Sprintf("Added %d %s to the %s list", item.Quantity, result.Product, basket)
+	case result.Added:
+		result.Speech = fmt.Sprintf("Added %d %s to the Willys cart", item.Quantity, result.Product)
+	case result.Product == "":
+		result.Speech = fmt.Sprintf("Couldn't find %s at Willys", item.Query)
+	default:
+		if msg, ok := results[0]["error"].(string); ok {
+			return nil, fmt.Errorf("failed to add %s: %s", result.Product, msg)
+		}
+		result.Speech = fmt.Sprintf("Couldn't add %s", item.Query)
+	}
+	return result, nil
+}
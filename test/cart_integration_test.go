@@ -49,7 +49,7 @@ func TestAddToCart(t *testing.T) {
 	}
 
 	t.Logf("✓ Product added to cart successfully")
-	t.Logf("✓ Cart total: %.2f kr (%d items)", cart.TotalPrice, cart.ItemCount)
+	t.Logf("✓ Cart total: %s (%d items)", cart.TotalPrice, cart.ItemCount)
 
 	_, err = client.RemoveFromCart(context.Background(), productCode, 0)
 	if err != nil {
@@ -77,7 +77,7 @@ func TestViewCart(t *testing.T) {
 	}
 
 	t.Logf("✓ Cart retrieved successfully")
-	t.Logf("✓ Cart has %d items, total: %.2f kr", cart.ItemCount, cart.TotalPrice)
+	t.Logf("✓ Cart has %d items, total: %s", cart.ItemCount, cart.TotalPrice)
 }
 
 func TestRemoveFromCart(t *testing.T) {
@@ -111,6 +111,6 @@ func TestSetupDelivery(t *testing.T) {
 	}
 
 	t.Logf("✓ Delivery setup successful")
-	t.Logf("✓ Delivery fee: %.2f kr, Picking fee: %.2f kr, Total: %.2f kr",
+	t.Logf("✓ Delivery fee: %s, Picking fee: %s, Total: %s",
 		deliveryInfo.DeliveryFee, deliveryInfo.PickingFee, deliveryInfo.TotalFee)
 }
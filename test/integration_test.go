@@ -64,7 +64,7 @@ func TestCompleteShoppingWorkflow(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Add to cart failed: %v", err)
 	}
-	t.Logf("✓ Cart total: %.2f kr (%d items)", cart.TotalPrice, cart.ItemCount)
+	t.Logf("✓ Cart total: %s (%d items)", cart.TotalPrice, cart.ItemCount)
 
 	t.Log("Step 3: Searching for bread...")
 	breadProducts, err := client.SearchProducts(context.Background(), "bröd", 0, 5, nil)
@@ -77,7 +77,7 @@ func TestCompleteShoppingWorkflow(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Add to cart failed: %v", err)
 		}
-		t.Logf("✓ Cart total: %.2f kr (%d items)", cart.TotalPrice, cart.ItemCount)
+		t.Logf("✓ Cart total: %s (%d items)", cart.TotalPrice, cart.ItemCount)
 	}
 
 	t.Log("Step 5: Viewing cart...")
@@ -87,7 +87,7 @@ func TestCompleteShoppingWorkflow(t *testing.T) {
 	}
 	t.Logf("✓ Cart contains %d items:", cart.ItemCount)
 	for _, item := range cart.Items {
-		t.Logf("  - %s x%d = %.2f kr", item.Name, item.Quantity, item.TotalPrice)
+		t.Logf("  - %s x%d = %s", item.Name, item.Quantity, item.TotalPrice)
 	}
 
 	t.Log("Step 6: Setting up delivery...")
@@ -172,7 +172,7 @@ func TestMultipleItemsWorkflow(t *testing.T) {
 		}
 
 		totalItems++
-		t.Logf("✓ Added %s to cart. Cart total: %.2f kr", products[0].Name, cart.TotalPrice)
+		t.Logf("✓ Added %s to cart. Cart total: %s", products[0].Name, cart.TotalPrice)
 	}
 
 	cart, err := client.GetCart(context.Background())
@@ -181,7 +181,7 @@ func TestMultipleItemsWorkflow(t *testing.T) {
 	}
 
 	t.Logf("✓ Final cart has %d different items", len(cart.Items))
-	t.Logf("✓ Total: %.2f kr", cart.FinalTotal)
+	t.Logf("✓ Total: %s", cart.FinalTotal)
 
 	if totalItems > 0 {
 		t.Logf("✅ Successfully added %d items to cart", totalItems)
@@ -2,39 +2,11 @@ package test
 
 import (
 	"context"
-	"fmt"
 	"testing"
 
 	"github.com/effati/willys-mcp/internal/willys"
 )
 
-func parseComparePrice(priceStr string) float64 {
-	var price float64
-	priceStr = trimSuffix(priceStr, " kr")
-	priceStr = replaceAll(priceStr, ",", ".")
-	fmt.Sscanf(priceStr, "%f", &price)
-	return price
-}
-
-func trimSuffix(s, suffix string) string {
-	if len(s) >= len(suffix) && s[len(s)-len(suffix):] == suffix {
-		return s[:len(s)-len(suffix)]
-	}
-	return s
-}
-
-func replaceAll(s, old, new string) string {
-	result := ""
-	for i := 0; i < len(s); i++ {
-		if i <= len(s)-len(old) && s[i:i+len(old)] == old {
-			result += new
-			i += len(old) - 1
-		} else {
-			result += string(s[i])
-		}
-	}
-	return result
-}
 func TestBasicProductSearch(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -64,11 +36,11 @@ func TestBasicProductSearch(t *testing.T) {
 		if p.Name == "" {
 			t.Error("Product name is empty")
 		}
-		if p.PriceValue <= 0 {
+		if p.PriceValue.Ore <= 0 {
 			t.Error("Product price is invalid")
 		}
 
-		t.Logf("✓ First product: %s (Code: %s, Price: %.2f kr)", p.Name, p.Code, p.PriceValue)
+		t.Logf("✓ First product: %s (Code: %s, Price: %s)", p.Name, p.Code, p.PriceValue)
 	}
 }
 
@@ -127,7 +99,7 @@ func TestSearchWithFiltering(t *testing.T) {
 	t.Logf("✓ Found %d products with price filter", len(products))
 
 	for _, p := range products {
-		comparePrice := parseComparePrice(p.ComparePrice)
+		comparePrice := p.ComparePrice.Float()
 		if comparePrice > 50.0 {
 			t.Errorf("Product %s exceeds price limit: %.2f kr/unit", p.Name, comparePrice)
 		}
@@ -198,8 +170,8 @@ func TestSearchWithSorting(t *testing.T) {
 
 	if len(products) > 1 {
 		for i := 1; i < len(products); i++ {
-			iPrice := parseComparePrice(products[i].ComparePrice)
-			iPrevPrice := parseComparePrice(products[i-1].ComparePrice)
+			iPrice := products[i].ComparePrice.Float()
+			iPrevPrice := products[i-1].ComparePrice.Float()
 			if iPrice < iPrevPrice {
 				t.Errorf("Products not sorted by price: product %d (%.2f) < product %d (%.2f)",
 					i, iPrice, i-1, iPrevPrice)